@@ -71,3 +71,29 @@ type PostsAttrs struct {
 	Categories  []CategoriesAttrs
 	Tags        []TagAttrs
 }
+
+type PostCoverPlaceholderAttrs struct {
+	BlurHash      string
+	DominantColor string
+	Width         int
+	Height        int
+}
+
+type PostCoverImageAttrs struct {
+	Format   string
+	Width    int
+	Height   int
+	MimeType string
+	Path     string
+}
+
+type MediaAttrs struct {
+	Path     string
+	MimeType string
+	Size     int64
+	Width    int
+	Height   int
+	Sha256   string
+	AltText  string
+	Owner    string
+}
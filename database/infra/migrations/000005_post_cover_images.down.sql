@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_post_cover_images_variant;
+DROP TABLE IF EXISTS post_cover_images;
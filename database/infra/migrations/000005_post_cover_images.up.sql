@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS post_cover_images (
+    id BIGSERIAL PRIMARY KEY,
+    post_id BIGINT NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    mime_type VARCHAR(50) NOT NULL,
+    path VARCHAR(1024) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_post FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_post_cover_images_variant ON post_cover_images (post_id, format, width);
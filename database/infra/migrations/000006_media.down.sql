@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_media_created_at;
+DROP INDEX IF EXISTS idx_media_owner;
+DROP INDEX IF EXISTS idx_media_sha256;
+DROP TABLE IF EXISTS media;
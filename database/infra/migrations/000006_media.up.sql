@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS media (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL,
+    path VARCHAR(1024) UNIQUE NOT NULL,
+    mime_type VARCHAR(50) NOT NULL,
+    size BIGINT NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    alt_text VARCHAR(255),
+    owner VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_sha256 ON media (sha256);
+CREATE INDEX IF NOT EXISTS idx_media_owner ON media (owner);
+CREATE INDEX IF NOT EXISTS idx_media_created_at ON media (created_at);
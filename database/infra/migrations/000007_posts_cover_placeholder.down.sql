@@ -0,0 +1,5 @@
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS cover_blur_hash,
+    DROP COLUMN IF EXISTS cover_dominant_color,
+    DROP COLUMN IF EXISTS cover_width,
+    DROP COLUMN IF EXISTS cover_height;
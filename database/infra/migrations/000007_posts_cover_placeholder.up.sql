@@ -0,0 +1,5 @@
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS cover_blur_hash VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS cover_dominant_color VARCHAR(7),
+    ADD COLUMN IF NOT EXISTS cover_width INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS cover_height INT NOT NULL DEFAULT 0;
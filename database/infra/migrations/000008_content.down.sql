@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS recommendations;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS links;
+DROP TABLE IF EXISTS talks;
+DROP TABLE IF EXISTS educations;
+DROP TABLE IF EXISTS experiences;
+DROP TABLE IF EXISTS profile_skills;
+DROP TABLE IF EXISTS profiles;
+DROP TABLE IF EXISTS content_sections;
@@ -0,0 +1,142 @@
+-------------------------------------------------- CONTENT SECTIONS ----------------------------------------------------
+-- One row per imported section (profile, talks, ...), holding the version its public route reports.
+CREATE TABLE IF NOT EXISTS content_sections (
+    id BIGSERIAL PRIMARY KEY,
+    slug VARCHAR(50) UNIQUE NOT NULL,
+    version VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+----------------------------------------------------- PROFILE ----------------------------------------------------------
+CREATE TABLE IF NOT EXISTS profiles (
+    id BIGSERIAL PRIMARY KEY,
+    nickname VARCHAR(255) NOT NULL,
+    handle VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(250) NOT NULL,
+    profession VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS profile_skills (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL,
+    profile_id BIGINT NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+    item VARCHAR(255) NOT NULL,
+    percentage INT NOT NULL,
+    description TEXT,
+    sort_order INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_profile_skills_profile_sort_order ON profile_skills (profile_id, sort_order);
+
+---------------------------------------------------- EXPERIENCE --------------------------------------------------------
+CREATE TABLE IF NOT EXISTS experiences (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL,
+    company VARCHAR(255) NOT NULL,
+    employment_type VARCHAR(100),
+    location_type VARCHAR(100),
+    position VARCHAR(255) NOT NULL,
+    start_date VARCHAR(50) NOT NULL,
+    end_date VARCHAR(50),
+    summary TEXT,
+    country VARCHAR(100),
+    city VARCHAR(100),
+    skills TEXT,
+    sort_order INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_experiences_sort_order ON experiences (sort_order);
+
+---------------------------------------------------- EDUCATION ---------------------------------------------------------
+CREATE TABLE IF NOT EXISTS educations (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL,
+    icon VARCHAR(2048),
+    school VARCHAR(255) NOT NULL,
+    degree VARCHAR(255) NOT NULL,
+    field VARCHAR(255),
+    description TEXT,
+    graduated_at VARCHAR(50),
+    issuing_country VARCHAR(100),
+    sort_order INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_educations_sort_order ON educations (sort_order);
+
+------------------------------------------------------ TALKS -----------------------------------------------------------
+CREATE TABLE IF NOT EXISTS talks (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    subject VARCHAR(255),
+    location VARCHAR(255),
+    url VARCHAR(2048),
+    photo VARCHAR(2048),
+    sort_order INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_talks_sort_order ON talks (sort_order);
+
+------------------------------------------------------ LINKS -----------------------------------------------------------
+CREATE TABLE IF NOT EXISTS links (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL,
+    handle VARCHAR(255),
+    url VARCHAR(2048) NOT NULL,
+    description TEXT,
+    name VARCHAR(100) NOT NULL,
+    sort_order INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_links_sort_order ON links (sort_order);
+
+----------------------------------------------------- PROJECTS ---------------------------------------------------------
+CREATE TABLE IF NOT EXISTS projects (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL,
+    sort INT NOT NULL,
+    language VARCHAR(255),
+    title VARCHAR(255) NOT NULL,
+    excerpt TEXT,
+    url VARCHAR(2048) NOT NULL,
+    icon VARCHAR(2048),
+    is_open_source BOOLEAN NOT NULL DEFAULT FALSE,
+    published_at DATE DEFAULT NULL,
+    sort_order INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_projects_sort_order ON projects (sort_order);
+
+------------------------------------------------- RECOMMENDATIONS ------------------------------------------------------
+CREATE TABLE IF NOT EXISTS recommendations (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL,
+    relation VARCHAR(255),
+    text TEXT NOT NULL,
+    featured BOOLEAN NOT NULL DEFAULT FALSE,
+    person_avatar VARCHAR(2048),
+    person_full_name VARCHAR(255) NOT NULL,
+    person_company VARCHAR(255),
+    person_designation VARCHAR(255),
+    sort_order INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_recommendations_sort_order ON recommendations (sort_order);
@@ -14,6 +14,10 @@ var schemaTables = []string{
 	"post_categories", "tags", "post_tags",
 	"post_views", "comments", "likes",
 	"newsletters", "api_keys", "api_key_signatures",
+	"post_cover_images", "media",
+	"content_sections", "profiles", "profile_skills",
+	"experiences", "educations", "talks",
+	"links", "projects", "recommendations",
 }
 
 func GetSchemaTables() []string {
@@ -95,12 +99,20 @@ type Post struct {
 	UpdatedAt     time.Time  `gorm:"default:CURRENT_TIMESTAMP"`
 	DeletedAt     gorm.DeletedAt
 
+	// Cover placeholder, painted by clients while the cover variants load.
+	CoverBlurHash      string `gorm:"type:varchar(64)"`
+	CoverDominantColor string `gorm:"type:varchar(7)"`
+	CoverWidth         int    `gorm:"not null;default:0"`
+	CoverHeight        int    `gorm:"not null;default:0"`
+
 	// Associations
 	Categories []Category `gorm:"many2many:post_categories;"`
 	Tags       []Tag      `gorm:"many2many:post_tags;"`
 	PostViews  []PostView `gorm:"foreignKey:PostID"`
 	Comments   []Comment  `gorm:"foreignKey:PostID"`
 	Likes      []Like     `gorm:"foreignKey:PostID"`
+
+	CoverImages []PostCoverImage `gorm:"foreignKey:PostID"`
 }
 
 type Category struct {
@@ -146,6 +158,39 @@ type PostTag struct {
 	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
 }
 
+// PostCoverImage is one responsive rendition of a post cover, stored under storage/media/posts.
+type PostCoverImage struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	PostID    uint64    `gorm:"not null;uniqueIndex:idx_post_cover_images_variant"`
+	Post      Post      `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE"`
+	Format    string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_post_cover_images_variant"`
+	Width     int       `gorm:"not null;uniqueIndex:idx_post_cover_images_variant"`
+	Height    int       `gorm:"not null"`
+	MimeType  string    `gorm:"type:varchar(50);not null"`
+	Path      string    `gorm:"type:varchar(1024);not null"` // Relative to storage/media, e.g. posts/<slug>/<file>.
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// Media indexes a file stored under storage/media, uploaded or mirrored from a post.
+type Media struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	UUID      string    `gorm:"type:uuid;unique;not null"`
+	Path      string    `gorm:"type:varchar(1024);unique;not null"` // Relative to storage/media, e.g. uploads/<file>.
+	MimeType  string    `gorm:"type:varchar(50);not null"`
+	Size      int64     `gorm:"not null"`
+	Width     int       `gorm:"not null"`
+	Height    int       `gorm:"not null"`
+	Sha256    string    `gorm:"type:char(64);not null;index"`
+	AltText   string    `gorm:"type:varchar(255)"`
+	Owner     string    `gorm:"type:varchar(255);not null;index"` // API account or author username that stored it.
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP;index"`
+}
+
+func (Media) TableName() string {
+	return "media"
+}
+
 type PostView struct {
 	ID        uint64    `gorm:"primaryKey;autoIncrement"`
 	PostID    uint64    `gorm:"not null;index:idx_post_views_post_viewed_at"`
@@ -196,3 +241,131 @@ type Newsletter struct {
 	CreatedAt      time.Time  `gorm:"default:CURRENT_TIMESTAMP;index:idx_newsletters_created_at"`
 	UpdatedAt      time.Time  `gorm:"default:CURRENT_TIMESTAMP"`
 }
+
+// ContentSection records that a section of the site content (profile, talks, ...) lives in
+// the database, and the version its public route reports.
+type ContentSection struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	Slug      string    `gorm:"type:varchar(50);unique;not null"`
+	Version   string    `gorm:"type:varchar(50);not null"` // The fixture version, frozen at import time.
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+type Profile struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement"`
+	Nickname   string    `gorm:"type:varchar(255);not null"`
+	Handle     string    `gorm:"type:varchar(255);not null"`
+	Name       string    `gorm:"type:varchar(255);not null"`
+	Email      string    `gorm:"type:varchar(250);not null"`
+	Profession string    `gorm:"type:varchar(255)"`
+	CreatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+
+	// Associations
+	Skills []ProfileSkill `gorm:"foreignKey:ProfileID"`
+}
+
+type ProfileSkill struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	UUID        string    `gorm:"type:uuid;unique;not null"`
+	ProfileID   uint64    `gorm:"not null;index:idx_profile_skills_profile_sort_order"`
+	Profile     Profile   `gorm:"foreignKey:ProfileID;constraint:OnDelete:CASCADE"`
+	Item        string    `gorm:"type:varchar(255);not null"`
+	Percentage  int       `gorm:"type:int;not null"`
+	Description string    `gorm:"type:text"`
+	SortOrder   int       `gorm:"type:int;not null;index:idx_profile_skills_profile_sort_order"`
+	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+type Experience struct {
+	ID             uint64    `gorm:"primaryKey;autoIncrement"`
+	UUID           string    `gorm:"type:uuid;unique;not null"`
+	Company        string    `gorm:"type:varchar(255);not null"`
+	EmploymentType string    `gorm:"type:varchar(100)"`
+	LocationType   string    `gorm:"type:varchar(100)"`
+	Position       string    `gorm:"type:varchar(255);not null"`
+	StartDate      string    `gorm:"type:varchar(50);not null"` // As displayed, e.g. "June, 2025".
+	EndDate        string    `gorm:"type:varchar(50)"`
+	Summary        string    `gorm:"type:text"`
+	Country        string    `gorm:"type:varchar(100)"`
+	City           string    `gorm:"type:varchar(100)"`
+	Skills         string    `gorm:"type:text"`
+	SortOrder      int       `gorm:"type:int;not null;index"`
+	CreatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+type Education struct {
+	ID             uint64    `gorm:"primaryKey;autoIncrement"`
+	UUID           string    `gorm:"type:uuid;unique;not null"`
+	Icon           string    `gorm:"type:varchar(2048)"`
+	School         string    `gorm:"type:varchar(255);not null"`
+	Degree         string    `gorm:"type:varchar(255);not null"`
+	Field          string    `gorm:"type:varchar(255)"`
+	Description    string    `gorm:"type:text"`
+	GraduatedAt    string    `gorm:"type:varchar(50)"` // As displayed, e.g. "2012".
+	IssuingCountry string    `gorm:"type:varchar(100)"`
+	SortOrder      int       `gorm:"type:int;not null;index"`
+	CreatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// Talk reports its CreatedAt and UpdatedAt as the dates the talk was given and revised.
+type Talk struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	UUID      string    `gorm:"type:uuid;unique;not null"`
+	Title     string    `gorm:"type:varchar(255);not null"`
+	Subject   string    `gorm:"type:varchar(255)"`
+	Location  string    `gorm:"type:varchar(255)"`
+	URL       string    `gorm:"type:varchar(2048)"`
+	Photo     string    `gorm:"type:varchar(2048)"`
+	SortOrder int       `gorm:"type:int;not null;index"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+type Link struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	UUID        string    `gorm:"type:uuid;unique;not null"`
+	Handle      string    `gorm:"type:varchar(255)"`
+	URL         string    `gorm:"type:varchar(2048);not null"`
+	Description string    `gorm:"type:text"`
+	Name        string    `gorm:"type:varchar(100);not null"`
+	SortOrder   int       `gorm:"type:int;not null;index"`
+	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+type Project struct {
+	ID           uint64     `gorm:"primaryKey;autoIncrement"`
+	UUID         string     `gorm:"type:uuid;unique;not null"`
+	Sort         int        `gorm:"type:int;not null"`
+	Language     string     `gorm:"type:varchar(255)"`
+	Title        string     `gorm:"type:varchar(255);not null"`
+	Excerpt      string     `gorm:"type:text"`
+	URL          string     `gorm:"type:varchar(2048);not null"`
+	Icon         string     `gorm:"type:varchar(2048)"`
+	IsOpenSource bool       `gorm:"not null;default:false"`
+	PublishedAt  *time.Time `gorm:"type:date"` // Resolved from the code host while empty.
+	SortOrder    int        `gorm:"type:int;not null;index"`
+	CreatedAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// Recommendation reports its CreatedAt and UpdatedAt as the dates it was written and revised.
+type Recommendation struct {
+	ID                uint64    `gorm:"primaryKey;autoIncrement"`
+	UUID              string    `gorm:"type:uuid;unique;not null"`
+	Relation          string    `gorm:"type:varchar(255)"`
+	Text              string    `gorm:"type:text;not null"`
+	Featured          bool      `gorm:"not null;default:false"`
+	PersonAvatar      string    `gorm:"type:varchar(2048)"`
+	PersonFullName    string    `gorm:"type:varchar(255);not null"`
+	PersonCompany     string    `gorm:"type:varchar(255)"`
+	PersonDesignation string    `gorm:"type:varchar(255)"`
+	SortOrder         int       `gorm:"type:int;not null;index"`
+	CreatedAt         time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt         time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/oullin/database"
+	"github.com/oullin/pkg/model"
+)
+
+// ErrContentImported is returned when importing a section that already lives in the database.
+var ErrContentImported = errors.New("the content section was already imported")
+
+// ContentItem is any of the ordered rows behind the list sections of the site content.
+type ContentItem interface {
+	database.Experience | database.Education | database.Talk | database.Link | database.Project | database.Recommendation
+}
+
+// Content reads and writes the site content: the profile, experience, education, talks,
+// links, projects and recommendations.
+type Content struct {
+	DB *database.Connection
+}
+
+// Section returns the imported section with the given slug, or nil when it still lives in
+// its fixture file.
+func (c Content) Section(slug string) (*database.ContentSection, error) {
+	if c.DB == nil || c.DB.Sql() == nil {
+		return nil, errors.New("content: no database connection")
+	}
+
+	section := database.ContentSection{}
+	result := c.DB.Sql().Where("slug = ?", slug).First(&section)
+
+	if model.IsNotFound(result.Error) {
+		return nil, nil
+	}
+
+	if model.HasDbIssues(result.Error) {
+		return nil, fmt.Errorf("issue finding the content section [%s]: %s", slug, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return &section, nil
+}
+
+// ImportedSlugs lists the sections that live in the database rather than in their fixture files.
+func (c Content) ImportedSlugs() ([]string, error) {
+	var slugs []string
+
+	if result := c.DB.Sql().Model(&database.ContentSection{}).Order("slug ASC").Pluck("slug", &slugs); model.HasDbIssues(result.Error) {
+		return nil, fmt.Errorf("issue listing the content sections: %s", result.Error)
+	}
+
+	return slugs, nil
+}
+
+// ImportSection stores the rows of a section, a pointer to a slice of items or to a
+// profile, together with the version its route reports. Nil rows import an empty section.
+// Sections are only imported once, and the version stays the imported one: admin writes
+// change the data, and so the ETag, but not the version.
+func (c Content) ImportSection(slug, version string, rows any) error {
+	return c.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+
+		if err := tx.Model(&database.ContentSection{}).Where("slug = ?", slug).Count(&count).Error; err != nil {
+			return fmt.Errorf("issue checking the content section [%s]: %w", slug, err)
+		}
+
+		if count > 0 {
+			return fmt.Errorf("%w: %s", ErrContentImported, slug)
+		}
+
+		if rows != nil {
+			if err := tx.Create(rows).Error; err != nil {
+				return fmt.Errorf("issue importing the content section [%s]: %w", slug, err)
+			}
+		}
+
+		if err := tx.Create(&database.ContentSection{Slug: slug, Version: version}).Error; err != nil {
+			return fmt.Errorf("issue recording the content section [%s]: %w", slug, err)
+		}
+
+		return nil
+	})
+}
+
+// Profile returns the profile with its skills in order, or nil when there is none.
+func (c Content) Profile() (*database.Profile, error) {
+	profile := database.Profile{}
+
+	result := c.DB.Sql().
+		Preload("Skills", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order ASC, id ASC")
+		}).
+		Order("id ASC").
+		First(&profile)
+
+	if model.IsNotFound(result.Error) {
+		return nil, nil
+	}
+
+	if model.HasDbIssues(result.Error) {
+		return nil, fmt.Errorf("issue finding the profile: %s", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return &profile, nil
+}
+
+// SaveProfile writes the profile and replaces its skills with the given ones.
+func (c Content) SaveProfile(profile *database.Profile) error {
+	return c.DB.Transaction(func(tx *gorm.DB) error {
+		skills := profile.Skills
+
+		if err := tx.Omit("Skills").Save(profile).Error; err != nil {
+			return fmt.Errorf("issue saving the profile: %w", err)
+		}
+
+		if err := tx.Where("profile_id = ?", profile.ID).Delete(&database.ProfileSkill{}).Error; err != nil {
+			return fmt.Errorf("issue clearing the profile skills: %w", err)
+		}
+
+		for i := range skills {
+			skills[i].ID = 0
+			skills[i].ProfileID = profile.ID
+		}
+
+		if len(skills) > 0 {
+			if err := tx.Create(&skills).Error; err != nil {
+				return fmt.Errorf("issue saving the profile skills: %w", err)
+			}
+		}
+
+		profile.Skills = skills
+
+		return nil
+	})
+}
+
+// ListContent returns every row of a section in order.
+func ListContent[M ContentItem](c Content) ([]M, error) {
+	var items []M
+
+	if result := c.DB.Sql().Order("sort_order ASC, id ASC").Find(&items); model.HasDbIssues(result.Error) {
+		return nil, fmt.Errorf("issue listing the content: %s", result.Error)
+	}
+
+	return items, nil
+}
+
+// FindContent returns the row with the given UUID, or nil when there is none.
+func FindContent[M ContentItem](c Content, uuid string) (*M, error) {
+	var item M
+
+	result := c.DB.Sql().Where("uuid = ?", uuid).First(&item)
+
+	if model.IsNotFound(result.Error) {
+		return nil, nil
+	}
+
+	if model.HasDbIssues(result.Error) {
+		return nil, fmt.Errorf("issue finding the content [%s]: %s", uuid, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return &item, nil
+}
+
+// CreateContent adds the row at the end of its section.
+func CreateContent[M ContentItem](c Content, item *M) error {
+	return c.DB.Transaction(func(tx *gorm.DB) error {
+		var last int
+
+		if err := tx.Model(new(M)).Select("COALESCE(MAX(sort_order), 0)").Scan(&last).Error; err != nil {
+			return fmt.Errorf("issue ordering the content: %w", err)
+		}
+
+		if err := tx.Create(item).Error; err != nil {
+			return fmt.Errorf("issue creating the content: %w", err)
+		}
+
+		if err := tx.Model(item).Update("sort_order", last+1).Error; err != nil {
+			return fmt.Errorf("issue ordering the content: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func SaveContent[M ContentItem](c Content, item *M) error {
+	if result := c.DB.Sql().Save(item); model.HasDbIssues(result.Error) {
+		return fmt.Errorf("issue saving the content: %s", result.Error)
+	}
+
+	return nil
+}
+
+func DeleteContent[M ContentItem](c Content, item *M) error {
+	if result := c.DB.Sql().Delete(item); model.HasDbIssues(result.Error) {
+		return fmt.Errorf("issue deleting the content: %s", result.Error)
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/internal/testutil/dbtest"
+)
+
+func TestContentImportSectionOncePostgres(t *testing.T) {
+	h := dbtest.NewTestsHelper(t, &database.ContentSection{}, &database.Talk{})
+	repo := repository.Content{DB: h.Conn()}
+
+	if section, err := repo.Section("talks"); err != nil || section != nil {
+		t.Fatalf("expected no section before the import, got %+v %v", section, err)
+	}
+
+	talks := []database.Talk{
+		{UUID: uuid.NewString(), Title: "Second", SortOrder: 2},
+		{UUID: uuid.NewString(), Title: "First", SortOrder: 1},
+	}
+
+	if err := repo.ImportSection("talks", "1.0.0", &talks); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	if err := repo.ImportSection("talks", "1.0.1", &[]database.Talk{{UUID: uuid.NewString(), Title: "Again"}}); !errors.Is(err, repository.ErrContentImported) {
+		t.Fatalf("expected the second import to be refused, got %v", err)
+	}
+
+	section, err := repo.Section("talks")
+	if err != nil || section == nil || section.Version != "1.0.0" {
+		t.Fatalf("unexpected section %+v %v", section, err)
+	}
+
+	listed, err := repository.ListContent[database.Talk](repo)
+	if err != nil || len(listed) != 2 || listed[0].Title != "First" {
+		t.Fatalf("expected the talks in order, got %+v %v", listed, err)
+	}
+}
+
+func TestContentCrudPostgres(t *testing.T) {
+	h := dbtest.NewTestsHelper(t, &database.Link{})
+	repo := repository.Content{DB: h.Conn()}
+
+	first := database.Link{UUID: uuid.NewString(), Name: "x", URL: "https://x.com/oullinio"}
+	second := database.Link{UUID: uuid.NewString(), Name: "github", URL: "https://github.com/oullin"}
+
+	for _, link := range []*database.Link{&first, &second} {
+		if err := repository.CreateContent(repo, link); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	if first.SortOrder != 1 || second.SortOrder != 2 {
+		t.Fatalf("expected new links at the end, got %d and %d", first.SortOrder, second.SortOrder)
+	}
+
+	found, err := repository.FindContent[database.Link](repo, second.UUID)
+	if err != nil || found == nil {
+		t.Fatalf("expected to find the link, got %v", err)
+	}
+
+	found.Name = "gitHub"
+	if err := repository.SaveContent(repo, found); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	if again, err := repository.FindContent[database.Link](repo, second.UUID); err != nil || again == nil || again.Name != "gitHub" {
+		t.Fatalf("expected the saved link, got %+v %v", again, err)
+	}
+
+	if err := repository.DeleteContent(repo, &first); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if deleted, err := repository.FindContent[database.Link](repo, first.UUID); err != nil || deleted != nil {
+		t.Fatalf("expected the link to be deleted, got %+v %v", deleted, err)
+	}
+}
+
+func TestContentSaveProfileReplacesSkillsPostgres(t *testing.T) {
+	h := dbtest.NewTestsHelper(t, &database.Profile{}, &database.ProfileSkill{})
+	repo := repository.Content{DB: h.Conn()}
+
+	profile := database.Profile{
+		Nickname: "gus",
+		Handle:   "gocanto",
+		Name:     "Gustavo Ocanto",
+		Email:    "gus@oullin.io",
+		Skills: []database.ProfileSkill{
+			{UUID: uuid.NewString(), Item: "Go", Percentage: 90, SortOrder: 1},
+		},
+	}
+
+	if err := repo.SaveProfile(&profile); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	profile.Skills = []database.ProfileSkill{
+		{UUID: uuid.NewString(), Item: "Leadership", Percentage: 80, SortOrder: 1},
+		{UUID: uuid.NewString(), Item: "Postgres", Percentage: 70, SortOrder: 2},
+	}
+
+	if err := repo.SaveProfile(&profile); err != nil {
+		t.Fatalf("save again: %v", err)
+	}
+
+	stored, err := repo.Profile()
+	if err != nil || stored == nil {
+		t.Fatalf("profile: %+v %v", stored, err)
+	}
+
+	if len(stored.Skills) != 2 || stored.Skills[0].Item != "Leadership" {
+		t.Fatalf("expected the skills to be replaced, got %+v", stored.Skills)
+	}
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository/pagination"
+	"github.com/oullin/database/repository/queries"
+	"github.com/oullin/pkg/model"
+)
+
+type Media struct {
+	DB *database.Connection
+}
+
+// ErrMediaExists is returned, with the row already indexing the path, when the same file
+// is stored twice. Files are content-addressed, so this means the same bytes.
+var ErrMediaExists = errors.New("the media file is already indexed")
+
+// Create indexes a stored file. A path indexed before is left untouched and returned with
+// ErrMediaExists, so its alt text and owner are never silently replaced. The lookup first
+// only saves an insert; the unique path index is what settles concurrent writes.
+func (m Media) Create(attrs database.MediaAttrs) (*database.Media, error) {
+	if existing := m.FindByPath(attrs.Path); existing != nil {
+		return existing, ErrMediaExists
+	}
+
+	item := database.Media{
+		UUID:     uuid.NewString(),
+		Path:     attrs.Path,
+		MimeType: attrs.MimeType,
+		Size:     attrs.Size,
+		Width:    attrs.Width,
+		Height:   attrs.Height,
+		Sha256:   attrs.Sha256,
+		AltText:  attrs.AltText,
+		Owner:    attrs.Owner,
+	}
+
+	result := m.DB.Sql().Create(&item)
+
+	if model.IsUniqueViolation(result.Error) {
+		if existing := m.FindByPath(attrs.Path); existing != nil {
+			return existing, ErrMediaExists
+		}
+	}
+
+	if model.HasDbIssues(result.Error) {
+		return nil, fmt.Errorf("issue creating the given media [%s]: %s", attrs.Path, result.Error)
+	}
+
+	return &item, nil
+}
+
+func (m Media) FindBy(uuid string) *database.Media {
+	return m.findWhere("uuid = ?", uuid)
+}
+
+func (m Media) FindByPath(path string) *database.Media {
+	return m.findWhere("path = ?", path)
+}
+
+func (m Media) GetAll(filters queries.MediaFilters, paginate pagination.Paginate) (*pagination.Pagination[database.Media], error) {
+	var numItems int64
+	var items []database.Media
+
+	query := m.DB.Sql().Model(&database.Media{})
+
+	queries.ApplyMediaFilters(&filters, query)
+
+	if err := pagination.Count[*int64](&numItems, query, m.DB.GetSession(), "media.id"); err != nil {
+		return nil, err
+	}
+
+	offset := (paginate.Page - 1) * paginate.Limit
+
+	err := query.
+		Order("media.created_at DESC, media.id DESC").
+		Limit(paginate.Limit).
+		Offset(offset).
+		Find(&items).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	paginate.SetNumItems(numItems)
+
+	return pagination.NewPagination[database.Media](items, paginate), nil
+}
+
+// GetPaths returns the path of every indexed file, for storage reconciliation.
+func (m Media) GetPaths() ([]string, error) {
+	var paths []string
+
+	if result := m.DB.Sql().Model(&database.Media{}).Order("path").Pluck("path", &paths); model.HasDbIssues(result.Error) {
+		return nil, fmt.Errorf("issue listing media paths: %s", result.Error)
+	}
+
+	return paths, nil
+}
+
+// GetCoverImagePaths returns the files recorded as post cover variants, which live
+// in the same storage but are indexed by post_cover_images instead.
+func (m Media) GetCoverImagePaths() ([]string, error) {
+	var paths []string
+
+	if result := m.DB.Sql().Model(&database.PostCoverImage{}).Order("path").Pluck("path", &paths); model.HasDbIssues(result.Error) {
+		return nil, fmt.Errorf("issue listing cover image paths: %s", result.Error)
+	}
+
+	return paths, nil
+}
+
+// GetPostContents returns the body of every post, trashed ones included since they can be
+// restored, so files they link to are never taken for orphans.
+func (m Media) GetPostContents() ([]string, error) {
+	var contents []string
+
+	if result := m.DB.Sql().Unscoped().Model(&database.Post{}).Pluck("content", &contents); model.HasDbIssues(result.Error) {
+		return nil, fmt.Errorf("issue listing post contents: %s", result.Error)
+	}
+
+	return contents, nil
+}
+
+// Delete removes the row only; callers own the file on disk.
+func (m Media) Delete(item database.Media) error {
+	if result := m.DB.Sql().Delete(&item); model.HasDbIssues(result.Error) {
+		return fmt.Errorf("issue deleting the given media [%s]: %s", item.Path, result.Error)
+	}
+
+	return nil
+}
+
+func (m Media) findWhere(condition string, value string) *database.Media {
+	item := database.Media{}
+
+	result := m.DB.Sql().
+		Where(condition, value).
+		First(&item)
+
+	if model.HasDbIssues(result.Error) {
+		return nil
+	}
+
+	if result.RowsAffected > 0 {
+		return &item
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package repository_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/database/repository/pagination"
+	"github.com/oullin/database/repository/queries"
+	"github.com/oullin/internal/testutil/dbtest"
+)
+
+func TestMediaCreateIsIdempotentPerPathPostgres(t *testing.T) {
+	h := dbtest.NewTestsHelper(t, &database.Media{})
+	repo := repository.Media{DB: h.Conn()}
+
+	attrs := database.MediaAttrs{
+		Path:     "uploads/abc.png",
+		MimeType: "image/png",
+		Size:     10,
+		Width:    2,
+		Height:   2,
+		Sha256:   strings.Repeat("a", 64),
+		Owner:    "gus",
+	}
+
+	first, err := repo.Create(attrs)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	second, err := repo.Create(attrs)
+	if !errors.Is(err, repository.ErrMediaExists) {
+		t.Fatalf("expected the path to be indexed already, got %v", err)
+	}
+
+	if first.UUID == "" || first.UUID != second.UUID {
+		t.Fatalf("expected the same row, got %s and %s", first.UUID, second.UUID)
+	}
+
+	if found := repo.FindBy(first.UUID); found == nil || found.Path != attrs.Path {
+		t.Fatalf("expected to find the media, got %+v", found)
+	}
+
+	if err = repo.Delete(*first); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if repo.FindBy(first.UUID) != nil {
+		t.Fatalf("expected the media to be deleted")
+	}
+}
+
+// uniqueViolation is the error Postgres drivers return for a duplicated key.
+type uniqueViolation struct{}
+
+func (uniqueViolation) Error() string { return "duplicate key value violates unique constraint" }
+
+func (uniqueViolation) SQLState() string { return "23505" }
+
+func TestMediaCreateLosingTheRaceReturnsTheWinner(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, PreferSimpleProtocol: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+
+	columns := []string{"id", "uuid", "path"}
+
+	// The path is free when checked, then taken by a concurrent upload before the insert.
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT").WillReturnError(uniqueViolation{})
+	mock.ExpectRollback()
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(columns).AddRow(7, "winner", "uploads/abc.png"))
+
+	repo := repository.Media{DB: database.NewConnectionFromGorm(gdb)}
+
+	item, err := repo.Create(database.MediaAttrs{Path: "uploads/abc.png", MimeType: "image/png", Sha256: strings.Repeat("a", 64)})
+
+	if !errors.Is(err, repository.ErrMediaExists) || item == nil || item.UUID != "winner" {
+		t.Fatalf("expected the concurrent row with ErrMediaExists, got %+v, %v", item, err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestMediaGetAllFiltersPostgres(t *testing.T) {
+	h := dbtest.NewTestsHelper(t, &database.Media{}, &database.User{}, &database.Post{}, &database.PostCoverImage{})
+	repo := repository.Media{DB: h.Conn()}
+
+	seeds := []database.MediaAttrs{
+		{Path: "uploads/a.png", MimeType: "image/png", AltText: "Team photo", Owner: "gus", Sha256: strings.Repeat("a", 64)},
+		{Path: "uploads/b.jpg", MimeType: "image/jpeg", Owner: "Gus", Sha256: strings.Repeat("b", 64)},
+		{Path: "posts/hello/c.webp", MimeType: "image/webp", Owner: "mirror", Sha256: strings.Repeat("c", 64)},
+	}
+
+	for _, seed := range seeds {
+		if _, err := repo.Create(seed); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	cases := []struct {
+		filters queries.MediaFilters
+		want    int64
+	}{
+		{queries.MediaFilters{}, 3},
+		{queries.MediaFilters{Text: "photo"}, 1},
+		{queries.MediaFilters{Text: "posts/hello"}, 1},
+		{queries.MediaFilters{MimeType: "image/"}, 3},
+		{queries.MediaFilters{MimeType: "image/jpeg"}, 1},
+		{queries.MediaFilters{Owner: "GUS"}, 2},
+	}
+
+	for _, tc := range cases {
+		result, err := repo.GetAll(tc.filters, pagination.Paginate{Page: 1, Limit: 10})
+		if err != nil {
+			t.Fatalf("get all: %v", err)
+		}
+
+		if result.Total != tc.want || int64(len(result.Data)) != tc.want {
+			t.Fatalf("filters %+v: expected %d, got %d", tc.filters, tc.want, result.Total)
+		}
+	}
+
+	paths, err := repo.GetPaths()
+	if err != nil || len(paths) != 3 || paths[0] != "posts/hello/c.webp" {
+		t.Fatalf("unexpected paths %v err %v", paths, err)
+	}
+}
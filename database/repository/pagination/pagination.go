@@ -5,6 +5,8 @@ import "math"
 const MinPage = 1
 const PostsMaxLimit = 10
 const CategoriesMaxLimit = 50
+const MediaMaxLimit = 50
+const FixturesMaxLimit = 100
 
 // Pagination holds the data for a single page along with all pagination metadata.
 // It's generic and can be used for any data type.
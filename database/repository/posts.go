@@ -2,8 +2,10 @@ package repository
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 
 	"github.com/oullin/database"
 	"github.com/oullin/database/repository/pagination"
@@ -17,7 +19,7 @@ type Posts struct {
 	Tags       *Tags
 }
 
-func (p Posts) GetAll(filters queries.PostFilters, paginate pagination.Paginate) (*pagination.Pagination[database.Post], error) {
+func (p Posts) GetAll(filters queries.PostFilters, fieldset queries.PostFieldset, paginate pagination.Paginate) (*pagination.Pagination[database.Post], error) {
 	var numItems int64
 	var posts []database.Post
 
@@ -34,11 +36,32 @@ func (p Posts) GetAll(filters queries.PostFilters, paginate pagination.Paginate)
 
 	offset := (paginate.Page - 1) * paginate.Limit
 
-	err := query.Preload("Author").
-		Preload("Categories").
-		Preload("Tags").
-		Order("posts.published_at DESC, posts.id DESC").
-		Select("DISTINCT ON (posts.published_at, posts.id) posts.*"). // ensure joined relations do not duplicate posts
+	columns := "posts.*"
+	if selected := fieldset.Columns(); len(selected) > 0 {
+		columns = strings.Join(selected, ", ") // sparse fieldsets never fetch unused columns, e.g. content.
+	}
+
+	if fieldset.Loads(queries.PostRelationAuthor) {
+		query = query.Preload("Author")
+	}
+
+	if fieldset.Loads(queries.PostRelationCategories) {
+		query = query.Preload("Categories")
+	}
+
+	if fieldset.Loads(queries.PostRelationTags) {
+		query = query.Preload("Tags")
+	}
+
+	if fieldset.Loads(queries.PostRelationCoverImages) {
+		query = query.Preload("CoverImages", orderCoverImages)
+	}
+
+	order := queries.NewPostsOrder(filters.GetSort())
+
+	err := query.
+		Order(order.OrderBy()).
+		Select(order.DistinctOn() + " " + columns). // ensure joined relations do not duplicate posts
 		Limit(paginate.Limit).
 		Offset(offset).
 		Find(&posts).Error
@@ -60,6 +83,7 @@ func (p Posts) FindBy(slug string) *database.Post {
 		Preload("Author").
 		Preload("Categories").
 		Preload("Tags").
+		Preload("CoverImages", orderCoverImages).
 		Where("LOWER(slug) = ?", slug).
 		First(&post)
 
@@ -116,13 +140,21 @@ func (p Posts) Create(attrs database.PostsAttrs) (*database.Post, error) {
 }
 
 func (p Posts) LinkCategories(post database.Post, categories []database.CategoriesAttrs) error {
+	return linkCategories(p.DB.Sql(), post, categories)
+}
+
+func (p Posts) LinkTags(post database.Post, tags []database.TagAttrs) error {
+	return linkTags(p.DB.Sql(), post, tags)
+}
+
+func linkCategories(db *gorm.DB, post database.Post, categories []database.CategoriesAttrs) error {
 	for _, category := range categories {
 		trace := database.PostCategory{
 			CategoryID: category.Id,
 			PostID:     post.ID,
 		}
 
-		if result := p.DB.Sql().Create(&trace); model.HasDbIssues(result.Error) {
+		if result := db.Create(&trace); model.HasDbIssues(result.Error) {
 			return fmt.Errorf("error linking categories [%s:%s]: %s", category.Name, post.Title, result.Error)
 		}
 	}
@@ -130,17 +162,176 @@ func (p Posts) LinkCategories(post database.Post, categories []database.Categori
 	return nil
 }
 
-func (p Posts) LinkTags(post database.Post, tags []database.TagAttrs) error {
+func linkTags(db *gorm.DB, post database.Post, tags []database.TagAttrs) error {
 	for _, tag := range tags {
 		trace := database.PostTag{
 			TagID:  tag.Id,
 			PostID: post.ID,
 		}
 
-		if result := p.DB.Sql().Create(&trace); model.HasDbIssues(result.Error) {
+		if result := db.Create(&trace); model.HasDbIssues(result.Error) {
 			return fmt.Errorf("error linking tags [%s:%s]: %s", tag.Name, post.Title, result.Error)
 		}
 	}
 
 	return nil
 }
+
+// FindWithTrashedBy looks the slug up across live and soft-deleted posts.
+func (p Posts) FindWithTrashedBy(slug string) *database.Post {
+	post := database.Post{}
+
+	result := p.DB.Sql().
+		Unscoped().
+		Where("LOWER(slug) = ?", strings.ToLower(slug)).
+		First(&post)
+
+	if model.HasDbIssues(result.Error) {
+		return nil
+	}
+
+	if result.RowsAffected > 0 {
+		return &post
+	}
+
+	return nil
+}
+
+func (p Posts) Update(post database.Post, attrs database.PostsAttrs) (*database.Post, error) {
+	coverChanged := attrs.ImageURL != post.CoverImageURL
+
+	err := p.DB.Transaction(func(tx *gorm.DB) error {
+		values := map[string]any{
+			"slug":            attrs.Slug,
+			"title":           attrs.Title,
+			"excerpt":         attrs.Excerpt,
+			"content":         attrs.Content,
+			"cover_image_url": attrs.ImageURL,
+			"published_at":    attrs.PublishedAt,
+		}
+
+		if result := tx.Model(&post).Updates(values); model.HasDbIssues(result.Error) {
+			return fmt.Errorf("issue updating the given post [%s]: %s", post.Slug, result.Error)
+		}
+
+		// The variants and placeholder of the previous cover would otherwise keep being
+		// served until the new ones are rendered, or for good when the cover is removed.
+		if coverChanged {
+			if err := clearCoverImages(tx, post); err != nil {
+				return err
+			}
+		}
+
+		if result := tx.Where("post_id = ?", post.ID).Delete(&database.PostCategory{}); model.HasDbIssues(result.Error) {
+			return fmt.Errorf("issue unlinking the given post [%s] categories: %s", post.Slug, result.Error)
+		}
+
+		if result := tx.Where("post_id = ?", post.ID).Delete(&database.PostTag{}); model.HasDbIssues(result.Error) {
+			return fmt.Errorf("issue unlinking the given post [%s] tags: %s", post.Slug, result.Error)
+		}
+
+		if err := linkCategories(tx, post, attrs.Categories); err != nil {
+			return err
+		}
+
+		return linkTags(tx, post, attrs.Tags)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+// Delete soft deletes the given post; its pivot links are kept so it can be restored.
+func (p Posts) Delete(post database.Post) error {
+	if result := p.DB.Sql().Delete(&post); model.HasDbIssues(result.Error) {
+		return fmt.Errorf("issue deleting the given post [%s]: %s", post.Slug, result.Error)
+	}
+
+	return nil
+}
+
+func (p Posts) Restore(post database.Post) (*database.Post, error) {
+	result := p.DB.Sql().
+		Unscoped().
+		Model(&post).
+		Update("deleted_at", nil)
+
+	if model.HasDbIssues(result.Error) {
+		return nil, fmt.Errorf("issue restoring the given post [%s]: %s", post.Slug, result.Error)
+	}
+
+	return &post, nil
+}
+
+// UpdateContent replaces the body of the given post, e.g. once its images are mirrored.
+func (p Posts) UpdateContent(post database.Post, content string) error {
+	if result := p.DB.Sql().Model(&database.Post{}).Where("id = ?", post.ID).UpdateColumn("content", content); model.HasDbIssues(result.Error) {
+		return fmt.Errorf("issue updating the given post [%s] content: %s", post.Slug, result.Error)
+	}
+
+	return nil
+}
+
+// SyncCoverImages replaces the recorded cover variants and the cover placeholder of the given post.
+func (p Posts) SyncCoverImages(post database.Post, placeholder database.PostCoverPlaceholderAttrs, images []database.PostCoverImageAttrs) error {
+	return p.DB.Transaction(func(tx *gorm.DB) error {
+		placeholderColumns := map[string]any{
+			"cover_blur_hash":      placeholder.BlurHash,
+			"cover_dominant_color": placeholder.DominantColor,
+			"cover_width":          placeholder.Width,
+			"cover_height":         placeholder.Height,
+		}
+
+		if result := tx.Model(&database.Post{}).Where("id = ?", post.ID).UpdateColumns(placeholderColumns); model.HasDbIssues(result.Error) {
+			return fmt.Errorf("issue updating the given post [%s] cover placeholder: %s", post.Slug, result.Error)
+		}
+
+		if result := tx.Where("post_id = ?", post.ID).Delete(&database.PostCoverImage{}); model.HasDbIssues(result.Error) {
+			return fmt.Errorf("issue removing the given post [%s] cover images: %s", post.Slug, result.Error)
+		}
+
+		for _, image := range images {
+			trace := database.PostCoverImage{
+				PostID:   post.ID,
+				Format:   image.Format,
+				Width:    image.Width,
+				Height:   image.Height,
+				MimeType: image.MimeType,
+				Path:     image.Path,
+			}
+
+			if result := tx.Create(&trace); model.HasDbIssues(result.Error) {
+				return fmt.Errorf("error recording cover image [%s:%s]: %s", image.Path, post.Slug, result.Error)
+			}
+		}
+
+		return nil
+	})
+}
+
+// clearCoverImages removes the recorded cover variants and resets the cover placeholder.
+func clearCoverImages(tx *gorm.DB, post database.Post) error {
+	placeholderColumns := map[string]any{
+		"cover_blur_hash":      "",
+		"cover_dominant_color": "",
+		"cover_width":          0,
+		"cover_height":         0,
+	}
+
+	if result := tx.Model(&database.Post{}).Where("id = ?", post.ID).UpdateColumns(placeholderColumns); model.HasDbIssues(result.Error) {
+		return fmt.Errorf("issue clearing the given post [%s] cover placeholder: %s", post.Slug, result.Error)
+	}
+
+	if result := tx.Where("post_id = ?", post.ID).Delete(&database.PostCoverImage{}); model.HasDbIssues(result.Error) {
+		return fmt.Errorf("issue removing the given post [%s] cover images: %s", post.Slug, result.Error)
+	}
+
+	return nil
+}
+
+func orderCoverImages(db *gorm.DB) *gorm.DB {
+	return db.Order("post_cover_images.width ASC, post_cover_images.format ASC")
+}
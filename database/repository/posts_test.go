@@ -15,6 +15,7 @@ func TestPostsCreateLinksAssociationsPostgres(t *testing.T) {
 	h := dbtest.NewTestsHelper(t,
 		&database.User{},
 		&database.Post{},
+		&database.PostCoverImage{},
 		&database.Category{},
 		&database.PostCategory{},
 		&database.Tag{},
@@ -85,6 +86,7 @@ func TestPostsFindByLoadsAssociationsPostgres(t *testing.T) {
 	h := dbtest.NewTestsHelper(t,
 		&database.User{},
 		&database.Post{},
+		&database.PostCoverImage{},
 		&database.Category{},
 		&database.PostCategory{},
 		&database.Tag{},
@@ -134,6 +136,7 @@ func TestPostsGetAllFiltersPublishedRecordsPostgres(t *testing.T) {
 	h := dbtest.NewTestsHelper(t,
 		&database.User{},
 		&database.Post{},
+		&database.PostCoverImage{},
 		&database.Category{},
 		&database.PostCategory{},
 		&database.Tag{},
@@ -161,7 +164,7 @@ func TestPostsGetAllFiltersPublishedRecordsPostgres(t *testing.T) {
 
 	paginate := pagination.Paginate{Page: 1, Limit: 5}
 
-	result, err := postsRepo.GetAll(queries.PostFilters{}, paginate)
+	result, err := postsRepo.GetAll(queries.PostFilters{}, queries.PostFieldset{}, paginate)
 	if err != nil {
 		t.Fatalf("get all: %v", err)
 	}
@@ -195,6 +198,7 @@ func TestPostsGetAllDeduplicatesResultsPostgres(t *testing.T) {
 	h := dbtest.NewTestsHelper(t,
 		&database.User{},
 		&database.Post{},
+		&database.PostCoverImage{},
 		&database.Category{},
 		&database.PostCategory{},
 		&database.Tag{},
@@ -228,7 +232,7 @@ func TestPostsGetAllDeduplicatesResultsPostgres(t *testing.T) {
 	filters := queries.PostFilters{Category: "eng", Tag: "eng"}
 	paginate := pagination.Paginate{Page: 1, Limit: 5}
 
-	result, err := postsRepo.GetAll(filters, paginate)
+	result, err := postsRepo.GetAll(filters, queries.PostFieldset{}, paginate)
 	if err != nil {
 		t.Fatalf("get all: %v", err)
 	}
@@ -246,6 +250,170 @@ func TestPostsGetAllDeduplicatesResultsPostgres(t *testing.T) {
 	}
 }
 
+func TestPostsGetAllHonoursFieldsetPostgres(t *testing.T) {
+	h := dbtest.NewTestsHelper(t,
+		&database.User{},
+		&database.Post{},
+		&database.PostCoverImage{},
+		&database.Category{},
+		&database.PostCategory{},
+		&database.Tag{},
+		&database.PostTag{},
+	)
+
+	author := h.SeedUser("Fay", "Sparse", "fay")
+	category := h.SeedCategory("sparse", "Sparse", 1)
+	tag := h.SeedTag("cards", "Cards")
+	post := h.SeedPost(author, category, tag, "card-grid", "Card Grid", true)
+
+	postsRepo := repository.Posts{DB: h.Conn()}
+
+	fieldset := queries.NewPostFieldset([]string{"slug", "title", "tags"}, nil)
+	paginate := pagination.Paginate{Page: 1, Limit: 5}
+
+	result, err := postsRepo.GetAll(queries.PostFilters{}, fieldset, paginate)
+	if err != nil {
+		t.Fatalf("get all: %v", err)
+	}
+
+	if len(result.Data) != 1 {
+		t.Fatalf("expected single result, got %d", len(result.Data))
+	}
+
+	found := result.Data[0]
+
+	if found.ID != post.ID || found.Slug != post.Slug || found.Title != post.Title {
+		t.Fatalf("expected selected columns to be hydrated, got %+v", found)
+	}
+
+	if found.Content != "" || found.Excerpt != "" {
+		t.Fatalf("expected unselected columns to be skipped")
+	}
+
+	if len(found.Tags) != 1 || len(found.Categories) != 0 || found.Author.ID != 0 {
+		t.Fatalf("expected only tags to be preloaded")
+	}
+}
+
+func TestPostsGetAllSortsAndBoundsByDatePostgres(t *testing.T) {
+	h := dbtest.NewTestsHelper(t,
+		&database.User{},
+		&database.Post{},
+		&database.PostCoverImage{},
+		&database.Category{},
+		&database.PostCategory{},
+		&database.Tag{},
+		&database.PostTag{},
+	)
+
+	author := h.SeedUser("Gil", "Sorted", "gil")
+	category := h.SeedCategory("sorting", "Sorting", 1)
+	tag := h.SeedTag("dates", "Dates")
+
+	conn := h.Conn()
+
+	dates := map[string]time.Time{
+		"alpha-post": time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+		"bravo-post": time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC),
+		"delta-post": time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC),
+	}
+
+	for slug, publishedAt := range dates {
+		post := h.SeedPost(author, category, tag, slug, slug, true)
+
+		if err := conn.Sql().Model(&post).Update("published_at", publishedAt).Error; err != nil {
+			t.Fatalf("update published_at: %v", err)
+		}
+	}
+
+	postsRepo := repository.Posts{DB: conn}
+	paginate := pagination.Paginate{Page: 1, Limit: 5}
+
+	oldest, err := postsRepo.GetAll(queries.PostFilters{Sort: queries.PostsSortOldest}, queries.PostFieldset{}, paginate)
+	if err != nil {
+		t.Fatalf("get all oldest: %v", err)
+	}
+
+	if len(oldest.Data) != 3 || oldest.Data[0].Slug != "alpha-post" || oldest.Data[2].Slug != "delta-post" {
+		t.Fatalf("unexpected oldest order: %+v", oldest.Data)
+	}
+
+	after := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	bounded, err := postsRepo.GetAll(queries.PostFilters{PublishedAfter: &after, PublishedBefore: &before}, queries.PostFieldset{}, paginate)
+	if err != nil {
+		t.Fatalf("get all bounded: %v", err)
+	}
+
+	if bounded.Total != 1 || bounded.Data[0].Slug != "bravo-post" {
+		t.Fatalf("unexpected bounded result: %+v", bounded.Data)
+	}
+}
+
+func TestPostsGetAllMatchesTagListsPostgres(t *testing.T) {
+	h := dbtest.NewTestsHelper(t,
+		&database.User{},
+		&database.Post{},
+		&database.PostCoverImage{},
+		&database.Category{},
+		&database.PostCategory{},
+		&database.Tag{},
+		&database.PostTag{},
+	)
+
+	author := h.SeedUser("Hal", "Lists", "hal")
+	category := h.SeedCategory("reading", "Reading", 1)
+	goTag := h.SeedTag("go", "Go")
+	pgTag := h.SeedTag("postgres", "Postgres")
+	draftTag := h.SeedTag("draft", "Draft")
+
+	both := h.SeedPost(author, category, goTag, "go-and-postgres", "Go and Postgres", true)
+	_ = h.SeedPost(author, category, goTag, "go-only", "Go only", true)
+	drafty := h.SeedPost(author, category, pgTag, "postgres-draft", "Postgres draft", true)
+
+	conn := h.Conn()
+
+	for _, link := range []database.PostTag{
+		{PostID: both.ID, TagID: pgTag.ID},
+		{PostID: drafty.ID, TagID: draftTag.ID},
+	} {
+		if err := conn.Sql().Create(&link).Error; err != nil {
+			t.Fatalf("link tag: %v", err)
+		}
+	}
+
+	postsRepo := repository.Posts{DB: conn}
+	paginate := pagination.Paginate{Page: 1, Limit: 5}
+
+	all, err := postsRepo.GetAll(queries.PostFilters{Tags: []string{"go", "postgres"}, TagsMode: queries.PostsMatchAll}, queries.PostFieldset{}, paginate)
+	if err != nil {
+		t.Fatalf("get all (all-of): %v", err)
+	}
+
+	if all.Total != 1 || all.Data[0].ID != both.ID {
+		t.Fatalf("expected only the post tagged with both, got %+v", all.Data)
+	}
+
+	anyOf, err := postsRepo.GetAll(queries.PostFilters{Tags: []string{"go", "postgres"}}, queries.PostFieldset{}, paginate)
+	if err != nil {
+		t.Fatalf("get all (any-of): %v", err)
+	}
+
+	if anyOf.Total != 3 {
+		t.Fatalf("expected three posts for any-of, got %d", anyOf.Total)
+	}
+
+	excluded, err := postsRepo.GetAll(queries.PostFilters{Tags: []string{"postgres"}, ExcludeTags: []string{"draft"}}, queries.PostFieldset{}, paginate)
+	if err != nil {
+		t.Fatalf("get all (excluded): %v", err)
+	}
+
+	if excluded.Total != 1 || excluded.Data[0].ID != both.ID {
+		t.Fatalf("expected draft post to be excluded, got %+v", excluded.Data)
+	}
+}
+
 func TestPostsFindCategoryByDelegatesPostgres(t *testing.T) {
 	h := dbtest.NewTestsHelper(t, &database.Category{})
 
@@ -286,3 +454,110 @@ func TestPostsFindTagByHandlesRepositoryErrorsPostgres(t *testing.T) {
 		t.Fatalf("expected nil tag when repository errors")
 	}
 }
+
+func TestPostsSyncCoverImagesPostgres(t *testing.T) {
+	h := dbtest.NewTestsHelper(t,
+		&database.User{},
+		&database.Post{},
+		&database.PostCoverImage{},
+		&database.Category{},
+		&database.PostCategory{},
+		&database.Tag{},
+		&database.PostTag{},
+	)
+
+	user := h.SeedUser("Alice", "Smith", "alice")
+	category := h.SeedCategory("tech", "Tech", 1)
+	tag := h.SeedTag("go", "Go")
+	post := h.SeedPost(user, category, tag, "covered", "Covered", true)
+
+	postsRepo := repository.Posts{DB: h.Conn()}
+
+	stale := []database.PostCoverImageAttrs{
+		{Format: "jpeg", Width: 480, Height: 240, MimeType: "image/jpeg", Path: "posts/covered/old-480w.jpg"},
+	}
+
+	if err := postsRepo.SyncCoverImages(post, database.PostCoverPlaceholderAttrs{}, stale); err != nil {
+		t.Fatalf("sync stale covers: %v", err)
+	}
+
+	fresh := []database.PostCoverImageAttrs{
+		{Format: "webp", Width: 960, Height: 480, MimeType: "image/webp", Path: "posts/covered/new-960w.webp"},
+		{Format: "jpeg", Width: 480, Height: 240, MimeType: "image/jpeg", Path: "posts/covered/new-480w.jpg"},
+		{Format: "avif", Width: 480, Height: 240, MimeType: "image/avif", Path: "posts/covered/new-480w.avif"},
+	}
+
+	placeholder := database.PostCoverPlaceholderAttrs{BlurHash: "LKO2?U%2Tw=w]~RBVZRi};RPxuwH", DominantColor: "#1a2b3c", Width: 960, Height: 480}
+
+	if err := postsRepo.SyncCoverImages(post, placeholder, fresh); err != nil {
+		t.Fatalf("sync fresh covers: %v", err)
+	}
+
+	found := postsRepo.FindBy("covered")
+	if found == nil {
+		t.Fatalf("expected to find post")
+	}
+
+	if len(found.CoverImages) != 3 {
+		t.Fatalf("expected the stale covers to be replaced, got %+v", found.CoverImages)
+	}
+
+	if found.CoverImages[0].Path != "posts/covered/new-480w.avif" || found.CoverImages[2].Width != 960 {
+		t.Fatalf("expected covers ordered by width then format, got %+v", found.CoverImages)
+	}
+
+	if found.CoverBlurHash != placeholder.BlurHash || found.CoverDominantColor != "#1a2b3c" || found.CoverWidth != 960 || found.CoverHeight != 480 {
+		t.Fatalf("expected the cover placeholder to be stored, got %+v", found)
+	}
+}
+
+func TestPostsUpdateClearsTheReplacedCoverPostgres(t *testing.T) {
+	h := dbtest.NewTestsHelper(t,
+		&database.User{},
+		&database.Post{},
+		&database.PostCoverImage{},
+		&database.Category{},
+		&database.PostCategory{},
+		&database.Tag{},
+		&database.PostTag{},
+	)
+
+	user := h.SeedUser("Alice", "Smith", "alice")
+	category := h.SeedCategory("tech", "Tech", 1)
+	tag := h.SeedTag("go", "Go")
+	post := h.SeedPost(user, category, tag, "covered", "Covered", true)
+
+	postsRepo := repository.Posts{DB: h.Conn()}
+
+	covers := []database.PostCoverImageAttrs{
+		{Format: "jpeg", Width: 480, Height: 240, MimeType: "image/jpeg", Path: "posts/covered/old-480w.jpg"},
+	}
+
+	placeholder := database.PostCoverPlaceholderAttrs{BlurHash: "LKO2?U%2Tw=w]~RBVZRi};RPxuwH", DominantColor: "#1a2b3c", Width: 480, Height: 240}
+
+	if err := postsRepo.SyncCoverImages(post, placeholder, covers); err != nil {
+		t.Fatalf("sync covers: %v", err)
+	}
+
+	attrs := database.PostsAttrs{Slug: post.Slug, Title: post.Title, Content: post.Content, ImageURL: post.CoverImageURL}
+
+	if _, err := postsRepo.Update(*postsRepo.FindBy("covered"), attrs); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	if found := postsRepo.FindBy("covered"); len(found.CoverImages) != 1 || found.CoverBlurHash == "" {
+		t.Fatalf("expected an unchanged cover to be kept, got %+v", found)
+	}
+
+	attrs.ImageURL = "https://example.com/new-cover.png"
+
+	if _, err := postsRepo.Update(*postsRepo.FindBy("covered"), attrs); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	found := postsRepo.FindBy("covered")
+
+	if len(found.CoverImages) != 0 || found.CoverBlurHash != "" || found.CoverDominantColor != "" || found.CoverWidth != 0 || found.CoverHeight != 0 {
+		t.Fatalf("expected the replaced cover to be cleared, got %+v", found)
+	}
+}
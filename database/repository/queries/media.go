@@ -0,0 +1,53 @@
+package queries
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/oullin/pkg/portal"
+)
+
+type MediaFilters struct {
+	Text     string // Case-insensitive partial match on the path or the alt text
+	MimeType string // Exact type, e.g. image/png, or a family prefix such as image/
+	Owner    string
+}
+
+func (f MediaFilters) GetText() string {
+	return f.sanitiseString(f.Text)
+}
+
+func (f MediaFilters) GetMimeType() string {
+	return f.sanitiseString(f.MimeType)
+}
+
+func (f MediaFilters) GetOwner() string {
+	return f.sanitiseString(f.Owner)
+}
+
+func (f MediaFilters) sanitiseString(seed string) string {
+	str := portal.NewStringable(seed)
+
+	return strings.TrimSpace(str.ToLower())
+}
+
+func ApplyMediaFilters(filters *MediaFilters, query *gorm.DB) {
+	if filters == nil {
+		return
+	}
+
+	if text := filters.GetText(); text != "" {
+		query.Where("LOWER(media.path) ILIKE ? OR LOWER(media.alt_text) ILIKE ?", "%"+text+"%", "%"+text+"%")
+	}
+
+	if mime := filters.GetMimeType(); strings.HasSuffix(mime, "/") {
+		query.Where("media.mime_type LIKE ?", mime+"%")
+	} else if mime != "" {
+		query.Where("media.mime_type = ?", mime)
+	}
+
+	if owner := filters.GetOwner(); owner != "" {
+		query.Where("LOWER(media.owner) = ?", owner)
+	}
+}
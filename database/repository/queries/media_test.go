@@ -0,0 +1,63 @@
+package queries_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository/queries"
+)
+
+func dryRunMediaSQL(t *testing.T, filters queries.MediaFilters) (string, []any) {
+	t.Helper()
+
+	sqlDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, PreferSimpleProtocol: true}), &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+
+	query := gdb.Model(&database.Media{})
+	queries.ApplyMediaFilters(&filters, query)
+
+	var items []database.Media
+	stmt := query.Find(&items).Statement
+
+	return stmt.SQL.String(), stmt.Vars
+}
+
+func TestApplyMediaFilters(t *testing.T) {
+	sql, vars := dryRunMediaSQL(t, queries.MediaFilters{Text: " Cover ", MimeType: "image/", Owner: "Admin"})
+
+	for _, expected := range []string{
+		"LOWER(media.path) ILIKE $1 OR LOWER(media.alt_text) ILIKE $2",
+		"media.mime_type LIKE $3",
+		"LOWER(media.owner) = $4",
+	} {
+		if !strings.Contains(sql, expected) {
+			t.Fatalf("expected %q in %s", expected, sql)
+		}
+	}
+
+	if len(vars) != 4 || vars[0] != "%cover%" || vars[2] != "image/%" || vars[3] != "admin" {
+		t.Fatalf("unexpected vars %v", vars)
+	}
+}
+
+func TestApplyMediaFiltersExactMimeType(t *testing.T) {
+	sql, _ := dryRunMediaSQL(t, queries.MediaFilters{MimeType: "image/png"})
+
+	if !strings.Contains(sql, "media.mime_type = $1") || strings.Contains(sql, "ILIKE") {
+		t.Fatalf("unexpected sql %s", sql)
+	}
+}
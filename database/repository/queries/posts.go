@@ -1,6 +1,8 @@
 package queries
 
 import (
+	"fmt"
+
 	"gorm.io/gorm"
 )
 
@@ -14,6 +16,14 @@ func ApplyPostsFilters(filters *PostFilters, query *gorm.DB) {
 		query.Where("LOWER(posts.title) ILIKE ?", "%"+filters.GetTitle()+"%")
 	}
 
+	if filters.PublishedAfter != nil {
+		query.Where("posts.published_at >= ?", *filters.PublishedAfter)
+	}
+
+	if filters.PublishedBefore != nil {
+		query.Where("posts.published_at < ?", *filters.PublishedBefore)
+	}
+
 	if filters.GetText() != "" {
 		query.
 			Where("LOWER(posts.slug) ILIKE ? OR LOWER(posts.excerpt) ILIKE ? OR LOWER(posts.content) ILIKE ?",
@@ -38,30 +48,57 @@ func ApplyPostsFilters(filters *PostFilters, query *gorm.DB) {
 	}
 
 	if filters.GetCategory() != "" {
-		query.
-			Joins("JOIN post_categories ON post_categories.post_id = posts.id").
-			Joins("JOIN categories ON categories.id = post_categories.category_id").
-			Where("categories.deleted_at IS NULL").
-			Where("("+
-				"LOWER(categories.slug) ILIKE ? OR LOWER(categories.name) ILIKE ? OR LOWER(categories.description) ILIKE ?"+
-				")",
-				"%"+filters.GetCategory()+"%",
-				"%"+filters.GetCategory()+"%",
-				"%"+filters.GetCategory()+"%",
-			)
+		query.Where(
+			pivotExists("post_categories", "categories", "category_id",
+				"(LOWER(categories.slug) ILIKE ? OR LOWER(categories.name) ILIKE ? OR LOWER(categories.description) ILIKE ?)",
+			),
+			"%"+filters.GetCategory()+"%",
+			"%"+filters.GetCategory()+"%",
+			"%"+filters.GetCategory()+"%",
+		)
 	}
 
 	if filters.GetTag() != "" {
-		query.
-			Joins("JOIN post_tags ON post_tags.post_id = posts.id").
-			Joins("JOIN tags ON tags.id = post_tags.tag_id").
-			Where("tags.deleted_at IS NULL").
-			Where("("+
-				"LOWER(tags.slug) ILIKE ? OR LOWER(tags.name) ILIKE ? OR LOWER(tags.description) ILIKE ?"+
-				")",
-				"%"+filters.GetTag()+"%",
-				"%"+filters.GetTag()+"%",
-				"%"+filters.GetTag()+"%",
-			)
+		query.Where(
+			pivotExists("post_tags", "tags", "tag_id",
+				"(LOWER(tags.slug) ILIKE ? OR LOWER(tags.name) ILIKE ? OR LOWER(tags.description) ILIKE ?)",
+			),
+			"%"+filters.GetTag()+"%",
+			"%"+filters.GetTag()+"%",
+			"%"+filters.GetTag()+"%",
+		)
+	}
+
+	applySlugsFilter(query, "post_categories", "categories", "category_id", filters.GetCategories(), filters.GetCategoriesMode())
+	applySlugsFilter(query, "post_tags", "tags", "tag_id", filters.GetTags(), filters.GetTagsMode())
+
+	if excluded := filters.GetExcludeTags(); len(excluded) > 0 {
+		query.Where("NOT "+pivotExists("post_tags", "tags", "tag_id", "LOWER(tags.slug) IN ?"), excluded)
+	}
+}
+
+// applySlugsFilter matches posts linked to any (or all) of the given exact slugs.
+func applySlugsFilter(query *gorm.DB, pivot, table, foreignKey string, slugs []string, mode string) {
+	if len(slugs) == 0 {
+		return
+	}
+
+	if mode != PostsMatchAll {
+		query.Where(pivotExists(pivot, table, foreignKey, "LOWER("+table+".slug) IN ?"), slugs)
+
+		return
 	}
+
+	for _, slug := range slugs {
+		query.Where(pivotExists(pivot, table, foreignKey, "LOWER("+table+".slug) = ?"), slug)
+	}
+}
+
+// pivotExists builds an EXISTS sub-query over a posts pivot table, so matching
+// several related rows never multiplies the posts rows.
+func pivotExists(pivot, table, foreignKey, condition string) string {
+	return fmt.Sprintf(
+		"EXISTS (SELECT 1 FROM %[1]s JOIN %[2]s ON %[2]s.id = %[1]s.%[3]s WHERE %[1]s.post_id = posts.id AND %[2]s.deleted_at IS NULL AND %[4]s)",
+		pivot, table, foreignKey, condition,
+	)
 }
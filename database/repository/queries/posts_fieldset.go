@@ -0,0 +1,154 @@
+package queries
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const PostRelationAuthor = "author"
+const PostRelationCategories = "categories"
+const PostRelationTags = "tags"
+const PostRelationCoverImages = "cover_images"
+
+// postColumns maps the public post field names onto their "posts" table columns.
+var postColumns = map[string][]string{
+	"uuid":              {"posts.uuid"},
+	"slug":              {"posts.slug"},
+	"title":             {"posts.title"},
+	"excerpt":           {"posts.excerpt"},
+	"content":           {"posts.content"},
+	"cover_image_url":   {"posts.cover_image_url"},
+	"cover_placeholder": {"posts.cover_blur_hash", "posts.cover_dominant_color", "posts.cover_width", "posts.cover_height"},
+	"published_at":      {"posts.published_at"},
+	"created_at":        {"posts.created_at"},
+	"updated_at":        {"posts.updated_at"},
+}
+
+var postRelations = []string{
+	PostRelationAuthor,
+	PostRelationCategories,
+	PostRelationTags,
+	PostRelationCoverImages,
+}
+
+// PostFieldset describes the sparse shape requested for a posts listing.
+// A zero value selects every column and preloads every relation.
+type PostFieldset struct {
+	Fields  []string // Post fields (and relation keys) to keep; empty keeps all of them.
+	Include []string // Relations to preload; nil defers to Fields, an empty slice adds none.
+}
+
+func NewPostFieldset(fields, include []string) PostFieldset {
+	fieldset := PostFieldset{
+		Fields: sanitiseList(fields),
+	}
+
+	if include != nil {
+		fieldset.Include = sanitiseList(include)
+	}
+
+	return fieldset
+}
+
+func (f PostFieldset) Validate() error {
+	for _, field := range f.Fields {
+		if _, ok := postColumns[field]; !ok && !slices.Contains(postRelations, field) {
+			return fmt.Errorf("unknown post field [%s]", field)
+		}
+	}
+
+	for _, relation := range f.Include {
+		if !slices.Contains(postRelations, relation) {
+			return fmt.Errorf("unknown post relation [%s]", relation)
+		}
+	}
+
+	return nil
+}
+
+// IsSparse reports whether the caller narrowed the default post shape.
+func (f PostFieldset) IsSparse() bool {
+	return len(f.Fields) > 0 || f.Include != nil
+}
+
+// Loads reports whether the given relation has to be preloaded: it was either
+// listed as a field, explicitly included, or nothing was narrowed at all.
+func (f PostFieldset) Loads(relation string) bool {
+	if slices.Contains(f.Fields, relation) {
+		return true
+	}
+
+	if f.Include != nil {
+		return slices.Contains(f.Include, relation)
+	}
+
+	return len(f.Fields) == 0
+}
+
+// Columns returns the "posts" columns to select, or nil when every column is needed.
+// The id and published_at columns are always kept since ordering and preloads rely on them.
+func (f PostFieldset) Columns() []string {
+	if len(f.Fields) == 0 {
+		return nil
+	}
+
+	columns := []string{"posts.id", "posts.published_at"}
+
+	if f.Loads(PostRelationAuthor) {
+		columns = append(columns, "posts.author_id")
+	}
+
+	for _, field := range f.Fields {
+		for _, column := range postColumns[field] {
+			if !slices.Contains(columns, column) {
+				columns = append(columns, column)
+			}
+		}
+	}
+
+	return columns
+}
+
+// Keys returns the response keys that survive the fieldset.
+func (f PostFieldset) Keys() []string {
+	var keys []string
+
+	if len(f.Fields) == 0 {
+		for field := range postColumns {
+			keys = append(keys, field)
+		}
+	}
+
+	for _, field := range f.Fields {
+		if _, ok := postColumns[field]; ok {
+			keys = append(keys, field)
+		}
+	}
+
+	for _, relation := range postRelations {
+		if f.Loads(relation) {
+			keys = append(keys, relation)
+		}
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+func sanitiseList(items []string) []string {
+	list := make([]string, 0, len(items))
+
+	for _, item := range items {
+		for _, part := range strings.Split(item, ",") {
+			part = strings.ToLower(strings.TrimSpace(part))
+
+			if part != "" && !slices.Contains(list, part) {
+				list = append(list, part)
+			}
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,100 @@
+package queries_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/oullin/database/repository/queries"
+)
+
+func TestPostFieldsetDefaultsLoadEverything(t *testing.T) {
+	f := queries.NewPostFieldset(nil, nil)
+
+	if f.IsSparse() {
+		t.Fatalf("expected default fieldset not to be sparse")
+	}
+
+	if f.Columns() != nil {
+		t.Fatalf("expected every column to be selected, got %v", f.Columns())
+	}
+
+	for _, relation := range []string{queries.PostRelationAuthor, queries.PostRelationCategories, queries.PostRelationTags} {
+		if !f.Loads(relation) {
+			t.Fatalf("expected relation %s to be loaded", relation)
+		}
+	}
+}
+
+func TestPostFieldsetNarrowsColumnsAndRelations(t *testing.T) {
+	f := queries.NewPostFieldset([]string{" Slug,title ", "tags", "slug"}, nil)
+
+	if err := f.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	want := []string{"posts.id", "posts.published_at", "posts.slug", "posts.title"}
+	if !slices.Equal(f.Columns(), want) {
+		t.Fatalf("expected columns %v, got %v", want, f.Columns())
+	}
+
+	if f.Loads(queries.PostRelationAuthor) || f.Loads(queries.PostRelationCategories) {
+		t.Fatalf("expected only tags to be loaded")
+	}
+
+	if !f.Loads(queries.PostRelationTags) {
+		t.Fatalf("expected tags to be loaded")
+	}
+
+	if keys := f.Keys(); !slices.Equal(keys, []string{"slug", "tags", "title"}) {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+}
+
+func TestPostFieldsetInclude(t *testing.T) {
+	none := queries.NewPostFieldset(nil, []string{})
+
+	if !none.IsSparse() || none.Loads(queries.PostRelationAuthor) {
+		t.Fatalf("expected an empty include to disable relations")
+	}
+
+	if slices.Contains(none.Keys(), "author") || !slices.Contains(none.Keys(), "content") {
+		t.Fatalf("unexpected keys %v", none.Keys())
+	}
+
+	author := queries.NewPostFieldset([]string{"slug"}, []string{"author"})
+
+	if !author.Loads(queries.PostRelationAuthor) {
+		t.Fatalf("expected author to be loaded")
+	}
+
+	if !slices.Contains(author.Columns(), "posts.author_id") {
+		t.Fatalf("expected author_id to be selected, got %v", author.Columns())
+	}
+}
+
+func TestPostFieldsetValidate(t *testing.T) {
+	if err := queries.NewPostFieldset([]string{"password"}, nil).Validate(); err == nil {
+		t.Fatalf("expected unknown field to be rejected")
+	}
+
+	if err := queries.NewPostFieldset(nil, []string{"comments"}).Validate(); err == nil {
+		t.Fatalf("expected unknown relation to be rejected")
+	}
+}
+
+func TestPostFieldsetCoverPlaceholderSelectsEveryColumn(t *testing.T) {
+	f := queries.NewPostFieldset([]string{"cover_placeholder"}, nil)
+
+	if err := f.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	want := []string{"posts.id", "posts.published_at", "posts.cover_blur_hash", "posts.cover_dominant_color", "posts.cover_width", "posts.cover_height"}
+	if !slices.Equal(f.Columns(), want) {
+		t.Fatalf("expected columns %v, got %v", want, f.Columns())
+	}
+
+	if keys := f.Keys(); !slices.Equal(keys, []string{"cover_placeholder"}) {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+}
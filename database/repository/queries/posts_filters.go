@@ -3,15 +3,28 @@ package queries
 import (
 	"github.com/oullin/pkg/portal"
 
+	"slices"
 	"strings"
+	"time"
 )
 
+const PostsMatchAny = "any"
+const PostsMatchAll = "all"
+
 type PostFilters struct {
-	Text     string
-	Title    string // Will perform a case-insensitive partial match
-	Author   string
-	Category string
-	Tag      string
+	Text            string
+	Title           string // Will perform a case-insensitive partial match
+	Author          string
+	Category        string
+	Tag             string
+	Categories      []string   // Exact category slugs, matched according to CategoriesMode
+	CategoriesMode  string     // PostsMatchAny (default) or PostsMatchAll
+	Tags            []string   // Exact tag slugs, matched according to TagsMode
+	TagsMode        string     // PostsMatchAny (default) or PostsMatchAll
+	ExcludeTags     []string   // Posts linked to any of these tag slugs are discarded
+	Sort            string     // One of GetPostsSorts(); defaults to newest first
+	PublishedAfter  *time.Time // Inclusive lower bound on posts.published_at
+	PublishedBefore *time.Time // Exclusive upper bound on posts.published_at
 }
 
 func (f PostFilters) GetText() string {
@@ -34,8 +47,58 @@ func (f PostFilters) GetTag() string {
 	return f.sanitiseString(f.Tag)
 }
 
+func (f PostFilters) GetCategories() []string {
+	return f.sanitiseSlugs(f.Categories)
+}
+
+func (f PostFilters) GetCategoriesMode() string {
+	return f.sanitiseMode(f.CategoriesMode)
+}
+
+func (f PostFilters) GetTags() []string {
+	return f.sanitiseSlugs(f.Tags)
+}
+
+func (f PostFilters) GetTagsMode() string {
+	return f.sanitiseMode(f.TagsMode)
+}
+
+func (f PostFilters) GetExcludeTags() []string {
+	return f.sanitiseSlugs(f.ExcludeTags)
+}
+
+func (f PostFilters) GetSort() string {
+	sort := f.sanitiseString(f.Sort)
+
+	if !slices.Contains(GetPostsSorts(), sort) {
+		return PostsSortNewest
+	}
+
+	return sort
+}
+
 func (f PostFilters) sanitiseString(seed string) string {
 	str := portal.NewStringable(seed)
 
 	return strings.TrimSpace(str.ToLower())
 }
+
+func (f PostFilters) sanitiseSlugs(seeds []string) []string {
+	var slugs []string
+
+	for _, seed := range seeds {
+		if slug := f.sanitiseString(seed); slug != "" && !slices.Contains(slugs, slug) {
+			slugs = append(slugs, slug)
+		}
+	}
+
+	return slugs
+}
+
+func (f PostFilters) sanitiseMode(seed string) string {
+	if f.sanitiseString(seed) == PostsMatchAll {
+		return PostsMatchAll
+	}
+
+	return PostsMatchAny
+}
@@ -35,3 +35,32 @@ func TestPostFiltersSanitise(t *testing.T) {
 		t.Fatalf("expected GetTag to return 'tag', got %s", f.GetTag())
 	}
 }
+
+func TestPostFiltersSlugLists(t *testing.T) {
+	f := queries.PostFilters{
+		Tags:        []string{" Go ", "go", "", "Postgres"},
+		TagsMode:    " ALL ",
+		Categories:  []string{"Engineering"},
+		ExcludeTags: []string{"  Draft"},
+	}
+
+	if tags := f.GetTags(); len(tags) != 2 || tags[0] != "go" || tags[1] != "postgres" {
+		t.Fatalf("expected deduplicated lower-case tags, got %v", tags)
+	}
+
+	if f.GetTagsMode() != queries.PostsMatchAll {
+		t.Fatalf("expected all mode, got %s", f.GetTagsMode())
+	}
+
+	if f.GetCategoriesMode() != queries.PostsMatchAny {
+		t.Fatalf("expected any mode by default, got %s", f.GetCategoriesMode())
+	}
+
+	if c := f.GetCategories(); len(c) != 1 || c[0] != "engineering" {
+		t.Fatalf("unexpected categories %v", c)
+	}
+
+	if e := f.GetExcludeTags(); len(e) != 1 || e[0] != "draft" {
+		t.Fatalf("unexpected excluded tags %v", e)
+	}
+}
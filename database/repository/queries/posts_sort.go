@@ -0,0 +1,81 @@
+package queries
+
+import (
+	"strings"
+)
+
+const PostsSortNewest = "newest"
+const PostsSortOldest = "oldest"
+const PostsSortTitle = "title"
+const PostsSortMostViewed = "most_viewed"
+const PostsSortMostLiked = "most_liked"
+
+const postViewsCount = "(SELECT COUNT(*) FROM post_views WHERE post_views.post_id = posts.id)"
+const postLikesCount = "(SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id AND likes.deleted_at IS NULL)"
+
+type postsOrderColumn struct {
+	expression string
+	direction  string
+}
+
+// PostsOrder keeps the ORDER BY and the DISTINCT ON clauses of a posts listing in sync,
+// since Postgres requires the DISTINCT ON expressions to lead the ORDER BY ones.
+type PostsOrder struct {
+	columns []postsOrderColumn
+}
+
+func GetPostsSorts() []string {
+	return []string{
+		PostsSortNewest,
+		PostsSortOldest,
+		PostsSortTitle,
+		PostsSortMostViewed,
+		PostsSortMostLiked,
+	}
+}
+
+func NewPostsOrder(sort string) PostsOrder {
+	newest := []postsOrderColumn{
+		{expression: "posts.published_at", direction: "DESC"},
+		{expression: "posts.id", direction: "DESC"},
+	}
+
+	switch sort {
+	case PostsSortOldest:
+		return PostsOrder{columns: []postsOrderColumn{
+			{expression: "posts.published_at", direction: "ASC"},
+			{expression: "posts.id", direction: "ASC"},
+		}}
+	case PostsSortTitle:
+		return PostsOrder{columns: []postsOrderColumn{
+			{expression: "posts.title", direction: "ASC"},
+			{expression: "posts.id", direction: "ASC"},
+		}}
+	case PostsSortMostViewed:
+		return PostsOrder{columns: append([]postsOrderColumn{{expression: postViewsCount, direction: "DESC"}}, newest...)}
+	case PostsSortMostLiked:
+		return PostsOrder{columns: append([]postsOrderColumn{{expression: postLikesCount, direction: "DESC"}}, newest...)}
+	default:
+		return PostsOrder{columns: newest}
+	}
+}
+
+func (o PostsOrder) OrderBy() string {
+	parts := make([]string, 0, len(o.columns))
+
+	for _, column := range o.columns {
+		parts = append(parts, column.expression+" "+column.direction)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func (o PostsOrder) DistinctOn() string {
+	parts := make([]string, 0, len(o.columns))
+
+	for _, column := range o.columns {
+		parts = append(parts, column.expression)
+	}
+
+	return "DISTINCT ON (" + strings.Join(parts, ", ") + ")"
+}
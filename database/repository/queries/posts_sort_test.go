@@ -0,0 +1,55 @@
+package queries_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oullin/database/repository/queries"
+)
+
+func TestPostFiltersGetSortDefaultsToNewest(t *testing.T) {
+	if s := (queries.PostFilters{}).GetSort(); s != queries.PostsSortNewest {
+		t.Fatalf("expected newest, got %s", s)
+	}
+
+	if s := (queries.PostFilters{Sort: "bogus"}).GetSort(); s != queries.PostsSortNewest {
+		t.Fatalf("expected unknown sort to fall back to newest, got %s", s)
+	}
+
+	if s := (queries.PostFilters{Sort: " Most_Liked "}).GetSort(); s != queries.PostsSortMostLiked {
+		t.Fatalf("expected most_liked, got %s", s)
+	}
+}
+
+func TestPostsOrderKeepsDistinctOnInSync(t *testing.T) {
+	for _, sort := range queries.GetPostsSorts() {
+		order := queries.NewPostsOrder(sort)
+
+		distinct := strings.TrimSuffix(strings.TrimPrefix(order.DistinctOn(), "DISTINCT ON ("), ")")
+		orderBy := strings.NewReplacer(" DESC", "", " ASC", "").Replace(order.OrderBy())
+
+		if distinct != orderBy {
+			t.Fatalf("sort %s: distinct on %q does not match order by %q", sort, distinct, orderBy)
+		}
+	}
+}
+
+func TestPostsOrderNewest(t *testing.T) {
+	order := queries.NewPostsOrder(queries.PostsSortNewest)
+
+	if order.OrderBy() != "posts.published_at DESC, posts.id DESC" {
+		t.Fatalf("unexpected order: %s", order.OrderBy())
+	}
+
+	if order.DistinctOn() != "DISTINCT ON (posts.published_at, posts.id)" {
+		t.Fatalf("unexpected distinct on: %s", order.DistinctOn())
+	}
+}
+
+func TestPostsOrderMostViewedCountsViews(t *testing.T) {
+	order := queries.NewPostsOrder(queries.PostsSortMostViewed)
+
+	if !strings.HasPrefix(order.OrderBy(), "(SELECT COUNT(*) FROM post_views") {
+		t.Fatalf("unexpected order: %s", order.OrderBy())
+	}
+}
@@ -0,0 +1,76 @@
+package queries_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository/queries"
+)
+
+func dryRunPostsSQL(t *testing.T, filters queries.PostFilters) string {
+	t.Helper()
+
+	sqlDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, PreferSimpleProtocol: true}), &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+
+	query := gdb.Model(&database.Post{}).Where("posts.deleted_at is null")
+	queries.ApplyPostsFilters(&filters, query)
+
+	var posts []database.Post
+	stmt := query.Find(&posts).Statement
+
+	return stmt.SQL.String()
+}
+
+func TestApplyPostsFiltersUsesExistsInsteadOfJoins(t *testing.T) {
+	sql := dryRunPostsSQL(t, queries.PostFilters{
+		Category:    "eng",
+		Tags:        []string{"go", "postgres"},
+		TagsMode:    queries.PostsMatchAll,
+		ExcludeTags: []string{"draft"},
+	})
+
+	if strings.Contains(sql, "JOIN post_tags ON post_tags.post_id") || strings.Contains(sql, "JOIN post_categories ON post_categories.post_id") {
+		t.Fatalf("expected no row-multiplying joins, got %s", sql)
+	}
+
+	if got := strings.Count(sql, "EXISTS (SELECT 1 FROM post_tags"); got != 3 {
+		t.Fatalf("expected one EXISTS per required tag plus the exclusion, got %d in %s", got, sql)
+	}
+
+	if !strings.Contains(sql, "NOT EXISTS (SELECT 1 FROM post_tags") {
+		t.Fatalf("expected excluded tags to use NOT EXISTS, got %s", sql)
+	}
+
+	if !strings.Contains(sql, "EXISTS (SELECT 1 FROM post_categories") {
+		t.Fatalf("expected category filter to use EXISTS, got %s", sql)
+	}
+}
+
+func TestApplyPostsFiltersAnyTagsUsesSingleExists(t *testing.T) {
+	sql := dryRunPostsSQL(t, queries.PostFilters{
+		Tags: []string{"go", "postgres"},
+	})
+
+	if got := strings.Count(sql, "EXISTS (SELECT 1 FROM post_tags"); got != 1 {
+		t.Fatalf("expected a single EXISTS for any-of tags, got %d in %s", got, sql)
+	}
+
+	if !strings.Contains(sql, "LOWER(tags.slug) IN") {
+		t.Fatalf("expected an IN list for any-of tags, got %s", sql)
+	}
+}
@@ -0,0 +1,19 @@
+package repoentity
+
+import (
+	"time"
+)
+
+type TrashedItem struct {
+	Kind       string
+	Identifier string // Slug for posts, categories and tags; UUID for comments and likes.
+	Label      string
+	DeletedAt  time.Time
+}
+
+// TrashPurgeResult counts the permanently deleted rows per kind, plus the
+// comments kept because they still have replies outside the purge window.
+type TrashPurgeResult struct {
+	Purged          map[string]int64
+	SkippedComments int64
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository/repoentity"
+	"github.com/oullin/pkg/model"
+)
+
+const TrashPosts = "posts"
+const TrashCategories = "categories"
+const TrashTags = "tags"
+const TrashComments = "comments"
+const TrashLikes = "likes"
+
+// Trash manages soft-deleted content. Pivot links (post_categories, post_tags) are
+// never removed by a soft delete, so restoring an item brings its links back into effect.
+type Trash struct {
+	DB *database.Connection
+}
+
+func GetTrashKinds() []string {
+	return []string{TrashPosts, TrashCategories, TrashTags, TrashComments, TrashLikes}
+}
+
+func (t Trash) List(kind string) ([]repoentity.TrashedItem, error) {
+	var items []repoentity.TrashedItem
+
+	for _, current := range GetTrashKinds() {
+		if kind != "" && kind != current {
+			continue
+		}
+
+		var rows []struct {
+			Identifier string
+			Label      string
+			DeletedAt  time.Time
+		}
+
+		result := t.DB.Sql().
+			Table(current).
+			Select(trashColumns(current)).
+			Where("deleted_at IS NOT NULL").
+			Order("deleted_at DESC").
+			Scan(&rows)
+
+		if model.HasDbIssues(result.Error) {
+			return nil, fmt.Errorf("issue listing trashed %s: %s", current, result.Error)
+		}
+
+		for _, row := range rows {
+			items = append(items, repoentity.TrashedItem{
+				Kind:       current,
+				Identifier: row.Identifier,
+				Label:      row.Label,
+				DeletedAt:  row.DeletedAt,
+			})
+		}
+	}
+
+	return items, nil
+}
+
+// Restore brings a trashed item back. Restoring a post also restores the comments
+// and likes that were trashed with it, or after it.
+func (t Trash) Restore(kind, identifier string) error {
+	if !slices.Contains(GetTrashKinds(), kind) {
+		return fmt.Errorf("unknown trash kind [%s]", kind)
+	}
+
+	return t.DB.Transaction(func(tx *gorm.DB) error {
+		var deletedAt *time.Time
+		var id uint64
+
+		row := tx.Table(kind).
+			Select("id, deleted_at").
+			Where(trashIdentifierColumn(kind)+" = ?", strings.ToLower(strings.TrimSpace(identifier))).
+			Where("deleted_at IS NOT NULL").
+			Row()
+
+		if err := row.Scan(&id, &deletedAt); err != nil {
+			return fmt.Errorf("the given %s [%s] is not in the trash: %w", kind, identifier, err)
+		}
+
+		if result := tx.Table(kind).Where("id = ?", id).Update("deleted_at", nil); model.HasDbIssues(result.Error) {
+			return fmt.Errorf("issue restoring %s [%s]: %s", kind, identifier, result.Error)
+		}
+
+		if kind != TrashPosts {
+			return nil
+		}
+
+		for _, dependant := range []string{TrashComments, TrashLikes} {
+			result := tx.Table(dependant).
+				Where("post_id = ?", id).
+				Where("deleted_at >= ?", *deletedAt).
+				Update("deleted_at", nil)
+
+			if model.HasDbIssues(result.Error) {
+				return fmt.Errorf("issue restoring %s of post [%s]: %s", dependant, identifier, result.Error)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Purge permanently removes items trashed before the given cutoff.
+// Rows covered by the OnDelete:CASCADE constraints declared on the models (post views,
+// comments and likes of a post) are left to the database; pivot links and comment
+// replies carry no such constraint, so they are removed explicitly first.
+func (t Trash) Purge(cutoff time.Time) (*repoentity.TrashPurgeResult, error) {
+	output := repoentity.TrashPurgeResult{Purged: map[string]int64{}}
+
+	err := t.DB.Transaction(func(tx *gorm.DB) error {
+		trashed := func(table string) *gorm.DB {
+			return tx.Table(table).Select("id").Where("deleted_at IS NOT NULL").Where("deleted_at < ?", cutoff)
+		}
+
+		result := tx.Where("id IN (?)", trashed(TrashLikes)).Unscoped().Delete(&database.Like{})
+		if model.HasDbIssues(result.Error) {
+			return fmt.Errorf("issue purging likes: %s", result.Error)
+		}
+
+		output.Purged[TrashLikes] = result.RowsAffected
+
+		purgedComments, err := purgeComments(tx, cutoff)
+		if err != nil {
+			return err
+		}
+
+		output.Purged[TrashComments] = purgedComments
+
+		for _, pivot := range []struct {
+			table  string
+			model  any
+			column string
+		}{
+			{table: TrashPosts, model: &database.PostCategory{}, column: "post_id"},
+			{table: TrashPosts, model: &database.PostTag{}, column: "post_id"},
+			{table: TrashCategories, model: &database.PostCategory{}, column: "category_id"},
+			{table: TrashTags, model: &database.PostTag{}, column: "tag_id"},
+		} {
+			if result = tx.Where(pivot.column+" IN (?)", trashed(pivot.table)).Delete(pivot.model); model.HasDbIssues(result.Error) {
+				return fmt.Errorf("issue purging %s links: %s", pivot.table, result.Error)
+			}
+		}
+
+		for _, parent := range []struct {
+			table string
+			model any
+		}{
+			{table: TrashPosts, model: &database.Post{}},
+			{table: TrashCategories, model: &database.Category{}},
+			{table: TrashTags, model: &database.Tag{}},
+		} {
+			result = tx.Where("id IN (?)", trashed(parent.table)).Unscoped().Delete(parent.model)
+			if model.HasDbIssues(result.Error) {
+				return fmt.Errorf("issue purging %s: %s", parent.table, result.Error)
+			}
+
+			output.Purged[parent.table] = result.RowsAffected
+		}
+
+		var skipped int64
+		if result = trashed(TrashComments).Count(&skipped); model.HasDbIssues(result.Error) {
+			return fmt.Errorf("issue counting kept comments: %s", result.Error)
+		}
+
+		output.SkippedComments = skipped
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+// purgeComments deletes trashed comments leaf first, so a reply is always gone before
+// its parent. Comments whose replies are still live (or recent) stay in the trash.
+func purgeComments(tx *gorm.DB, cutoff time.Time) (int64, error) {
+	var total int64
+
+	for {
+		result := tx.
+			Where("deleted_at IS NOT NULL").
+			Where("deleted_at < ?", cutoff).
+			Where("NOT EXISTS (SELECT 1 FROM comments AS replies WHERE replies.parent_id = comments.id)").
+			Unscoped().
+			Delete(&database.Comment{})
+
+		if model.HasDbIssues(result.Error) {
+			return total, fmt.Errorf("issue purging comments: %s", result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return total, nil
+		}
+
+		total += result.RowsAffected
+	}
+}
+
+func trashIdentifierColumn(kind string) string {
+	switch kind {
+	case TrashComments, TrashLikes:
+		return "uuid::text"
+	default:
+		return "LOWER(slug)"
+	}
+}
+
+func trashColumns(kind string) string {
+	switch kind {
+	case TrashPosts:
+		return "slug AS identifier, title AS label, deleted_at"
+	case TrashComments:
+		return "uuid::text AS identifier, LEFT(content, 60) AS label, deleted_at"
+	case TrashLikes:
+		return "uuid::text AS identifier, 'post #' || post_id AS label, deleted_at"
+	default:
+		return "slug AS identifier, name AS label, deleted_at"
+	}
+}
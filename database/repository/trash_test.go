@@ -0,0 +1,151 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/internal/testutil/dbtest"
+)
+
+func newTrashHelper(t *testing.T) *dbtest.TestsHelper {
+	t.Helper()
+
+	return dbtest.NewTestsHelper(t,
+		&database.User{},
+		&database.Post{},
+		&database.PostCoverImage{},
+		&database.Category{},
+		&database.PostCategory{},
+		&database.Tag{},
+		&database.PostTag{},
+		&database.PostView{},
+		&database.Comment{},
+		&database.Like{},
+	)
+}
+
+func trashAt(t *testing.T, conn *database.Connection, table string, id uint64, at time.Time) {
+	t.Helper()
+
+	if err := conn.Sql().Table(table).Where("id = ?", id).Update("deleted_at", at).Error; err != nil {
+		t.Fatalf("trash %s: %v", table, err)
+	}
+}
+
+func TestTrashListAndRestorePostPostgres(t *testing.T) {
+	h := newTrashHelper(t)
+	conn := h.Conn()
+
+	user := h.SeedUser("Alice", "Smith", "alice")
+	category := h.SeedCategory("tech", "Tech", 1)
+	tag := h.SeedTag("go", "Go")
+	post := h.SeedPost(user, category, tag, "trashed-post", "Trashed Post", true)
+
+	comment := database.Comment{UUID: uuid.NewString(), PostID: post.ID, AuthorID: user.ID, Content: "Nice"}
+	if err := conn.Sql().Create(&comment).Error; err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+
+	deletedAt := time.Now().UTC().Add(-time.Hour)
+	trashAt(t, conn, "posts", post.ID, deletedAt)
+	trashAt(t, conn, "comments", comment.ID, deletedAt)
+
+	trash := repository.Trash{DB: conn}
+
+	items, err := trash.List(repository.TrashPosts)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+
+	if len(items) != 1 || items[0].Identifier != "trashed-post" || items[0].Label != "Trashed Post" {
+		t.Fatalf("unexpected trashed posts: %+v", items)
+	}
+
+	all, err := trash.List("")
+	if err != nil {
+		t.Fatalf("list all: %v", err)
+	}
+
+	if len(all) != 2 {
+		t.Fatalf("expected post and comment in the trash, got %+v", all)
+	}
+
+	if err := trash.Restore(repository.TrashPosts, "Trashed-Post"); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	if left, _ := trash.List(""); len(left) != 0 {
+		t.Fatalf("expected an empty trash after restoring the post, got %+v", left)
+	}
+
+	if err := trash.Restore(repository.TrashPosts, "trashed-post"); err == nil {
+		t.Fatalf("expected restoring a live post to fail")
+	}
+
+	if err := trash.Restore("users", "alice"); err == nil {
+		t.Fatalf("expected unknown kinds to be rejected")
+	}
+}
+
+func TestTrashPurgeRespectsRetentionAndRepliesPostgres(t *testing.T) {
+	h := newTrashHelper(t)
+	conn := h.Conn()
+
+	user := h.SeedUser("Alice", "Smith", "alice")
+	category := h.SeedCategory("tech", "Tech", 1)
+	tag := h.SeedTag("go", "Go")
+
+	old := h.SeedPost(user, category, tag, "old-post", "Old Post", true)
+	recent := h.SeedPost(user, category, tag, "recent-post", "Recent Post", true)
+	live := h.SeedPost(user, category, tag, "live-post", "Live Post", true)
+
+	parent := database.Comment{UUID: uuid.NewString(), PostID: live.ID, AuthorID: user.ID, Content: "Parent"}
+	if err := conn.Sql().Create(&parent).Error; err != nil {
+		t.Fatalf("create parent comment: %v", err)
+	}
+
+	reply := database.Comment{UUID: uuid.NewString(), PostID: live.ID, AuthorID: user.ID, ParentID: &parent.ID, Content: "Reply"}
+	if err := conn.Sql().Create(&reply).Error; err != nil {
+		t.Fatalf("create reply: %v", err)
+	}
+
+	now := time.Now().UTC()
+	trashAt(t, conn, "posts", old.ID, now.AddDate(0, 0, -40))
+	trashAt(t, conn, "posts", recent.ID, now.AddDate(0, 0, -2))
+	trashAt(t, conn, "comments", parent.ID, now.AddDate(0, 0, -40))
+
+	result, err := repository.Trash{DB: conn}.Purge(now.AddDate(0, 0, -30))
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+
+	if result.Purged[repository.TrashPosts] != 1 {
+		t.Fatalf("expected one purged post, got %+v", result.Purged)
+	}
+
+	if result.Purged[repository.TrashComments] != 0 || result.SkippedComments != 1 {
+		t.Fatalf("expected the parent comment to be kept, got %+v", result)
+	}
+
+	var posts int64
+	if err := conn.Sql().Unscoped().Model(&database.Post{}).Count(&posts).Error; err != nil {
+		t.Fatalf("count posts: %v", err)
+	}
+
+	if posts != 2 {
+		t.Fatalf("expected the recent and live posts to remain, got %d", posts)
+	}
+
+	var links int64
+	if err := conn.Sql().Model(&database.PostCategory{}).Where("post_id = ?", old.ID).Count(&links).Error; err != nil {
+		t.Fatalf("count links: %v", err)
+	}
+
+	if links != 0 {
+		t.Fatalf("expected the purged post links to be removed, got %d", links)
+	}
+}
@@ -73,6 +73,7 @@ func testConnection(t *testing.T, e *env.Environment) *database.Connection {
 	if err := conn.Sql().AutoMigrate(
 		&database.User{},
 		&database.Post{},
+		&database.PostCoverImage{},
 		&database.Category{},
 		&database.PostCategory{},
 		&database.Tag{},
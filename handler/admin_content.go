@@ -0,0 +1,378 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/handler/payload"
+	"github.com/oullin/pkg/endpoint"
+	"github.com/oullin/pkg/portal"
+)
+
+// AdminContentHandler writes one list section of the site content, e.g. talks. Requests
+// and responses use the same items the public route lists, so D is the payload item and M
+// the row it is stored as.
+type AdminContentHandler[D any, M repository.ContentItem] struct {
+	Content   *repository.Content
+	Validator *portal.Validator
+	section   string
+	uuidOf    func(*D) *string
+	fill      func(*M, D) error
+	present   func(M) D
+	onChange  func()
+}
+
+// OnChange is called after every write, so the public route can reload at once.
+func (h AdminContentHandler[D, M]) OnChange(callback func()) AdminContentHandler[D, M] {
+	h.onChange = callback
+
+	return h
+}
+
+func NewAdminExperienceHandler(content *repository.Content, validator *portal.Validator) AdminContentHandler[payload.ExperienceData, database.Experience] {
+	return AdminContentHandler[payload.ExperienceData, database.Experience]{
+		Content:   content,
+		Validator: validator,
+		section:   "experience",
+		uuidOf:    func(data *payload.ExperienceData) *string { return &data.UUID },
+		fill:      payload.FillExperience,
+		present:   payload.GetExperienceData,
+	}
+}
+
+func NewAdminEducationHandler(content *repository.Content, validator *portal.Validator) AdminContentHandler[payload.EducationData, database.Education] {
+	return AdminContentHandler[payload.EducationData, database.Education]{
+		Content:   content,
+		Validator: validator,
+		section:   "education",
+		uuidOf:    func(data *payload.EducationData) *string { return &data.UUID },
+		fill:      payload.FillEducation,
+		present:   payload.GetEducationData,
+	}
+}
+
+func NewAdminTalksHandler(content *repository.Content, validator *portal.Validator) AdminContentHandler[payload.TalksData, database.Talk] {
+	return AdminContentHandler[payload.TalksData, database.Talk]{
+		Content:   content,
+		Validator: validator,
+		section:   "talks",
+		uuidOf:    func(data *payload.TalksData) *string { return &data.UUID },
+		fill:      payload.FillTalk,
+		present:   payload.GetTalksData,
+	}
+}
+
+func NewAdminLinksHandler(content *repository.Content, validator *portal.Validator) AdminContentHandler[payload.LinksData, database.Link] {
+	return AdminContentHandler[payload.LinksData, database.Link]{
+		Content:   content,
+		Validator: validator,
+		section:   "links",
+		uuidOf:    func(data *payload.LinksData) *string { return &data.UUID },
+		fill:      payload.FillLink,
+		present:   payload.GetLinksData,
+	}
+}
+
+func NewAdminProjectsHandler(content *repository.Content, validator *portal.Validator) AdminContentHandler[payload.ProjectsData, database.Project] {
+	return AdminContentHandler[payload.ProjectsData, database.Project]{
+		Content:   content,
+		Validator: validator,
+		section:   "projects",
+		uuidOf:    func(data *payload.ProjectsData) *string { return &data.UUID },
+		fill:      payload.FillProject,
+		present:   payload.GetProjectsData,
+	}
+}
+
+func NewAdminRecommendationsHandler(content *repository.Content, validator *portal.Validator) AdminContentHandler[payload.RecommendationsData, database.Recommendation] {
+	return AdminContentHandler[payload.RecommendationsData, database.Recommendation]{
+		Content:   content,
+		Validator: validator,
+		section:   "recommendations",
+		uuidOf:    func(data *payload.RecommendationsData) *string { return &data.UUID },
+		fill:      payload.FillRecommendation,
+		present:   payload.GetRecommendationsData,
+	}
+}
+
+func (h AdminContentHandler[D, M]) Show(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	item, apiErr := h.find(r)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	return h.respondWith(w, r, *item, http.StatusOK)
+}
+
+// Store adds the item at the end of the section, under a new UUID unless one is given.
+func (h AdminContentHandler[D, M]) Store(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	defer portal.CloseWithLog(r.Body)
+
+	if apiErr := h.requireImported(); apiErr != nil {
+		return apiErr
+	}
+
+	data, apiErr := h.parse(w, r, "")
+	if apiErr != nil {
+		return apiErr
+	}
+
+	existing, err := repository.FindContent[M](*h.Content, *h.uuidOf(&data))
+	if err != nil {
+		return endpoint.LogInternalError(fmt.Sprintf("could not read the given %s item", h.section), err)
+	}
+
+	if existing != nil {
+		return endpoint.Conflict(fmt.Sprintf("The given %s item '%s' already exists", h.section, *h.uuidOf(&data)))
+	}
+
+	var item M
+	if err := h.fill(&item, data); err != nil {
+		return endpoint.LogBadRequestError("could not parse the given data.", err)
+	}
+
+	if err := repository.CreateContent(*h.Content, &item); err != nil {
+		return endpoint.LogInternalError(fmt.Sprintf("could not create the given %s item", h.section), err)
+	}
+
+	h.changed()
+
+	return h.respondWith(w, r, item, http.StatusCreated)
+}
+
+// Update replaces every field of the item; its UUID and place in the section are kept.
+func (h AdminContentHandler[D, M]) Update(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	defer portal.CloseWithLog(r.Body)
+
+	item, apiErr := h.find(r)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	data, apiErr := h.parse(w, r, h.itemUUID(*item))
+	if apiErr != nil {
+		return apiErr
+	}
+
+	if err := h.fill(item, data); err != nil {
+		return endpoint.LogBadRequestError("could not parse the given data.", err)
+	}
+
+	if err := repository.SaveContent(*h.Content, item); err != nil {
+		return endpoint.LogInternalError(fmt.Sprintf("could not update the given %s item", h.section), err)
+	}
+
+	h.changed()
+
+	return h.respondWith(w, r, *item, http.StatusOK)
+}
+
+func (h AdminContentHandler[D, M]) Destroy(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	item, apiErr := h.find(r)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	if err := repository.DeleteContent(*h.Content, item); err != nil {
+		return endpoint.LogInternalError(fmt.Sprintf("could not delete the given %s item", h.section), err)
+	}
+
+	h.changed()
+
+	endpoint.NewNoCacheResponse(w, r).RespondNoContent()
+
+	return nil
+}
+
+func (h AdminContentHandler[D, M]) find(r *http.Request) (*M, *endpoint.ApiError) {
+	id := strings.TrimSpace(r.PathValue("uuid"))
+
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, endpoint.BadRequestError(fmt.Sprintf("A valid UUID is required to find %s items", h.section))
+	}
+
+	item, err := repository.FindContent[M](*h.Content, id)
+	if err != nil {
+		return nil, endpoint.LogInternalError(fmt.Sprintf("could not read the given %s item", h.section), err)
+	}
+
+	if item == nil {
+		return nil, endpoint.NotFound(fmt.Sprintf("The given %s item '%s' was not found", h.section, id))
+	}
+
+	return item, nil
+}
+
+// parse decodes and validates the item. The UUID of an existing item always wins over the
+// body; a new item without one gets a fresh UUID.
+func (h AdminContentHandler[D, M]) parse(w http.ResponseWriter, r *http.Request, existing string) (D, *endpoint.ApiError) {
+	var data D
+
+	r.Body = http.MaxBytesReader(w, r.Body, endpoint.MaxRequestSize)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&data); err != nil {
+		return data, endpoint.LogBadRequestError("could not parse the given data.", err)
+	}
+
+	switch id := h.uuidOf(&data); {
+	case existing != "":
+		*id = existing
+	case strings.TrimSpace(*id) == "":
+		*id = uuid.NewString()
+	}
+
+	if apiErr := validateContent(h.Validator, &data); apiErr != nil {
+		return data, apiErr
+	}
+
+	return data, nil
+}
+
+func (h AdminContentHandler[D, M]) requireImported() *endpoint.ApiError {
+	return requireImportedSection(h.Content, h.section)
+}
+
+func (h AdminContentHandler[D, M]) itemUUID(item M) string {
+	data := h.present(item)
+
+	return *h.uuidOf(&data)
+}
+
+func (h AdminContentHandler[D, M]) changed() {
+	if h.onChange != nil {
+		h.onChange()
+	}
+}
+
+func (h AdminContentHandler[D, M]) respondWith(w http.ResponseWriter, r *http.Request, item M, status int) *endpoint.ApiError {
+	resp := endpoint.NewNoCacheResponse(w, r)
+
+	if err := resp.RespondWithStatus(status, h.present(item)); err != nil {
+		slog.Error("Error marshaling JSON for admin content response", "section", h.section, "error", err)
+
+		return endpoint.InternalError(fmt.Sprintf("could not encode the %s item", h.section))
+	}
+
+	return nil
+}
+
+// AdminProfileHandler replaces the profile and its skills.
+type AdminProfileHandler struct {
+	Content   *repository.Content
+	Validator *portal.Validator
+	onChange  func()
+}
+
+func NewAdminProfileHandler(content *repository.Content, validator *portal.Validator) AdminProfileHandler {
+	return AdminProfileHandler{
+		Content:   content,
+		Validator: validator,
+	}
+}
+
+// OnChange is called after every write, so the public route can reload at once.
+func (h AdminProfileHandler) OnChange(callback func()) AdminProfileHandler {
+	h.onChange = callback
+
+	return h
+}
+
+func (h AdminProfileHandler) Update(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	defer portal.CloseWithLog(r.Body)
+
+	if apiErr := requireImportedSection(h.Content, "profile"); apiErr != nil {
+		return apiErr
+	}
+
+	var data payload.ProfileDataResponse
+
+	r.Body = http.MaxBytesReader(w, r.Body, endpoint.MaxRequestSize)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&data); err != nil {
+		return endpoint.LogBadRequestError("could not parse the given data.", err)
+	}
+
+	for i := range data.Skills {
+		if strings.TrimSpace(data.Skills[i].Uuid) == "" {
+			data.Skills[i].Uuid = uuid.NewString()
+		}
+	}
+
+	if apiErr := validateContent(h.Validator, &data); apiErr != nil {
+		return apiErr
+	}
+
+	profile, err := h.Content.Profile()
+	if err != nil {
+		return endpoint.LogInternalError("could not read the profile", err)
+	}
+
+	if profile == nil {
+		profile = &database.Profile{}
+	}
+
+	payload.FillProfile(profile, data)
+
+	if err = h.Content.SaveProfile(profile); err != nil {
+		return endpoint.LogInternalError("could not update the profile", err)
+	}
+
+	if h.onChange != nil {
+		h.onChange()
+	}
+
+	resp := endpoint.NewNoCacheResponse(w, r)
+
+	if err = resp.RespondOk(payload.GetProfileResponse("", *profile).Data); err != nil {
+		slog.Error("Error marshaling JSON for admin profile response", "error", err)
+
+		return endpoint.InternalError("could not encode the profile")
+	}
+
+	return nil
+}
+
+// requireImportedSection refuses writes to a section still served from its fixture file,
+// where they would never show.
+func requireImportedSection(content *repository.Content, section string) *endpoint.ApiError {
+	imported, err := content.Section(section)
+	if err != nil {
+		return endpoint.LogInternalError(fmt.Sprintf("could not read the %s section", section), err)
+	}
+
+	if imported == nil {
+		return endpoint.Conflict(fmt.Sprintf("The %s section has not been imported into the database yet", section))
+	}
+
+	return nil
+}
+
+// validateContent reports every broken rule of the item, keyed by its JSON path.
+func validateContent(validator *portal.Validator, data any) *endpoint.ApiError {
+	violations, err := validator.Violations(data)
+	if err != nil {
+		return endpoint.LogBadRequestError("could not validate the given data.", err)
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	errs := make(map[string]any, len(violations))
+	for _, violation := range violations {
+		errs[violation.Path] = violation.Message
+	}
+
+	return endpoint.UnprocessableEntity("The given fields are invalid", errs)
+}
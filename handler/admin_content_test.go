@@ -0,0 +1,129 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/handler"
+	"github.com/oullin/handler/payload"
+	"github.com/oullin/internal/testutil/dbtest"
+	"github.com/oullin/pkg/portal"
+)
+
+func TestAdminContentShow_RejectsInvalidUUIDs(t *testing.T) {
+	h := handler.NewAdminTalksHandler(&repository.Content{}, portal.GetDefaultValidator())
+
+	req := httptest.NewRequest("GET", "/admin/talks/nope", nil)
+	req.SetPathValue("uuid", "nope")
+
+	if err := h.Show(httptest.NewRecorder(), req); err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("expected bad request, got %+v", err)
+	}
+}
+
+func TestAdminContentShow_ReportsDatabaseErrors(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, PreferSimpleProtocol: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnError(errors.New("connection reset"))
+
+	content := &repository.Content{DB: database.NewConnectionFromGorm(gdb)}
+	h := handler.NewAdminTalksHandler(content, portal.GetDefaultValidator())
+
+	id := "0b8a6c3e-4c1d-4f7a-9d2e-8f1c2b3a4d5e"
+	req := httptest.NewRequest("GET", "/admin/talks/"+id, nil)
+	req.SetPathValue("uuid", id)
+
+	if apiErr := h.Show(httptest.NewRecorder(), req); apiErr == nil || apiErr.Status != http.StatusInternalServerError {
+		t.Fatalf("expected an internal error, got %+v", apiErr)
+	}
+}
+
+func TestAdminContentLifecyclePostgres(t *testing.T) {
+	conn := dbtest.NewTestsHelper(t, &database.ContentSection{}, &database.Talk{}).Conn()
+	content := &repository.Content{DB: conn}
+	admin := &database.User{ID: 1, IsAdmin: true}
+
+	changes := 0
+	h := handler.NewAdminTalksHandler(content, portal.GetDefaultValidator()).OnChange(func() { changes++ })
+
+	body := `{"title":"Go at scale","url":"https://oullin.io/talks/go","created_at":"2024-05-01"}`
+
+	if err := h.Store(httptest.NewRecorder(), adminRequest("POST", "/admin/talks", body, admin)); err == nil || err.Status != http.StatusConflict {
+		t.Fatalf("expected a conflict before the section is imported, got %+v", err)
+	}
+
+	if err := content.ImportSection("talks", "1.0.0", nil); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	invalid := adminRequest("POST", "/admin/talks", `{"title":"","created_at":"01/05/2024"}`, admin)
+	if err := h.Store(httptest.NewRecorder(), invalid); err == nil || err.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("expected unprocessable entity, got %+v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	if err := h.Store(rec, adminRequest("POST", "/admin/talks", body, admin)); err != nil {
+		t.Fatalf("store: %v", err)
+	}
+
+	var created payload.TalksData
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if rec.Code != http.StatusCreated || created.UUID == "" || created.CreatedAt != "2024-05-01" {
+		t.Fatalf("unexpected created talk %d %+v", rec.Code, created)
+	}
+
+	update := adminRequest("PUT", "/admin/talks/"+created.UUID, strings.Replace(body, "Go at scale", "Go at any scale", 1), admin)
+	update.SetPathValue("uuid", created.UUID)
+	rec = httptest.NewRecorder()
+
+	if err := h.Update(rec, update); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	var updated payload.TalksData
+	if err := json.NewDecoder(rec.Body).Decode(&updated); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if updated.UUID != created.UUID || updated.Title != "Go at any scale" {
+		t.Fatalf("unexpected updated talk %+v", updated)
+	}
+
+	destroy := adminRequest("DELETE", "/admin/talks/"+created.UUID, "", admin)
+	destroy.SetPathValue("uuid", created.UUID)
+
+	if err := h.Destroy(httptest.NewRecorder(), destroy); err != nil {
+		t.Fatalf("destroy: %v", err)
+	}
+
+	if deleted, err := repository.FindContent[database.Talk](*content, created.UUID); err != nil || deleted != nil {
+		t.Fatalf("expected the talk to be deleted, got %+v %v", deleted, err)
+	}
+
+	if changes != 3 {
+		t.Fatalf("expected every write to be announced, got %d", changes)
+	}
+}
@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/handler/payload"
+	"github.com/oullin/pkg/endpoint"
+	"github.com/oullin/pkg/middleware"
+	"github.com/oullin/pkg/portal"
+)
+
+type AdminPostsHandler struct {
+	Posts     *repository.Posts
+	Validator *portal.Validator
+	Images    PostImages
+}
+
+// PostImages prepares the images of a saved post the way the posts import does: remote
+// images of the body are mirrored into the media storage and the cover variants rendered.
+type PostImages interface {
+	MirrorContentImages(slug, owner, content string) string
+	PrepareCoverImages(post database.Post) error
+}
+
+func NewAdminPostsHandler(repo *repository.Posts, validator *portal.Validator) AdminPostsHandler {
+	return AdminPostsHandler{
+		Posts:     repo,
+		Validator: validator,
+	}
+}
+
+// WithImages mirrors the body images and renders the cover variants of every post written.
+func (h AdminPostsHandler) WithImages(images PostImages) AdminPostsHandler {
+	h.Images = images
+
+	return h
+}
+
+func (h *AdminPostsHandler) Store(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	defer portal.CloseWithLog(r.Body)
+
+	author := middleware.AdminUserFrom(r)
+	if author == nil {
+		return endpoint.InternalError("The admin user could not be resolved for this request.")
+	}
+
+	req, apiErr := h.parseSaveRequest(w, r)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	if h.Posts.FindWithTrashedBy(req.Slug) != nil {
+		return endpoint.Conflict(fmt.Sprintf("The given slug '%s' is already taken", req.Slug))
+	}
+
+	attrs, apiErr := h.attrsFrom(req, author.ID)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	post, err := h.Posts.Create(attrs)
+	if err != nil {
+		return endpoint.LogInternalError("could not create the given post", err)
+	}
+
+	h.prepareImages(*post, author.Username, true)
+
+	return h.respondWith(w, r, req.Slug, http.StatusCreated)
+}
+
+func (h *AdminPostsHandler) Update(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	defer portal.CloseWithLog(r.Body)
+
+	slug := payload.GetSlugFrom(r)
+	if slug == "" {
+		return endpoint.BadRequestError("Slugs are required to update posts")
+	}
+
+	post := h.Posts.FindBy(slug)
+	if post == nil {
+		return endpoint.NotFound(fmt.Sprintf("The given post '%s' was not found", slug))
+	}
+
+	req, apiErr := h.parseSaveRequest(w, r)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	if req.Slug != strings.ToLower(post.Slug) && h.Posts.FindWithTrashedBy(req.Slug) != nil {
+		return endpoint.Conflict(fmt.Sprintf("The given slug '%s' is already taken", req.Slug))
+	}
+
+	attrs, apiErr := h.attrsFrom(req, post.AuthorID)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	coverChanged := attrs.ImageURL != post.CoverImageURL
+
+	if _, err := h.Posts.Update(*post, attrs); err != nil {
+		return endpoint.LogInternalError("could not update the given post", err)
+	}
+
+	if updated := h.Posts.FindBy(strings.ToLower(req.Slug)); updated != nil {
+		h.prepareImages(*updated, editorOf(r, *post), coverChanged)
+	}
+
+	return h.respondWith(w, r, req.Slug, http.StatusOK)
+}
+
+func (h *AdminPostsHandler) Destroy(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	slug := payload.GetSlugFrom(r)
+	if slug == "" {
+		return endpoint.BadRequestError("Slugs are required to delete posts")
+	}
+
+	post := h.Posts.FindBy(slug)
+	if post == nil {
+		return endpoint.NotFound(fmt.Sprintf("The given post '%s' was not found", slug))
+	}
+
+	if err := h.Posts.Delete(*post); err != nil {
+		return endpoint.LogInternalError("could not delete the given post", err)
+	}
+
+	endpoint.NewNoCacheResponse(w, r).RespondNoContent()
+
+	return nil
+}
+
+func (h *AdminPostsHandler) Restore(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	slug := payload.GetSlugFrom(r)
+	if slug == "" {
+		return endpoint.BadRequestError("Slugs are required to restore posts")
+	}
+
+	post := h.Posts.FindWithTrashedBy(slug)
+	if post == nil || !post.DeletedAt.Valid {
+		return endpoint.NotFound(fmt.Sprintf("The given post '%s' is not in the trash", slug))
+	}
+
+	if _, err := h.Posts.Restore(*post); err != nil {
+		return endpoint.LogInternalError("could not restore the given post", err)
+	}
+
+	return h.respondWith(w, r, post.Slug, http.StatusOK)
+}
+
+// prepareImages runs once the post is saved, so a rejected write leaves no files behind.
+// Images that cannot be processed do not undo the write; their original URL keeps being served.
+func (h *AdminPostsHandler) prepareImages(post database.Post, owner string, coverChanged bool) {
+	if h.Images == nil {
+		return
+	}
+
+	if content := h.Images.MirrorContentImages(post.Slug, owner, post.Content); content != post.Content {
+		if err := h.Posts.UpdateContent(post, content); err != nil {
+			slog.Warn("Mirrored images were not linked", "slug", post.Slug, "error", err)
+		}
+	}
+
+	if !coverChanged {
+		return
+	}
+
+	if err := h.Images.PrepareCoverImages(post); err != nil {
+		slog.Warn("Cover images were not generated", "slug", post.Slug, "error", err)
+	}
+}
+
+// editorOf is the account mirrored images are indexed under: the admin making the edit,
+// or else the post author.
+func editorOf(r *http.Request, post database.Post) string {
+	if admin := middleware.AdminUserFrom(r); admin != nil {
+		return admin.Username
+	}
+
+	return post.Author.Username
+}
+
+func (h *AdminPostsHandler) parseSaveRequest(w http.ResponseWriter, r *http.Request) (payload.SavePostRequest, *endpoint.ApiError) {
+	var req payload.SavePostRequest
+
+	r.Body = http.MaxBytesReader(w, r.Body, endpoint.MaxRequestSize)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&req); err != nil {
+		return req, endpoint.LogBadRequestError("could not parse the given data.", err)
+	}
+
+	req.Slug = strings.TrimSpace(req.Slug)
+
+	if _, err := h.Validator.Rejects(req); err != nil {
+		return req, endpoint.UnprocessableEntity("The given fields are invalid", h.Validator.GetErrors())
+	}
+
+	return req, nil
+}
+
+func (h *AdminPostsHandler) attrsFrom(req payload.SavePostRequest, authorID uint64) (database.PostsAttrs, *endpoint.ApiError) {
+	attrs := database.PostsAttrs{
+		AuthorID:    authorID,
+		Slug:        req.Slug,
+		Title:       strings.TrimSpace(req.Title),
+		Excerpt:     strings.TrimSpace(req.Excerpt),
+		Content:     req.Content,
+		ImageURL:    strings.TrimSpace(req.CoverImageURL),
+		PublishedAt: req.PublishedAt,
+	}
+
+	var unknown []string
+
+	for _, seed := range req.Categories {
+		slug := strings.TrimSpace(strings.ToLower(seed))
+		category := h.Posts.FindCategoryBy(slug)
+
+		if category == nil {
+			unknown = append(unknown, slug)
+			continue
+		}
+
+		attrs.Categories = append(attrs.Categories, database.CategoriesAttrs{
+			Id:          category.ID,
+			Slug:        category.Slug,
+			Name:        category.Name,
+			Description: category.Description,
+		})
+	}
+
+	if len(unknown) > 0 {
+		return attrs, endpoint.UnprocessableEntity("The given fields are invalid", map[string]any{
+			"categories": fmt.Sprintf("unknown categories [%s]", strings.Join(unknown, ", ")),
+		})
+	}
+
+	for _, seed := range req.Tags {
+		slug := strings.TrimSpace(strings.ToLower(seed))
+		tag := h.Posts.FindTagBy(slug)
+
+		if tag == nil {
+			return attrs, endpoint.InternalError(fmt.Sprintf("could not resolve the given tag [%s]", slug))
+		}
+
+		attrs.Tags = append(attrs.Tags, database.TagAttrs{
+			Id:   tag.ID,
+			Slug: tag.Slug,
+			Name: tag.Name,
+		})
+	}
+
+	return attrs, nil
+}
+
+func (h *AdminPostsHandler) respondWith(w http.ResponseWriter, r *http.Request, slug string, status int) *endpoint.ApiError {
+	post := h.Posts.FindBy(strings.ToLower(slug))
+	if post == nil {
+		return endpoint.InternalError(fmt.Sprintf("The given post '%s' could not be reloaded", slug))
+	}
+
+	resp := endpoint.NewNoCacheResponse(w, r)
+
+	if err := resp.RespondWithStatus(status, payload.GetPostsResponse(*post)); err != nil {
+		slog.Error("Error marshaling JSON for admin posts response", "error", err)
+
+		return endpoint.InternalError("could not encode the post response")
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/handler"
+	"github.com/oullin/handler/payload"
+	"github.com/oullin/internal/testutil/dbtest"
+	"github.com/oullin/pkg/portal"
+)
+
+func adminRequest(method, target, body string, admin *database.User) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+
+	return req.WithContext(context.WithValue(req.Context(), portal.AuthAdminUserKey, admin))
+}
+
+func TestAdminPostsStore_RequiresAdminUser(t *testing.T) {
+	h := handler.NewAdminPostsHandler(&repository.Posts{}, portal.GetDefaultValidator())
+
+	req := httptest.NewRequest("POST", "/admin/posts", bytes.NewReader([]byte("{}")))
+
+	if err := h.Store(httptest.NewRecorder(), req); err == nil || err.Status != http.StatusInternalServerError {
+		t.Fatalf("expected internal error without admin user, got %+v", err)
+	}
+}
+
+func TestAdminPostsStore_ParseAndValidation(t *testing.T) {
+	h := handler.NewAdminPostsHandler(&repository.Posts{}, portal.GetDefaultValidator())
+	admin := &database.User{ID: 1, IsAdmin: true}
+
+	if err := h.Store(httptest.NewRecorder(), adminRequest("POST", "/admin/posts", "{", admin)); err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("expected bad request, got %+v", err)
+	}
+
+	if err := h.Store(httptest.NewRecorder(), adminRequest("POST", "/admin/posts", `{"unknown":true}`, admin)); err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("expected unknown fields to be rejected, got %+v", err)
+	}
+
+	if err := h.Store(httptest.NewRecorder(), adminRequest("POST", "/admin/posts", `{"slug":"x"}`, admin)); err == nil || err.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("expected unprocessable entity, got %+v", err)
+	}
+}
+
+// recordedImages records the posts handed over for their images.
+type recordedImages struct {
+	mirrored []string
+	covers   []string
+}
+
+func (i *recordedImages) MirrorContentImages(slug, owner, content string) string {
+	i.mirrored = append(i.mirrored, slug+":"+owner)
+
+	return strings.ReplaceAll(content, "https://example.com/", "/media/posts/"+slug+"/")
+}
+
+func (i *recordedImages) PrepareCoverImages(post database.Post) error {
+	i.covers = append(i.covers, post.Slug+":"+post.CoverImageURL)
+
+	return nil
+}
+
+func TestAdminPostsLifecyclePostgres(t *testing.T) {
+	conn, author := dbtest.NewTestDB(t)
+
+	category := database.Category{UUID: uuid.NewString(), Name: "Engineering", Slug: "engineering", Sort: 1}
+	if err := conn.Sql().Create(&category).Error; err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+
+	images := &recordedImages{}
+
+	h := handler.NewAdminPostsHandler(&repository.Posts{
+		DB:         conn,
+		Categories: &repository.Categories{DB: conn},
+		Tags:       &repository.Tags{DB: conn},
+	}, portal.GetDefaultValidator()).WithImages(images)
+
+	body := `{"slug":"admin-post","title":"Admin Post","excerpt":"Ex","content":"Body","cover_image_url":"https://example.com/cover.png","categories":["engineering"],"tags":["go"]}`
+	rec := httptest.NewRecorder()
+
+	if err := h.Store(rec, adminRequest("POST", "/admin/posts", body, &author)); err != nil {
+		t.Fatalf("store: %v", err)
+	}
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+
+	var created payload.PostResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if created.Slug != "admin-post" || len(created.Categories) != 1 || len(created.Tags) != 1 || created.PublishedAt != nil {
+		t.Fatalf("unexpected created post: %+v", created)
+	}
+
+	if err := h.Store(httptest.NewRecorder(), adminRequest("POST", "/admin/posts", body, &author)); err == nil || err.Status != http.StatusConflict {
+		t.Fatalf("expected duplicated slug conflict, got %+v", err)
+	}
+
+	update := `{"slug":"admin-post-v2","title":"Admin Post v2","excerpt":"Ex","content":"Body v2 ![a](https://example.com/a.png)","categories":["engineering"]}`
+	req := adminRequest("PUT", "/admin/posts/admin-post", update, &author)
+	req.SetPathValue("slug", "admin-post")
+	rec = httptest.NewRecorder()
+
+	if err := h.Update(rec, req); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	var updated payload.PostResponse
+	if err := json.NewDecoder(rec.Body).Decode(&updated); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if updated.Slug != "admin-post-v2" || updated.Content != "Body v2 ![a](/media/posts/admin-post-v2/a.png)" || len(updated.Tags) != 0 {
+		t.Fatalf("unexpected updated post: %+v", updated)
+	}
+
+	if len(images.mirrored) != 2 || len(images.covers) != 2 || images.covers[1] != "admin-post-v2:" {
+		t.Fatalf("expected the images of both writes to be prepared, got %+v", images)
+	}
+
+	req = adminRequest("DELETE", "/admin/posts/admin-post-v2", "", &author)
+	req.SetPathValue("slug", "admin-post-v2")
+	rec = httptest.NewRecorder()
+
+	if err := h.Destroy(rec, req); err != nil || rec.Code != http.StatusNoContent {
+		t.Fatalf("destroy: %v (%d)", err, rec.Code)
+	}
+
+	req = adminRequest("POST", "/admin/posts/admin-post-v2/restore", "", &author)
+	req.SetPathValue("slug", "admin-post-v2")
+	rec = httptest.NewRecorder()
+
+	if err := h.Restore(rec, req); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("restore: %v (%d)", err, rec.Code)
+	}
+
+	req = adminRequest("POST", "/admin/posts/admin-post-v2/restore", "", &author)
+	req.SetPathValue("slug", "admin-post-v2")
+
+	if err := h.Restore(httptest.NewRecorder(), req); err == nil || err.Status != http.StatusNotFound {
+		t.Fatalf("expected restoring a live post to 404, got %+v", err)
+	}
+}
@@ -1,17 +1,18 @@
 package handler
 
 import (
+	"github.com/oullin/handler/listing"
 	"github.com/oullin/handler/payload"
 	"github.com/oullin/pkg/endpoint"
-	"github.com/oullin/pkg/portal"
+	"github.com/oullin/pkg/fixtures"
 
-	"log/slog"
 	"net/http"
 )
 
 type EducationHandler struct {
-	filePath     string
+	source       *fixtures.Source[payload.EducationResponse]
 	cacheEnabled bool
+	list         fixtureList[payload.EducationResponse, payload.EducationData]
 }
 
 func NewEducationHandler(filePath string) EducationHandler {
@@ -20,38 +21,59 @@ func NewEducationHandler(filePath string) EducationHandler {
 
 func NewEducationHandlerWithCache(filePath string, cacheEnabled bool) EducationHandler {
 	return EducationHandler{
-		filePath:     filePath,
+		source:       fixtures.NewSource(filePath, validated[payload.EducationResponse](nil)),
 		cacheEnabled: cacheEnabled,
+		list:         newEducationList(),
 	}
 }
 
-func (h EducationHandler) Handle(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
-	data, err := portal.ParseJsonFile[payload.EducationResponse](h.filePath)
-
-	if err != nil {
-		slog.Error("Error reading education file", "error", err)
-
-		return endpoint.InternalError("could not read education data")
+// NewEducationHandlerWithLoader serves the education load returns, e.g. out of the database, rather
+// than the fixture file it falls back to.
+func NewEducationHandlerWithLoader(file string, load func() (payload.EducationResponse, error), cacheEnabled bool) EducationHandler {
+	return EducationHandler{
+		source:       fixtures.NewLoadedSource(file, load, validated[payload.EducationResponse](nil)),
+		cacheEnabled: cacheEnabled,
+		list:         newEducationList(),
 	}
+}
 
-	resp, err := endpoint.NewResponseForPayload(data, 3600, h.cacheEnabled, w, r)
-	if err != nil {
-		slog.Error("Error preparing education response cache", "error", err)
-
-		return endpoint.InternalError("could not prepare education response")
-	}
+// Fixture is the source the router keeps reloading.
+func (h EducationHandler) Fixture() fixtures.Reloader {
+	return h.source
+}
 
-	if resp.HasCache() {
-		resp.RespondWithNotModified()
+func (h EducationHandler) Handle(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	return h.list.serve(w, r, h.source, h.cacheEnabled)
+}
 
-		return nil
-	}
+// Render is the education list GET /site embeds, as GET /education returns it.
+func (h EducationHandler) Render() (listing.Variant, *endpoint.ApiError) {
+	return h.list.render(h.source, nil)
+}
 
-	if err := resp.RespondOk(data); err != nil {
-		slog.Error("Error marshaling JSON for education response", "error", err)
+// Snapshot is the education list Render encodes, for handlers built on top of it. The route has
+// no default filters, so it holds every item.
+func (h EducationHandler) Snapshot() (*fixtures.Snapshot[payload.EducationResponse], *endpoint.ApiError) {
+	return h.list.snapshot(h.source)
+}
 
-		return endpoint.InternalError("could not encode education response")
-	}
+func newEducationList() fixtureList[payload.EducationResponse, payload.EducationData] {
+	return newFixtureList(
+		"education",
+		listing.Engine[payload.EducationData]{
+			Filters: []listing.Filter[payload.EducationData]{
+				{Name: "school", Value: func(item payload.EducationData) string { return item.School }, Match: listing.Partial},
+				{Name: "issuing_country", Value: func(item payload.EducationData) string { return item.IssuingCountry }},
+			},
+			Sorts: []listing.Sort[payload.EducationData]{
+				{Name: "graduated_at", Date: func(item payload.EducationData) string { return item.GraduatedAt }},
+			},
+		},
+		func(data payload.EducationResponse) []payload.EducationData { return data.Data },
+		func(data payload.EducationResponse, items []payload.EducationData, page *payload.ListPage) payload.EducationResponse {
+			data.Data, data.ListPage = items, page
 
-	return nil // A nil return indicates success.
+			return data
+		},
+	)
 }
@@ -1,17 +1,18 @@
 package handler
 
 import (
+	"github.com/oullin/handler/listing"
 	"github.com/oullin/handler/payload"
 	"github.com/oullin/pkg/endpoint"
-	"github.com/oullin/pkg/portal"
+	"github.com/oullin/pkg/fixtures"
 
-	"log/slog"
 	"net/http"
 )
 
 type ExperienceHandler struct {
-	filePath     string
+	source       *fixtures.Source[payload.ExperienceResponse]
 	cacheEnabled bool
+	list         fixtureList[payload.ExperienceResponse, payload.ExperienceData]
 }
 
 func NewExperienceHandler(filePath string) ExperienceHandler {
@@ -20,38 +21,62 @@ func NewExperienceHandler(filePath string) ExperienceHandler {
 
 func NewExperienceHandlerWithCache(filePath string, cacheEnabled bool) ExperienceHandler {
 	return ExperienceHandler{
-		filePath:     filePath,
+		source:       fixtures.NewSource(filePath, validated[payload.ExperienceResponse](nil)),
 		cacheEnabled: cacheEnabled,
+		list:         newExperienceList(),
 	}
 }
 
-func (h ExperienceHandler) Handle(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
-	data, err := portal.ParseJsonFile[payload.ExperienceResponse](h.filePath)
-
-	if err != nil {
-		slog.Error("Error reading experience file", "error", err)
-
-		return endpoint.InternalError("could not read experience data")
+// NewExperienceHandlerWithLoader serves the experience load returns, e.g. out of the database, rather
+// than the fixture file it falls back to.
+func NewExperienceHandlerWithLoader(file string, load func() (payload.ExperienceResponse, error), cacheEnabled bool) ExperienceHandler {
+	return ExperienceHandler{
+		source:       fixtures.NewLoadedSource(file, load, validated[payload.ExperienceResponse](nil)),
+		cacheEnabled: cacheEnabled,
+		list:         newExperienceList(),
 	}
+}
 
-	resp, err := endpoint.NewResponseForPayload(data, 3600, h.cacheEnabled, w, r)
-	if err != nil {
-		slog.Error("Error preparing experience response cache", "error", err)
-
-		return endpoint.InternalError("could not prepare experience response")
-	}
+// Fixture is the source the router keeps reloading.
+func (h ExperienceHandler) Fixture() fixtures.Reloader {
+	return h.source
+}
 
-	if resp.HasCache() {
-		resp.RespondWithNotModified()
+func (h ExperienceHandler) Handle(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	return h.list.serve(w, r, h.source, h.cacheEnabled)
+}
 
-		return nil
-	}
+// Render is the experience list GET /site embeds, as GET /experience returns it.
+func (h ExperienceHandler) Render() (listing.Variant, *endpoint.ApiError) {
+	return h.list.render(h.source, nil)
+}
 
-	if err := resp.RespondOk(data); err != nil {
-		slog.Error("Error marshaling JSON for experience response", "error", err)
+// Snapshot is the experience list Render encodes, for handlers built on top of it. The route has
+// no default filters, so it holds every item.
+func (h ExperienceHandler) Snapshot() (*fixtures.Snapshot[payload.ExperienceResponse], *endpoint.ApiError) {
+	return h.list.snapshot(h.source)
+}
 
-		return endpoint.InternalError("could not encode experience response")
-	}
+func newExperienceList() fixtureList[payload.ExperienceResponse, payload.ExperienceData] {
+	return newFixtureList(
+		"experience",
+		listing.Engine[payload.ExperienceData]{
+			Filters: []listing.Filter[payload.ExperienceData]{
+				{Name: "company", Value: func(item payload.ExperienceData) string { return item.Company }, Match: listing.Partial},
+				{Name: "employment_type", Value: func(item payload.ExperienceData) string { return item.EmploymentType }},
+				{Name: "location_type", Value: func(item payload.ExperienceData) string { return item.LocationType }},
+				{Name: "country", Value: func(item payload.ExperienceData) string { return item.Country }},
+			},
+			Sorts: []listing.Sort[payload.ExperienceData]{
+				{Name: "start_date", Date: func(item payload.ExperienceData) string { return item.StartDate }},
+				{Name: "end_date", Date: func(item payload.ExperienceData) string { return item.EndDate }},
+			},
+		},
+		func(data payload.ExperienceResponse) []payload.ExperienceData { return data.Data },
+		func(data payload.ExperienceResponse, items []payload.ExperienceData, page *payload.ListPage) payload.ExperienceResponse {
+			data.Data, data.ListPage = items, page
 
-	return nil // A nil return indicates success.
+			return data
+		},
+	)
 }
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/oullin/handler/listing"
+	"github.com/oullin/handler/payload"
+	"github.com/oullin/pkg/endpoint"
+	"github.com/oullin/pkg/fixtures"
+)
+
+// listVariants caps how many filtered responses each list fixture keeps encoded.
+const listVariants = 64
+
+// fixtureList serves a list fixture R of items T, filtered, sorted and paginated by the
+// query string. Without a query the snapshot is served as it is.
+type fixtureList[R any, T any] struct {
+	name     string
+	engine   listing.Engine[T]
+	items    func(R) []T
+	with     func(R, []T, *payload.ListPage) R
+	variants *listing.Variants
+}
+
+func newFixtureList[R any, T any](name string, engine listing.Engine[T], items func(R) []T, with func(R, []T, *payload.ListPage) R) fixtureList[R, T] {
+	return fixtureList[R, T]{
+		name:     name,
+		engine:   engine,
+		items:    items,
+		with:     with,
+		variants: listing.NewVariants(listVariants),
+	}
+}
+
+func (l fixtureList[R, T]) serve(w http.ResponseWriter, r *http.Request, source *fixtures.Source[R], cacheEnabled bool) *endpoint.ApiError {
+	variant, apiErr := l.render(source, r.URL.Query())
+	if apiErr != nil {
+		return apiErr
+	}
+
+	resp := endpoint.NewResponseForBody(variant.Body, variant.Checksum, 3600, cacheEnabled, w, r)
+
+	if resp.HasCache() {
+		resp.RespondWithNotModified()
+
+		return nil
+	}
+
+	if err := resp.RespondOk(nil); err != nil {
+		slog.Error(fmt.Sprintf("Error marshaling JSON for %s response", l.name), "error", err)
+
+		return endpoint.InternalError(fmt.Sprintf("could not encode %s response", l.name))
+	}
+
+	return nil // A nil return indicates success.
+}
+
+// render encodes the list the query asks for. Without a query it is the snapshot as it is.
+func (l fixtureList[R, T]) render(source *fixtures.Source[R], values url.Values) (listing.Variant, *endpoint.ApiError) {
+	snapshot, apiErr := l.snapshot(source)
+	if apiErr != nil {
+		return listing.Variant{}, apiErr
+	}
+
+	query, err := l.engine.Parse(values)
+	if err != nil {
+		return listing.Variant{}, endpoint.BadRequestError(err.Error())
+	}
+
+	if query.IsZero() {
+		return listing.Variant{Body: snapshot.Body, Checksum: snapshot.Checksum}, nil
+	}
+
+	variant, err := l.variants.Get(snapshot.Checksum, query.Key(), func() any {
+		return l.apply(snapshot.Data, query)
+	})
+
+	if err != nil {
+		slog.Error(fmt.Sprintf("Error marshaling JSON for %s response", l.name), "error", err)
+
+		return listing.Variant{}, endpoint.InternalError(fmt.Sprintf("could not encode %s response", l.name))
+	}
+
+	return variant, nil
+}
+
+func (l fixtureList[R, T]) snapshot(source *fixtures.Source[R]) (*fixtures.Snapshot[R], *endpoint.ApiError) {
+	snapshot, err := source.Current()
+
+	if err != nil {
+		slog.Error(fmt.Sprintf("Error reading %s file", l.name), "error", err)
+
+		return nil, endpoint.InternalError(fmt.Sprintf("could not read %s data", l.name))
+	}
+
+	return snapshot, nil
+}
+
+func (l fixtureList[R, T]) apply(data R, query listing.Query) R {
+	result := l.engine.Apply(l.items(data), query)
+
+	var page *payload.ListPage
+	if query.Paged() {
+		page = payload.NewListPage(result)
+	}
+
+	return l.with(data, result.Data, page)
+}
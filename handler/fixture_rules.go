@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/oullin/pkg/portal"
+)
+
+// validated runs the payload rules, the ones CheckFixtures applies at boot, before the
+// given prepare hook. A fixture or database section breaking them is refused, so every
+// reload keeps serving the last good snapshot.
+func validated[T any](prepare func(*T) error) func(*T) error {
+	return func(data *T) error {
+		violations, err := portal.GetDefaultValidator().Violations(data)
+		if err != nil {
+			return err
+		}
+
+		if len(violations) > 0 {
+			errs := make([]error, 0, len(violations))
+
+			for _, violation := range violations {
+				errs = append(errs, fmt.Errorf("%s: %s", violation.Path, violation.Message))
+			}
+
+			return errors.Join(errs...)
+		}
+
+		if prepare == nil {
+			return nil
+		}
+
+		return prepare(data)
+	}
+}
@@ -0,0 +1,472 @@
+package handler
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	stdimage "image"
+	"image/draw"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oullin/pkg/cache"
+	"github.com/oullin/pkg/endpoint"
+	pkgimages "github.com/oullin/pkg/images"
+	"github.com/oullin/pkg/storage"
+)
+
+// ImagesCacheMaxBytes bounds the disk used by the generated renditions.
+const ImagesCacheMaxBytes = int64(512 << 20)
+
+// imageRenderTimeout bounds a single render, which no longer follows any request.
+const imageRenderTimeout = 30 * time.Second
+
+// imageHashesCapacity caps how many source hashes are remembered. Each edit of a source
+// leaves its previous key behind, so the least recently used ones are dropped.
+const imageHashesCapacity = 4096
+
+const (
+	imageFitContain = "contain"
+	imageFitCover   = "cover"
+)
+
+// imageSizes is the allow-list for the w and h parameters. Keeping it short bounds the
+// number of renditions a single source can fill the cache with.
+var imageSizes = []int{160, 320, 480, 640, 768, 1024, 1280, 1440, 1920}
+
+// imageFormats maps the fmt parameter onto the extension it is encoded with.
+var imageFormats = map[string]string{
+	"jpeg": ".jpg",
+	"jpg":  ".jpg",
+	"png":  ".png",
+	"webp": ".webp",
+	"avif": ".avif",
+}
+
+type imageRendition struct {
+	width  int
+	height int
+	fit    string
+	ext    string
+}
+
+type imageSourceKey struct {
+	key     string
+	size    int64
+	modTime int64
+	etag    string
+}
+
+// ImagesHandler resizes and transcodes the files in the media storage on demand. Renditions
+// are stored in an LRU disk cache named after the parameters and the hash of the source,
+// so an edited source never serves a stale rendition. The hash is also the v parameter of
+// the URLs that are cached for good.
+type ImagesHandler struct {
+	Storage  storage.Storage
+	CacheDir string
+
+	cacheOnce sync.Once
+	cache     *cache.DiskLRU
+	cacheErr  error
+
+	hashes *imageHashes
+}
+
+// NewImagesHandler renders the files kept in store. The cache always lives on the local disk
+// and is only opened on the first request.
+func NewImagesHandler(store storage.Storage, cacheDir string) *ImagesHandler {
+	return &ImagesHandler{
+		Storage:  store,
+		CacheDir: cacheDir,
+		hashes:   newImageHashes(imageHashesCapacity),
+	}
+}
+
+func (h *ImagesHandler) Show(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	rel := strings.TrimPrefix(path.Clean("/"+r.PathValue("path")), "/")
+
+	if !isServableImagePath(rel) {
+		return endpoint.NotFound(fmt.Sprintf("The given image '%s' was not found", rel))
+	}
+
+	info, err := h.Storage.Stat(r.Context(), rel)
+
+	if errors.Is(err, storage.ErrNotFound) {
+		return endpoint.NotFound(fmt.Sprintf("The given image '%s' was not found", rel))
+	}
+
+	if err != nil {
+		return endpoint.LogInternalError("There was an issue reading the image. Please, try again later.", err)
+	}
+
+	rendition, negotiated, apiErr := parseImageRendition(r, strings.ToLower(path.Ext(rel)))
+
+	if apiErr != nil {
+		return apiErr
+	}
+
+	lru, err := h.openCache()
+
+	if err != nil {
+		return endpoint.LogInternalError("The image cache is not available. Please, try again later.", err)
+	}
+
+	hash, err := h.sourceHash(r.Context(), info)
+
+	if err != nil {
+		return endpoint.LogInternalError("There was an issue reading the image. Please, try again later.", err)
+	}
+
+	name := fmt.Sprintf("%s-%dx%d-%s%s", hash, rendition.width, rendition.height, rendition.fit, rendition.ext)
+
+	file, err := lru.GetOrCreate(name, func(target string) error {
+		// Every request waiting on this rendition shares the render, so it must not end
+		// with the first client that goes away.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), imageRenderTimeout)
+		defer cancel()
+
+		return h.renderImage(ctx, rel, target, rendition)
+	})
+
+	if err != nil {
+		slog.Error("failed to render image", "path", rel, "err", err)
+
+		return endpoint.InternalError("There was an issue rendering the image. Please, try again later.")
+	}
+
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return endpoint.LogInternalError("There was an issue reading the image. Please, try again later.", err)
+	}
+
+	if negotiated {
+		w.Header().Add("Vary", "Accept")
+	}
+
+	// A URL carrying the source hash never changes content, since an edited source gets a new
+	// hash. Any other URL is revalidated against the ETag and points at its versioned one.
+	if version := r.URL.Query().Get("v"); version == hash {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=0, must-revalidate")
+		w.Header().Set("Content-Location", versionedImageURL(r, hash))
+	}
+
+	w.Header().Set("Content-Type", pkgimages.MIMEFromExtension(rendition.ext))
+	w.Header().Set("ETag", `"`+strings.TrimSuffix(name, rendition.ext)+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	http.ServeContent(w, r, name, stat.ModTime(), file)
+
+	return nil
+}
+
+// versionedImageURL is the request URL with v set to the source hash.
+func versionedImageURL(r *http.Request, hash string) string {
+	values := r.URL.Query()
+	values.Set("v", hash)
+
+	return r.URL.Path + "?" + values.Encode()
+}
+
+func (h *ImagesHandler) openCache() (*cache.DiskLRU, error) {
+	h.cacheOnce.Do(func() {
+		h.cache, h.cacheErr = cache.NewDiskLRU(h.CacheDir, ImagesCacheMaxBytes)
+	})
+
+	return h.cache, h.cacheErr
+}
+
+// sourceHash is memoised by key, size, modification time and ETag so that cache hits do not
+// re-read the source.
+func (h *ImagesHandler) sourceHash(ctx context.Context, info storage.ObjectInfo) (string, error) {
+	key := imageSourceKey{key: info.Key, size: info.Size, modTime: info.ModTime.UnixNano(), etag: info.ETag}
+
+	if hash, ok := h.hashes.get(key); ok {
+		return hash, nil
+	}
+
+	object, err := h.Storage.Get(ctx, info.Key)
+	if err != nil {
+		return "", err
+	}
+	defer object.Body.Close()
+
+	digest := sha256.New()
+	if _, err = io.Copy(digest, object.Body); err != nil {
+		return "", err
+	}
+
+	hash := hex.EncodeToString(digest.Sum(nil))[:32]
+	h.hashes.add(key, hash)
+
+	return hash, nil
+}
+
+// imageHashes is a small LRU of source hashes.
+type imageHashes struct {
+	mu       sync.Mutex
+	order    *list.List // Front is the most recently used hash.
+	entries  map[imageSourceKey]*list.Element
+	capacity int
+}
+
+type imageHashEntry struct {
+	key  imageSourceKey
+	hash string
+}
+
+func newImageHashes(capacity int) *imageHashes {
+	return &imageHashes{
+		order:    list.New(),
+		entries:  make(map[imageSourceKey]*list.Element),
+		capacity: capacity,
+	}
+}
+
+func (c *imageHashes) get(key imageSourceKey) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+
+	c.order.MoveToFront(element)
+
+	return element.Value.(*imageHashEntry).hash, true
+}
+
+func (c *imageHashes) add(key imageSourceKey, hash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&imageHashEntry{key: key, hash: hash})
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*imageHashEntry).key)
+	}
+}
+
+func isServableImagePath(rel string) bool {
+	if rel == "" || !slices.Contains(mediaExtensions, strings.ToLower(path.Ext(rel))) {
+		return false
+	}
+
+	for _, segment := range strings.Split(rel, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseImageRendition reads w, h, fit and fmt. It reports whether the format was picked
+// from the Accept header, in which case the response varies on it.
+func parseImageRendition(r *http.Request, sourceExt string) (imageRendition, bool, *endpoint.ApiError) {
+	values := r.URL.Query()
+	rendition := imageRendition{fit: imageFitContain}
+
+	var err *endpoint.ApiError
+
+	if rendition.width, err = parseImageSize(values.Get("w"), "w"); err != nil {
+		return rendition, false, err
+	}
+
+	if rendition.height, err = parseImageSize(values.Get("h"), "h"); err != nil {
+		return rendition, false, err
+	}
+
+	switch fit := strings.ToLower(strings.TrimSpace(values.Get("fit"))); fit {
+	case "", imageFitContain:
+	case imageFitCover:
+		rendition.fit = imageFitCover
+	default:
+		return rendition, false, endpoint.BadRequestError(fmt.Sprintf("The fit '%s' is not supported; use contain or cover", fit))
+	}
+
+	format := strings.ToLower(strings.TrimSpace(values.Get("fmt")))
+
+	if format == "" {
+		rendition.ext = negotiateImageExtension(r.Header.Get("Accept"), sourceExt)
+
+		return rendition, true, nil
+	}
+
+	ext, ok := imageFormats[format]
+
+	if !ok || (ext == ".webp" && !slices.Contains(pkgimages.GetVariantFormats(), pkgimages.FormatWebP)) {
+		return rendition, false, endpoint.BadRequestError(fmt.Sprintf("The format '%s' is not supported", format))
+	}
+
+	rendition.ext = ext
+
+	return rendition, false, nil
+}
+
+func parseImageSize(value, name string) (int, *endpoint.ApiError) {
+	if value == "" {
+		return 0, nil
+	}
+
+	size, err := strconv.Atoi(value)
+
+	if err != nil || !slices.Contains(imageSizes, size) {
+		return 0, endpoint.BadRequestError(fmt.Sprintf("The %s parameter must be one of %v", name, imageSizes))
+	}
+
+	return size, nil
+}
+
+// negotiateImageExtension prefers AVIF, then WebP, and otherwise keeps the source format.
+// WebP and AVIF sources fall back to PNG so that transparency survives.
+func negotiateImageExtension(accept, sourceExt string) string {
+	if acceptsImageType(accept, "image/avif") {
+		return ".avif"
+	}
+
+	if acceptsImageType(accept, "image/webp") && slices.Contains(pkgimages.GetVariantFormats(), pkgimages.FormatWebP) {
+		return ".webp"
+	}
+
+	if sourceExt == ".jpg" || sourceExt == ".png" {
+		return sourceExt
+	}
+
+	return ".png"
+}
+
+// acceptsImageType only honours exact media types; wildcards do not opt a client into
+// formats it might not decode.
+func acceptsImageType(accept, mimeType string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(part, ";")
+
+		if !strings.EqualFold(strings.TrimSpace(fields[0]), mimeType) {
+			continue
+		}
+
+		for _, param := range fields[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+
+			if strings.EqualFold(key, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil && q <= 0 {
+					return false
+				}
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func (h *ImagesHandler) renderImage(ctx context.Context, key, target string, rendition imageRendition) error {
+	object, err := h.Storage.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(object.Body)
+	_ = object.Body.Close()
+
+	if err != nil {
+		return err
+	}
+
+	img, _, err := pkgimages.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	crop, width, height := imageRenditionBounds(img.Bounds(), rendition)
+
+	if crop != img.Bounds() {
+		img = cropImage(img, crop)
+	}
+
+	if width != crop.Dx() || height != crop.Dy() {
+		img = pkgimages.Resize(img, width, height)
+	}
+
+	return pkgimages.Save(target, img, rendition.ext, pkgimages.DefaultJPEGQuality)
+}
+
+// imageRenditionBounds returns the part of the source to keep and the size to scale it to.
+// contain fits the image inside the requested box; cover fills it and crops the overflow
+// around the centre. Sources are never upscaled.
+func imageRenditionBounds(bounds stdimage.Rectangle, rendition imageRendition) (stdimage.Rectangle, int, int) {
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+
+	if rendition.fit == imageFitCover && rendition.width > 0 && rendition.height > 0 {
+		cropWidth, cropHeight := srcWidth, srcWidth*rendition.height/rendition.width
+
+		if cropHeight > srcHeight {
+			cropWidth, cropHeight = srcHeight*rendition.width/rendition.height, srcHeight
+		}
+
+		cropWidth, cropHeight = max(1, cropWidth), max(1, cropHeight)
+
+		x := bounds.Min.X + (srcWidth-cropWidth)/2
+		y := bounds.Min.Y + (srcHeight-cropHeight)/2
+		crop := stdimage.Rect(x, y, x+cropWidth, y+cropHeight)
+
+		if cropWidth <= rendition.width {
+			return crop, cropWidth, cropHeight
+		}
+
+		return crop, rendition.width, rendition.height
+	}
+
+	scale := 1.0
+
+	if rendition.width > 0 {
+		scale = math.Min(scale, float64(rendition.width)/float64(srcWidth))
+	}
+
+	if rendition.height > 0 {
+		scale = math.Min(scale, float64(rendition.height)/float64(srcHeight))
+	}
+
+	width := max(1, int(math.Round(float64(srcWidth)*scale)))
+	height := max(1, int(math.Round(float64(srcHeight)*scale)))
+
+	return bounds, width, height
+}
+
+func cropImage(img stdimage.Image, crop stdimage.Rectangle) stdimage.Image {
+	if sub, ok := img.(interface {
+		SubImage(stdimage.Rectangle) stdimage.Image
+	}); ok {
+		return sub.SubImage(crop)
+	}
+
+	dst := stdimage.NewRGBA(stdimage.Rect(0, 0, crop.Dx(), crop.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, crop.Min, draw.Src)
+
+	return dst
+}
@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/oullin/pkg/endpoint"
+	pkgimages "github.com/oullin/pkg/images"
+	"github.com/oullin/pkg/storage"
+)
+
+func newImagesHandlerFixture(t *testing.T) *ImagesHandler {
+	t.Helper()
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "media")
+
+	if err := os.MkdirAll(filepath.Join(dir, "posts", "hello"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 800, 400))
+	for y := 0; y < 400; y++ {
+		for x := 0; x < 800; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x % 256), G: uint8(y % 256), B: 90, A: 255})
+		}
+	}
+
+	if err := pkgimages.Save(filepath.Join(dir, "posts", "hello", "cover.png"), img, ".png", pkgimages.DefaultJPEGQuality); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "posts", "notes.txt"), []byte("nope"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	return NewImagesHandler(storage.NewLocal(dir, "/media"), filepath.Join(root, "cache"))
+}
+
+func requestImage(h *ImagesHandler, rel, query, accept string) (*httptest.ResponseRecorder, *endpoint.ApiError) {
+	req := httptest.NewRequest(http.MethodGet, "/images/"+rel+query, nil)
+	req.SetPathValue("path", rel)
+
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	rec := httptest.NewRecorder()
+
+	if err := h.Show(rec, req); err != nil {
+		return rec, err
+	}
+
+	return rec, nil
+}
+
+func TestImagesHandlerResizes(t *testing.T) {
+	h := newImagesHandlerFixture(t)
+
+	rec, err := requestImage(h, "posts/hello/cover.png", "?w=320&fmt=png", "")
+	if err != nil {
+		t.Fatalf("show: %v", err)
+	}
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("unexpected response %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	if rec.Header().Get("Cache-Control") != "public, max-age=0, must-revalidate" {
+		t.Fatalf("unexpected cache control %q", rec.Header().Get("Cache-Control"))
+	}
+
+	if rec.Header().Get("Vary") != "" {
+		t.Fatalf("expected no Vary header for an explicit format, got %q", rec.Header().Get("Vary"))
+	}
+
+	decoded, decodeErr := png.Decode(bytes.NewReader(rec.Body.Bytes()))
+	if decodeErr != nil {
+		t.Fatalf("decode: %v", decodeErr)
+	}
+
+	if decoded.Bounds().Dx() != 320 || decoded.Bounds().Dy() != 160 {
+		t.Fatalf("expected 320x160, got %v", decoded.Bounds())
+	}
+
+	entries, _ := os.ReadDir(h.CacheDir)
+	if len(entries) != 1 {
+		t.Fatalf("expected one cached rendition, got %d", len(entries))
+	}
+}
+
+func TestImagesHandlerCoverCrops(t *testing.T) {
+	h := newImagesHandlerFixture(t)
+
+	rec, err := requestImage(h, "posts/hello/cover.png", "?w=160&h=160&fit=cover&fmt=png", "")
+	if err != nil {
+		t.Fatalf("show: %v", err)
+	}
+
+	decoded, decodeErr := png.Decode(bytes.NewReader(rec.Body.Bytes()))
+	if decodeErr != nil {
+		t.Fatalf("decode: %v", decodeErr)
+	}
+
+	if decoded.Bounds().Dx() != 160 || decoded.Bounds().Dy() != 160 {
+		t.Fatalf("expected 160x160, got %v", decoded.Bounds())
+	}
+}
+
+func TestImagesHandlerNeverUpscales(t *testing.T) {
+	h := newImagesHandlerFixture(t)
+
+	rec, err := requestImage(h, "posts/hello/cover.png", "?w=1920&fmt=png", "")
+	if err != nil {
+		t.Fatalf("show: %v", err)
+	}
+
+	decoded, decodeErr := png.Decode(bytes.NewReader(rec.Body.Bytes()))
+	if decodeErr != nil {
+		t.Fatalf("decode: %v", decodeErr)
+	}
+
+	if decoded.Bounds().Dx() != 800 || decoded.Bounds().Dy() != 400 {
+		t.Fatalf("expected the source size, got %v", decoded.Bounds())
+	}
+}
+
+func TestImagesHandlerNegotiatesFormat(t *testing.T) {
+	h := newImagesHandlerFixture(t)
+
+	rec, err := requestImage(h, "posts/hello/cover.png", "?w=160", "image/avif,image/webp,*/*")
+	if err != nil {
+		t.Fatalf("show: %v", err)
+	}
+
+	if rec.Header().Get("Content-Type") != "image/avif" || rec.Header().Get("Vary") != "Accept" {
+		t.Fatalf("expected a negotiated avif, got %q (vary %q)", rec.Header().Get("Content-Type"), rec.Header().Get("Vary"))
+	}
+
+	rec, err = requestImage(h, "posts/hello/cover.png", "?w=160", "image/avif;q=0,*/*")
+	if err != nil {
+		t.Fatalf("show: %v", err)
+	}
+
+	if rec.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("expected the source format, got %q", rec.Header().Get("Content-Type"))
+	}
+}
+
+func TestImagesHandlerConditionalRequest(t *testing.T) {
+	h := newImagesHandlerFixture(t)
+
+	rec, err := requestImage(h, "posts/hello/cover.png", "?w=160&fmt=png", "")
+	if err != nil {
+		t.Fatalf("show: %v", err)
+	}
+
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("expected an etag")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/images/posts/hello/cover.png?w=160&fmt=png", nil)
+	req.SetPathValue("path", "posts/hello/cover.png")
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+
+	if err := h.Show(rec, req); err != nil {
+		t.Fatalf("show: %v", err)
+	}
+
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", rec.Code)
+	}
+}
+
+func TestImagesHandlerRejectsInvalidRequests(t *testing.T) {
+	h := newImagesHandlerFixture(t)
+
+	cases := []struct {
+		name   string
+		rel    string
+		query  string
+		status int
+	}{
+		{"size outside the allow-list", "posts/hello/cover.png", "?w=333", http.StatusBadRequest},
+		{"unknown fit", "posts/hello/cover.png", "?w=160&fit=stretch", http.StatusBadRequest},
+		{"unknown format", "posts/hello/cover.png", "?fmt=gif", http.StatusBadRequest},
+		{"missing file", "posts/hello/missing.png", "?w=160", http.StatusNotFound},
+		{"non image file", "posts/notes.txt", "", http.StatusNotFound},
+		{"traversal", "../cache/x.png", "", http.StatusNotFound},
+		{"hidden file", "posts/.hidden.png", "", http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := requestImage(h, tc.rel, tc.query, "")
+
+			if err == nil || err.Status != tc.status {
+				t.Fatalf("expected status %d, got %v", tc.status, err)
+			}
+		})
+	}
+}
+
+// contextStorage fails reads once their context is done, as a remote driver would.
+type contextStorage struct {
+	storage.Storage
+}
+
+func (s contextStorage) Get(ctx context.Context, key string) (*storage.Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.Storage.Get(ctx, key)
+}
+
+func TestImagesHandlerRenderOutlivesTheRequest(t *testing.T) {
+	h := newImagesHandlerFixture(t)
+	h.Storage = contextStorage{Storage: h.Storage}
+
+	// Another rendition first, so the source hash is already known.
+	if _, err := requestImage(h, "posts/hello/cover.png", "?w=480&fmt=png", ""); err != nil {
+		t.Fatalf("warm up: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/images/posts/hello/cover.png?w=320&fmt=png", nil)
+	req.SetPathValue("path", "posts/hello/cover.png")
+
+	// The render is shared by everyone waiting on the rendition, so a client that went
+	// away must not fail it.
+	if err := h.Show(httptest.NewRecorder(), req); err != nil {
+		t.Fatalf("expected the render to ignore the request cancellation, got %v", err)
+	}
+}
+
+func TestImagesHandlerVersionedRequestsAreImmutable(t *testing.T) {
+	h := newImagesHandlerFixture(t)
+
+	rec, err := requestImage(h, "posts/hello/cover.png", "?w=160&fmt=png", "")
+	if err != nil {
+		t.Fatalf("show: %v", err)
+	}
+
+	location := rec.Header().Get("Content-Location")
+	if location == "" {
+		t.Fatalf("expected the versioned URL in Content-Location")
+	}
+
+	query := location[len("/images/posts/hello/cover.png"):]
+
+	rec, err = requestImage(h, "posts/hello/cover.png", query, "")
+	if err != nil {
+		t.Fatalf("show: %v", err)
+	}
+
+	if rec.Header().Get("Cache-Control") != "public, max-age=31536000, immutable" || rec.Header().Get("Content-Location") != "" {
+		t.Fatalf("unexpected headers for %q: %v", query, rec.Header())
+	}
+
+	rec, err = requestImage(h, "posts/hello/cover.png", "?w=160&fmt=png&v=outdated", "")
+	if err != nil {
+		t.Fatalf("show: %v", err)
+	}
+
+	if rec.Header().Get("Cache-Control") != "public, max-age=0, must-revalidate" || rec.Header().Get("Content-Location") != location {
+		t.Fatalf("expected an outdated version to be revalidated, got %v", rec.Header())
+	}
+}
+
+func TestImageHashesDropTheLeastRecentlyUsed(t *testing.T) {
+	hashes := newImageHashes(2)
+
+	hashes.add(imageSourceKey{key: "a"}, "1")
+	hashes.add(imageSourceKey{key: "b"}, "2")
+	hashes.get(imageSourceKey{key: "a"})
+	hashes.add(imageSourceKey{key: "c"}, "3")
+
+	if _, ok := hashes.get(imageSourceKey{key: "b"}); ok {
+		t.Fatalf("expected the least recently used hash to be dropped")
+	}
+
+	if hash, ok := hashes.get(imageSourceKey{key: "a"}); !ok || hash != "1" {
+		t.Fatalf("expected the recently used hash to be kept, got %q", hash)
+	}
+}
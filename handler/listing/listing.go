@@ -0,0 +1,308 @@
+// Package listing filters, sorts and paginates the items of a fixture list by the query
+// string, e.g. /talks?year=2019&sort=-created_at&page=2.
+package listing
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oullin/database/repository/pagination"
+)
+
+// ErrInvalidQuery is wrapped by every Parse error, which are the caller's fault.
+var ErrInvalidQuery = errors.New("invalid list query")
+
+// Match says how a filter compares the query value with the item field. Both sides are
+// trimmed and compared case-insensitively.
+type Match int
+
+const (
+	Exact   Match = iota // the whole value, e.g. ?featured=1
+	Partial              // any part of the value, e.g. ?company=aspire
+	Flag                 // a boolean, given as true/false or 1/0
+)
+
+// Filter narrows the list down by one item field. Comma-separated query values match any
+// of them, e.g. ?featured=0,1.
+type Filter[T any] struct {
+	Name    string
+	Value   func(T) string
+	Match   Match
+	Default string // Applied when the query leaves the filter out.
+}
+
+// Sort orders the list by one date field: ?sort=created_at is oldest first and
+// ?sort=-created_at newest first. Items without a readable date always go last.
+type Sort[T any] struct {
+	Name string
+	Date func(T) string
+}
+
+// Engine is the list definition of one fixture.
+type Engine[T any] struct {
+	Filters  []Filter[T]
+	Sorts    []Sort[T]
+	PageSize int // Zero returns every item unless ?page= or ?limit= is given.
+}
+
+// Query is a parsed query string. Unknown parameters are ignored.
+type Query struct {
+	filters [][]string // Indexed like Engine.Filters; nil when the filter is off.
+	sort    int        // Index in Engine.Sorts, or -1.
+	desc    bool
+	page    int
+	limit   int
+	paged   bool
+}
+
+func (e Engine[T]) Parse(values url.Values) (Query, error) {
+	query := Query{
+		filters: make([][]string, len(e.Filters)),
+		sort:    -1,
+		page:    pagination.MinPage,
+		limit:   e.PageSize,
+		paged:   e.PageSize > 0,
+	}
+
+	for i, filter := range e.Filters {
+		raw := strings.TrimSpace(values.Get(filter.Name))
+		if raw == "" {
+			raw = filter.Default
+		}
+
+		for _, value := range strings.Split(raw, ",") {
+			value = strings.ToLower(strings.TrimSpace(value))
+			if value == "" {
+				continue
+			}
+
+			if filter.Match == Flag {
+				flag, err := strconv.ParseBool(value)
+				if err != nil {
+					return query, fmt.Errorf("%w: %s must be true or false", ErrInvalidQuery, filter.Name)
+				}
+
+				value = strconv.FormatBool(flag)
+			}
+
+			query.filters[i] = append(query.filters[i], value)
+		}
+
+		slices.Sort(query.filters[i])
+	}
+
+	if name := strings.TrimSpace(values.Get("sort")); name != "" {
+		query.desc = strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		query.sort = slices.IndexFunc(e.Sorts, func(s Sort[T]) bool { return s.Name == name })
+		if query.sort < 0 {
+			return query, fmt.Errorf("%w: cannot sort by %q", ErrInvalidQuery, name)
+		}
+	}
+
+	if raw := strings.TrimSpace(values.Get("page")); raw != "" {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < pagination.MinPage {
+			return query, fmt.Errorf("%w: page must be a positive number", ErrInvalidQuery)
+		}
+
+		query.page = page
+		query.paged = true
+	}
+
+	if raw := strings.TrimSpace(values.Get("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return query, fmt.Errorf("%w: limit must be a positive number", ErrInvalidQuery)
+		}
+
+		query.limit = limit
+		query.paged = true
+	}
+
+	if query.paged && query.limit <= 0 {
+		query.limit = pagination.FixturesMaxLimit
+	}
+
+	query.limit = min(query.limit, pagination.FixturesMaxLimit)
+
+	return query, nil
+}
+
+// IsZero reports whether the query leaves the list as it is.
+func (q Query) IsZero() bool {
+	return !q.paged && q.sort < 0 && !slices.ContainsFunc(q.filters, func(values []string) bool { return len(values) > 0 })
+}
+
+// Paged reports whether the result is a page of the list rather than all of it.
+func (q Query) Paged() bool {
+	return q.paged
+}
+
+// Key identifies the query: two queries with the same key give the same result.
+func (q Query) Key() string {
+	var key strings.Builder
+
+	for i, values := range q.filters {
+		fmt.Fprintf(&key, "%d=%s&", i, strings.Join(values, ","))
+	}
+
+	fmt.Fprintf(&key, "sort=%d,%t&page=%d&limit=%d,%t", q.sort, q.desc, q.page, q.limit, q.paged)
+
+	return key.String()
+}
+
+// Apply returns the matching items in a new slice, with the page the query asks for.
+// Without paging the result is a single page holding every match.
+func (e Engine[T]) Apply(items []T, q Query) *pagination.Pagination[T] {
+	matches := make([]T, 0, len(items))
+
+	for _, item := range items {
+		if e.matches(item, q) {
+			matches = append(matches, item)
+		}
+	}
+
+	if q.sort >= 0 {
+		SortByDate(matches, e.Sorts[q.sort].Date, q.desc)
+	}
+
+	page := pagination.Paginate{Page: q.page, Limit: q.limit}
+	page.SetNumItems(int64(len(matches)))
+
+	if !q.paged {
+		page.Page, page.Limit = pagination.MinPage, max(len(matches), 1)
+
+		return pagination.NewPagination(matches, page)
+	}
+
+	start := min((page.Page-1)*page.Limit, len(matches))
+	end := min(start+page.Limit, len(matches))
+
+	return pagination.NewPagination(matches[start:end], page)
+}
+
+func (e Engine[T]) matches(item T, q Query) bool {
+	for i, filter := range e.Filters {
+		wanted := q.filters[i]
+		if len(wanted) == 0 {
+			continue
+		}
+
+		value := strings.ToLower(strings.TrimSpace(filter.Value(item)))
+
+		if !slices.ContainsFunc(wanted, func(want string) bool { return filter.Match.compare(value, want) }) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m Match) compare(value, want string) bool {
+	switch m {
+	case Partial:
+		return strings.Contains(value, want)
+	case Flag:
+		flag, err := strconv.ParseBool(value)
+
+		return err == nil && strconv.FormatBool(flag) == want
+	default:
+		return value == want
+	}
+}
+
+// SortByDate orders the items by the date of each, in place and keeping the order of equal
+// dates. Items without a readable date go last.
+func SortByDate[T any](items []T, date func(T) string, desc bool) {
+	type keyed struct {
+		item T
+		time time.Time
+		ok   bool
+	}
+
+	keys := make([]keyed, len(items))
+	for i, item := range items {
+		parsed, ok := ParseDate(date(item))
+		keys[i] = keyed{item: item, time: parsed, ok: ok}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		left, right := keys[i], keys[j]
+
+		switch {
+		case left.ok && right.ok && desc:
+			return left.time.After(right.time)
+		case left.ok && right.ok:
+			return left.time.Before(right.time)
+		default:
+			return left.ok && !right.ok
+		}
+	})
+
+	for i, key := range keys {
+		items[i] = key.item
+	}
+}
+
+// dateLayouts are the date formats used across the fixtures, e.g. 2019-02-11 for talks,
+// "June, 2025" for experience and 2012 for education, each with the ISO 8601 layout of
+// the same precision.
+var dateLayouts = []struct{ layout, iso string }{
+	{time.DateOnly, time.DateOnly},
+	{time.RFC3339, time.DateOnly},
+	{"2006-01", "2006-01"},
+	{"January, 2006", "2006-01"},
+	{"January 2006", "2006-01"},
+	{"Jan, 2006", "2006-01"},
+	{"Jan 2006", "2006-01"},
+	{"2006", "2006"},
+}
+
+// ParseDate reads a fixture date in any of the formats the fixtures use.
+func ParseDate(value string) (time.Time, bool) {
+	parsed, _, ok := parseDate(value)
+
+	return parsed, ok
+}
+
+// ISODate writes a fixture date in ISO 8601 at the precision it was given in, e.g.
+// "June, 2025" becomes 2025-06 and 2012 stays 2012. It is empty when the date cannot be read.
+func ISODate(value string) string {
+	parsed, iso, ok := parseDate(value)
+	if !ok {
+		return ""
+	}
+
+	return parsed.Format(iso)
+}
+
+func parseDate(value string) (time.Time, string, bool) {
+	value = strings.TrimSpace(value)
+
+	for _, date := range dateLayouts {
+		if parsed, err := time.Parse(date.layout, value); err == nil {
+			return parsed, date.iso, true
+		}
+	}
+
+	return time.Time{}, "", false
+}
+
+// Year is the year of a fixture date, or empty when it cannot be read; it backs the ?year=
+// filters.
+func Year(value string) string {
+	parsed, ok := ParseDate(value)
+	if !ok {
+		return ""
+	}
+
+	return strconv.Itoa(parsed.Year())
+}
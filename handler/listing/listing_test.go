@@ -0,0 +1,210 @@
+package listing_test
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/oullin/handler/listing"
+)
+
+type item struct {
+	name     string
+	city     string
+	featured bool
+	date     string
+}
+
+func engine() listing.Engine[item] {
+	return listing.Engine[item]{
+		Filters: []listing.Filter[item]{
+			{Name: "city", Value: func(i item) string { return i.city }, Match: listing.Partial},
+			{Name: "featured", Value: func(i item) string { return strconv.FormatBool(i.featured) }, Match: listing.Flag},
+			{Name: "year", Value: func(i item) string { return listing.Year(i.date) }},
+		},
+		Sorts: []listing.Sort[item]{
+			{Name: "date", Date: func(i item) string { return i.date }},
+		},
+	}
+}
+
+var items = []item{
+	{name: "a", city: "Singapore", featured: true, date: "2019-02-11"},
+	{name: "b", city: "Kuala Lumpur", featured: false, date: "June, 2021"},
+	{name: "c", city: "Singapore", featured: false, date: ""},
+	{name: "d", city: "Caracas", featured: true, date: "2012"},
+}
+
+func names(list []item) string {
+	out := ""
+	for _, i := range list {
+		out += i.name
+	}
+
+	return out
+}
+
+func apply(t *testing.T, query string) string {
+	t.Helper()
+
+	values, _ := url.ParseQuery(query)
+
+	parsed, err := engine().Parse(values)
+	if err != nil {
+		t.Fatalf("parse %q: %v", query, err)
+	}
+
+	return names(engine().Apply(items, parsed).Data)
+}
+
+func TestApplyFiltersAndSorts(t *testing.T) {
+	cases := map[string]string{
+		"":                      "abcd",
+		"city=singa":            "ac",
+		"city=caracas,kuala":    "bd",
+		"featured=1":            "ad",
+		"featured=false":        "bc",
+		"year=2021":             "b",
+		"sort=date":             "dabc",
+		"sort=-date":            "badc",
+		"sort=-date&featured=1": "ad",
+		"limit=2&page=2":        "cd",
+		"limit=2&page=9":        "",
+	}
+
+	for query, want := range cases {
+		if got := apply(t, query); got != want {
+			t.Errorf("%q: expected %q, got %q", query, want, got)
+		}
+	}
+}
+
+func TestParseRejectsUnknownSortsAndBadFlags(t *testing.T) {
+	for _, query := range []string{"sort=name", "featured=maybe", "page=abc", "page=0", "limit=0", "limit=-5"} {
+		values, _ := url.ParseQuery(query)
+
+		if _, err := engine().Parse(values); !errors.Is(err, listing.ErrInvalidQuery) {
+			t.Errorf("%q: expected an invalid query, got %v", query, err)
+		}
+	}
+}
+
+func TestQueryPagingAndKeys(t *testing.T) {
+	plain, _ := engine().Parse(url.Values{})
+	if !plain.IsZero() || plain.Paged() {
+		t.Fatalf("expected an empty query to leave the list alone")
+	}
+
+	first, _ := engine().Parse(url.Values{"city": {"Singapore, Caracas"}, "limit": {"500"}})
+	second, _ := engine().Parse(url.Values{"city": {"caracas,singapore"}, "limit": {"100"}, "unknown": {"x"}})
+
+	if first.IsZero() || !first.Paged() || first.Key() != second.Key() {
+		t.Fatalf("expected equivalent queries to share a key: %q vs %q", first.Key(), second.Key())
+	}
+
+	result := engine().Apply(items, first)
+	if result.PageSize != 100 || result.Total != 3 || result.TotalPages != 1 {
+		t.Fatalf("unexpected page %+v", result)
+	}
+
+	defaults := engine()
+	defaults.Filters[1].Default = "true"
+
+	query, _ := defaults.Parse(url.Values{})
+	if got := names(defaults.Apply(items, query).Data); got != "ad" {
+		t.Fatalf("expected the default filter to apply, got %q", got)
+	}
+}
+
+func TestVariantsAreDroppedWithTheirSnapshot(t *testing.T) {
+	variants := listing.NewVariants(2)
+	builds := 0
+	build := func() any { builds++; return builds }
+
+	first, _ := variants.Get("v1", "a", build)
+	again, _ := variants.Get("v1", "a", build)
+	if builds != 1 || first.Checksum != again.Checksum {
+		t.Fatalf("expected the variant to be reused, built %d times", builds)
+	}
+
+	_, _ = variants.Get("v1", "b", build)
+	_, _ = variants.Get("v1", "c", build)
+	_, _ = variants.Get("v2", "a", build)
+
+	if builds != 4 {
+		t.Fatalf("expected a new snapshot to rebuild the variant, built %d times", builds)
+	}
+}
+
+func TestVariantsEvictTheLeastRecentlyUsed(t *testing.T) {
+	variants := listing.NewVariants(2)
+	builds := map[string]int{}
+	get := func(key string) {
+		_, _ = variants.Get("v1", key, func() any { builds[key]++; return key })
+	}
+
+	get("a")
+	get("b")
+	get("a")
+	get("c")
+	get("a")
+	get("b")
+
+	if builds["a"] != 1 || builds["b"] != 2 || builds["c"] != 1 {
+		t.Fatalf("expected b to be evicted as the least recently used, got %v", builds)
+	}
+}
+
+func TestVariantsShareConcurrentBuilds(t *testing.T) {
+	variants := listing.NewVariants(2)
+	release := make(chan struct{})
+	var builds atomic.Int32
+	var wg sync.WaitGroup
+
+	for range 8 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, _ = variants.Get("v1", "a", func() any {
+				builds.Add(1)
+				<-release
+
+				return "a"
+			})
+		}()
+	}
+
+	// Other queries are not held up by the build in flight.
+	if _, err := variants.Get("v1", "b", func() any { return "b" }); err != nil {
+		t.Fatalf("get b: %v", err)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if builds.Load() != 1 {
+		t.Fatalf("expected one shared build, got %d", builds.Load())
+	}
+}
+
+func TestISODateKeepsThePrecisionOfTheFixture(t *testing.T) {
+	cases := map[string]string{
+		"2019-02-11":           "2019-02-11",
+		"2019-02-11T10:00:00Z": "2019-02-11",
+		"June, 2025":           "2025-06",
+		"Jan 2020":             "2020-01",
+		" 2012 ":               "2012",
+		"Present":              "",
+	}
+
+	for value, want := range cases {
+		if got := listing.ISODate(value); got != want {
+			t.Errorf("%q: expected %q, got %q", value, want, got)
+		}
+	}
+}
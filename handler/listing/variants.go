@@ -0,0 +1,131 @@
+package listing
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Variant is one query's response, encoded once with the checksum its ETag is made of.
+type Variant struct {
+	Body     []byte
+	Checksum string
+}
+
+// Variants keeps the encoded responses of the queries asked of one snapshot, so a repeated
+// query costs no more than the unfiltered list. It is emptied whenever the snapshot
+// changes, and holds at most capacity queries, dropping the least recently used first,
+// since their number is up to the client.
+type Variants struct {
+	mu       sync.Mutex
+	snapshot string
+	order    *list.List // Front is the most recently used variant.
+	entries  map[string]*list.Element
+	inflight map[string]*variantCall
+	capacity int
+}
+
+type variantEntry struct {
+	key     string
+	variant Variant
+}
+
+type variantCall struct {
+	done    chan struct{}
+	variant Variant
+	err     error
+}
+
+func NewVariants(capacity int) *Variants {
+	return &Variants{
+		order:    list.New(),
+		entries:  make(map[string]*list.Element, capacity),
+		inflight: make(map[string]*variantCall),
+		capacity: capacity,
+	}
+}
+
+// Get returns the variant of the query for the snapshot with the given checksum, building
+// and encoding it on a miss. The build runs outside the lock, and concurrent callers for
+// the same query share it.
+func (v *Variants) Get(snapshot, key string, build func() any) (Variant, error) {
+	return v.GetEncoded(snapshot, key, func() (Variant, error) {
+		return Encode(build())
+	})
+}
+
+// GetEncoded is Get for a body the caller encodes, e.g. one that is not JSON. Errors are
+// not kept, so the next call builds again.
+func (v *Variants) GetEncoded(snapshot, key string, encode func() (Variant, error)) (Variant, error) {
+	v.mu.Lock()
+
+	if v.snapshot != snapshot {
+		v.order.Init()
+		clear(v.entries)
+		clear(v.inflight)
+		v.snapshot = snapshot
+	}
+
+	if element, ok := v.entries[key]; ok {
+		v.order.MoveToFront(element)
+		v.mu.Unlock()
+
+		return element.Value.(*variantEntry).variant, nil
+	}
+
+	if call, ok := v.inflight[key]; ok {
+		v.mu.Unlock()
+		<-call.done
+
+		return call.variant, call.err
+	}
+
+	call := &variantCall{done: make(chan struct{})}
+	v.inflight[key] = call
+	v.mu.Unlock()
+
+	call.variant, call.err = encode()
+
+	v.mu.Lock()
+
+	if v.inflight[key] == call {
+		delete(v.inflight, key)
+	}
+
+	// A build that finished after the snapshot changed is handed to its callers only.
+	if call.err == nil && v.snapshot == snapshot {
+		v.add(key, call.variant)
+	}
+
+	v.mu.Unlock()
+	close(call.done)
+
+	return call.variant, call.err
+}
+
+func (v *Variants) add(key string, variant Variant) {
+	v.entries[key] = v.order.PushFront(&variantEntry{key: key, variant: variant})
+
+	for v.order.Len() > max(v.capacity, 1) {
+		oldest := v.order.Back()
+		v.order.Remove(oldest)
+		delete(v.entries, oldest.Value.(*variantEntry).key)
+	}
+}
+
+// Encode turns a response computed on the fly into a variant.
+func Encode(payload any) (Variant, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Variant{}, err
+	}
+
+	return Checksum(body), nil
+}
+
+// Checksum wraps a body encoded elsewhere, e.g. one that is not JSON.
+func Checksum(body []byte) Variant {
+	return Variant{Body: body, Checksum: fmt.Sprintf("%x", sha256.Sum256(body))}
+}
@@ -0,0 +1,405 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/database/repository/pagination"
+	"github.com/oullin/database/repository/queries"
+	"github.com/oullin/handler/paginate"
+	"github.com/oullin/handler/payload"
+	"github.com/oullin/pkg/endpoint"
+	pkgimages "github.com/oullin/pkg/images"
+	"github.com/oullin/pkg/media"
+	"github.com/oullin/pkg/portal"
+	"github.com/oullin/pkg/storage"
+)
+
+// mediaExtensions lists the files produced by the cover variants, content mirroring and upload pipelines.
+var mediaExtensions = []string{".jpg", ".png", ".webp", ".avif"}
+
+// multipartOverhead leaves room for the boundaries and part headers around the uploaded file.
+const multipartOverhead = 64 << 10
+
+const mediaFileField = "file"
+
+const mediaAltField = "alt"
+
+// mediaAltMaxLength matches the size of the media.alt_text column.
+const mediaAltMaxLength = 255
+
+type MediaHandler struct {
+	Media   *repository.Media
+	Storage storage.Storage
+}
+
+// NewMediaHandler serves and indexes the files kept in the media storage.
+func NewMediaHandler(repo *repository.Media, store storage.Storage) MediaHandler {
+	return MediaHandler{
+		Media:   repo,
+		Storage: store,
+	}
+}
+
+func (h MediaHandler) Index(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	values := r.URL.Query()
+
+	filters := queries.MediaFilters{
+		Text:     values.Get("q"),
+		MimeType: values.Get("type"),
+		Owner:    values.Get("owner"),
+	}
+
+	result, err := h.Media.GetAll(filters, paginate.NewFrom(r.URL, 20))
+
+	if err != nil {
+		slog.Error("failed to fetch media", "err", err)
+
+		return endpoint.InternalError("There was an issue reading the media. Please, try again later.")
+	}
+
+	items := pagination.HydratePagination(result, payload.GetMediaResponse)
+
+	if err = endpoint.NewNoCacheResponse(w, r).RespondOk(items); err != nil {
+		slog.Error("Error marshaling JSON for media response", "error", err)
+
+		return endpoint.InternalError("could not encode the media response")
+	}
+
+	return nil
+}
+
+func (h MediaHandler) Show(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	item, apiErr := h.findMedia(r)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	if err := endpoint.NewNoCacheResponse(w, r).RespondOk(payload.GetMediaResponse(*item)); err != nil {
+		slog.Error("Error marshaling JSON for media response", "error", err)
+
+		return endpoint.InternalError("could not encode the media response")
+	}
+
+	return nil
+}
+
+// Destroy removes the file before its row, so a failure never leaves a row pointing at
+// nothing that the reconcile command would not also report.
+func (h MediaHandler) Destroy(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	item, apiErr := h.findMedia(r)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	key, err := storage.CleanKey(item.Path)
+	if err != nil {
+		return endpoint.InternalError(fmt.Sprintf("The media '%s' points outside the storage", item.UUID))
+	}
+
+	if err = h.Storage.Delete(r.Context(), key); err != nil {
+		return endpoint.LogInternalError("could not delete the given media file", err)
+	}
+
+	if err = h.Media.Delete(*item); err != nil {
+		return endpoint.LogInternalError("could not delete the given media", err)
+	}
+
+	endpoint.NewNoCacheResponse(w, r).RespondNoContent()
+
+	return nil
+}
+
+func (h MediaHandler) findMedia(r *http.Request) (*database.Media, *endpoint.ApiError) {
+	id := strings.TrimSpace(r.PathValue("uuid"))
+
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, endpoint.NotFound(fmt.Sprintf("The given media '%s' was not found", id))
+	}
+
+	item := h.Media.FindBy(id)
+	if item == nil {
+		return nil, endpoint.NotFound(fmt.Sprintf("The given media '%s' was not found", id))
+	}
+
+	return item, nil
+}
+
+// ShowPostImage serves a cover variant or a mirrored content image of a post. File names
+// carry a fingerprint of their source or content, so responses can be cached forever.
+func (h MediaHandler) ShowPostImage(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	return serveMediaFile(w, r, h.Storage, media.PostsDir)
+}
+
+// ShowUpload serves a file stored through Store; upload names are content-addressed.
+func (h MediaHandler) ShowUpload(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	return serveMediaFile(w, r, h.Storage, media.UploadsDir)
+}
+
+// Store accepts a multipart "file" field and an optional "alt" text. The content type is sniffed from
+// the bytes and the image is re-encoded before it is stored, so client names, headers and metadata
+// are all dropped. Every stored file is indexed in the media table under the calling account.
+func (h MediaHandler) Store(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	defer portal.CloseWithLog(r.Body)
+
+	limit := media.GetMaxFileSize()
+	r.Body = http.MaxBytesReader(w, r.Body, limit+multipartOverhead)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return endpoint.LogBadRequestError("the request must be a multipart form", err)
+	}
+
+	upload, apiErr := readUploadedFile(reader, limit)
+	defer upload.Close()
+
+	if apiErr != nil {
+		return apiErr
+	}
+
+	sample := make([]byte, 512)
+	n, err := upload.File.ReadAt(sample, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return endpoint.LogInternalError("could not read the given file", err)
+	}
+
+	contentType, err := media.SniffContentType(sample[:n])
+	if err != nil {
+		return endpoint.UnprocessableEntity("The given file is invalid", map[string]any{
+			mediaFileField: err.Error(),
+		})
+	}
+
+	img, _, err := pkgimages.DecodeReader(upload.File)
+	if errors.Is(err, pkgimages.ErrTooManyPixels) {
+		return endpoint.UnprocessableEntity("The given file is invalid", map[string]any{
+			mediaFileField: fmt.Sprintf("the given image exceeds %d pixels", pkgimages.MaxPixels),
+		})
+	}
+
+	if err != nil {
+		return endpoint.UnprocessableEntity("The given file is invalid", map[string]any{
+			mediaFileField: "the given image could not be decoded",
+		})
+	}
+
+	ext := uploadExtensionFor(contentType)
+
+	stored, err := pkgimages.SaveContentAddressed(r.Context(), h.Storage, media.UploadsDir, img, ext, pkgimages.DefaultJPEGQuality)
+	if err != nil {
+		return endpoint.LogInternalError("could not store the given file", err)
+	}
+
+	owner, _ := r.Context().Value(portal.AuthAccountNameKey).(string)
+	bounds := img.Bounds()
+
+	item, err := h.Media.Create(database.MediaAttrs{
+		Path:     path.Join(media.UploadsDir, stored.Name),
+		MimeType: pkgimages.MIMEFromExtension(ext),
+		Size:     stored.Size,
+		Width:    bounds.Dx(),
+		Height:   bounds.Dy(),
+		Sha256:   stored.Sha256,
+		AltText:  upload.Alt,
+		Owner:    owner,
+	})
+
+	if errors.Is(err, repository.ErrMediaExists) {
+		return endpoint.Conflict(fmt.Sprintf("The given file is already stored as media '%s'", item.UUID))
+	}
+
+	if err != nil {
+		return endpoint.LogInternalError("could not index the given file", err)
+	}
+
+	if err = endpoint.NewNoCacheResponse(w, r).RespondCreated(payload.GetMediaResponse(*item)); err != nil {
+		slog.Error("Error marshaling JSON for media response", "error", err)
+
+		return endpoint.InternalError("could not encode the media response")
+	}
+
+	return nil
+}
+
+// uploadedFile is spooled to a temporary file rather than held in memory.
+type uploadedFile struct {
+	File *os.File
+	Alt  string
+}
+
+func (u uploadedFile) Close() {
+	if u.File == nil {
+		return
+	}
+
+	_ = u.File.Close()
+	_ = os.Remove(u.File.Name())
+}
+
+func readUploadedFile(reader *multipart.Reader, limit int64) (uploadedFile, *endpoint.ApiError) {
+	var upload uploadedFile
+
+	for {
+		part, err := reader.NextPart()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return upload, uploadReadError(err)
+		}
+
+		switch part.FormName() {
+		case mediaAltField:
+			alt, err := io.ReadAll(io.LimitReader(part, mediaAltMaxLength+1))
+			_ = part.Close()
+
+			if err != nil {
+				return upload, uploadReadError(err)
+			}
+
+			if len(alt) > mediaAltMaxLength {
+				return upload, endpoint.UnprocessableEntity("The given fields are invalid", map[string]any{
+					mediaAltField: fmt.Sprintf("the alt text must not exceed %d characters", mediaAltMaxLength),
+				})
+			}
+
+			upload.Alt = strings.TrimSpace(string(alt))
+		case mediaFileField:
+			if upload.File != nil {
+				_ = part.Close()
+				continue
+			}
+
+			file, err := os.CreateTemp("", "media-upload-*")
+			if err != nil {
+				_ = part.Close()
+
+				return upload, endpoint.LogInternalError("could not spool the uploaded file", err)
+			}
+
+			upload.File = file
+
+			size, err := io.Copy(file, io.LimitReader(part, limit+1))
+			_ = part.Close()
+
+			if err != nil {
+				return upload, uploadReadError(err)
+			}
+
+			if size > limit {
+				return upload, endpoint.PayloadTooLarge(fmt.Sprintf("the given file exceeds %d bytes", limit))
+			}
+
+			if size == 0 {
+				return upload, endpoint.UnprocessableEntity("The given fields are invalid", map[string]any{
+					mediaFileField: "the given file is empty",
+				})
+			}
+		default:
+			_ = part.Close()
+		}
+	}
+
+	if upload.File == nil {
+		return upload, endpoint.UnprocessableEntity("The given fields are invalid", map[string]any{
+			mediaFileField: "field 'file' is required",
+		})
+	}
+
+	return upload, nil
+}
+
+func uploadReadError(err error) *endpoint.ApiError {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return endpoint.PayloadTooLarge(fmt.Sprintf("the request exceeds %d bytes", maxBytesErr.Limit))
+	}
+
+	return endpoint.LogBadRequestError("could not read the uploaded file", err)
+}
+
+// uploadExtensionFor picks the encoding of a stored upload. GIFs are flattened to PNG and
+// WebP falls back to PNG on builds that cannot encode it.
+func uploadExtensionFor(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/webp":
+		if slices.Contains(pkgimages.GetVariantFormats(), pkgimages.FormatWebP) {
+			return ".webp"
+		}
+
+		return ".png"
+	default:
+		return ".png"
+	}
+}
+
+func serveMediaFile(w http.ResponseWriter, r *http.Request, store storage.Storage, area string) *endpoint.ApiError {
+	rel := strings.TrimPrefix(path.Clean("/"+r.PathValue("path")), "/")
+
+	if rel == "" || !slices.Contains(mediaExtensions, strings.ToLower(path.Ext(rel))) {
+		return endpoint.NotFound(fmt.Sprintf("The given image '%s' was not found", rel))
+	}
+
+	object, err := store.Get(r.Context(), path.Join(area, rel))
+
+	if errors.Is(err, storage.ErrNotFound) {
+		return endpoint.NotFound(fmt.Sprintf("The given image '%s' was not found", rel))
+	}
+
+	if err != nil {
+		return endpoint.LogInternalError("could not read the given image", err)
+	}
+
+	defer portal.CloseWithLog(object.Body)
+
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if err = serveStoredObject(w, r, object); err != nil {
+		return endpoint.LogInternalError("could not read the given image", err)
+	}
+
+	return nil
+}
+
+// serveStoredObject answers conditional and range requests. Local files are served as they
+// are; remote bodies cannot seek, so they are read into memory, which the upload limit bounds.
+func serveStoredObject(w http.ResponseWriter, r *http.Request, object *storage.Object) error {
+	content, ok := object.Body.(io.ReadSeeker)
+
+	if !ok {
+		data, err := io.ReadAll(object.Body)
+		if err != nil {
+			return err
+		}
+
+		content = bytes.NewReader(data)
+	}
+
+	w.Header().Set("Content-Type", object.ContentType)
+
+	if object.ETag != "" && w.Header().Get("ETag") == "" {
+		w.Header().Set("ETag", object.ETag)
+	}
+
+	http.ServeContent(w, r, path.Base(object.Key), object.ModTime, content)
+
+	return nil
+}
@@ -0,0 +1,303 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/database/repository/pagination"
+	"github.com/oullin/handler/payload"
+	"github.com/oullin/internal/testutil/dbtest"
+	"github.com/oullin/pkg/portal"
+	"github.com/oullin/pkg/storage"
+)
+
+func TestMediaHandlerShowPostImage(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "posts")
+
+	if err := os.MkdirAll(filepath.Join(dir, "hello"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "hello", "hello-abc-480w.webp"), []byte("RIFF0000WEBP"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("nope"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	h := NewMediaHandler(nil, storage.NewLocal(root, "/media"))
+
+	req := httptest.NewRequest(http.MethodGet, "/media/posts/hello/hello-abc-480w.webp", nil)
+	req.SetPathValue("path", "hello/hello-abc-480w.webp")
+	rec := httptest.NewRecorder()
+
+	if err := h.ShowPostImage(rec, req); err != nil {
+		t.Fatalf("show: %v", err)
+	}
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "RIFF0000WEBP" {
+		t.Fatalf("unexpected response %d %q", rec.Code, rec.Body.String())
+	}
+
+	if rec.Header().Get("Cache-Control") != "public, max-age=31536000, immutable" {
+		t.Fatalf("unexpected cache control %q", rec.Header().Get("Cache-Control"))
+	}
+
+	for _, rel := range []string{"secret.txt", "../secret.txt", "hello/missing-480w.webp", "hello"} {
+		req = httptest.NewRequest(http.MethodGet, "/media/posts/x", nil)
+		req.SetPathValue("path", rel)
+
+		if err := h.ShowPostImage(httptest.NewRecorder(), req); err == nil || err.Status != http.StatusNotFound {
+			t.Fatalf("expected 404 for %q, got %v", rel, err)
+		}
+	}
+}
+
+func newUploadRequest(t *testing.T, field, name string, content []byte, alt string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	if alt != "" {
+		if err := writer.WriteField("alt", alt); err != nil {
+			t.Fatalf("write alt field: %v", err)
+		}
+	}
+
+	part, err := writer.CreateFormFile(field, name)
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+
+	if _, err = part.Write(content); err != nil {
+		t.Fatalf("write form file: %v", err)
+	}
+
+	if err = writer.Close(); err != nil {
+		t.Fatalf("close writer: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/media", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return req.WithContext(context.WithValue(req.Context(), portal.AuthAccountNameKey, "gus"))
+}
+
+func newMediaHandler(t *testing.T) (MediaHandler, *database.Connection) {
+	t.Helper()
+
+	conn, _ := dbtest.NewTestDB(t)
+
+	return NewMediaHandler(&repository.Media{DB: conn}, storage.NewLocal(t.TempDir(), "/media")), conn
+}
+
+func newPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	var img bytes.Buffer
+	if err := png.Encode(&img, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	return img.Bytes()
+}
+
+// pngHeader is the start of a PNG claiming the given dimensions, enough for the size check.
+func pngHeader(width, height uint32) []byte {
+	ihdr := []byte("IHDR")
+	ihdr = binary.BigEndian.AppendUint32(ihdr, width)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, height)
+	ihdr = append(ihdr, 8, 0, 0, 0, 0)
+
+	header := []byte("\x89PNG\r\n\x1a\n")
+	header = binary.BigEndian.AppendUint32(header, 13)
+	header = append(header, ihdr...)
+
+	return binary.BigEndian.AppendUint32(header, crc32.ChecksumIEEE(ihdr))
+}
+
+func TestMediaHandlerStore(t *testing.T) {
+	h, _ := newMediaHandler(t)
+
+	// The extension lies; the bytes decide how the file is stored.
+	rec := httptest.NewRecorder()
+	if err := h.Store(rec, newUploadRequest(t, "file", "avatar.jpg", newPNG(t, 12, 7), " A cat ")); err != nil {
+		t.Fatalf("store: %v", err)
+	}
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status %d", rec.Code)
+	}
+
+	var resp payload.MediaResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if resp.Width != 12 || resp.Height != 7 || resp.MimeType != "image/png" || resp.Size == 0 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	if resp.UUID == "" || resp.Owner != "gus" || resp.AltText != "A cat" || len(resp.Sha256) != 64 {
+		t.Fatalf("expected the upload to be indexed, got %+v", resp)
+	}
+
+	if stored := h.Media.FindBy(resp.UUID); stored == nil || stored.Path != "uploads/"+path.Base(resp.URL) {
+		t.Fatalf("expected a media row for the upload, got %+v", stored)
+	}
+
+	if !strings.HasPrefix(resp.URL, "/media/uploads/") || !strings.HasSuffix(resp.URL, ".png") {
+		t.Fatalf("unexpected url %s", resp.URL)
+	}
+
+	if _, err := os.Stat(filepath.Join(h.Storage.(*storage.Local).Root, "uploads", path.Base(resp.URL))); err != nil {
+		t.Fatalf("expected the upload on disk: %v", err)
+	}
+
+	// The same bytes again are refused rather than silently keeping the first alt text.
+	if err := h.Store(httptest.NewRecorder(), newUploadRequest(t, "file", "again.png", newPNG(t, 12, 7), "A dog")); err == nil || err.Status != http.StatusConflict {
+		t.Fatalf("expected 409 for a file stored before, got %v", err)
+	}
+
+	if stored := h.Media.FindBy(resp.UUID); stored == nil || stored.AltText != "A cat" {
+		t.Fatalf("expected the first alt text to be kept, got %+v", stored)
+	}
+}
+
+func TestMediaHandlerStoreRejectsInvalidUploads(t *testing.T) {
+	h := NewMediaHandler(nil, storage.NewLocal(t.TempDir(), "/media"))
+
+	cases := map[string]*http.Request{
+		"disguised text":  newUploadRequest(t, "file", "shot.png", []byte("<html>not an image</html>"), ""),
+		"missing field":   newUploadRequest(t, "other", "shot.png", []byte("data"), ""),
+		"long alt text":   newUploadRequest(t, "file", "shot.png", newPNG(t, 2, 2), strings.Repeat("a", 256)),
+		"too many pixels": newUploadRequest(t, "file", "bomb.png", pngHeader(20000, 20000), ""),
+	}
+
+	for name, req := range cases {
+		if err := h.Store(httptest.NewRecorder(), req); err == nil || err.Status != http.StatusUnprocessableEntity {
+			t.Fatalf("%s: expected 422, got %v", name, err)
+		}
+	}
+
+	plain := httptest.NewRequest(http.MethodPost, "/media", strings.NewReader("{}"))
+	plain.Header.Set("Content-Type", "application/json")
+
+	if err := h.Store(httptest.NewRecorder(), plain); err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for non multipart requests, got %v", err)
+	}
+}
+
+func TestReadUploadedFileEnforcesLimit(t *testing.T) {
+	req := newUploadRequest(t, "file", "big.png", bytes.Repeat([]byte("a"), 64), "")
+
+	reader, err := req.MultipartReader()
+	if err != nil {
+		t.Fatalf("reader: %v", err)
+	}
+
+	upload, apiErr := readUploadedFile(reader, 32)
+	defer upload.Close()
+
+	if apiErr == nil || apiErr.Status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %v", apiErr)
+	}
+
+	if _, err = os.Stat(upload.File.Name()); err != nil {
+		t.Fatalf("expected the upload to be spooled to disk: %v", err)
+	}
+
+	upload.Close()
+
+	if _, err = os.Stat(upload.File.Name()); !os.IsNotExist(err) {
+		t.Fatalf("expected the spooled upload to be removed, got %v", err)
+	}
+}
+
+func TestMediaHandlerIndexShowAndDestroy(t *testing.T) {
+	h, conn := newMediaHandler(t)
+
+	rec := httptest.NewRecorder()
+	if err := h.Store(rec, newUploadRequest(t, "file", "a.png", newPNG(t, 3, 3), "Diagram")); err != nil {
+		t.Fatalf("store: %v", err)
+	}
+
+	var stored payload.MediaResponse
+	if err := json.NewDecoder(rec.Body).Decode(&stored); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	other := database.Media{UUID: uuid.NewString(), Path: "posts/hello/other.jpg", MimeType: "image/jpeg", Sha256: strings.Repeat("b", 64), Owner: "mirror"}
+	if err := conn.Sql().Create(&other).Error; err != nil {
+		t.Fatalf("create media: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/media?q=diagram&type=image/", nil)
+	rec = httptest.NewRecorder()
+
+	if err := h.Index(rec, req); err != nil {
+		t.Fatalf("index: %v", err)
+	}
+
+	var page pagination.Pagination[payload.MediaResponse]
+	if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if page.Total != 1 || len(page.Data) != 1 || page.Data[0].UUID != stored.UUID {
+		t.Fatalf("expected only the matching upload, got %+v", page)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/media/"+other.UUID, nil)
+	req.SetPathValue("uuid", other.UUID)
+	rec = httptest.NewRecorder()
+
+	if err := h.Show(rec, req); err != nil || !strings.Contains(rec.Body.String(), "/media/posts/hello/other.jpg") {
+		t.Fatalf("show: %v %s", err, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/media/"+stored.UUID, nil)
+	req.SetPathValue("uuid", stored.UUID)
+	rec = httptest.NewRecorder()
+
+	if err := h.Destroy(rec, req); err != nil || rec.Code != http.StatusNoContent {
+		t.Fatalf("destroy: %v %d", err, rec.Code)
+	}
+
+	if h.Media.FindBy(stored.UUID) != nil {
+		t.Fatalf("expected the media row to be deleted")
+	}
+
+	if _, err := os.Stat(filepath.Join(h.Storage.(*storage.Local).Root, "uploads", path.Base(stored.URL))); !os.IsNotExist(err) {
+		t.Fatalf("expected the file to be deleted, got %v", err)
+	}
+
+	for _, id := range []string{stored.UUID, "not-a-uuid"} {
+		req = httptest.NewRequest(http.MethodGet, "/media/"+id, nil)
+		req.SetPathValue("uuid", id)
+
+		if err := h.Show(httptest.NewRecorder(), req); err == nil || err.Status != http.StatusNotFound {
+			t.Fatalf("expected 404 for %q, got %v", id, err)
+		}
+	}
+}
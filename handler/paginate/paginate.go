@@ -29,6 +29,10 @@ func NewFrom(url *url.URL, pageSize int) pagination.Paginate {
 		pageSize = pagination.CategoriesMaxLimit
 	}
 
+	if strings.Contains(path, "media") && pageSize > pagination.MediaMaxLimit {
+		pageSize = pagination.MediaMaxLimit
+	}
+
 	if strings.Contains(path, "posts") && pageSize > pagination.PostsMaxLimit {
 		pageSize = pagination.PostsMaxLimit
 	}
@@ -0,0 +1,64 @@
+package payload
+
+import (
+	"fmt"
+
+	"github.com/oullin/database"
+	"github.com/oullin/pkg/images"
+	"github.com/oullin/pkg/media"
+)
+
+// CoverImageResponse is one responsive cover rendition; Srcset is the ready-made
+// "url <width>w" candidate for an <img srcset> or <source srcset> attribute.
+type CoverImageResponse struct {
+	URL      string `json:"url"`
+	Srcset   string `json:"srcset"`
+	Format   string `json:"format"`
+	MimeType string `json:"mime_type"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+func GetCoverImagesResponse(images []database.PostCoverImage) []CoverImageResponse {
+	var data []CoverImageResponse
+
+	for _, image := range images {
+		url := media.GetPublicURL(image.Path)
+
+		data = append(data, CoverImageResponse{
+			URL:      url,
+			Srcset:   fmt.Sprintf("%s %dw", url, image.Width),
+			Format:   image.Format,
+			MimeType: image.MimeType,
+			Width:    image.Width,
+			Height:   image.Height,
+		})
+	}
+
+	return data
+}
+
+// CoverPlaceholderResponse lets clients reserve the cover box and paint a preview before
+// any variant loads.
+type CoverPlaceholderResponse struct {
+	BlurHash      string  `json:"blurhash"`
+	DominantColor string  `json:"dominant_color"`
+	AspectRatio   float64 `json:"aspect_ratio"`
+	Width         int     `json:"width"`
+	Height        int     `json:"height"`
+}
+
+// GetCoverPlaceholderResponse returns nil for posts whose cover was never processed.
+func GetCoverPlaceholderResponse(p database.Post) *CoverPlaceholderResponse {
+	if p.CoverBlurHash == "" {
+		return nil
+	}
+
+	return &CoverPlaceholderResponse{
+		BlurHash:      p.CoverBlurHash,
+		DominantColor: p.CoverDominantColor,
+		AspectRatio:   images.AspectRatio(p.CoverWidth, p.CoverHeight),
+		Width:         p.CoverWidth,
+		Height:        p.CoverHeight,
+	}
+}
@@ -0,0 +1,61 @@
+package payload_test
+
+import (
+	"testing"
+
+	"github.com/oullin/database"
+	"github.com/oullin/handler/payload"
+)
+
+func TestGetCoverImagesResponse(t *testing.T) {
+	images := []database.PostCoverImage{
+		{
+			Format:   "webp",
+			Width:    480,
+			Height:   252,
+			MimeType: "image/webp",
+			Path:     "posts/hello/hello-abc-480w.webp",
+		},
+	}
+
+	r := payload.GetCoverImagesResponse(images)
+
+	if len(r) != 1 {
+		t.Fatalf("unexpected %#v", r)
+	}
+
+	if r[0].URL != "/media/posts/hello/hello-abc-480w.webp" || r[0].Srcset != "/media/posts/hello/hello-abc-480w.webp 480w" {
+		t.Fatalf("unexpected urls %#v", r[0])
+	}
+
+	if r[0].Width != 480 || r[0].Height != 252 || r[0].MimeType != "image/webp" || r[0].Format != "webp" {
+		t.Fatalf("unexpected dimensions %#v", r[0])
+	}
+
+	if payload.GetCoverImagesResponse(nil) != nil {
+		t.Fatalf("expected nil for posts without variants")
+	}
+}
+
+func TestGetCoverPlaceholderResponse(t *testing.T) {
+	post := database.Post{
+		CoverBlurHash:      "LKO2?U%2Tw=w]~RBVZRi};RPxuwH",
+		CoverDominantColor: "#1a2b3c",
+		CoverWidth:         1200,
+		CoverHeight:        630,
+	}
+
+	r := payload.GetCoverPlaceholderResponse(post)
+
+	if r == nil || r.BlurHash != post.CoverBlurHash || r.DominantColor != "#1a2b3c" {
+		t.Fatalf("unexpected %#v", r)
+	}
+
+	if r.AspectRatio != 1.9048 || r.Width != 1200 || r.Height != 630 {
+		t.Fatalf("unexpected dimensions %#v", r)
+	}
+
+	if payload.GetCoverPlaceholderResponse(database.Post{}) != nil {
+		t.Fatalf("expected nil for posts without a processed cover")
+	}
+}
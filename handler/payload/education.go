@@ -1,17 +1,58 @@
 package payload
 
+import "github.com/oullin/database"
+
 type EducationResponse struct {
-	Version string          `json:"version"`
-	Data    []EducationData `json:"data"`
+	Version string          `json:"version" validate:"required"`
+	Data    []EducationData `json:"data" validate:"dive"`
+
+	*ListPage
 }
 
 type EducationData struct {
-	UUID           string `json:"uuid"`
+	UUID           string `json:"uuid" validate:"required,uuid"`
 	Icon           string `json:"icon"`
-	School         string `json:"school"`
-	Degree         string `json:"degree"`
+	School         string `json:"school" validate:"required"`
+	Degree         string `json:"degree" validate:"required"`
 	Field          string `json:"field"`
 	Description    string `json:"description"`
 	GraduatedAt    string `json:"graduated_at"`
 	IssuingCountry string `json:"issuing_country"`
 }
+
+func GetEducationResponse(version string, educations []database.Education) EducationResponse {
+	data := make([]EducationData, 0, len(educations))
+
+	for _, education := range educations {
+		data = append(data, GetEducationData(education))
+	}
+
+	return EducationResponse{Version: version, Data: data}
+}
+
+func GetEducationData(education database.Education) EducationData {
+	return EducationData{
+		UUID:           education.UUID,
+		Icon:           education.Icon,
+		School:         education.School,
+		Degree:         education.Degree,
+		Field:          education.Field,
+		Description:    education.Description,
+		GraduatedAt:    education.GraduatedAt,
+		IssuingCountry: education.IssuingCountry,
+	}
+}
+
+// FillEducation copies the data onto the education, leaving its id and order alone.
+func FillEducation(education *database.Education, data EducationData) error {
+	education.UUID = data.UUID
+	education.Icon = data.Icon
+	education.School = data.School
+	education.Degree = data.Degree
+	education.Field = data.Field
+	education.Description = data.Description
+	education.GraduatedAt = data.GraduatedAt
+	education.IssuingCountry = data.IssuingCountry
+
+	return nil
+}
@@ -1,20 +1,67 @@
 package payload
 
+import "github.com/oullin/database"
+
 type ExperienceResponse struct {
-	Version string           `json:"version"`
-	Data    []ExperienceData `json:"data"`
+	Version string           `json:"version" validate:"required"`
+	Data    []ExperienceData `json:"data" validate:"dive"`
+
+	*ListPage
 }
 
 type ExperienceData struct {
-	UUID           string `json:"uuid"`
-	Company        string `json:"company"`
+	UUID           string `json:"uuid" validate:"required,uuid"`
+	Company        string `json:"company" validate:"required"`
 	EmploymentType string `json:"employment_type"`
 	LocationType   string `json:"location_type"`
-	Position       string `json:"position"`
-	StartDate      string `json:"start_date"`
+	Position       string `json:"position" validate:"required"`
+	StartDate      string `json:"start_date" validate:"required"`
 	EndDate        string `json:"end_date"`
 	Summary        string `json:"summary"`
 	Country        string `json:"country"`
 	City           string `json:"city"`
 	Skills         string `json:"skills"`
 }
+
+func GetExperienceResponse(version string, experiences []database.Experience) ExperienceResponse {
+	data := make([]ExperienceData, 0, len(experiences))
+
+	for _, experience := range experiences {
+		data = append(data, GetExperienceData(experience))
+	}
+
+	return ExperienceResponse{Version: version, Data: data}
+}
+
+func GetExperienceData(experience database.Experience) ExperienceData {
+	return ExperienceData{
+		UUID:           experience.UUID,
+		Company:        experience.Company,
+		EmploymentType: experience.EmploymentType,
+		LocationType:   experience.LocationType,
+		Position:       experience.Position,
+		StartDate:      experience.StartDate,
+		EndDate:        experience.EndDate,
+		Summary:        experience.Summary,
+		Country:        experience.Country,
+		City:           experience.City,
+		Skills:         experience.Skills,
+	}
+}
+
+// FillExperience copies the data onto the experience, leaving its id and order alone.
+func FillExperience(experience *database.Experience, data ExperienceData) error {
+	experience.UUID = data.UUID
+	experience.Company = data.Company
+	experience.EmploymentType = data.EmploymentType
+	experience.LocationType = data.LocationType
+	experience.Position = data.Position
+	experience.StartDate = data.StartDate
+	experience.EndDate = data.EndDate
+	experience.Summary = data.Summary
+	experience.Country = data.Country
+	experience.City = data.City
+	experience.Skills = data.Skills
+
+	return nil
+}
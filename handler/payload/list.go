@@ -0,0 +1,25 @@
+package payload
+
+import "github.com/oullin/database/repository/pagination"
+
+// ListPage is the pagination of a fixture list. It is left out of the response unless the
+// list was paginated with ?page= or ?limit=.
+type ListPage struct {
+	Page         int   `json:"page"`
+	Total        int64 `json:"total"`
+	PageSize     int   `json:"page_size"`
+	TotalPages   int   `json:"total_pages"`
+	NextPage     *int  `json:"next_page,omitempty"`
+	PreviousPage *int  `json:"previous_page,omitempty"`
+}
+
+func NewListPage[T any](result *pagination.Pagination[T]) *ListPage {
+	return &ListPage{
+		Page:         result.Page,
+		Total:        result.Total,
+		PageSize:     result.PageSize,
+		TotalPages:   result.TotalPages,
+		NextPage:     result.NextPage,
+		PreviousPage: result.PreviousPage,
+	}
+}
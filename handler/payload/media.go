@@ -0,0 +1,38 @@
+package payload
+
+import (
+	"time"
+
+	"github.com/oullin/database"
+	"github.com/oullin/pkg/media"
+)
+
+type MediaResponse struct {
+	UUID      string    `json:"uuid"`
+	URL       string    `json:"url"`
+	Path      string    `json:"path"`
+	MimeType  string    `json:"mime_type"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	Size      int64     `json:"size"`
+	Sha256    string    `json:"sha256"`
+	AltText   string    `json:"alt_text"`
+	Owner     string    `json:"owner"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func GetMediaResponse(m database.Media) MediaResponse {
+	return MediaResponse{
+		UUID:      m.UUID,
+		URL:       media.GetPublicURL(m.Path),
+		Path:      m.Path,
+		MimeType:  m.MimeType,
+		Width:     m.Width,
+		Height:    m.Height,
+		Size:      m.Size,
+		Sha256:    m.Sha256,
+		AltText:   m.AltText,
+		Owner:     m.Owner,
+		CreatedAt: m.CreatedAt,
+	}
+}
@@ -5,17 +5,42 @@ import (
 	"github.com/oullin/database/repository/queries"
 	"github.com/oullin/pkg/portal"
 
+	"encoding/json"
 	"net/http"
 	"strings"
 	"time"
 )
 
+// PostsDateLayout is the format of the published_after/published_before filters.
+const PostsDateLayout = time.DateOnly
+
 type IndexRequestBody struct {
-	Title    string `json:"title"`
-	Author   string `json:"author"`
-	Category string `json:"category"`
-	Tag      string `json:"tag"`
-	Text     string `json:"text"`
+	Title           string   `json:"title"`
+	Author          string   `json:"author"`
+	Category        string   `json:"category"`
+	Tag             string   `json:"tag"`
+	Text            string   `json:"text"`
+	Categories      []string `json:"categories" validate:"omitempty,max=20,dive,required,max=255"`
+	CategoriesMode  string   `json:"categories_mode" validate:"omitempty,oneof=any all"`
+	Tags            []string `json:"tags" validate:"omitempty,max=20,dive,required,max=255"`
+	TagsMode        string   `json:"tags_mode" validate:"omitempty,oneof=any all"`
+	ExcludeTags     []string `json:"exclude_tags" validate:"omitempty,max=20,dive,required,max=255"`
+	Sort            string   `json:"sort" validate:"omitempty,oneof=newest oldest title most_viewed most_liked"`
+	PublishedAfter  string   `json:"published_after" validate:"omitempty,datetime=2006-01-02"`
+	PublishedBefore string   `json:"published_before" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// SavePostRequest is the body accepted by the admin create/update post endpoints.
+// A nil PublishedAt keeps the post as a draft.
+type SavePostRequest struct {
+	Slug          string     `json:"slug" validate:"required,lowercase,min=3,max=255"`
+	Title         string     `json:"title" validate:"required,min=3,max=255"`
+	Excerpt       string     `json:"excerpt" validate:"required"`
+	Content       string     `json:"content" validate:"required"`
+	CoverImageURL string     `json:"cover_image_url" validate:"omitempty,url,max=2048"`
+	PublishedAt   *time.Time `json:"published_at"`
+	Categories    []string   `json:"categories" validate:"required,min=1,max=20,dive,required,max=255"`
+	Tags          []string   `json:"tags" validate:"omitempty,max=20,dive,required,max=255"`
 }
 
 type PostResponse struct {
@@ -30,21 +55,135 @@ type PostResponse struct {
 	CreatedAt     time.Time    `json:"created_at"`
 	UpdatedAt     time.Time    `json:"updated_at"`
 
+	CoverPlaceholder *CoverPlaceholderResponse `json:"cover_placeholder"`
+
 	// Associations
-	Categories []CategoryResponse `json:"categories"`
-	Tags       []TagResponse      `json:"tags"`
+	Categories  []CategoryResponse   `json:"categories"`
+	Tags        []TagResponse        `json:"tags"`
+	CoverImages []CoverImageResponse `json:"cover_images"`
+
+	keys []string // When set, only these JSON keys are encoded (sparse fieldsets).
+}
+
+// MarshalJSON drops the keys left out by a sparse fieldset; full responses encode as usual.
+func (p PostResponse) MarshalJSON() ([]byte, error) {
+	type plain PostResponse
+
+	if p.keys == nil {
+		return json.Marshal(plain(p))
+	}
+
+	fields := make(map[string]any, len(p.keys))
+
+	for _, key := range p.keys {
+		if value, ok := p.field(key); ok {
+			fields[key] = value
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// field returns the value encoded under the given JSON key.
+func (p PostResponse) field(key string) (any, bool) {
+	switch key {
+	case "uuid":
+		return p.UUID, true
+	case "author":
+		return p.Author, true
+	case "slug":
+		return p.Slug, true
+	case "title":
+		return p.Title, true
+	case "excerpt":
+		return p.Excerpt, true
+	case "content":
+		return p.Content, true
+	case "cover_image_url":
+		return p.CoverImageURL, true
+	case "published_at":
+		return p.PublishedAt, true
+	case "created_at":
+		return p.CreatedAt, true
+	case "updated_at":
+		return p.UpdatedAt, true
+	case "cover_placeholder":
+		return p.CoverPlaceholder, true
+	case "categories":
+		return p.Categories, true
+	case "tags":
+		return p.Tags, true
+	case "cover_images":
+		return p.CoverImages, true
+	}
+
+	return nil, false
 }
 
 func GetPostsFiltersFrom(request IndexRequestBody) queries.PostFilters {
 	return queries.PostFilters{
-		Title:    request.Title,
-		Author:   request.Author,
-		Category: request.Category,
-		Tag:      request.Tag,
-		Text:     request.Text,
+		Title:           request.Title,
+		Author:          request.Author,
+		Category:        request.Category,
+		Tag:             request.Tag,
+		Text:            request.Text,
+		Categories:      request.Categories,
+		CategoriesMode:  request.CategoriesMode,
+		Tags:            request.Tags,
+		TagsMode:        request.TagsMode,
+		ExcludeTags:     request.ExcludeTags,
+		Sort:            request.Sort,
+		PublishedAfter:  parsePostsDate(request.PublishedAfter, 0),
+		PublishedBefore: parsePostsDate(request.PublishedBefore, 1), // the whole "before" day is included.
 	}
 }
 
+// GetPostsDateRangeErrors reports a published_after bound that is not earlier than published_before.
+func GetPostsDateRangeErrors(filters queries.PostFilters) map[string]any {
+	if filters.PublishedAfter == nil || filters.PublishedBefore == nil {
+		return nil
+	}
+
+	if filters.PublishedAfter.Before(*filters.PublishedBefore) {
+		return nil
+	}
+
+	return map[string]any{
+		"published_after": "field 'published_after' must be on or before 'published_before'",
+	}
+}
+
+func parsePostsDate(seed string, addDays int) *time.Time {
+	date, err := time.Parse(PostsDateLayout, strings.TrimSpace(seed))
+
+	if err != nil {
+		return nil
+	}
+
+	date = date.AddDate(0, 0, addDays)
+
+	return &date
+}
+
+// GetPostsFieldsetFrom reads the "fields" and "include" query parameters.
+// A present but empty "include" disables every relation.
+func GetPostsFieldsetFrom(r *http.Request) (queries.PostFieldset, error) {
+	values := r.URL.Query()
+
+	var include []string
+	if values.Has("include") {
+		include = append([]string{}, values["include"]...)
+	}
+
+	fieldset := queries.NewPostFieldset(values["fields"], include)
+
+	if err := fieldset.Validate(); err != nil {
+		return queries.PostFieldset{}, err
+	}
+
+	return fieldset, nil
+}
+
 func GetSlugFrom(r *http.Request) string {
 	str := portal.NewStringable(r.PathValue("slug"))
 
@@ -64,6 +203,7 @@ func GetPostsResponse(p database.Post) PostResponse {
 		UpdatedAt:     p.UpdatedAt,
 		Categories:    GetCategoriesResponse(p.Categories),
 		Tags:          GetTagsResponse(p.Tags),
+		CoverImages:   GetCoverImagesResponse(p.CoverImages),
 		Author: UserResponse{
 			UUID:              p.Author.UUID,
 			FirstName:         p.Author.FirstName,
@@ -75,5 +215,19 @@ func GetPostsResponse(p database.Post) PostResponse {
 			ProfilePictureURL: p.Author.ProfilePictureURL,
 			IsAdmin:           p.Author.IsAdmin,
 		},
+		CoverPlaceholder: GetCoverPlaceholderResponse(p),
+	}
+}
+
+// GetPostsResponseFor maps posts while keeping only the keys the fieldset asks for.
+func GetPostsResponseFor(fieldset queries.PostFieldset) func(database.Post) PostResponse {
+	return func(p database.Post) PostResponse {
+		response := GetPostsResponse(p)
+
+		if fieldset.IsSparse() {
+			response.keys = fieldset.Keys()
+		}
+
+		return response
 	}
 }
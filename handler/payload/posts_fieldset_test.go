@@ -0,0 +1,128 @@
+package payload_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/oullin/database"
+	"github.com/oullin/handler/payload"
+)
+
+func TestGetPostsFieldsetFrom(t *testing.T) {
+	req := httptest.NewRequest("POST", "/posts?fields=slug,title&include=", nil)
+
+	f, err := payload.GetPostsFieldsetFrom(req)
+	if err != nil {
+		t.Fatalf("fieldset: %v", err)
+	}
+
+	if len(f.Fields) != 2 || f.Include == nil || len(f.Include) != 0 {
+		t.Fatalf("unexpected fieldset: %+v", f)
+	}
+
+	bad := httptest.NewRequest("POST", "/posts?fields=secret", nil)
+
+	if _, err := payload.GetPostsFieldsetFrom(bad); err == nil {
+		t.Fatalf("expected unknown field error")
+	}
+}
+
+func TestGetPostsResponseForSparseFieldset(t *testing.T) {
+	req := httptest.NewRequest("POST", "/posts?fields=slug,title,tags", nil)
+
+	f, err := payload.GetPostsFieldsetFrom(req)
+	if err != nil {
+		t.Fatalf("fieldset: %v", err)
+	}
+
+	post := database.Post{
+		Slug:    "slug",
+		Title:   "title",
+		Content: "a very long body",
+		Tags:    []database.Tag{{Slug: "go"}},
+	}
+
+	body, err := json.Marshal(payload.GetPostsResponseFor(f)(post))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if len(got) != 3 || got["slug"] != "slug" || got["title"] != "title" || got["tags"] == nil {
+		t.Fatalf("unexpected sparse response: %s", body)
+	}
+}
+
+func TestGetPostsResponseForFullFieldset(t *testing.T) {
+	req := httptest.NewRequest("POST", "/posts", nil)
+
+	f, err := payload.GetPostsFieldsetFrom(req)
+	if err != nil {
+		t.Fatalf("fieldset: %v", err)
+	}
+
+	body, err := json.Marshal(payload.GetPostsResponseFor(f)(database.Post{Slug: "slug"}))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	for _, key := range []string{"content", "author", "categories", "tags"} {
+		if _, ok := got[key]; !ok {
+			t.Fatalf("expected key %s in %s", key, body)
+		}
+	}
+}
+
+func TestGetPostsResponseForEveryFieldMatchesTheFullResponse(t *testing.T) {
+	fields := "uuid,author,slug,title,excerpt,content,cover_image_url,cover_placeholder,published_at,created_at,updated_at,categories,tags,cover_images"
+
+	sparse, err := payload.GetPostsFieldsetFrom(httptest.NewRequest("POST", "/posts?fields="+fields, nil))
+	if err != nil {
+		t.Fatalf("fieldset: %v", err)
+	}
+
+	full, err := payload.GetPostsFieldsetFrom(httptest.NewRequest("POST", "/posts", nil))
+	if err != nil {
+		t.Fatalf("fieldset: %v", err)
+	}
+
+	post := database.Post{
+		UUID:          "1",
+		Slug:          "slug",
+		Title:         "title",
+		Content:       "body",
+		CoverImageURL: "https://example.com/cover.png",
+		CoverBlurHash: "LKO2?U%2Tw=w]~RBVZRi};RPxuwH",
+		Author:        database.User{UUID: "u1", Username: "gus"},
+		Tags:          []database.Tag{{Slug: "go"}},
+	}
+
+	want, err := json.Marshal(payload.GetPostsResponseFor(full)(post))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	got, err := json.Marshal(payload.GetPostsResponseFor(sparse)(post))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var wantKeys, gotKeys map[string]any
+	_ = json.Unmarshal(want, &wantKeys)
+	_ = json.Unmarshal(got, &gotKeys)
+
+	if !reflect.DeepEqual(wantKeys, gotKeys) {
+		t.Fatalf("expected every listed field to encode as in the full response\nwant %s\ngot  %s", want, got)
+	}
+}
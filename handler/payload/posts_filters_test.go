@@ -2,6 +2,7 @@ package payload_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/oullin/handler/payload"
 )
@@ -21,3 +22,64 @@ func TestGetPostsFiltersFrom(t *testing.T) {
 		t.Fatalf("unexpected filters: %+v", f)
 	}
 }
+
+func TestGetPostsFiltersFromDates(t *testing.T) {
+	req := payload.IndexRequestBody{
+		Sort:            "oldest",
+		PublishedAfter:  "2024-01-01",
+		PublishedBefore: "2024-01-31",
+	}
+
+	f := payload.GetPostsFiltersFrom(req)
+
+	if f.Sort != "oldest" {
+		t.Fatalf("unexpected sort: %s", f.Sort)
+	}
+
+	if f.PublishedAfter == nil || !f.PublishedAfter.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected published after: %v", f.PublishedAfter)
+	}
+
+	if f.PublishedBefore == nil || !f.PublishedBefore.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected published before to include the whole day, got %v", f.PublishedBefore)
+	}
+
+	if errs := payload.GetPostsDateRangeErrors(f); errs != nil {
+		t.Fatalf("unexpected range errors: %v", errs)
+	}
+}
+
+func TestGetPostsDateRangeErrors(t *testing.T) {
+	f := payload.GetPostsFiltersFrom(payload.IndexRequestBody{
+		PublishedAfter:  "2024-02-01",
+		PublishedBefore: "2024-01-01",
+	})
+
+	if errs := payload.GetPostsDateRangeErrors(f); errs == nil {
+		t.Fatalf("expected inverted range to be reported")
+	}
+
+	if errs := payload.GetPostsDateRangeErrors(payload.GetPostsFiltersFrom(payload.IndexRequestBody{})); errs != nil {
+		t.Fatalf("expected open range to pass, got %v", errs)
+	}
+}
+
+func TestGetPostsFiltersFromSlugLists(t *testing.T) {
+	req := payload.IndexRequestBody{
+		Categories:     []string{"engineering"},
+		CategoriesMode: "any",
+		Tags:           []string{"go", "postgres"},
+		TagsMode:       "all",
+		ExcludeTags:    []string{"draft"},
+	}
+
+	f := payload.GetPostsFiltersFrom(req)
+
+	if len(f.Categories) != 1 || f.CategoriesMode != "any" {
+		t.Fatalf("unexpected categories: %+v", f)
+	}
+
+	if len(f.Tags) != 2 || f.TagsMode != "all" || len(f.ExcludeTags) != 1 {
+		t.Fatalf("unexpected tags: %+v", f)
+	}
+}
@@ -1,22 +1,70 @@
 package payload
 
+import "github.com/oullin/database"
+
 type ProfileResponse struct {
-	Version string              `json:"version"`
-	Data    ProfileDataResponse `json:"data"`
+	Version string              `json:"version" validate:"required"`
+	Data    ProfileDataResponse `json:"data" validate:"required"`
 }
 
 type ProfileDataResponse struct {
-	Nickname   string                  `json:"nickname"`
-	Handle     string                  `json:"handle"`
-	Name       string                  `json:"name"`
-	Email      string                  `json:"email"`
+	Nickname   string                  `json:"nickname" validate:"required"`
+	Handle     string                  `json:"handle" validate:"required"`
+	Name       string                  `json:"name" validate:"required"`
+	Email      string                  `json:"email" validate:"required,email"`
 	Profession string                  `json:"profession"`
-	Skills     []ProfileSkillsResponse `json:"skills"`
+	Skills     []ProfileSkillsResponse `json:"skills" validate:"dive"`
 }
 
 type ProfileSkillsResponse struct {
-	Uuid        string `json:"uuid"`
-	Percentage  int    `json:"percentage"`
-	Item        string `json:"item"`
+	Uuid        string `json:"uuid" validate:"required,uuid"`
+	Percentage  int    `json:"percentage" validate:"min=0,max=100"`
+	Item        string `json:"item" validate:"required"`
 	Description string `json:"description"`
 }
+
+func GetProfileResponse(version string, profile database.Profile) ProfileResponse {
+	skills := make([]ProfileSkillsResponse, 0, len(profile.Skills))
+
+	for _, skill := range profile.Skills {
+		skills = append(skills, ProfileSkillsResponse{
+			Uuid:        skill.UUID,
+			Percentage:  skill.Percentage,
+			Item:        skill.Item,
+			Description: skill.Description,
+		})
+	}
+
+	return ProfileResponse{
+		Version: version,
+		Data: ProfileDataResponse{
+			Nickname:   profile.Nickname,
+			Handle:     profile.Handle,
+			Name:       profile.Name,
+			Email:      profile.Email,
+			Profession: profile.Profession,
+			Skills:     skills,
+		},
+	}
+}
+
+// FillProfile copies the data onto the profile and replaces its skills, kept in the
+// given order.
+func FillProfile(profile *database.Profile, data ProfileDataResponse) {
+	profile.Nickname = data.Nickname
+	profile.Handle = data.Handle
+	profile.Name = data.Name
+	profile.Email = data.Email
+	profile.Profession = data.Profession
+	profile.Skills = make([]database.ProfileSkill, 0, len(data.Skills))
+
+	for i, skill := range data.Skills {
+		profile.Skills = append(profile.Skills, database.ProfileSkill{
+			UUID:        skill.Uuid,
+			Item:        skill.Item,
+			Percentage:  skill.Percentage,
+			Description: skill.Description,
+			SortOrder:   i + 1,
+		})
+	}
+}
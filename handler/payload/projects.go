@@ -1,8 +1,10 @@
 package payload
 
+import "github.com/oullin/database"
+
 type ProjectsResponse struct {
-	Version      string         `json:"version"`
-	Data         []ProjectsData `json:"data"`
+	Version      string         `json:"version" validate:"required"`
+	Data         []ProjectsData `json:"data" validate:"dive"`
 	Page         int            `json:"page"`
 	Total        int64          `json:"total"`
 	PageSize     int            `json:"page_size"`
@@ -12,13 +14,92 @@ type ProjectsResponse struct {
 }
 
 type ProjectsData struct {
-	UUID         string `json:"uuid"`
-	Sort         *int   `json:"sort"`
-	Language     string `json:"language"`
-	Title        string `json:"title"`
-	Excerpt      string `json:"excerpt"`
-	URL          string `json:"url"`
-	Icon         string `json:"icon"`
-	IsOpenSource bool   `json:"is_open_source"`
-	PublishedAt  string `json:"published_at"`
+	UUID         string        `json:"uuid" validate:"required,uuid"`
+	Sort         *int          `json:"sort" validate:"required,gt=0"`
+	Language     string        `json:"language"`
+	Title        string        `json:"title" validate:"required"`
+	Excerpt      string        `json:"excerpt"`
+	URL          string        `json:"url" validate:"required,url"`
+	Icon         string        `json:"icon"`
+	IsOpenSource bool          `json:"is_open_source"`
+	PublishedAt  string        `json:"published_at" validate:"omitempty,datetime=2006-01-02"`
+	Stats        *ProjectStats `json:"stats,omitempty"`
+}
+
+// ProjectStats are the live repository figures of an open-source project, refreshed from
+// GitHub in the background. Projects not seen on GitHub yet have none.
+type ProjectStats struct {
+	Stars     int               `json:"stars"`
+	Forks     int               `json:"forks"`
+	Topics    []string          `json:"topics"`
+	Languages []ProjectLanguage `json:"languages"`
+	License   string            `json:"license,omitempty"`
+	PushedAt  string            `json:"pushed_at,omitempty"`
+}
+
+// ProjectLanguage is one language's share of a repository. Bytes are left out by hosts
+// that only report the share.
+type ProjectLanguage struct {
+	Name    string  `json:"name"`
+	Bytes   int64   `json:"bytes,omitempty"`
+	Percent float64 `json:"percent"`
+}
+
+func GetProjectsResponse(version string, projects []database.Project) ProjectsResponse {
+	data := make([]ProjectsData, 0, len(projects))
+
+	for _, project := range projects {
+		data = append(data, GetProjectsData(project))
+	}
+
+	return ProjectsResponse{Version: version, Data: data}
+}
+
+func GetProjectsData(project database.Project) ProjectsData {
+	sort := project.Sort
+	publishedAt := ""
+
+	if project.PublishedAt != nil {
+		publishedAt = FormatContentDate(*project.PublishedAt)
+	}
+
+	return ProjectsData{
+		UUID:         project.UUID,
+		Sort:         &sort,
+		Language:     project.Language,
+		Title:        project.Title,
+		Excerpt:      project.Excerpt,
+		URL:          project.URL,
+		Icon:         project.Icon,
+		IsOpenSource: project.IsOpenSource,
+		PublishedAt:  publishedAt,
+	}
+}
+
+// FillProject copies the data onto the project, leaving its id and order alone. An empty
+// published_at is stored as unknown, to be resolved from the code host.
+func FillProject(project *database.Project, data ProjectsData) error {
+	project.UUID = data.UUID
+	project.Language = data.Language
+	project.Title = data.Title
+	project.Excerpt = data.Excerpt
+	project.URL = data.URL
+	project.Icon = data.Icon
+	project.IsOpenSource = data.IsOpenSource
+	project.PublishedAt = nil
+
+	if data.Sort != nil {
+		project.Sort = *data.Sort
+	}
+
+	if data.PublishedAt != "" {
+		publishedAt, err := ParseContentDate(data.PublishedAt)
+		if err != nil {
+			return err
+		}
+
+		project.PublishedAt = &publishedAt
+	}
+
+	return nil
 }
@@ -1,23 +1,74 @@
 package payload
 
+import "github.com/oullin/database"
+
 type RecommendationsResponse struct {
-	Version string                `json:"version"`
-	Data    []RecommendationsData `json:"data"`
+	Version string                `json:"version" validate:"required"`
+	Data    []RecommendationsData `json:"data" validate:"dive"`
+
+	*ListPage
 }
 
 type RecommendationsData struct {
-	UUID      string                    `json:"uuid"`
+	UUID      string                    `json:"uuid" validate:"required,uuid"`
 	Relation  string                    `json:"relation"`
-	Text      string                    `json:"text"`
-	Featured  int                       `json:"featured"`
-	CreatedAt string                    `json:"created_at"`
-	UpdatedAt string                    `json:"updated_at"`
-	Person    RecommendationsPersonData `json:"person"`
+	Text      string                    `json:"text" validate:"required"`
+	Featured  int                       `json:"featured" validate:"oneof=0 1"`
+	CreatedAt string                    `json:"created_at" validate:"omitempty,datetime=2006-01-02"`
+	UpdatedAt string                    `json:"updated_at" validate:"omitempty,datetime=2006-01-02"`
+	Person    RecommendationsPersonData `json:"person" validate:"required"`
 }
 
 type RecommendationsPersonData struct {
 	Avatar      string `json:"avatar"`
-	FullName    string `json:"full_name"`
+	FullName    string `json:"full_name" validate:"required"`
 	Company     string `json:"company"`
 	Designation string `json:"designation"`
 }
+
+func GetRecommendationsResponse(version string, recommendations []database.Recommendation) RecommendationsResponse {
+	data := make([]RecommendationsData, 0, len(recommendations))
+
+	for _, recommendation := range recommendations {
+		data = append(data, GetRecommendationsData(recommendation))
+	}
+
+	return RecommendationsResponse{Version: version, Data: data}
+}
+
+func GetRecommendationsData(recommendation database.Recommendation) RecommendationsData {
+	featured := 0
+	if recommendation.Featured {
+		featured = 1
+	}
+
+	return RecommendationsData{
+		UUID:      recommendation.UUID,
+		Relation:  recommendation.Relation,
+		Text:      recommendation.Text,
+		Featured:  featured,
+		CreatedAt: FormatContentDate(recommendation.CreatedAt),
+		UpdatedAt: FormatContentDate(recommendation.UpdatedAt),
+		Person: RecommendationsPersonData{
+			Avatar:      recommendation.PersonAvatar,
+			FullName:    recommendation.PersonFullName,
+			Company:     recommendation.PersonCompany,
+			Designation: recommendation.PersonDesignation,
+		},
+	}
+}
+
+// FillRecommendation copies the data onto the recommendation, leaving its id and order
+// alone. Dates are only taken when given; the database fills them otherwise.
+func FillRecommendation(recommendation *database.Recommendation, data RecommendationsData) error {
+	recommendation.UUID = data.UUID
+	recommendation.Relation = data.Relation
+	recommendation.Text = data.Text
+	recommendation.Featured = data.Featured == 1
+	recommendation.PersonAvatar = data.Person.Avatar
+	recommendation.PersonFullName = data.Person.FullName
+	recommendation.PersonCompany = data.Person.Company
+	recommendation.PersonDesignation = data.Person.Designation
+
+	return fillContentDates(&recommendation.CreatedAt, &recommendation.UpdatedAt, data.CreatedAt, data.UpdatedAt)
+}
@@ -1,18 +1,53 @@
 package payload
 
+import "github.com/oullin/database"
+
 type LinksResponse struct {
-	Version string      `json:"version"`
-	Data    []LinksData `json:"data"`
+	Version string      `json:"version" validate:"required"`
+	Data    []LinksData `json:"data" validate:"dive"`
+
+	*ListPage
 }
 
 type LinksData struct {
-	UUID        string `json:"uuid"`
+	UUID        string `json:"uuid" validate:"required,uuid"`
 	Handle      string `json:"handle"`
-	URL         string `json:"url"`
+	URL         string `json:"url" validate:"required,url"`
 	Description string `json:"description"`
-	Name        string `json:"name"`
+	Name        string `json:"name" validate:"required"`
 }
 
 type SocialResponse = LinksResponse
 
 type SocialData = LinksData
+
+func GetLinksResponse(version string, links []database.Link) LinksResponse {
+	data := make([]LinksData, 0, len(links))
+
+	for _, link := range links {
+		data = append(data, GetLinksData(link))
+	}
+
+	return LinksResponse{Version: version, Data: data}
+}
+
+func GetLinksData(link database.Link) LinksData {
+	return LinksData{
+		UUID:        link.UUID,
+		Handle:      link.Handle,
+		URL:         link.URL,
+		Description: link.Description,
+		Name:        link.Name,
+	}
+}
+
+// FillLink copies the data onto the link, leaving its id and order alone.
+func FillLink(link *database.Link, data LinksData) error {
+	link.UUID = data.UUID
+	link.Handle = data.Handle
+	link.URL = data.URL
+	link.Description = data.Description
+	link.Name = data.Name
+
+	return nil
+}
@@ -1,17 +1,100 @@
 package payload
 
+import (
+	"time"
+
+	"github.com/oullin/database"
+)
+
 type TalksResponse struct {
-	Version string      `json:"version"`
-	Data    []TalksData `json:"data"`
+	Version string      `json:"version" validate:"required"`
+	Data    []TalksData `json:"data" validate:"dive"`
+
+	*ListPage
 }
 
 type TalksData struct {
-	UUID      string `json:"uuid"`
-	Title     string `json:"title"`
+	UUID      string `json:"uuid" validate:"required,uuid"`
+	Title     string `json:"title" validate:"required"`
 	Subject   string `json:"subject"`
 	Location  string `json:"location"`
-	URL       string `json:"url"`
+	URL       string `json:"url" validate:"omitempty,url"`
 	Photo     string `json:"photo"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	CreatedAt string `json:"created_at" validate:"omitempty,datetime=2006-01-02"`
+	UpdatedAt string `json:"updated_at" validate:"omitempty,datetime=2006-01-02"`
+}
+
+func GetTalksResponse(version string, talks []database.Talk) TalksResponse {
+	data := make([]TalksData, 0, len(talks))
+
+	for _, talk := range talks {
+		data = append(data, GetTalksData(talk))
+	}
+
+	return TalksResponse{Version: version, Data: data}
+}
+
+func GetTalksData(talk database.Talk) TalksData {
+	return TalksData{
+		UUID:      talk.UUID,
+		Title:     talk.Title,
+		Subject:   talk.Subject,
+		Location:  talk.Location,
+		URL:       talk.URL,
+		Photo:     talk.Photo,
+		CreatedAt: FormatContentDate(talk.CreatedAt),
+		UpdatedAt: FormatContentDate(talk.UpdatedAt),
+	}
+}
+
+// FillTalk copies the data onto the talk, leaving its id and order alone. Dates are only
+// taken when given; the database fills them otherwise.
+func FillTalk(talk *database.Talk, data TalksData) error {
+	talk.UUID = data.UUID
+	talk.Title = data.Title
+	talk.Subject = data.Subject
+	talk.Location = data.Location
+	talk.URL = data.URL
+	talk.Photo = data.Photo
+
+	return fillContentDates(&talk.CreatedAt, &talk.UpdatedAt, data.CreatedAt, data.UpdatedAt)
+}
+
+// FormatContentDate renders the date-only created_at, updated_at and published_at values
+// of the site content, e.g. 2019-02-11.
+func FormatContentDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+
+	return date.Format(time.DateOnly)
+}
+
+// ParseContentDate reads a FormatContentDate value; an empty one is the zero time.
+func ParseContentDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.DateOnly, value)
+}
+
+func fillContentDates(createdAt, updatedAt *time.Time, created, updated string) error {
+	for _, date := range []struct {
+		into  *time.Time
+		value string
+	}{{createdAt, created}, {updatedAt, updated}} {
+		if date.value == "" {
+			continue
+		}
+
+		parsed, err := ParseContentDate(date.value)
+		if err != nil {
+			return err
+		}
+
+		*date.into = parsed
+	}
+
+	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/oullin/database"
 	"github.com/oullin/handler/payload"
 )
 
@@ -19,3 +20,26 @@ func TestTalksResponseJSON(t *testing.T) {
 		t.Fatalf("unexpected response: %+v", res)
 	}
 }
+
+func TestTalksRoundTripThroughTheDatabaseModel(t *testing.T) {
+	data := payload.TalksData{
+		UUID:      "b222d84c-5bbe-4c21-8ba8-a9baa7e5eaa9",
+		Title:     "Deprecating APIs",
+		URL:       "https://engineers.sg/v/3204",
+		CreatedAt: "2019-02-11",
+		UpdatedAt: "2019-02-12",
+	}
+
+	var talk database.Talk
+	if err := payload.FillTalk(&talk, data); err != nil {
+		t.Fatalf("fill: %v", err)
+	}
+
+	if got := payload.GetTalksResponse("1.0.0", []database.Talk{talk}); got.Version != "1.0.0" || got.Data[0] != data {
+		t.Fatalf("expected the talk back, got %+v", got)
+	}
+
+	if err := payload.FillTalk(&talk, payload.TalksData{CreatedAt: "11/02/2019"}); err == nil {
+		t.Fatalf("expected an invalid date to be rejected")
+	}
+}
@@ -15,11 +15,15 @@ import (
 )
 
 type PostsHandler struct {
-	Posts *repository.Posts
+	Posts     *repository.Posts
+	Validator *portal.Validator
 }
 
-func NewPostsHandler(repo *repository.Posts) PostsHandler {
-	return PostsHandler{Posts: repo}
+func NewPostsHandler(repo *repository.Posts, validator *portal.Validator) PostsHandler {
+	return PostsHandler{
+		Posts:     repo,
+		Validator: validator,
+	}
 }
 
 func (h *PostsHandler) Index(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
@@ -33,8 +37,25 @@ func (h *PostsHandler) Index(w http.ResponseWriter, r *http.Request) *endpoint.A
 		return endpoint.InternalError("There was an issue reading the request. Please, try again later.")
 	}
 
+	if _, err = h.Validator.Rejects(requestBody); err != nil {
+		return endpoint.UnprocessableEntity("The given fields are invalid", h.Validator.GetErrors())
+	}
+
+	filters := payload.GetPostsFiltersFrom(requestBody)
+
+	if errs := payload.GetPostsDateRangeErrors(filters); errs != nil {
+		return endpoint.UnprocessableEntity("The given fields are invalid", errs)
+	}
+
+	fieldset, err := payload.GetPostsFieldsetFrom(r)
+
+	if err != nil {
+		return endpoint.LogBadRequestError("the given fields or includes are invalid.", err)
+	}
+
 	result, err := h.Posts.GetAll(
-		payload.GetPostsFiltersFrom(requestBody),
+		filters,
+		fieldset,
 		paginate.NewFrom(r.URL, 10),
 	)
 
@@ -46,7 +67,7 @@ func (h *PostsHandler) Index(w http.ResponseWriter, r *http.Request) *endpoint.A
 
 	items := pagination.HydratePagination(
 		result,
-		payload.GetPostsResponse,
+		payload.GetPostsResponseFor(fieldset),
 	)
 
 	if err := json.NewEncoder(w).Encode(items); err != nil {
@@ -16,11 +16,13 @@ import (
 	"github.com/oullin/handler"
 	"github.com/oullin/handler/payload"
 	"github.com/oullin/internal/testutil/dbtest"
+	"github.com/oullin/pkg/portal"
 )
 
 func TestPostsHandlerIndex_ParseError(t *testing.T) {
 	h := handler.PostsHandler{
-		Posts: &repository.Posts{},
+		Posts:     &repository.Posts{},
+		Validator: portal.GetDefaultValidator(),
 	}
 
 	badReq := httptest.NewRequest("POST", "/posts", bytes.NewReader([]byte("{")))
@@ -31,9 +33,75 @@ func TestPostsHandlerIndex_ParseError(t *testing.T) {
 	}
 }
 
+func TestPostsHandlerIndex_InvalidFieldset(t *testing.T) {
+	h := handler.PostsHandler{
+		Posts:     &repository.Posts{},
+		Validator: portal.GetDefaultValidator(),
+	}
+
+	req := httptest.NewRequest("POST", "/posts?fields=password", bytes.NewReader([]byte("{}")))
+	rec := httptest.NewRecorder()
+
+	err := h.Index(rec, req)
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("expected bad request, got %+v", err)
+	}
+}
+
+func TestPostsHandlerIndex_InvalidSort(t *testing.T) {
+	h := handler.PostsHandler{
+		Posts:     &repository.Posts{},
+		Validator: portal.GetDefaultValidator(),
+	}
+
+	req := httptest.NewRequest("POST", "/posts", bytes.NewReader([]byte(`{"sort":"random"}`)))
+	rec := httptest.NewRecorder()
+
+	err := h.Index(rec, req)
+	if err == nil || err.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("expected unprocessable entity, got %+v", err)
+	}
+}
+
+func TestPostsHandlerIndex_InvalidDateRange(t *testing.T) {
+	h := handler.PostsHandler{
+		Posts:     &repository.Posts{},
+		Validator: portal.GetDefaultValidator(),
+	}
+
+	body := `{"published_after":"2024-05-01","published_before":"2024-04-01"}`
+	req := httptest.NewRequest("POST", "/posts", bytes.NewReader([]byte(body)))
+	rec := httptest.NewRecorder()
+
+	err := h.Index(rec, req)
+	if err == nil || err.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("expected unprocessable entity, got %+v", err)
+	}
+
+	if _, ok := err.Data["published_after"]; !ok {
+		t.Fatalf("expected published_after error, got %+v", err.Data)
+	}
+}
+
+func TestPostsHandlerIndex_InvalidTagsMode(t *testing.T) {
+	h := handler.PostsHandler{
+		Posts:     &repository.Posts{},
+		Validator: portal.GetDefaultValidator(),
+	}
+
+	req := httptest.NewRequest("POST", "/posts", bytes.NewReader([]byte(`{"tags":["go"],"tags_mode":"some"}`)))
+	rec := httptest.NewRecorder()
+
+	err := h.Index(rec, req)
+	if err == nil || err.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("expected unprocessable entity, got %+v", err)
+	}
+}
+
 func TestPostsHandlerShow_MissingSlug(t *testing.T) {
 	h := handler.PostsHandler{
-		Posts: &repository.Posts{},
+		Posts:     &repository.Posts{},
+		Validator: portal.GetDefaultValidator(),
 	}
 
 	req := httptest.NewRequest("GET", "/posts/", nil)
@@ -63,7 +131,7 @@ func TestPostsHandlerIndex_Success(t *testing.T) {
 
 	h := handler.NewPostsHandler(&repository.Posts{
 		DB: conn,
-	})
+	}, portal.GetDefaultValidator())
 
 	req := httptest.NewRequest("POST", "/posts", bytes.NewReader([]byte("{}")))
 	rec := httptest.NewRecorder()
@@ -120,7 +188,7 @@ func TestPostsHandlerShow_Success(t *testing.T) {
 
 	h := handler.NewPostsHandler(&repository.Posts{
 		DB: conn,
-	})
+	}, portal.GetDefaultValidator())
 
 	req := httptest.NewRequest("GET", "/posts/hello", nil)
 	req.SetPathValue("slug", "hello")
@@ -1,16 +1,17 @@
 package handler
 
 import (
+	"github.com/oullin/handler/listing"
 	"github.com/oullin/handler/payload"
 	"github.com/oullin/pkg/endpoint"
-	"github.com/oullin/pkg/portal"
+	"github.com/oullin/pkg/fixtures"
 
 	"log/slog"
 	"net/http"
 )
 
 type ProfileHandler struct {
-	filePath     string
+	source       *fixtures.Source[payload.ProfileResponse]
 	cacheEnabled bool
 }
 
@@ -20,13 +21,27 @@ func NewProfileHandler(filePath string) ProfileHandler {
 
 func NewProfileHandlerWithCache(filePath string, cacheEnabled bool) ProfileHandler {
 	return ProfileHandler{
-		filePath:     filePath,
+		source:       fixtures.NewSource(filePath, validated[payload.ProfileResponse](nil)),
 		cacheEnabled: cacheEnabled,
 	}
 }
 
+// NewProfileHandlerWithLoader serves the profile load returns, e.g. out of the database, rather
+// than the fixture file it falls back to.
+func NewProfileHandlerWithLoader(file string, load func() (payload.ProfileResponse, error), cacheEnabled bool) ProfileHandler {
+	return ProfileHandler{
+		source:       fixtures.NewLoadedSource(file, load, validated[payload.ProfileResponse](nil)),
+		cacheEnabled: cacheEnabled,
+	}
+}
+
+// Fixture is the source the router keeps reloading.
+func (h ProfileHandler) Fixture() fixtures.Reloader {
+	return h.source
+}
+
 func (h ProfileHandler) Handle(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
-	data, err := portal.ParseJsonFile[payload.ProfileResponse](h.filePath)
+	snapshot, err := h.source.Current()
 
 	if err != nil {
 		slog.Error("Error reading profile file", "error", err)
@@ -34,12 +49,7 @@ func (h ProfileHandler) Handle(w http.ResponseWriter, r *http.Request) *endpoint
 		return endpoint.InternalError("could not read profile data")
 	}
 
-	resp, err := endpoint.NewResponseForPayload(data, 3600, h.cacheEnabled, w, r)
-	if err != nil {
-		slog.Error("Error preparing profile response cache", "error", err)
-
-		return endpoint.InternalError("could not prepare profile response")
-	}
+	resp := endpoint.NewResponseForBody(snapshot.Body, snapshot.Checksum, 3600, h.cacheEnabled, w, r)
 
 	if resp.HasCache() {
 		resp.RespondWithNotModified()
@@ -47,7 +57,7 @@ func (h ProfileHandler) Handle(w http.ResponseWriter, r *http.Request) *endpoint
 		return nil
 	}
 
-	if err := resp.RespondOk(data); err != nil {
+	if err := resp.RespondOk(snapshot.Data); err != nil {
 		slog.Error("Error marshaling JSON for profile response", "error", err)
 
 		return endpoint.InternalError("could not encode profile response")
@@ -55,3 +65,26 @@ func (h ProfileHandler) Handle(w http.ResponseWriter, r *http.Request) *endpoint
 
 	return nil // A nil return indicates success.
 }
+
+// Render is the profile GET /site embeds, as GET /profile returns it.
+func (h ProfileHandler) Render() (listing.Variant, *endpoint.ApiError) {
+	snapshot, apiErr := h.Snapshot()
+	if apiErr != nil {
+		return listing.Variant{}, apiErr
+	}
+
+	return listing.Variant{Body: snapshot.Body, Checksum: snapshot.Checksum}, nil
+}
+
+// Snapshot is the profile Render encodes, for handlers built on top of it.
+func (h ProfileHandler) Snapshot() (*fixtures.Snapshot[payload.ProfileResponse], *endpoint.ApiError) {
+	snapshot, err := h.source.Current()
+
+	if err != nil {
+		slog.Error("Error reading profile file", "error", err)
+
+		return nil, endpoint.InternalError("could not read profile data")
+	}
+
+	return snapshot, nil
+}
@@ -1,20 +1,29 @@
 package handler
 
 import (
+	"fmt"
+	"hash/fnv"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
 
-	"github.com/oullin/database/repository/pagination"
-	"github.com/oullin/handler/paginate"
+	"github.com/oullin/handler/listing"
 	"github.com/oullin/handler/payload"
 	"github.com/oullin/pkg/endpoint"
-	"github.com/oullin/pkg/portal"
+	"github.com/oullin/pkg/fixtures"
 	"github.com/oullin/pkg/projects"
 )
 
 type ProjectsHandler struct {
-	filePath     string
+	source       *fixtures.Source[payload.ProjectsResponse]
 	cacheEnabled bool
+	publishedAt  projects.PublishedAtLookup
+	stats        projects.StatsLookup
+	list         listing.Engine[payload.ProjectsData]
+	views        *projectsViews
 }
 
 func NewProjectsHandler(filePath string) ProjectsHandler {
@@ -23,48 +32,175 @@ func NewProjectsHandler(filePath string) ProjectsHandler {
 
 func NewProjectsHandlerWithCache(filePath string, cacheEnabled bool) ProjectsHandler {
 	return ProjectsHandler{
-		filePath:     filePath,
+		source:       fixtures.NewSource(filePath, validated(projects.ValidateResponse)),
 		cacheEnabled: cacheEnabled,
+		list:         newProjectsList(),
+		views:        newProjectsViews(),
 	}
 }
 
+// NewProjectsHandlerWithLoader serves the projects load returns, e.g. out of the database, rather
+// than the fixture file it falls back to.
+func NewProjectsHandlerWithLoader(file string, load func() (payload.ProjectsResponse, error), cacheEnabled bool) ProjectsHandler {
+	return ProjectsHandler{
+		source:       fixtures.NewLoadedSource(file, load, validated(projects.ValidateResponse)),
+		cacheEnabled: cacheEnabled,
+		list:         newProjectsList(),
+		views:        newProjectsViews(),
+	}
+}
+
+// Fixture is the source the router keeps reloading.
+func (h ProjectsHandler) Fixture() fixtures.Reloader {
+	return h.source
+}
+
+// WithPublishedAtLookup fills in the missing published_at of GitHub projects from the
+// lookup instead of rejecting them.
+func (h ProjectsHandler) WithPublishedAtLookup(lookup projects.PublishedAtLookup) ProjectsHandler {
+	h.publishedAt = lookup
+
+	return h
+}
+
+// WithStatsLookup attaches the live repository stats of open-source projects.
+func (h ProjectsHandler) WithStatsLookup(lookup projects.StatsLookup) ProjectsHandler {
+	h.stats = lookup
+
+	return h
+}
+
 func (h ProjectsHandler) Handle(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
-	data, err := portal.ParseJsonFile[payload.ProjectsResponse](h.filePath)
+	variant, apiErr := h.render(r.URL.Query())
+	if apiErr != nil {
+		return apiErr
+	}
+
+	resp := endpoint.NewResponseForBody(variant.Body, variant.Checksum, 3600, h.cacheEnabled, w, r)
+
+	if resp.HasCache() {
+		resp.RespondWithNotModified()
+
+		return nil
+	}
+
+	if err := resp.RespondOk(nil); err != nil {
+		slog.Error("Error marshaling JSON for projects response", "error", err)
+
+		return endpoint.InternalError("could not encode projects response")
+	}
+
+	return nil // A nil return indicates success.
+}
+
+// Render is the first page of projects GET /site embeds, as GET /projects returns it.
+func (h ProjectsHandler) Render() (listing.Variant, *endpoint.ApiError) {
+	return h.render(nil)
+}
+
+func (h ProjectsHandler) render(values url.Values) (listing.Variant, *endpoint.ApiError) {
+	snapshot, err := h.source.Current()
 
 	if err != nil {
 		slog.Error("Error reading projects file", "error", err)
 
-		return endpoint.InternalError("could not read projects data")
+		return listing.Variant{}, endpoint.InternalError("could not read projects data")
+	}
+
+	query, err := h.list.Parse(values)
+	if err != nil {
+		return listing.Variant{}, endpoint.BadRequestError(err.Error())
+	}
+
+	view, apiErr := h.view(snapshot)
+	if apiErr != nil {
+		return listing.Variant{}, apiErr
+	}
+
+	if query.IsZero() {
+		return view.variant, nil
+	}
+
+	variant, err := h.views.variants.Get(view.variant.Checksum, query.Key(), func() any {
+		return h.apply(view.data, query)
+	})
+
+	if err != nil {
+		slog.Error("Error marshaling JSON for projects response", "error", err)
+
+		return listing.Variant{}, endpoint.InternalError("could not encode projects response")
 	}
 
-	if err := projects.EnrichResponse(&data); err != nil {
+	return variant, nil
+}
+
+// view returns the projects of the snapshot enriched with what the lookups know right now.
+// It is only built again when the snapshot or the answer of a lookup changes.
+func (h ProjectsHandler) view(snapshot *fixtures.Snapshot[payload.ProjectsResponse]) (*projectsView, *endpoint.ApiError) {
+	key := snapshot.Checksum + ":" + h.fingerprint(snapshot.Data.Data)
+
+	if view := h.views.current(key); view != nil {
+		return view, nil
+	}
+
+	data := snapshot.Data
+	data.Data = slices.Clone(snapshot.Data.Data)
+
+	if err := projects.EnrichResponseWith(&data, h.publishedAt); err != nil {
 		slog.Error("Error enriching projects data", "error", err)
 
 		// Trusted fixture validation failures are treated as 500s because they
 		// indicate a deployment/configuration issue. Revisit this if the source
 		// data becomes user-controlled or external.
-		return endpoint.InternalError("could not enrich projects data")
+		return nil, endpoint.InternalError("could not enrich projects data")
 	}
 
-	page := paginate.NewFrom(r.URL, projects.PageSize)
-	page.SetNumItems(int64(len(data.Data)))
+	projects.AttachStats(data.Data, h.stats)
 
-	start := (page.Page - 1) * page.Limit
-	switch {
-	case start < 0:
-		start = 0
-	case start > len(data.Data):
-		start = len(data.Data)
+	first, err := h.list.Parse(nil)
+	if err != nil {
+		return nil, endpoint.InternalError("could not read projects data")
 	}
 
-	end := start + page.Limit
-	if end > len(data.Data) {
-		end = len(data.Data)
+	variant, err := listing.Encode(h.apply(data, first))
+	if err != nil {
+		slog.Error("Error marshaling JSON for projects response", "error", err)
+
+		return nil, endpoint.InternalError("could not encode projects response")
+	}
+
+	view := &projectsView{key: key, data: data, variant: variant}
+	h.views.store(view)
+
+	return view, nil
+}
+
+// fingerprint sums up what the lookups know about the projects. Asking them on every request
+// is cheap, and is what keeps the projects on their refresh schedule.
+func (h ProjectsHandler) fingerprint(items []payload.ProjectsData) string {
+	hash := fnv.New64a()
+
+	for _, item := range items {
+		if h.publishedAt != nil && item.PublishedAt == "" {
+			publishedAt, hosted := h.publishedAt(item)
+			fmt.Fprintf(hash, "%s|%t|", publishedAt, hosted)
+		}
+
+		if h.stats != nil {
+			stats, ok := h.stats(item)
+			fmt.Fprintf(hash, "%v|%t|", stats, ok)
+		}
+
+		hash.Write([]byte{0})
 	}
 
-	items := append([]payload.ProjectsData(nil), data.Data[start:end]...)
-	result := pagination.NewPagination(items, page)
-	response := payload.ProjectsResponse{
+	return strconv.FormatUint(hash.Sum64(), 16)
+}
+
+func (h ProjectsHandler) apply(data payload.ProjectsResponse, query listing.Query) payload.ProjectsResponse {
+	result := h.list.Apply(data.Data, query)
+
+	return payload.ProjectsResponse{
 		Version:      data.Version,
 		Data:         result.Data,
 		Page:         result.Page,
@@ -74,25 +210,57 @@ func (h ProjectsHandler) Handle(w http.ResponseWriter, r *http.Request) *endpoin
 		NextPage:     result.NextPage,
 		PreviousPage: result.PreviousPage,
 	}
+}
 
-	resp, err := endpoint.NewResponseForPayload(response, 3600, h.cacheEnabled, w, r)
-	if err != nil {
-		slog.Error("Error preparing projects response cache", "error", err)
+// projectsView is the enriched and sorted projects of one snapshot, with the first page
+// encoded once.
+type projectsView struct {
+	key     string
+	data    payload.ProjectsResponse
+	variant listing.Variant
+}
 
-		return endpoint.InternalError("could not prepare projects response")
-	}
+// projectsViews keeps the latest view and the filtered responses built from it.
+type projectsViews struct {
+	mu       sync.Mutex
+	latest   *projectsView
+	variants *listing.Variants
+}
 
-	if resp.HasCache() {
-		resp.RespondWithNotModified()
+func newProjectsViews() *projectsViews {
+	return &projectsViews{variants: listing.NewVariants(listVariants)}
+}
 
-		return nil
+func (v *projectsViews) current(key string) *projectsView {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.latest != nil && v.latest.key == key {
+		return v.latest
 	}
 
-	if err := resp.RespondOk(response); err != nil {
-		slog.Error("Error marshaling JSON for projects response", "error", err)
+	return nil
+}
 
-		return endpoint.InternalError("could not encode projects response")
-	}
+func (v *projectsViews) store(view *projectsView) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
 
-	return nil // A nil return indicates success.
+	v.latest = view
+}
+
+// newProjectsList pages the projects by projects.PageSize. Filters and sorting apply after
+// the dates resolved in the background are filled in.
+func newProjectsList() listing.Engine[payload.ProjectsData] {
+	return listing.Engine[payload.ProjectsData]{
+		Filters: []listing.Filter[payload.ProjectsData]{
+			{Name: "is_open_source", Value: func(item payload.ProjectsData) string { return strconv.FormatBool(item.IsOpenSource) }, Match: listing.Flag},
+			{Name: "language", Value: func(item payload.ProjectsData) string { return item.Language }, Match: listing.Partial},
+			{Name: "year", Value: func(item payload.ProjectsData) string { return listing.Year(item.PublishedAt) }},
+		},
+		Sorts: []listing.Sort[payload.ProjectsData]{
+			{Name: "published_at", Date: func(item payload.ProjectsData) string { return item.PublishedAt }},
+		},
+		PageSize: projects.PageSize,
+	}
 }
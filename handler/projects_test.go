@@ -9,26 +9,33 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/uuid"
+
 	"github.com/oullin/handler"
 	"github.com/oullin/handler/payload"
 )
 
 func intPtr(v int) *int { return &v }
 
+// projectID turns a readable name into the UUID the fixture rules ask for.
+func projectID(name string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(name)).String()
+}
+
 func TestProjectsHandler_SortsAndPaginates(t *testing.T) {
 	fixture := writeProjectsFixture(t, payload.ProjectsResponse{
 		Version: "1.0.0",
 		Data: []payload.ProjectsData{
-			{UUID: "project-1", Sort: intPtr(10), Title: "One", URL: "https://github.com/example/one", PublishedAt: "2026-03-01T00:00:00Z"},
-			{UUID: "project-2", Sort: intPtr(1), Title: "Two", URL: "https://github.com/example/two", PublishedAt: "2026-03-10T00:00:00Z"},
-			{UUID: "project-3", Sort: intPtr(9), Title: "Three", URL: "https://github.com/example/three", PublishedAt: "2026-03-03T00:00:00Z"},
-			{UUID: "project-4", Sort: intPtr(3), Title: "Four", URL: "https://github.com/example/four", PublishedAt: "2026-03-08T00:00:00Z"},
-			{UUID: "project-5", Sort: intPtr(8), Title: "Five", URL: "https://github.com/example/five", PublishedAt: "2026-03-02T00:00:00Z"},
-			{UUID: "project-6", Sort: intPtr(4), Title: "Six", URL: "https://github.com/example/six", PublishedAt: "2026-03-07T00:00:00Z"},
-			{UUID: "project-7", Sort: intPtr(5), Title: "Seven", URL: "https://github.com/example/seven", PublishedAt: "2026-03-06T00:00:00Z"},
-			{UUID: "project-8", Sort: intPtr(2), Title: "Eight", URL: "https://github.com/example/eight", PublishedAt: "2026-03-09T00:00:00Z"},
-			{UUID: "project-9", Sort: intPtr(6), Title: "Nine", URL: "https://github.com/example/nine", PublishedAt: "2026-03-05T00:00:00Z"},
-			{UUID: "project-10", Sort: intPtr(7), Title: "Ten", URL: "https://github.com/example/ten", PublishedAt: "2026-03-04T00:00:00Z"},
+			{UUID: projectID("project-1"), Sort: intPtr(10), Title: "One", URL: "https://github.com/example/one", PublishedAt: "2026-03-01"},
+			{UUID: projectID("project-2"), Sort: intPtr(1), Title: "Two", URL: "https://github.com/example/two", PublishedAt: "2026-03-10"},
+			{UUID: projectID("project-3"), Sort: intPtr(9), Title: "Three", URL: "https://github.com/example/three", PublishedAt: "2026-03-03"},
+			{UUID: projectID("project-4"), Sort: intPtr(3), Title: "Four", URL: "https://github.com/example/four", PublishedAt: "2026-03-08"},
+			{UUID: projectID("project-5"), Sort: intPtr(8), Title: "Five", URL: "https://github.com/example/five", PublishedAt: "2026-03-02"},
+			{UUID: projectID("project-6"), Sort: intPtr(4), Title: "Six", URL: "https://github.com/example/six", PublishedAt: "2026-03-07"},
+			{UUID: projectID("project-7"), Sort: intPtr(5), Title: "Seven", URL: "https://github.com/example/seven", PublishedAt: "2026-03-06"},
+			{UUID: projectID("project-8"), Sort: intPtr(2), Title: "Eight", URL: "https://github.com/example/eight", PublishedAt: "2026-03-09"},
+			{UUID: projectID("project-9"), Sort: intPtr(6), Title: "Nine", URL: "https://github.com/example/nine", PublishedAt: "2026-03-05"},
+			{UUID: projectID("project-10"), Sort: intPtr(7), Title: "Ten", URL: "https://github.com/example/ten", PublishedAt: "2026-03-04"},
 		},
 	})
 
@@ -66,11 +73,11 @@ func TestProjectsHandler_SortsAndPaginates(t *testing.T) {
 		t.Fatalf("expected 8 items on page 1, got %d", len(resp.Data))
 	}
 
-	if resp.Data[0].UUID != "project-2" || resp.Data[0].Sort == nil || *resp.Data[0].Sort != 1 {
+	if resp.Data[0].UUID != projectID("project-2") || resp.Data[0].Sort == nil || *resp.Data[0].Sort != 1 {
 		t.Fatalf("expected lowest sort project first, got %+v", resp.Data[0])
 	}
 
-	if resp.Data[1].UUID != "project-8" {
+	if resp.Data[1].UUID != projectID("project-8") {
 		t.Fatalf("expected second-lowest sort project second, got %+v", resp.Data[1])
 	}
 }
@@ -79,15 +86,15 @@ func TestProjectsHandler_Page2(t *testing.T) {
 	fixture := writeProjectsFixture(t, payload.ProjectsResponse{
 		Version: "1.0.0",
 		Data: []payload.ProjectsData{
-			{UUID: "project-1", Sort: intPtr(9), Title: "One", URL: "https://github.com/example/one", PublishedAt: "2026-03-09T00:00:00Z"},
-			{UUID: "project-2", Sort: intPtr(8), Title: "Two", URL: "https://github.com/example/two", PublishedAt: "2026-03-08T00:00:00Z"},
-			{UUID: "project-3", Sort: intPtr(7), Title: "Three", URL: "https://github.com/example/three", PublishedAt: "2026-03-07T00:00:00Z"},
-			{UUID: "project-4", Sort: intPtr(6), Title: "Four", URL: "https://github.com/example/four", PublishedAt: "2026-03-06T00:00:00Z"},
-			{UUID: "project-5", Sort: intPtr(5), Title: "Five", URL: "https://github.com/example/five", PublishedAt: "2026-03-05T00:00:00Z"},
-			{UUID: "project-6", Sort: intPtr(4), Title: "Six", URL: "https://github.com/example/six", PublishedAt: "2026-03-04T00:00:00Z"},
-			{UUID: "project-7", Sort: intPtr(3), Title: "Seven", URL: "https://github.com/example/seven", PublishedAt: "2026-03-03T00:00:00Z"},
-			{UUID: "project-8", Sort: intPtr(2), Title: "Eight", URL: "https://github.com/example/eight", PublishedAt: "2026-03-02T00:00:00Z"},
-			{UUID: "project-9", Sort: intPtr(1), Title: "Nine", URL: "https://github.com/example/nine", PublishedAt: "2026-03-01T00:00:00Z"},
+			{UUID: projectID("project-1"), Sort: intPtr(9), Title: "One", URL: "https://github.com/example/one", PublishedAt: "2026-03-09"},
+			{UUID: projectID("project-2"), Sort: intPtr(8), Title: "Two", URL: "https://github.com/example/two", PublishedAt: "2026-03-08"},
+			{UUID: projectID("project-3"), Sort: intPtr(7), Title: "Three", URL: "https://github.com/example/three", PublishedAt: "2026-03-07"},
+			{UUID: projectID("project-4"), Sort: intPtr(6), Title: "Four", URL: "https://github.com/example/four", PublishedAt: "2026-03-06"},
+			{UUID: projectID("project-5"), Sort: intPtr(5), Title: "Five", URL: "https://github.com/example/five", PublishedAt: "2026-03-05"},
+			{UUID: projectID("project-6"), Sort: intPtr(4), Title: "Six", URL: "https://github.com/example/six", PublishedAt: "2026-03-04"},
+			{UUID: projectID("project-7"), Sort: intPtr(3), Title: "Seven", URL: "https://github.com/example/seven", PublishedAt: "2026-03-03"},
+			{UUID: projectID("project-8"), Sort: intPtr(2), Title: "Eight", URL: "https://github.com/example/eight", PublishedAt: "2026-03-02"},
+			{UUID: projectID("project-9"), Sort: intPtr(1), Title: "Nine", URL: "https://github.com/example/nine", PublishedAt: "2026-03-01"},
 		},
 	})
 
@@ -122,30 +129,64 @@ func TestProjectsHandler_Page2(t *testing.T) {
 	}
 }
 
+func TestProjectsHandler_FiltersAndSortsByQuery(t *testing.T) {
+	fixture := writeProjectsFixture(t, payload.ProjectsResponse{
+		Version: "1.0.0",
+		Data: []payload.ProjectsData{
+			{UUID: projectID("project-1"), Sort: intPtr(1), Title: "One", URL: "https://github.com/example/one", Language: "Go", IsOpenSource: true, PublishedAt: "2024-01-01"},
+			{UUID: projectID("project-2"), Sort: intPtr(2), Title: "Two", URL: "https://example.com/two", Language: "PHP", PublishedAt: "2023-01-01"},
+			{UUID: projectID("project-3"), Sort: intPtr(3), Title: "Three", URL: "https://github.com/example/three", Language: "Go / Docker", IsOpenSource: true, PublishedAt: "2025-01-01"},
+		},
+	})
+
+	h := handler.NewProjectsHandlerWithCache(fixture, true)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects?is_open_source=true&language=go&sort=-published_at", nil)
+	rec := httptest.NewRecorder()
+
+	if err := h.Handle(rec, req); err != nil {
+		t.Fatalf("handle: %v", err)
+	}
+
+	var resp payload.ProjectsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if resp.Total != 2 || len(resp.Data) != 2 || resp.Data[0].UUID != projectID("project-3") || resp.Data[1].UUID != projectID("project-1") {
+		t.Fatalf("expected the open-source Go projects newest first, got %+v", resp)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/projects?is_open_source=sometimes", nil)
+	if err := h.Handle(httptest.NewRecorder(), req); err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("expected a bad request, got %+v", err)
+	}
+}
+
 func TestProjectsHandler_SortsBySortWithPublishedAtTieBreaker(t *testing.T) {
 	fixture := writeProjectsFixture(t, payload.ProjectsResponse{
 		Version: "1.0.0",
 		Data: []payload.ProjectsData{
 			{
-				UUID:        "project-older",
+				UUID:        projectID("project-older"),
 				Sort:        intPtr(1),
 				Title:       "Older",
 				URL:         "https://github.com/example/older",
-				PublishedAt: "2026-03-01T00:00:00Z",
+				PublishedAt: "2026-03-01",
 			},
 			{
-				UUID:        "project-newer",
+				UUID:        projectID("project-newer"),
 				Sort:        intPtr(1),
 				Title:       "Newer",
 				URL:         "https://github.com/example/newer",
-				PublishedAt: "2026-03-10T00:00:00Z",
+				PublishedAt: "2026-03-10",
 			},
 			{
-				UUID:        "project-later-sort",
+				UUID:        projectID("project-later-sort"),
 				Sort:        intPtr(2),
 				Title:       "Later Sort",
 				URL:         "https://github.com/example/later-sort",
-				PublishedAt: "2026-03-17T12:00:00Z",
+				PublishedAt: "2026-03-17",
 			},
 		},
 	})
@@ -168,15 +209,15 @@ func TestProjectsHandler_SortsBySortWithPublishedAtTieBreaker(t *testing.T) {
 		t.Fatalf("expected 3 items, got %d", len(resp.Data))
 	}
 
-	if resp.Data[0].UUID != "project-newer" {
+	if resp.Data[0].UUID != projectID("project-newer") {
 		t.Fatalf("expected newer project first within equal sort, got %+v", resp.Data[0])
 	}
 
-	if resp.Data[1].UUID != "project-older" {
+	if resp.Data[1].UUID != projectID("project-older") {
 		t.Fatalf("expected older project second within equal sort, got %+v", resp.Data[1])
 	}
 
-	if resp.Data[2].UUID != "project-later-sort" {
+	if resp.Data[2].UUID != projectID("project-later-sort") {
 		t.Fatalf("expected higher sort project last, got %+v", resp.Data[2])
 	}
 }
@@ -185,7 +226,7 @@ func TestProjectsHandler_NoStoreWhenCacheDisabled(t *testing.T) {
 	fixture := writeProjectsFixture(t, payload.ProjectsResponse{
 		Version: "1.0.0",
 		Data: []payload.ProjectsData{
-			{UUID: "project-1", Sort: intPtr(1), Title: "One", URL: "https://github.com/example/one", PublishedAt: "2026-03-01T00:00:00Z"},
+			{UUID: projectID("project-1"), Sort: intPtr(1), Title: "One", URL: "https://github.com/example/one", PublishedAt: "2026-03-01"},
 		},
 	})
 
@@ -278,3 +319,129 @@ func writeProjectsFixture(t *testing.T, data payload.ProjectsResponse) string {
 
 	return file
 }
+
+func TestProjectsHandler_FillsMissingDatesWithoutWaiting(t *testing.T) {
+	fixture := writeProjectsFixture(t, payload.ProjectsResponse{
+		Version: "1.0.0",
+		Data: []payload.ProjectsData{
+			{UUID: projectID("resolved"), Sort: intPtr(1), Title: "Resolved", URL: "https://github.com/example/resolved"},
+			{UUID: projectID("pending"), Sort: intPtr(2), Title: "Pending", URL: "https://github.com/example/pending"},
+		},
+	})
+
+	lookup := func(project payload.ProjectsData) (string, bool) {
+		if project.UUID == projectID("resolved") {
+			return "2024-01-02T03:04:05Z", true
+		}
+
+		// Nothing cached yet, e.g. GitHub is down: the project goes out without a date.
+		return "", true
+	}
+
+	h := handler.NewProjectsHandlerWithCache(fixture, true).WithPublishedAtLookup(lookup)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects", nil)
+	rec := httptest.NewRecorder()
+
+	if err := h.Handle(rec, req); err != nil {
+		t.Fatalf("handle: %v", err)
+	}
+
+	var resp payload.ProjectsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if len(resp.Data) != 2 || resp.Data[0].PublishedAt != "2024-01-02T03:04:05Z" || resp.Data[1].PublishedAt != "" {
+		t.Fatalf("unexpected projects %+v", resp.Data)
+	}
+}
+
+func TestProjectsHandler_AttachesRepositoryStats(t *testing.T) {
+	fixture := writeProjectsFixture(t, payload.ProjectsResponse{
+		Version: "1.0.0",
+		Data: []payload.ProjectsData{
+			{UUID: projectID("tracked"), Sort: intPtr(1), Title: "Tracked", URL: "https://github.com/example/tracked", IsOpenSource: true, PublishedAt: "2024-01-02"},
+			{UUID: projectID("untracked"), Sort: intPtr(2), Title: "Untracked", URL: "https://github.com/example/untracked", IsOpenSource: true, PublishedAt: "2024-01-03"},
+		},
+	})
+
+	lookup := func(project payload.ProjectsData) (*payload.ProjectStats, bool) {
+		if project.UUID != projectID("tracked") {
+			return nil, false
+		}
+
+		return &payload.ProjectStats{Stars: 12, Forks: 2, Topics: []string{"go"}, License: "MIT"}, true
+	}
+
+	h := handler.NewProjectsHandlerWithCache(fixture, true).WithStatsLookup(lookup)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects", nil)
+	rec := httptest.NewRecorder()
+
+	if err := h.Handle(rec, req); err != nil {
+		t.Fatalf("handle: %v", err)
+	}
+
+	var resp payload.ProjectsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if len(resp.Data) != 2 || resp.Data[0].Stats == nil || resp.Data[0].Stats.Stars != 12 || resp.Data[0].Stats.License != "MIT" {
+		t.Fatalf("expected stats on the tracked project, got %+v", resp.Data)
+	}
+
+	if resp.Data[1].Stats != nil {
+		t.Fatalf("expected no stats on the untracked project, got %+v", resp.Data[1].Stats)
+	}
+}
+
+func TestProjectsHandler_RebuildsWhenTheStatsChange(t *testing.T) {
+	fixture := writeProjectsFixture(t, payload.ProjectsResponse{
+		Version: "1.0.0",
+		Data: []payload.ProjectsData{
+			{UUID: projectID("tracked"), Sort: intPtr(1), Title: "Tracked", URL: "https://github.com/example/tracked", IsOpenSource: true, PublishedAt: "2024-01-02"},
+		},
+	})
+
+	stars := 1
+	lookup := func(project payload.ProjectsData) (*payload.ProjectStats, bool) {
+		return &payload.ProjectStats{Stars: stars}, true
+	}
+
+	h := handler.NewProjectsHandlerWithCache(fixture, true).WithStatsLookup(lookup)
+
+	fetch := func() (string, payload.ProjectsResponse) {
+		rec := httptest.NewRecorder()
+
+		if err := h.Handle(rec, httptest.NewRequest(http.MethodGet, "/projects?is_open_source=true", nil)); err != nil {
+			t.Fatalf("handle: %v", err)
+		}
+
+		var resp payload.ProjectsResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+
+		return rec.Header().Get("ETag"), resp
+	}
+
+	first, _ := fetch()
+	again, _ := fetch()
+
+	if first == "" || first != again {
+		t.Fatalf("expected the same ETag while the stats hold, got %q and %q", first, again)
+	}
+
+	stars = 5
+	changed, resp := fetch()
+
+	if changed == first {
+		t.Fatalf("expected a new ETag once the stats change")
+	}
+
+	if len(resp.Data) != 1 || resp.Data[0].Stats == nil || resp.Data[0].Stats.Stars != 5 {
+		t.Fatalf("expected the new stats, got %+v", resp.Data)
+	}
+}
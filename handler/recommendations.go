@@ -1,20 +1,19 @@
 package handler
 
 import (
+	"github.com/oullin/handler/listing"
 	"github.com/oullin/handler/payload"
 	"github.com/oullin/pkg/endpoint"
-	"github.com/oullin/pkg/portal"
+	"github.com/oullin/pkg/fixtures"
 
-	"log/slog"
 	"net/http"
-	"sort"
-	"strings"
-	"time"
+	"strconv"
 )
 
 type RecommendationsHandler struct {
-	filePath     string
+	source       *fixtures.Source[payload.RecommendationsResponse]
 	cacheEnabled bool
+	list         fixtureList[payload.RecommendationsResponse, payload.RecommendationsData]
 }
 
 func NewRecommendationsHandler(filePath string) RecommendationsHandler {
@@ -23,92 +22,64 @@ func NewRecommendationsHandler(filePath string) RecommendationsHandler {
 
 func NewRecommendationsHandlerWithCache(filePath string, cacheEnabled bool) RecommendationsHandler {
 	return RecommendationsHandler{
-		filePath:     filePath,
+		source:       fixtures.NewSource(filePath, validated(prepareRecommendations)),
 		cacheEnabled: cacheEnabled,
+		list:         newRecommendationsList(),
 	}
 }
 
-func (h RecommendationsHandler) Handle(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
-	data, err := portal.ParseJsonFile[payload.RecommendationsResponse](h.filePath)
-
-	if err != nil {
-		slog.Error("Error reading recommendations file", "error", err)
-
-		return endpoint.InternalError("could not read recommendations data")
-	}
-
-	data.Data = h.featured(data.Data)
-
-	resp, err := endpoint.NewResponseForPayload(data, 3600, h.cacheEnabled, w, r)
-	if err != nil {
-		slog.Error("Error preparing recommendations response cache", "error", err)
-
-		return endpoint.InternalError("could not prepare recommendations response")
-	}
-
-	if resp.HasCache() {
-		resp.RespondWithNotModified()
-
-		return nil
-	}
-
-	if err := resp.RespondOk(data); err != nil {
-		slog.Error("Error marshaling JSON for recommendations response", "error", err)
-
-		return endpoint.InternalError("could not encode recommendations response")
+// NewRecommendationsHandlerWithLoader serves the recommendations load returns, e.g. out of the database, rather
+// than the fixture file it falls back to.
+func NewRecommendationsHandlerWithLoader(file string, load func() (payload.RecommendationsResponse, error), cacheEnabled bool) RecommendationsHandler {
+	return RecommendationsHandler{
+		source:       fixtures.NewLoadedSource(file, load, validated(prepareRecommendations)),
+		cacheEnabled: cacheEnabled,
+		list:         newRecommendationsList(),
 	}
-
-	return nil // A nil return indicates success.
 }
 
-func (h RecommendationsHandler) featured(items []payload.RecommendationsData) []payload.RecommendationsData {
-	filtered := make([]payload.RecommendationsData, 0, len(items))
-
-	for _, item := range items {
-		if item.Featured == 1 {
-			filtered = append(filtered, item)
-		}
-	}
-
-	type sortKey struct {
-		time time.Time
-		ok   bool
-	}
-
-	keys := make([]sortKey, len(filtered))
-	for i, item := range filtered {
-		t, ok := h.createdAt(item)
-		keys[i] = sortKey{time: t, ok: ok}
-	}
-
-	sort.SliceStable(filtered, func(i, j int) bool {
-		left, right := keys[i], keys[j]
+// Fixture is the source the router keeps reloading.
+func (h RecommendationsHandler) Fixture() fixtures.Reloader {
+	return h.source
+}
 
-		switch {
-		case left.ok && right.ok:
-			return left.time.After(right.time)
-		case left.ok:
-			return true
-		case right.ok:
-			return false
-		default:
-			return false
-		}
-	})
+func (h RecommendationsHandler) Handle(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	return h.list.serve(w, r, h.source, h.cacheEnabled)
+}
 
-	return filtered
+// Render is the recommendations list GET /site embeds, as GET /recommendations returns it.
+func (h RecommendationsHandler) Render() (listing.Variant, *endpoint.ApiError) {
+	return h.list.render(h.source, nil)
 }
 
-func (h RecommendationsHandler) createdAt(item payload.RecommendationsData) (time.Time, bool) {
-	createdAt := strings.TrimSpace(item.CreatedAt)
-	if createdAt == "" {
-		return time.Time{}, false
-	}
+func newRecommendationsList() fixtureList[payload.RecommendationsResponse, payload.RecommendationsData] {
+	return newFixtureList(
+		"recommendations",
+		listing.Engine[payload.RecommendationsData]{
+			Filters: []listing.Filter[payload.RecommendationsData]{
+				{Name: "featured", Value: func(item payload.RecommendationsData) string { return strconv.Itoa(item.Featured) }, Default: "1"},
+				{Name: "year", Value: func(item payload.RecommendationsData) string { return listing.Year(item.CreatedAt) }},
+				{Name: "company", Value: func(item payload.RecommendationsData) string { return item.Person.Company }, Match: listing.Partial},
+				{Name: "relation", Value: func(item payload.RecommendationsData) string { return item.Relation }, Match: listing.Partial},
+			},
+			Sorts: []listing.Sort[payload.RecommendationsData]{
+				{Name: "created_at", Date: func(item payload.RecommendationsData) string { return item.CreatedAt }},
+				{Name: "updated_at", Date: func(item payload.RecommendationsData) string { return item.UpdatedAt }},
+			},
+		},
+		func(data payload.RecommendationsResponse) []payload.RecommendationsData { return data.Data },
+		func(data payload.RecommendationsResponse, items []payload.RecommendationsData, page *payload.ListPage) payload.RecommendationsResponse {
+			data.Data, data.ListPage = items, page
+
+			return data
+		},
+	)
+}
 
-	parsed, err := time.Parse("2006-01-02", createdAt)
-	if err != nil {
-		return time.Time{}, false
-	}
+// prepareRecommendations orders the recommendations newest first once per load rather than
+// on every request. Only the featured ones are listed unless ?featured= asks otherwise.
+func prepareRecommendations(data *payload.RecommendationsResponse) error {
+	listing.SortByDate(data.Data, func(item payload.RecommendationsData) string { return item.CreatedAt }, true)
 
-	return parsed, true
+	return nil
 }
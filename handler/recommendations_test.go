@@ -26,7 +26,7 @@ func TestRecommendationsHandlerFiltersAndSortsFeaturedItems(t *testing.T) {
 		"version": "v1",
 		"data": [
 			{
-				"uuid": "older-featured",
+				"uuid": "3f9c1b62-5a7e-4d1f-9b8a-0c2e4d6f8a10",
 				"relation": "Worked together",
 				"text": "Older featured",
 				"featured": 1,
@@ -40,7 +40,7 @@ func TestRecommendationsHandlerFiltersAndSortsFeaturedItems(t *testing.T) {
 				}
 			},
 			{
-				"uuid": "newer-not-featured",
+				"uuid": "7a2d4e6f-8b1c-4e3a-9d5f-1b3c5e7a9c20",
 				"relation": "Worked together",
 				"text": "Should be excluded",
 				"featured": 0,
@@ -54,7 +54,7 @@ func TestRecommendationsHandlerFiltersAndSortsFeaturedItems(t *testing.T) {
 				}
 			},
 			{
-				"uuid": "newer-featured",
+				"uuid": "c4e6a8b0-2d4f-4a6c-8e0b-3d5f7a9c1e30",
 				"relation": "Worked together",
 				"text": "Newer featured",
 				"featured": 1,
@@ -98,7 +98,7 @@ func TestRecommendationsHandlerFiltersAndSortsFeaturedItems(t *testing.T) {
 		t.Fatalf("expected 2 featured items, got %+v", res.Data)
 	}
 
-	if res.Data[0].UUID != "newer-featured" || res.Data[1].UUID != "older-featured" {
+	if res.Data[0].UUID != "c4e6a8b0-2d4f-4a6c-8e0b-3d5f7a9c1e30" || res.Data[1].UUID != "3f9c1b62-5a7e-4d1f-9b8a-0c2e4d6f8a10" {
 		t.Fatalf("unexpected order: %+v", res.Data)
 	}
 
@@ -106,3 +106,40 @@ func TestRecommendationsHandlerFiltersAndSortsFeaturedItems(t *testing.T) {
 		t.Fatalf("expected featured items only: %+v", res.Data)
 	}
 }
+
+func TestRecommendationsHandlerListsEveryItemWhenAsked(t *testing.T) {
+	h := handler.NewRecommendationsHandler("../storage/fixture/recommendations.json")
+
+	count := func(target string) []payload.RecommendationsData {
+		rec := httptest.NewRecorder()
+
+		if err := h.Handle(rec, httptest.NewRequest(http.MethodGet, target, nil)); err != nil {
+			t.Fatalf("%s: %v", target, err)
+		}
+
+		var res payload.RecommendationsResponse
+		if err := json.NewDecoder(rec.Body).Decode(&res); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+
+		return res.Data
+	}
+
+	featured := count("/recommendations")
+	everything := count("/recommendations?featured=0,1")
+	hidden := count("/recommendations?featured=0&company=aspire")
+
+	if len(featured) == 0 || len(everything) <= len(featured) {
+		t.Fatalf("expected the featured items by default, got %d of %d", len(featured), len(everything))
+	}
+
+	for _, item := range hidden {
+		if item.Featured != 0 || item.Person.Company != "Aspire" {
+			t.Fatalf("unexpected item %+v", item)
+		}
+	}
+
+	if len(hidden) == 0 {
+		t.Fatalf("expected the non-featured Aspire recommendations")
+	}
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"log/slog"
+	"net/http"
+
+	"github.com/oullin/handler/listing"
+	"github.com/oullin/handler/payload"
+	"github.com/oullin/pkg/endpoint"
+	"github.com/oullin/pkg/fixtures"
+	"github.com/oullin/pkg/resume"
+)
+
+// ResumeHandler exports the profile, experience, education and links as a JSON Resume
+// document and a vCard. The sections are read through the routes registered in GET /site,
+// so both follow the same fixtures, or database content, as the website. Each document is
+// built once per version of its sections.
+type ResumeHandler struct {
+	sections     *SiteHandler
+	siteURL      string
+	cacheEnabled bool
+	documents    *listing.Variants
+}
+
+func NewResumeHandler(sections *SiteHandler, siteURL string, cacheEnabled bool) ResumeHandler {
+	return ResumeHandler{
+		sections:     sections,
+		siteURL:      siteURL,
+		cacheEnabled: cacheEnabled,
+		documents:    listing.NewVariants(2),
+	}
+}
+
+func (h ResumeHandler) JSONResume(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	sources, checksum, apiErr := h.sources()
+	if apiErr != nil {
+		return apiErr
+	}
+
+	variant, err := h.documents.Get(checksum, "json", func() any {
+		return resume.New(sources)
+	})
+
+	if err != nil {
+		slog.Error("Error marshaling JSON for resume response", "error", err)
+
+		return endpoint.InternalError("could not encode resume response")
+	}
+
+	return h.respond(w, r, variant, "application/json")
+}
+
+func (h ResumeHandler) VCard(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	sources, checksum, apiErr := h.sources()
+	if apiErr != nil {
+		return apiErr
+	}
+
+	variant, err := h.documents.GetEncoded(checksum, "vcard", func() (listing.Variant, error) {
+		card, err := resume.VCard(sources)
+		if err != nil {
+			return listing.Variant{}, err
+		}
+
+		return listing.Checksum(card), nil
+	})
+
+	if err != nil {
+		return endpoint.LogInternalError("could not write the contact card", err)
+	}
+
+	return h.respond(w, r, variant, resume.VCardContentType)
+}
+
+func (h ResumeHandler) respond(w http.ResponseWriter, r *http.Request, variant listing.Variant, contentType string) *endpoint.ApiError {
+	resp := endpoint.NewResponseForBody(variant.Body, variant.Checksum, 3600, h.cacheEnabled, w, r).WithContentType(contentType)
+
+	if resp.HasCache() {
+		resp.RespondWithNotModified()
+
+		return nil
+	}
+
+	if err := resp.RespondOk(nil); err != nil {
+		slog.Error("Error writing resume response", "content_type", contentType, "error", err)
+
+		return endpoint.InternalError("could not write resume response")
+	}
+
+	return nil // A nil return indicates success.
+}
+
+// sources collects the snapshots of the sections, with a checksum made of theirs that
+// changes whenever any of them does.
+func (h ResumeHandler) sources() (resume.Sources, string, *endpoint.ApiError) {
+	sources := resume.Sources{SiteURL: h.siteURL}
+	checksum := sha256.New()
+
+	profile, apiErr := sectionSnapshot[payload.ProfileResponse](h.sections, "profile", checksum)
+	if apiErr != nil {
+		return sources, "", apiErr
+	}
+
+	experience, apiErr := sectionSnapshot[payload.ExperienceResponse](h.sections, "experience", checksum)
+	if apiErr != nil {
+		return sources, "", apiErr
+	}
+
+	education, apiErr := sectionSnapshot[payload.EducationResponse](h.sections, "education", checksum)
+	if apiErr != nil {
+		return sources, "", apiErr
+	}
+
+	links, apiErr := sectionSnapshot[payload.LinksResponse](h.sections, "links", checksum)
+	if apiErr != nil {
+		return sources, "", apiErr
+	}
+
+	sources.Profile, sources.Experience, sources.Education, sources.Links = profile.Data, experience.Data, education.Data, links.Data
+
+	return sources, fmt.Sprintf("%x", checksum.Sum(nil)), nil
+}
+
+// sectionSnapshot reads the typed snapshot of the section registered under the name and
+// adds its checksum to the given hash.
+func sectionSnapshot[T any](sections *SiteHandler, name string, checksum hash.Hash) (*fixtures.Snapshot[T], *endpoint.ApiError) {
+	section, apiErr := sections.Section(name)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	typed, ok := section.(interface {
+		Snapshot() (*fixtures.Snapshot[T], *endpoint.ApiError)
+	})
+
+	if !ok {
+		return nil, endpoint.InternalError(fmt.Sprintf("the %s section is not available", name))
+	}
+
+	snapshot, apiErr := typed.Snapshot()
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	fmt.Fprintf(checksum, "%s:%s\n", name, snapshot.Checksum)
+
+	return snapshot, nil
+}
@@ -0,0 +1,99 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oullin/handler"
+	"github.com/oullin/pkg/resume"
+)
+
+func newResumeHandler() handler.ResumeHandler {
+	site := handler.NewSiteHandler(true)
+	site.Add("profile", handler.NewProfileHandler("../storage/fixture/profile.json"))
+	site.Add("experience", handler.NewExperienceHandler("../storage/fixture/experience.json"))
+	site.Add("education", handler.NewEducationHandler("../storage/fixture/education.json"))
+	site.Add("links", handler.NewLinksHandler("../storage/fixture/links.json"))
+
+	return handler.NewResumeHandler(site, "https://oullin.io", true)
+}
+
+func TestResumeHandlerServesJSONResume(t *testing.T) {
+	h := newResumeHandler()
+	rec := httptest.NewRecorder()
+
+	if err := h.JSONResume(rec, httptest.NewRequest(http.MethodGet, "/resume.json", nil)); err != nil {
+		t.Fatalf("handle: %v", err)
+	}
+
+	var doc resume.Resume
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if doc.Basics.Name == "" || len(doc.Work) == 0 || len(doc.Education) == 0 || len(doc.Basics.Profiles) == 0 {
+		t.Fatalf("expected every section in the resume, got %+v", doc)
+	}
+
+	for _, work := range doc.Work {
+		if work.StartDate == "" {
+			t.Fatalf("expected every job to have an ISO start date, got %+v", work)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/resume.json", nil)
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	again := httptest.NewRecorder()
+
+	if err := h.JSONResume(again, req); err != nil || again.Code != http.StatusNotModified {
+		t.Fatalf("expected not modified, got %d (%v)", again.Code, err)
+	}
+}
+
+func TestResumeHandlerServesVCard(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	if err := newResumeHandler().VCard(rec, httptest.NewRequest(http.MethodGet, "/contact.vcf", nil)); err != nil {
+		t.Fatalf("handle: %v", err)
+	}
+
+	if got := rec.Header().Get("Content-Type"); got != resume.VCardContentType {
+		t.Fatalf("expected a vCard, got %q", got)
+	}
+
+	if body := rec.Body.String(); !strings.HasPrefix(body, "BEGIN:VCARD\r\nVERSION:4.0\r\n") || !strings.Contains(body, "EMAIL;TYPE=work:") {
+		t.Fatalf("unexpected card %q", body)
+	}
+}
+
+func TestResumeHandlerNeedsItsSections(t *testing.T) {
+	h := handler.NewResumeHandler(handler.NewSiteHandler(true), "", true)
+
+	if err := h.VCard(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/contact.vcf", nil)); err == nil || err.Status != http.StatusInternalServerError {
+		t.Fatalf("expected an internal error, got %+v", err)
+	}
+}
+
+func TestResumeHandlerRefusesAVCardWithoutName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.json")
+	if err := os.WriteFile(path, []byte(`{"version":"1","data":{"nickname":"gus","handle":"gocanto","name":" ","email":"gus@oullin.io"}}`), 0o644); err != nil {
+		t.Fatalf("write profile: %v", err)
+	}
+
+	site := handler.NewSiteHandler(true)
+	site.Add("profile", handler.NewProfileHandler(path))
+	site.Add("experience", handler.NewExperienceHandler("../storage/fixture/experience.json"))
+	site.Add("education", handler.NewEducationHandler("../storage/fixture/education.json"))
+	site.Add("links", handler.NewLinksHandler("../storage/fixture/links.json"))
+
+	h := handler.NewResumeHandler(site, "https://oullin.io", true)
+
+	if err := h.VCard(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/contact.vcf", nil)); err == nil || err.Status != http.StatusInternalServerError {
+		t.Fatalf("expected an internal error, got %+v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/oullin/handler/listing"
+	"github.com/oullin/pkg/endpoint"
+)
+
+// SiteSection is a fixture route GET /site can embed. Render returns the body the route
+// serves to a request without a query, with its checksum.
+type SiteSection interface {
+	Render() (listing.Variant, *endpoint.ApiError)
+}
+
+type siteSection struct {
+	name    string
+	section SiteSection
+}
+
+// SiteHandler answers GET /site with the sections the SPA needs on its first load, in one
+// signed request instead of one per section. ?include=profile,links picks the sections;
+// without it every section is included.
+type SiteHandler struct {
+	sections     []siteSection
+	cacheEnabled bool
+}
+
+func NewSiteHandler(cacheEnabled bool) *SiteHandler {
+	return &SiteHandler{cacheEnabled: cacheEnabled}
+}
+
+// Add registers a section under the name of its route. Sections keep the order they were
+// added in.
+func (h *SiteHandler) Add(name string, section SiteSection) {
+	if h == nil {
+		return
+	}
+
+	h.sections = append(h.sections, siteSection{name: name, section: section})
+}
+
+func (h *SiteHandler) Handle(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	selected, apiErr := h.selected(r.URL.Query().Get("include"))
+	if apiErr != nil {
+		return apiErr
+	}
+
+	// The body is a JSON object of the sections' own bodies, and the ETag is made of their
+	// checksums, so it changes whenever any included section does.
+	body := bytes.NewBufferString("{")
+	etag := sha256.New()
+
+	for i, current := range selected {
+		variant, apiErr := current.section.Render()
+		if apiErr != nil {
+			return apiErr
+		}
+
+		name, _ := json.Marshal(current.name)
+
+		if i > 0 {
+			body.WriteByte(',')
+		}
+
+		body.Write(name)
+		body.WriteByte(':')
+		body.Write(variant.Body)
+
+		fmt.Fprintf(etag, "%s:%s\n", current.name, variant.Checksum)
+	}
+
+	body.WriteByte('}')
+
+	resp := endpoint.NewResponseForBody(body.Bytes(), fmt.Sprintf("%x", etag.Sum(nil)), 3600, h.cacheEnabled, w, r)
+
+	if resp.HasCache() {
+		resp.RespondWithNotModified()
+
+		return nil
+	}
+
+	if err := resp.RespondOk(nil); err != nil {
+		slog.Error("Error writing site response", "error", err)
+
+		return endpoint.InternalError("could not encode site response")
+	}
+
+	return nil // A nil return indicates success.
+}
+
+// Render returns the section registered under the name, as its route serves it.
+func (h *SiteHandler) Render(name string) (listing.Variant, *endpoint.ApiError) {
+	section, apiErr := h.Section(name)
+	if apiErr != nil {
+		return listing.Variant{}, apiErr
+	}
+
+	return section.Render()
+}
+
+// Section returns the route registered under the name.
+func (h *SiteHandler) Section(name string) (SiteSection, *endpoint.ApiError) {
+	if h != nil {
+		for _, current := range h.sections {
+			if current.name == name {
+				return current.section, nil
+			}
+		}
+	}
+
+	return nil, endpoint.InternalError(fmt.Sprintf("the %s section is not available", name))
+}
+
+func (h *SiteHandler) selected(include string) ([]siteSection, *endpoint.ApiError) {
+	if strings.TrimSpace(include) == "" {
+		return h.sections, nil
+	}
+
+	var names []string
+
+	for _, name := range strings.Split(include, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if name == "" {
+			continue
+		}
+
+		if !slices.ContainsFunc(h.sections, func(s siteSection) bool { return s.name == name }) {
+			return nil, endpoint.BadRequestError(fmt.Sprintf("unknown site section %q, expected any of %s", name, strings.Join(h.names(), ", ")))
+		}
+
+		names = append(names, name)
+	}
+
+	var selected []siteSection
+
+	for _, current := range h.sections {
+		if slices.Contains(names, current.name) {
+			selected = append(selected, current)
+		}
+	}
+
+	return selected, nil
+}
+
+func (h *SiteHandler) names() []string {
+	names := make([]string, len(h.sections))
+
+	for i, current := range h.sections {
+		names[i] = current.name
+	}
+
+	return names
+}
@@ -0,0 +1,74 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/oullin/handler"
+)
+
+func TestSiteHandlerEmbedsTheSectionsAsTheirRoutesServeThem(t *testing.T) {
+	talks := handler.NewTalksHandler("../storage/fixture/talks.json")
+	recommendations := handler.NewRecommendationsHandler("../storage/fixture/recommendations.json")
+	projects := handler.NewProjectsHandler("../storage/fixture/projects.json")
+
+	site := handler.NewSiteHandler(true)
+	site.Add("talks", talks)
+	site.Add("recommendations", recommendations)
+	site.Add("projects", projects)
+
+	get := func(h handler.FileHandler, target, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("If-None-Match", etag)
+		rec := httptest.NewRecorder()
+
+		if err := h.Handle(rec, req); err != nil {
+			t.Fatalf("%s: %v", target, err)
+		}
+
+		return rec
+	}
+
+	all := get(site, "/site", "")
+
+	var sections map[string]json.RawMessage
+	if err := json.NewDecoder(all.Body).Decode(&sections); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	for name, route := range map[string]handler.FileHandler{"talks": talks, "recommendations": recommendations, "projects": projects} {
+		own := get(route, "/"+name, "").Body.Bytes()
+
+		if !bytes.Equal(bytes.TrimSpace(own), sections[name]) {
+			t.Fatalf("expected %s to match its own route", name)
+		}
+	}
+
+	some := get(site, "/site?include=talks,%20TALKS", "")
+	sections = nil
+
+	if err := json.NewDecoder(some.Body).Decode(&sections); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if len(sections) != 1 || sections["talks"] == nil {
+		t.Fatalf("expected talks only, got %d sections", len(sections))
+	}
+
+	etag := all.Header().Get("ETag")
+	if etag == "" || etag == some.Header().Get("ETag") {
+		t.Fatalf("expected each selection to have its own ETag, got %q", etag)
+	}
+
+	if rec := get(site, "/site", etag); rec.Code != http.StatusNotModified {
+		t.Fatalf("expected not modified, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/site?include=talks,secrets", nil)
+	if err := site.Handle(httptest.NewRecorder(), req); err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("expected an unknown section to be a bad request, got %+v", err)
+	}
+}
@@ -1,17 +1,18 @@
 package handler
 
 import (
+	"github.com/oullin/handler/listing"
 	"github.com/oullin/handler/payload"
 	"github.com/oullin/pkg/endpoint"
-	"github.com/oullin/pkg/portal"
+	"github.com/oullin/pkg/fixtures"
 
-	"log/slog"
 	"net/http"
 )
 
 type LinksHandler struct {
-	filePath     string
+	source       *fixtures.Source[payload.LinksResponse]
 	cacheEnabled bool
+	list         fixtureList[payload.LinksResponse, payload.LinksData]
 }
 
 type SocialHandler = LinksHandler
@@ -22,11 +23,27 @@ func NewLinksHandler(filePath string) LinksHandler {
 
 func NewLinksHandlerWithCache(filePath string, cacheEnabled bool) LinksHandler {
 	return LinksHandler{
-		filePath:     filePath,
+		source:       fixtures.NewSource(filePath, validated[payload.LinksResponse](nil)),
 		cacheEnabled: cacheEnabled,
+		list:         newLinksList(),
 	}
 }
 
+// NewLinksHandlerWithLoader serves the links load returns, e.g. out of the database, rather
+// than the fixture file it falls back to.
+func NewLinksHandlerWithLoader(file string, load func() (payload.LinksResponse, error), cacheEnabled bool) LinksHandler {
+	return LinksHandler{
+		source:       fixtures.NewLoadedSource(file, load, validated[payload.LinksResponse](nil)),
+		cacheEnabled: cacheEnabled,
+		list:         newLinksList(),
+	}
+}
+
+// Fixture is the source the router keeps reloading.
+func (h LinksHandler) Fixture() fixtures.Reloader {
+	return h.source
+}
+
 func NewSocialHandler(filePath string) LinksHandler {
 	return NewLinksHandler(filePath)
 }
@@ -36,33 +53,33 @@ func NewSocialHandlerWithCache(filePath string, cacheEnabled bool) LinksHandler
 }
 
 func (h LinksHandler) Handle(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
-	data, err := portal.ParseJsonFile[payload.LinksResponse](h.filePath)
-
-	if err != nil {
-		slog.Error("Error reading links file", "error", err)
-
-		return endpoint.InternalError("could not read links data")
-	}
-
-	// Cache for 1 week (604800 seconds) since links data rarely changes.
-	resp, err := endpoint.NewResponseForPayload(data, 604800, h.cacheEnabled, w, r)
-	if err != nil {
-		slog.Error("Error preparing links response cache", "error", err)
-
-		return endpoint.InternalError("could not prepare links response")
-	}
-
-	if resp.HasCache() {
-		resp.RespondWithNotModified()
-
-		return nil
-	}
+	return h.list.serve(w, r, h.source, h.cacheEnabled)
+}
 
-	if err := resp.RespondOk(data); err != nil {
-		slog.Error("Error marshaling JSON for links response", "error", err)
+// Render is the links list GET /site embeds, as GET /links returns it.
+func (h LinksHandler) Render() (listing.Variant, *endpoint.ApiError) {
+	return h.list.render(h.source, nil)
+}
 
-		return endpoint.InternalError("could not encode links response")
-	}
+// Snapshot is the links list Render encodes, for handlers built on top of it. The route has
+// no default filters, so it holds every item.
+func (h LinksHandler) Snapshot() (*fixtures.Snapshot[payload.LinksResponse], *endpoint.ApiError) {
+	return h.list.snapshot(h.source)
+}
 
-	return nil // A nil return indicates success.
+func newLinksList() fixtureList[payload.LinksResponse, payload.LinksData] {
+	return newFixtureList(
+		"links",
+		listing.Engine[payload.LinksData]{
+			Filters: []listing.Filter[payload.LinksData]{
+				{Name: "name", Value: func(item payload.LinksData) string { return item.Name }},
+			},
+		},
+		func(data payload.LinksResponse) []payload.LinksData { return data.Data },
+		func(data payload.LinksResponse, items []payload.LinksData, page *payload.ListPage) payload.LinksResponse {
+			data.Data, data.ListPage = items, page
+
+			return data
+		},
+	)
 }
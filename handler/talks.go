@@ -1,17 +1,18 @@
 package handler
 
 import (
+	"github.com/oullin/handler/listing"
 	"github.com/oullin/handler/payload"
 	"github.com/oullin/pkg/endpoint"
-	"github.com/oullin/pkg/portal"
+	"github.com/oullin/pkg/fixtures"
 
-	"log/slog"
 	"net/http"
 )
 
 type TalksHandler struct {
-	filePath     string
+	source       *fixtures.Source[payload.TalksResponse]
 	cacheEnabled bool
+	list         fixtureList[payload.TalksResponse, payload.TalksData]
 }
 
 func NewTalksHandler(filePath string) TalksHandler {
@@ -20,38 +21,55 @@ func NewTalksHandler(filePath string) TalksHandler {
 
 func NewTalksHandlerWithCache(filePath string, cacheEnabled bool) TalksHandler {
 	return TalksHandler{
-		filePath:     filePath,
+		source:       fixtures.NewSource(filePath, validated[payload.TalksResponse](nil)),
 		cacheEnabled: cacheEnabled,
+		list:         newTalksList(),
 	}
 }
 
-func (h TalksHandler) Handle(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
-	data, err := portal.ParseJsonFile[payload.TalksResponse](h.filePath)
-
-	if err != nil {
-		slog.Error("Error reading talks file", "error", err)
-
-		return endpoint.InternalError("could not read talks data")
-	}
-
-	resp, err := endpoint.NewResponseForPayload(data, 3600, h.cacheEnabled, w, r)
-	if err != nil {
-		slog.Error("Error preparing talks response cache", "error", err)
-
-		return endpoint.InternalError("could not prepare talks response")
+// NewTalksHandlerWithLoader serves the talks load returns, e.g. out of the database, rather
+// than the fixture file it falls back to.
+func NewTalksHandlerWithLoader(file string, load func() (payload.TalksResponse, error), cacheEnabled bool) TalksHandler {
+	return TalksHandler{
+		source:       fixtures.NewLoadedSource(file, load, validated[payload.TalksResponse](nil)),
+		cacheEnabled: cacheEnabled,
+		list:         newTalksList(),
 	}
+}
 
-	if resp.HasCache() {
-		resp.RespondWithNotModified()
+// Fixture is the source the router keeps reloading.
+func (h TalksHandler) Fixture() fixtures.Reloader {
+	return h.source
+}
 
-		return nil
-	}
+func (h TalksHandler) Handle(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+	return h.list.serve(w, r, h.source, h.cacheEnabled)
+}
 
-	if err := resp.RespondOk(data); err != nil {
-		slog.Error("Error marshaling JSON for talks response", "error", err)
+// Render is the talks list GET /site embeds, as GET /talks returns it.
+func (h TalksHandler) Render() (listing.Variant, *endpoint.ApiError) {
+	return h.list.render(h.source, nil)
+}
 
-		return endpoint.InternalError("could not encode talks response")
-	}
+func newTalksList() fixtureList[payload.TalksResponse, payload.TalksData] {
+	return newFixtureList(
+		"talks",
+		listing.Engine[payload.TalksData]{
+			Filters: []listing.Filter[payload.TalksData]{
+				{Name: "year", Value: func(item payload.TalksData) string { return listing.Year(item.CreatedAt) }},
+				{Name: "location", Value: func(item payload.TalksData) string { return item.Location }, Match: listing.Partial},
+				{Name: "subject", Value: func(item payload.TalksData) string { return item.Subject }, Match: listing.Partial},
+			},
+			Sorts: []listing.Sort[payload.TalksData]{
+				{Name: "created_at", Date: func(item payload.TalksData) string { return item.CreatedAt }},
+				{Name: "updated_at", Date: func(item payload.TalksData) string { return item.UpdatedAt }},
+			},
+		},
+		func(data payload.TalksResponse) []payload.TalksData { return data.Data },
+		func(data payload.TalksResponse, items []payload.TalksData, page *payload.ListPage) payload.TalksResponse {
+			data.Data, data.ListPage = items, page
 
-	return nil // A nil return indicates success.
+			return data
+		},
+	)
 }
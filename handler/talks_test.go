@@ -1,9 +1,17 @@
 package handler_test
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/oullin/handler"
+	"github.com/oullin/handler/payload"
 )
 
 func TestTalksHandler(t *testing.T) {
@@ -14,3 +22,88 @@ func TestTalksHandler(t *testing.T) {
 		Assert:   handler.AssertFirstUUID("b222d84c-5bbe-4c21-8ba8-a9baa7e5eaa9"),
 	})
 }
+
+func TestTalksHandlerFiltersSortsAndPagesByQuery(t *testing.T) {
+	h := handler.NewTalksHandler("../storage/fixture/talks.json")
+
+	get := func(target, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("If-None-Match", etag)
+		rec := httptest.NewRecorder()
+
+		if err := h.Handle(rec, req); err != nil {
+			t.Fatalf("%s: %v", target, err)
+		}
+
+		return rec
+	}
+
+	all := get("/talks", "")
+	filtered := get("/talks?year=2018&location=singapore&sort=created_at", "")
+
+	var res payload.TalksResponse
+	if err := json.NewDecoder(filtered.Body).Decode(&res); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if len(res.Data) != 2 || res.Data[0].CreatedAt != "2018-10-04" || res.Data[1].CreatedAt != "2018-12-04" {
+		t.Fatalf("expected the 2018 talks oldest first, got %+v", res.Data)
+	}
+
+	if res.ListPage != nil || res.Version == "" {
+		t.Fatalf("expected an unpaginated list with its version, got %+v", res)
+	}
+
+	etag := filtered.Header().Get("ETag")
+	if etag == "" || etag == all.Header().Get("ETag") {
+		t.Fatalf("expected the filtered list to have its own ETag, got %q", etag)
+	}
+
+	if rec := get("/talks?sort=created_at&location=Singapore&year=2018", etag); rec.Code != http.StatusNotModified {
+		t.Fatalf("expected the same query to be not modified, got %d", rec.Code)
+	}
+
+	paged := get("/talks?limit=1&page=2", "")
+	res = payload.TalksResponse{}
+
+	if err := json.NewDecoder(paged.Body).Decode(&res); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if len(res.Data) != 1 || res.ListPage == nil || res.Page != 2 || res.Total != 3 || res.TotalPages != 3 {
+		t.Fatalf("unexpected page %+v", res)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/talks?sort=title", nil)
+	if err := h.Handle(httptest.NewRecorder(), req); err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("expected an unknown sort to be a bad request, got %+v", err)
+	}
+}
+
+func TestTalksHandlerRefusesReloadsThatBreakTheRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "talks.json")
+	write := func(body string, modTime time.Time) {
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatalf("write fixture: %v", err)
+		}
+
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+
+	write(`{"version":"1","data":[{"uuid":"b222d84c-5bbe-4c21-8ba8-a9baa7e5eaa9","title":"Go at scale"}]}`, time.Unix(1000, 0))
+
+	h := handler.NewTalksHandler(path)
+
+	write(`{"version":"2","data":[{"uuid":"not-a-uuid","title":"Go at scale"}]}`, time.Unix(2000, 0))
+
+	if reloaded, err := h.Fixture().Reload(false); reloaded || err == nil || !strings.Contains(err.Error(), "$.data[0].uuid") {
+		t.Fatalf("expected the invalid edit to be refused, got %t %v", reloaded, err)
+	}
+
+	variant, apiErr := h.Render()
+	if apiErr != nil || !strings.Contains(string(variant.Body), `"version":"1"`) {
+		t.Fatalf("expected the last good version to be served, got %s %+v", variant.Body, apiErr)
+	}
+}
@@ -58,6 +58,8 @@ func NewTestDB(t *testing.T) (*database.Connection, database.User) {
 		t,
 		&database.User{},
 		&database.Post{},
+		&database.PostCoverImage{},
+		&database.Media{},
 		&database.Category{},
 		&database.Tag{},
 		&database.PostCategory{},
@@ -0,0 +1,22 @@
+package content
+
+import (
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/metal/router"
+	"github.com/oullin/pkg/portal"
+)
+
+type Handler struct {
+	Content   *repository.Content
+	Fixture   router.Fixture
+	Validator *portal.Validator
+}
+
+func NewHandler(db *database.Connection, fixture router.Fixture, validator *portal.Validator) Handler {
+	return Handler{
+		Content:   &repository.Content{DB: db},
+		Fixture:   fixture,
+		Validator: validator,
+	}
+}
@@ -0,0 +1,207 @@
+package content
+
+import (
+	"fmt"
+
+	"github.com/oullin/database"
+	"github.com/oullin/handler/payload"
+	"github.com/oullin/metal/router"
+	"github.com/oullin/pkg/fixtures"
+)
+
+// Imported is the outcome of importing one section.
+type Imported struct {
+	Section string
+	Version string
+	Items   int
+	Skipped bool // Already in the database; the fixture file was left unread.
+}
+
+type section struct {
+	slug string
+	read func() (version string, rows any, items int, err error)
+}
+
+// Import copies every fixture section that is not in the database yet, each in its own
+// transaction. Nothing is imported unless every fixture file validates, and sections
+// imported before are skipped, so running it again is harmless.
+func (h Handler) Import() ([]Imported, error) {
+	if err := h.Fixture.CheckFixtures(h.Validator); err != nil {
+		return nil, fmt.Errorf("the fixtures are invalid, nothing was imported:\n%w", err)
+	}
+
+	var results []Imported
+
+	for _, current := range h.sections() {
+		existing, err := h.Content.Section(current.slug)
+		if err != nil {
+			return results, err
+		}
+
+		if existing != nil {
+			results = append(results, Imported{Section: current.slug, Version: existing.Version, Skipped: true})
+			continue
+		}
+
+		version, rows, items, err := current.read()
+		if err != nil {
+			return results, fmt.Errorf("read the %s fixture: %w", current.slug, err)
+		}
+
+		if err = h.Content.ImportSection(current.slug, version, rows); err != nil {
+			return results, err
+		}
+
+		results = append(results, Imported{Section: current.slug, Version: version, Items: items})
+	}
+
+	return results, nil
+}
+
+func (h Handler) sections() []section {
+	return []section{
+		{router.FixtureProfile, h.readProfile},
+		{router.FixtureExperience, h.readExperience},
+		{router.FixtureEducation, h.readEducation},
+		{router.FixtureTalks, h.readTalks},
+		{router.FixtureLinks, h.readLinks},
+		{router.FixtureProjects, h.readProjects},
+		{router.FixtureRecommendations, h.readRecommendations},
+	}
+}
+
+func (h Handler) readProfile() (string, any, int, error) {
+	data, err := fixtures.ReadFile[payload.ProfileResponse](h.Fixture.GetProfileFile())
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	profile := database.Profile{}
+	payload.FillProfile(&profile, data.Data)
+
+	return data.Version, &profile, len(profile.Skills), nil
+}
+
+func (h Handler) readExperience() (string, any, int, error) {
+	data, err := fixtures.ReadFile[payload.ExperienceResponse](h.Fixture.GetExperienceFile())
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	rows := make([]database.Experience, len(data.Data))
+
+	for i, item := range data.Data {
+		if err = payload.FillExperience(&rows[i], item); err != nil {
+			return "", nil, 0, fmt.Errorf("item %d: %w", i, err)
+		}
+
+		rows[i].SortOrder = i + 1
+	}
+
+	return data.Version, rowsOf(rows), len(rows), nil
+}
+
+func (h Handler) readEducation() (string, any, int, error) {
+	data, err := fixtures.ReadFile[payload.EducationResponse](h.Fixture.GetEducationFile())
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	rows := make([]database.Education, len(data.Data))
+
+	for i, item := range data.Data {
+		if err = payload.FillEducation(&rows[i], item); err != nil {
+			return "", nil, 0, fmt.Errorf("item %d: %w", i, err)
+		}
+
+		rows[i].SortOrder = i + 1
+	}
+
+	return data.Version, rowsOf(rows), len(rows), nil
+}
+
+func (h Handler) readTalks() (string, any, int, error) {
+	data, err := fixtures.ReadFile[payload.TalksResponse](h.Fixture.GetTalksFile())
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	rows := make([]database.Talk, len(data.Data))
+
+	for i, item := range data.Data {
+		if err = payload.FillTalk(&rows[i], item); err != nil {
+			return "", nil, 0, fmt.Errorf("item %d: %w", i, err)
+		}
+
+		rows[i].SortOrder = i + 1
+	}
+
+	return data.Version, rowsOf(rows), len(rows), nil
+}
+
+func (h Handler) readLinks() (string, any, int, error) {
+	data, err := fixtures.ReadFile[payload.LinksResponse](h.Fixture.GetLinksFile())
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	rows := make([]database.Link, len(data.Data))
+
+	for i, item := range data.Data {
+		if err = payload.FillLink(&rows[i], item); err != nil {
+			return "", nil, 0, fmt.Errorf("item %d: %w", i, err)
+		}
+
+		rows[i].SortOrder = i + 1
+	}
+
+	return data.Version, rowsOf(rows), len(rows), nil
+}
+
+func (h Handler) readProjects() (string, any, int, error) {
+	data, err := fixtures.ReadFile[payload.ProjectsResponse](h.Fixture.GetProjectsFile())
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	rows := make([]database.Project, len(data.Data))
+
+	for i, item := range data.Data {
+		if err = payload.FillProject(&rows[i], item); err != nil {
+			return "", nil, 0, fmt.Errorf("item %d: %w", i, err)
+		}
+
+		rows[i].SortOrder = i + 1
+	}
+
+	return data.Version, rowsOf(rows), len(rows), nil
+}
+
+func (h Handler) readRecommendations() (string, any, int, error) {
+	data, err := fixtures.ReadFile[payload.RecommendationsResponse](h.Fixture.GetRecommendationsFile())
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	rows := make([]database.Recommendation, len(data.Data))
+
+	for i, item := range data.Data {
+		if err = payload.FillRecommendation(&rows[i], item); err != nil {
+			return "", nil, 0, fmt.Errorf("item %d: %w", i, err)
+		}
+
+		rows[i].SortOrder = i + 1
+	}
+
+	return data.Version, rowsOf(rows), len(rows), nil
+}
+
+// rowsOf hands gorm a pointer to the rows, or nothing for an empty section, which it
+// would refuse to insert.
+func rowsOf[M any](rows []M) any {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return &rows
+}
@@ -0,0 +1,59 @@
+package content_test
+
+import (
+	"testing"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/metal/cli/clitest"
+	"github.com/oullin/metal/cli/content"
+	"github.com/oullin/metal/router"
+	"github.com/oullin/pkg/portal"
+)
+
+func TestImportCopiesEverySectionOnce(t *testing.T) {
+	conn := clitest.NewTestConnection(t,
+		&database.ContentSection{},
+		&database.Profile{},
+		&database.ProfileSkill{},
+		&database.Experience{},
+		&database.Education{},
+		&database.Talk{},
+		&database.Link{},
+		&database.Project{},
+		&database.Recommendation{},
+	)
+
+	h := content.NewHandler(conn, router.NewFixtureIn("../../../storage/fixture"), portal.GetDefaultValidator())
+
+	first, err := h.Import()
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	if len(first) != 7 {
+		t.Fatalf("expected seven sections, got %d", len(first))
+	}
+
+	for _, result := range first {
+		if result.Skipped || result.Version == "" {
+			t.Fatalf("expected %s to be imported, got %+v", result.Section, result)
+		}
+	}
+
+	talks, err := repository.ListContent[database.Talk](*h.Content)
+	if err != nil || len(talks) == 0 {
+		t.Fatalf("expected the talks to be imported, got %d (%v)", len(talks), err)
+	}
+
+	second, err := h.Import()
+	if err != nil {
+		t.Fatalf("second import: %v", err)
+	}
+
+	for _, result := range second {
+		if !result.Skipped {
+			t.Fatalf("expected %s to be skipped the second time", result.Section)
+		}
+	}
+}
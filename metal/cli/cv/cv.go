@@ -0,0 +1,179 @@
+package cv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/oullin/handler/listing"
+	"github.com/oullin/handler/payload"
+	"github.com/oullin/pkg/resume"
+)
+
+// Source is where the sections are read from, as the API serves them; *seo.Client is
+// one. Recommendations are expected to be the featured ones only.
+type Source interface {
+	GetProfile() (*payload.ProfileResponse, error)
+	GetExperience() (*payload.ExperienceResponse, error)
+	GetEducation() (*payload.EducationResponse, error)
+	GetTalks() (*payload.TalksResponse, error)
+	GetRecommendations() (*payload.RecommendationsResponse, error)
+}
+
+// CV is what both the HTML page and the PDF are drawn from.
+type CV struct {
+	Name            string
+	Profession      string
+	Email           string
+	SiteURL         string
+	Skills          []string
+	Experience      []Job
+	Education       []School
+	Talks           []Talk
+	Recommendations []Recommendation
+}
+
+type Job struct {
+	Position   string
+	Company    string
+	Period     string
+	Details    string // Location and kind of employment, e.g. Singapore · Contract · On-Site.
+	Paragraphs []string
+	Skills     string
+}
+
+type School struct {
+	Degree     string
+	School     string
+	Details    string
+	Paragraphs []string
+}
+
+type Talk struct {
+	Title   string
+	Details string
+	URL     string
+}
+
+type Recommendation struct {
+	Author     string
+	Role       string
+	Paragraphs []string
+}
+
+// Read fetches every section the CV is made of.
+func Read(source Source, siteURL string) (CV, error) {
+	profile, err := source.GetProfile()
+	if err != nil {
+		return CV{}, err
+	}
+
+	experience, err := source.GetExperience()
+	if err != nil {
+		return CV{}, err
+	}
+
+	education, err := source.GetEducation()
+	if err != nil {
+		return CV{}, err
+	}
+
+	talks, err := source.GetTalks()
+	if err != nil {
+		return CV{}, err
+	}
+
+	recommendations, err := source.GetRecommendations()
+	if err != nil {
+		return CV{}, err
+	}
+
+	return New(*profile, *experience, *education, *talks, *recommendations, siteURL), nil
+}
+
+func New(
+	profile payload.ProfileResponse,
+	experience payload.ExperienceResponse,
+	education payload.EducationResponse,
+	talks payload.TalksResponse,
+	recommendations payload.RecommendationsResponse,
+	siteURL string,
+) CV {
+	cv := CV{
+		Name:       profile.Data.Name,
+		Profession: profile.Data.Profession,
+		Email:      profile.Data.Email,
+		SiteURL:    strings.TrimSuffix(siteURL, "/"),
+	}
+
+	for _, skill := range profile.Data.Skills {
+		cv.Skills = append(cv.Skills, skill.Item)
+	}
+
+	for _, item := range experience.Data {
+		end := item.EndDate
+		if strings.TrimSpace(end) == "" {
+			end = "Present"
+		}
+
+		cv.Experience = append(cv.Experience, Job{
+			Position:   item.Position,
+			Company:    item.Company,
+			Period:     fmt.Sprintf("%s – %s", item.StartDate, end),
+			Details:    details(item.City, item.Country, item.EmploymentType, item.LocationType),
+			Paragraphs: resume.Paragraphs(item.Summary),
+			Skills:     strings.TrimSuffix(strings.TrimSpace(item.Skills), "."),
+		})
+	}
+
+	for _, item := range education.Data {
+		cv.Education = append(cv.Education, School{
+			Degree:     details(item.Degree, item.Field),
+			School:     item.School,
+			Details:    details(item.IssuingCountry, item.GraduatedAt),
+			Paragraphs: resume.Paragraphs(item.Description),
+		})
+	}
+
+	for _, item := range talks.Data {
+		cv.Talks = append(cv.Talks, Talk{
+			Title:   item.Title,
+			Details: details(item.Subject, item.Location, monthOf(item.CreatedAt)),
+			URL:     item.URL,
+		})
+	}
+
+	for _, item := range recommendations.Data {
+		role := item.Person.Designation
+		if item.Person.Company != "" {
+			role = details(role, item.Person.Company)
+		}
+
+		cv.Recommendations = append(cv.Recommendations, Recommendation{
+			Author:     item.Person.FullName,
+			Role:       role,
+			Paragraphs: resume.Paragraphs(item.Text),
+		})
+	}
+
+	return cv
+}
+
+// Contact is the line under the name.
+func (c CV) Contact() string {
+	return details(c.Email, strings.TrimPrefix(strings.TrimPrefix(c.SiteURL, "https://"), "http://"))
+}
+
+// details joins the values the way every secondary line of the CV reads.
+func details(values ...string) string {
+	return resume.JoinNonEmpty(" · ", values...)
+}
+
+// monthOf reads a fixture date as e.g. February 2019.
+func monthOf(date string) string {
+	parsed, ok := listing.ParseDate(date)
+	if !ok {
+		return ""
+	}
+
+	return parsed.Format("January 2006")
+}
@@ -0,0 +1,93 @@
+package cv_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oullin/handler/payload"
+	"github.com/oullin/metal/cli/cv"
+	"github.com/oullin/metal/cli/seo"
+	"github.com/oullin/metal/router"
+)
+
+func TestNewShapesTheSections(t *testing.T) {
+	doc := cv.New(
+		payload.ProfileResponse{Data: payload.ProfileDataResponse{
+			Name:   "Gustavo Ocanto",
+			Email:  "gus@oullin.io",
+			Skills: []payload.ProfileSkillsResponse{{Item: "Go"}, {Item: "PHP"}},
+		}},
+		payload.ExperienceResponse{Data: []payload.ExperienceData{
+			{Company: "Silverlake", Position: "Technical Lead", StartDate: "June, 2025", City: "Singapore", Country: "Singapore", EmploymentType: "Contract", Summary: "Led a squad.<br/><br/>Shipped.", Skills: "Go, Kafka."},
+		}},
+		payload.EducationResponse{Data: []payload.EducationData{
+			{School: "UAH", Degree: "Bachelor's degree", Field: "Computer Science", GraduatedAt: "2012", IssuingCountry: "Venezuela"},
+		}},
+		payload.TalksResponse{Data: []payload.TalksData{
+			{Title: "Deprecating APIs", Subject: "PHP APIs", Location: "Singapore", CreatedAt: "2019-02-11"},
+		}},
+		payload.RecommendationsResponse{Data: []payload.RecommendationsData{
+			{Text: "Great <script>lead</script>.", Person: payload.RecommendationsPersonData{FullName: "Jane Doe", Designation: "CTO", Company: "Acme"}},
+		}},
+		"https://oullin.io/",
+	)
+
+	job := doc.Experience[0]
+	if job.Period != "June, 2025 – Present" || job.Details != "Singapore · Contract" || len(job.Paragraphs) != 2 || job.Skills != "Go, Kafka" {
+		t.Fatalf("unexpected job %+v", job)
+	}
+
+	if doc.Education[0].Degree != "Bachelor's degree · Computer Science" || doc.Education[0].Details != "Venezuela · 2012" {
+		t.Fatalf("unexpected education %+v", doc.Education[0])
+	}
+
+	if doc.Talks[0].Details != "PHP APIs · Singapore · February 2019" {
+		t.Fatalf("unexpected talk %+v", doc.Talks[0])
+	}
+
+	if doc.Recommendations[0].Role != "CTO · Acme" || doc.Contact() != "gus@oullin.io · oullin.io" {
+		t.Fatalf("unexpected recommendation %+v or contact %q", doc.Recommendations[0], doc.Contact())
+	}
+
+	html, err := cv.HTML(doc)
+	if err != nil {
+		t.Fatalf("html: %v", err)
+	}
+
+	for _, want := range []string{"<h1>Gustavo Ocanto</h1>", "@page { size: A4", "<p>Shipped.</p>", "Great &lt;script&gt;lead&lt;/script&gt;."} {
+		if !strings.Contains(string(html), want) {
+			t.Fatalf("expected %q in\n%s", want, html)
+		}
+	}
+}
+
+func TestGenerateWritesTheHTMLAndPDFFromTheFixtures(t *testing.T) {
+	client := seo.NewClient(&router.WebsiteRoutes{Fixture: router.NewFixtureIn(filepath.Join("..", "..", "..", "storage", "fixture"))})
+	dir := t.TempDir()
+
+	paths, err := cv.NewGenerator(client, "https://oullin.io", dir).Generate()
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	if len(paths) != 2 || paths[0] != filepath.Join(dir, "cv.html") || paths[1] != filepath.Join(dir, "cv.pdf") {
+		t.Fatalf("unexpected paths %v", paths)
+	}
+
+	html, _ := os.ReadFile(paths[0])
+	if !bytes.Contains(html, []byte("Silverlake")) {
+		t.Fatalf("expected the experience in the html")
+	}
+
+	data, _ := os.ReadFile(paths[1])
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("expected a pdf document")
+	}
+
+	if bytes.Contains(data, []byte("/Count 1 ")) {
+		t.Fatalf("expected the fixtures to flow onto more than one page")
+	}
+}
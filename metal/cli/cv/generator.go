@@ -0,0 +1,64 @@
+package cv
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// OutputDir is where the CV is written unless another directory is given.
+const OutputDir = "./storage/cv"
+
+type Generator struct {
+	Source    Source
+	SiteURL   string
+	OutputDir string
+}
+
+func NewGenerator(source Source, siteURL, outputDir string) Generator {
+	if outputDir == "" {
+		outputDir = OutputDir
+	}
+
+	return Generator{
+		Source:    source,
+		SiteURL:   siteURL,
+		OutputDir: outputDir,
+	}
+}
+
+// Generate writes cv.html and cv.pdf into the output directory and returns their paths.
+func (g Generator) Generate() ([]string, error) {
+	cv, err := Read(g.Source, g.SiteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.MkdirAll(g.OutputDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create %s: %w", g.OutputDir, err)
+	}
+
+	var paths []string
+
+	for _, file := range []struct {
+		name   string
+		render func(CV) ([]byte, error)
+	}{
+		{"cv.html", HTML},
+		{"cv.pdf", PDF},
+	} {
+		data, err := file.render(cv)
+		if err != nil {
+			return paths, fmt.Errorf("render %s: %w", file.name, err)
+		}
+
+		path := filepath.Join(g.OutputDir, file.name)
+		if err = os.WriteFile(path, data, 0o644); err != nil {
+			return paths, fmt.Errorf("write %s: %w", path, err)
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,209 @@
+package cv
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/oullin/pkg/pdf"
+)
+
+const TemplatePath = "cv.html"
+
+//go:embed cv.html
+var templatesFS embed.FS
+
+// HTML renders the CV as a standalone page, styles included, that prints onto A4.
+func HTML(cv CV) ([]byte, error) {
+	raw, err := templatesFS.ReadFile(TemplatePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading template: %w", err)
+	}
+
+	tmpl, err := template.
+		New("cv").
+		Funcs(template.FuncMap{"join": strings.Join}).
+		Parse(string(raw))
+
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+
+	var out bytes.Buffer
+	if err = tmpl.Execute(&out, cv); err != nil {
+		return nil, fmt.Errorf("rendering template: %w", err)
+	}
+
+	return out.Bytes(), nil
+}
+
+// PDF lays the CV out on A4 pages with the same sections as the HTML page.
+func PDF(cv CV) ([]byte, error) {
+	l := &layout{doc: pdf.New(cv.Name + " - CV")}
+	l.newPage()
+
+	l.text(cv.Name, pdf.HelveticaBold, 24, ink, 0)
+	l.text(cv.Profession, pdf.Helvetica, 13, ink, 0)
+	l.gap(2)
+	l.text(cv.Contact(), pdf.Helvetica, 10, muted, 0)
+	l.gap(4)
+	l.doc.Line(margin, l.y, pdf.PageWidth-margin, l.y, 1.5, accent)
+	l.gap(4)
+
+	if len(cv.Skills) > 0 {
+		l.heading("Skills")
+		l.text(strings.Join(cv.Skills, " · "), pdf.Helvetica, 10, ink, 0)
+	}
+
+	if len(cv.Experience) > 0 {
+		l.heading("Experience")
+
+		for _, job := range cv.Experience {
+			l.entry(job.Position+", "+job.Company, job.Period)
+			l.text(job.Details, pdf.Helvetica, 9.5, muted, 0)
+			l.paragraphs(job.Paragraphs, pdf.Helvetica, 0)
+			l.text(job.Skills, pdf.Helvetica, 9, muted, 0)
+			l.gap(8)
+		}
+	}
+
+	if len(cv.Education) > 0 {
+		l.heading("Education")
+
+		for _, school := range cv.Education {
+			l.entry(school.Degree, school.Details)
+			l.text(school.School, pdf.Helvetica, 9.5, muted, 0)
+			l.paragraphs(school.Paragraphs, pdf.Helvetica, 0)
+			l.gap(8)
+		}
+	}
+
+	if len(cv.Talks) > 0 {
+		l.heading("Talks")
+
+		for _, talk := range cv.Talks {
+			l.entry(talk.Title, "")
+			l.text(talk.Details, pdf.Helvetica, 9.5, muted, 0)
+			l.text(talk.URL, pdf.Helvetica, 9, accent, 0)
+			l.gap(6)
+		}
+	}
+
+	if len(cv.Recommendations) > 0 {
+		l.heading("Recommendations")
+
+		for _, recommendation := range cv.Recommendations {
+			// Kept on one page unless it takes more than half of one.
+			l.room(min(l.height(recommendation.Paragraphs, pdf.HelveticaOblique, 12)+leading(9.5), (pdf.PageHeight-2*margin)/2))
+			l.paragraphs(recommendation.Paragraphs, pdf.HelveticaOblique, 12)
+			l.text(details(recommendation.Author, recommendation.Role), pdf.Helvetica, 9.5, muted, 12)
+			l.gap(10)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := l.doc.Write(&out); err != nil {
+		return nil, fmt.Errorf("writing pdf: %w", err)
+	}
+
+	return out.Bytes(), nil
+}
+
+const (
+	margin   = 50.0
+	bodySize = 10.0
+)
+
+var (
+	ink    = pdf.Color{R: 31, G: 41, B: 51}
+	muted  = pdf.Color{R: 98, G: 125, B: 152}
+	accent = pdf.Color{R: 31, G: 111, B: 139}
+	rule   = pdf.Color{R: 217, G: 226, B: 236}
+)
+
+// layout flows text down the page from the cursor y, starting a new page whenever the
+// next line would not fit above the bottom margin.
+type layout struct {
+	doc *pdf.Document
+	y   float64
+}
+
+func leading(size float64) float64 {
+	return size * 1.4
+}
+
+func (l *layout) newPage() {
+	l.doc.AddPage()
+	l.y = margin
+}
+
+// room starts a new page unless height fits on this one.
+func (l *layout) room(height float64) {
+	if l.y+height > pdf.PageHeight-margin {
+		l.newPage()
+	}
+}
+
+func (l *layout) gap(height float64) {
+	l.y += height
+}
+
+func (l *layout) text(text string, font pdf.Font, size float64, color pdf.Color, indent float64) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	for _, line := range pdf.Wrap(text, font, size, pdf.PageWidth-2*margin-indent) {
+		l.room(leading(size))
+		l.doc.Text(margin+indent, l.y+size, font, size, color, line)
+		l.gap(leading(size))
+	}
+}
+
+func (l *layout) paragraphs(paragraphs []string, font pdf.Font, indent float64) {
+	for _, paragraph := range paragraphs {
+		l.gap(3)
+		l.text(paragraph, font, bodySize, ink, indent)
+	}
+}
+
+// height is how far paragraphs would move the cursor.
+func (l *layout) height(paragraphs []string, font pdf.Font, indent float64) float64 {
+	height := 0.0
+
+	for _, paragraph := range paragraphs {
+		height += 3 + float64(len(pdf.Wrap(paragraph, font, bodySize, pdf.PageWidth-2*margin-indent)))*leading(bodySize)
+	}
+
+	return height
+}
+
+// heading starts a section, keeping it together with the first lines that follow.
+func (l *layout) heading(title string) {
+	l.gap(14)
+	l.room(60)
+	l.text(strings.ToUpper(title), pdf.HelveticaBold, 11, accent, 0)
+	l.doc.Line(margin, l.y, pdf.PageWidth-margin, l.y, 0.75, rule)
+	l.gap(8)
+}
+
+// entry writes an entry title with the period aligned to the right of its first line.
+func (l *layout) entry(title, period string) {
+	const size = 11
+
+	periodWidth := pdf.TextWidth(period, pdf.Helvetica, 9.5)
+	lines := pdf.Wrap(title, pdf.HelveticaBold, size, pdf.PageWidth-2*margin-periodWidth-12)
+
+	l.room(float64(len(lines)+2) * leading(size))
+
+	if period != "" {
+		l.doc.Text(pdf.PageWidth-margin-periodWidth, l.y+size, pdf.Helvetica, 9.5, muted, period)
+	}
+
+	for _, line := range lines {
+		l.doc.Text(margin, l.y+size, pdf.HelveticaBold, size, ink, line)
+		l.gap(leading(size))
+	}
+}
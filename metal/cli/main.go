@@ -10,13 +10,20 @@ import (
 
 	"github.com/oullin/database"
 	"github.com/oullin/metal/cli/accounts"
+	"github.com/oullin/metal/cli/content"
+	"github.com/oullin/metal/cli/cv"
+	climedia "github.com/oullin/metal/cli/media"
 	"github.com/oullin/metal/cli/panel"
 	"github.com/oullin/metal/cli/posts"
 	"github.com/oullin/metal/cli/seo"
+	"github.com/oullin/metal/cli/trash"
 	"github.com/oullin/metal/env"
 	"github.com/oullin/metal/kernel"
+	"github.com/oullin/metal/router"
 	"github.com/oullin/pkg/cli"
+	"github.com/oullin/pkg/media"
 	"github.com/oullin/pkg/portal"
+	"github.com/oullin/pkg/storage"
 )
 
 func main() {
@@ -31,6 +38,10 @@ func main() {
 }
 
 func run() error {
+	if len(os.Args) > 1 && os.Args[1] == "validate-fixtures" {
+		return validateFixtures(os.Args[2:])
+	}
+
 	cli.ClearScreen()
 
 	validate := portal.GetDefaultValidator()
@@ -49,6 +60,19 @@ func run() error {
 	}
 	defer dbConn.Close()
 
+	if len(os.Args) > 1 && os.Args[1] == "import-fixtures" {
+		return importFixtures(dbConn, validate)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "cv" {
+		return generateCV(dbConn, environment, os.Args[2:])
+	}
+
+	store, err := media.NewStorage(environment.Storage)
+	if err != nil {
+		return fmt.Errorf("open media storage: %w", err)
+	}
+
 	menu := panel.NewMenu()
 
 	for {
@@ -59,7 +83,7 @@ func run() error {
 
 		switch menu.GetChoice() {
 		case 1:
-			if err := createBlogPost(menu, dbConn); err != nil {
+			if err := createBlogPost(menu, dbConn, store); err != nil {
 				return err
 			}
 		case 2:
@@ -86,6 +110,38 @@ func run() error {
 			if err := printTimestamp(); err != nil {
 				return err
 			}
+		case 8:
+			if err := listTrash(menu, dbConn); err != nil {
+				return err
+			}
+		case 9:
+			if err := restoreTrashedItem(menu, dbConn); err != nil {
+				return err
+			}
+		case 10:
+			if err := purgeTrash(menu, dbConn); err != nil {
+				return err
+			}
+		case 11:
+			if err := listMedia(menu, dbConn, store); err != nil {
+				return err
+			}
+		case 12:
+			if err := reconcileMedia(menu, dbConn, store); err != nil {
+				return err
+			}
+		case 13:
+			if err := validateFixtures(nil); err != nil {
+				cli.Errorln(err.Error())
+			}
+		case 14:
+			if err := importFixtures(dbConn, validate); err != nil {
+				cli.Errorln(err.Error())
+			}
+		case 15:
+			if err := generateCV(dbConn, environment, nil); err != nil {
+				cli.Errorln(err.Error())
+			}
 		case 0:
 			cli.Successln("Goodbye!")
 			return nil
@@ -99,14 +155,14 @@ func run() error {
 	}
 }
 
-func createBlogPost(menu panel.Menu, dbConn *database.Connection) error {
+func createBlogPost(menu panel.Menu, dbConn *database.Connection, store storage.Storage) error {
 	input, err := menu.CapturePostURL()
 	if err != nil {
 		return err
 	}
 
 	httpClient := portal.NewDefaultClient(nil)
-	handler := posts.NewHandler(input, httpClient, dbConn)
+	handler := posts.NewHandler(input, httpClient, dbConn, store)
 
 	if _, err = handler.NotParsed(); err != nil {
 		return err
@@ -192,6 +248,142 @@ func newSEOGenerator(dbConn *database.Connection, environment *env.Environment)
 	return gen, nil
 }
 
+func listTrash(menu panel.Menu, dbConn *database.Connection) error {
+	kind, err := menu.CaptureTrashKind(true)
+	if err != nil {
+		return err
+	}
+
+	return trash.NewHandler(dbConn).List(kind)
+}
+
+func restoreTrashedItem(menu panel.Menu, dbConn *database.Connection) error {
+	kind, err := menu.CaptureTrashKind(false)
+	if err != nil {
+		return err
+	}
+
+	identifier, err := menu.CaptureTrashIdentifier()
+	if err != nil {
+		return err
+	}
+
+	return trash.NewHandler(dbConn).Restore(kind, identifier)
+}
+
+func purgeTrash(menu panel.Menu, dbConn *database.Connection) error {
+	days, err := menu.CaptureRetentionDays()
+	if err != nil {
+		return err
+	}
+
+	return trash.NewHandler(dbConn).Purge(days)
+}
+
+func listMedia(menu panel.Menu, dbConn *database.Connection, store storage.Storage) error {
+	search, err := menu.CaptureMediaSearch()
+	if err != nil {
+		return err
+	}
+
+	return climedia.NewHandler(dbConn, store).List(search)
+}
+
+func reconcileMedia(menu panel.Menu, dbConn *database.Connection, store storage.Storage) error {
+	handler := climedia.NewHandler(dbConn, store)
+
+	report, err := handler.Reconcile()
+	if err != nil {
+		return err
+	}
+
+	handler.PrintReport(report)
+
+	if !report.CanPrune() {
+		return nil
+	}
+
+	prune, err := menu.CaptureConfirmation("Delete the orphaned files and dangling rows?")
+	if err != nil || !prune {
+		return err
+	}
+
+	return handler.Prune(report)
+}
+
+// validateFixtures checks the fixtures in the given directory, ./storage/fixture by
+// default, and prints every broken rule with the JSON path it was found at.
+func validateFixtures(args []string) error {
+	fixture := router.NewFixture()
+	if len(args) > 0 {
+		fixture = router.NewFixtureIn(args[0])
+	}
+
+	failed := 0
+
+	for _, report := range fixture.ValidateFixtures(portal.GetDefaultValidator()) {
+		if report.Passes() {
+			cli.Successln(fmt.Sprintf("%s: ok", report.File))
+			continue
+		}
+
+		failed++
+
+		if report.Err != nil {
+			cli.Errorln(report.Err.Error())
+		}
+
+		for _, violation := range report.Violations {
+			cli.Errorln(fmt.Sprintf("%s %s: %s", report.File, violation.Path, violation.Message))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d fixture file(s) failed validation", failed)
+	}
+
+	return nil
+}
+
+// importFixtures copies the fixture sections that are not in the database yet; the
+// public routes read them from there afterwards.
+func importFixtures(dbConn *database.Connection, validate *portal.Validator) error {
+	handler := content.NewHandler(dbConn, router.NewFixture(), validate)
+
+	results, err := handler.Import()
+
+	for _, result := range results {
+		if result.Skipped {
+			cli.Warningln(fmt.Sprintf("%s: already imported (version %s), skipped", result.Section, result.Version))
+			continue
+		}
+
+		cli.Successln(fmt.Sprintf("%s: imported %d item(s) at version %s", result.Section, result.Items, result.Version))
+	}
+
+	return err
+}
+
+// generateCV writes the CV as HTML and PDF into the given directory, ./storage/cv by
+// default, reading each section the way the API serves it.
+func generateCV(dbConn *database.Connection, environment *env.Environment, args []string) error {
+	dir := ""
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	routes := router.NewWebsiteRoutes(environment)
+	client := seo.NewClient(routes).WithContent(router.NewContent(dbConn, routes.Fixture))
+
+	paths, err := cv.NewGenerator(client, environment.App.URL, dir).Generate()
+
+	for _, path := range paths {
+		cli.Successln(fmt.Sprintf("wrote %s", path))
+	}
+
+	return err
+}
+
 func printTimestamp() error {
 	now := time.Now()
 
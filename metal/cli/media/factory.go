@@ -0,0 +1,19 @@
+package media
+
+import (
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/pkg/storage"
+)
+
+type Handler struct {
+	Media   *repository.Media
+	Storage storage.Storage
+}
+
+func NewHandler(db *database.Connection, store storage.Storage) Handler {
+	return Handler{
+		Media:   &repository.Media{DB: db},
+		Storage: store,
+	}
+}
@@ -0,0 +1,39 @@
+package media
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/oullin/database/repository/pagination"
+	"github.com/oullin/database/repository/queries"
+	"github.com/oullin/pkg/cli"
+)
+
+// List prints the most recent media matching the given search, newest first.
+func (h Handler) List(search string) error {
+	result, err := h.Media.GetAll(
+		queries.MediaFilters{Text: search},
+		pagination.Paginate{Page: pagination.MinPage, Limit: pagination.MediaMaxLimit},
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to list the media: %v", err)
+	}
+
+	if len(result.Data) == 0 {
+		cli.Warningln("\nNo media found.\n")
+
+		return nil
+	}
+
+	cli.Successln(fmt.Sprintf("\nShowing %d of %d media file(s):\n", len(result.Data), result.Total))
+
+	for _, item := range result.Data {
+		cli.Blueln("   > " + fmt.Sprintf("[%s] %s", item.UUID, item.Path))
+		cli.Grayln("     " + fmt.Sprintf("%s %dx%d, %d bytes, by %s at %s", item.MimeType, item.Width, item.Height, item.Size, item.Owner, item.CreatedAt.Format(time.RFC3339)))
+	}
+
+	fmt.Println(" ")
+
+	return nil
+}
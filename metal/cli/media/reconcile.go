@@ -0,0 +1,172 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/oullin/database"
+	"github.com/oullin/pkg/cli"
+	"github.com/oullin/pkg/media"
+)
+
+// Report lists the differences between the files in the media storage and their index.
+type Report struct {
+	Orphaned   []string         // Stored files that nothing points at.
+	Referenced []string         // Unindexed files a post body still links to; never pruned.
+	Dangling   []database.Media // Media rows whose file is gone.
+}
+
+func (r Report) IsClean() bool {
+	return len(r.Orphaned) == 0 && len(r.Referenced) == 0 && len(r.Dangling) == 0
+}
+
+// CanPrune reports whether Prune has anything to remove.
+func (r Report) CanPrune() bool {
+	return len(r.Orphaned) > 0 || len(r.Dangling) > 0
+}
+
+// Reconcile compares the uploads and posts directories with the media and post cover
+// image tables. It only reads; Prune applies the result. Avatars are not indexed, so the
+// users directory is left out. Unindexed files a post body links to, e.g. an image whose
+// media row failed to be written while mirroring it, are reported apart so they are kept.
+func (h Handler) Reconcile() (Report, error) {
+	var report Report
+
+	indexed, err := h.Media.GetPaths()
+	if err != nil {
+		return report, err
+	}
+
+	covers, err := h.Media.GetCoverImagePaths()
+	if err != nil {
+		return report, err
+	}
+
+	known := make(map[string]bool, len(indexed)+len(covers))
+	for _, rel := range append(indexed, covers...) {
+		known[rel] = true
+	}
+
+	contents, err := h.Media.GetPostContents()
+	if err != nil {
+		return report, err
+	}
+
+	onDisk := make(map[string]bool)
+
+	for _, dir := range []string{media.UploadsDir, media.PostsDir} {
+		files, err := h.list(dir)
+		if err != nil {
+			return report, err
+		}
+
+		for _, rel := range files {
+			onDisk[rel] = true
+
+			switch {
+			case known[rel]:
+			case isReferenced(rel, contents):
+				report.Referenced = append(report.Referenced, rel)
+			default:
+				report.Orphaned = append(report.Orphaned, rel)
+			}
+		}
+	}
+
+	for _, rel := range indexed {
+		if onDisk[rel] {
+			continue
+		}
+
+		if item := h.Media.FindByPath(rel); item != nil {
+			report.Dangling = append(report.Dangling, *item)
+		}
+	}
+
+	slices.Sort(report.Orphaned)
+	slices.Sort(report.Referenced)
+
+	return report, nil
+}
+
+// isReferenced reports whether any of the post bodies links to the stored file, whether by
+// its public URL or an absolute one.
+func isReferenced(rel string, contents []string) bool {
+	for _, content := range contents {
+		if strings.Contains(content, rel) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Prune deletes the orphaned files and the dangling rows of the given report. Referenced
+// files are left for the admin to index or unlink.
+func (h Handler) Prune(report Report) error {
+	ctx := context.Background()
+
+	for _, rel := range report.Orphaned {
+		if err := h.Storage.Delete(ctx, rel); err != nil {
+			return fmt.Errorf("failed to remove the orphaned file [%s]: %v", rel, err)
+		}
+	}
+
+	for _, item := range report.Dangling {
+		if err := h.Media.Delete(item); err != nil {
+			return err
+		}
+	}
+
+	cli.Successln(fmt.Sprintf("\nRemoved %d orphaned file(s) and %d dangling row(s).\n", len(report.Orphaned), len(report.Dangling)))
+
+	return nil
+}
+
+func (h Handler) PrintReport(report Report) {
+	if report.IsClean() {
+		cli.Successln("\nThe media storage and its index are in sync.\n")
+
+		return
+	}
+
+	cli.Warningln(fmt.Sprintf("\nFound %d orphaned file(s) in storage:", len(report.Orphaned)))
+
+	for _, rel := range report.Orphaned {
+		cli.Grayln("   > " + rel)
+	}
+
+	if len(report.Referenced) > 0 {
+		cli.Warningln(fmt.Sprintf("\nFound %d unindexed file(s) still linked from posts; they are kept:", len(report.Referenced)))
+
+		for _, rel := range report.Referenced {
+			cli.Grayln("   > " + rel)
+		}
+	}
+
+	cli.Warningln(fmt.Sprintf("\nFound %d dangling media row(s):", len(report.Dangling)))
+
+	for _, item := range report.Dangling {
+		cli.Grayln("   > " + fmt.Sprintf("[%s] %s", item.UUID, item.Path))
+	}
+
+	fmt.Println(" ")
+}
+
+// list returns the keys stored under the given media sub-directory. Dot files, such as
+// .gitkeep or interrupted writes, are never listed by the storage.
+func (h Handler) list(dir string) ([]string, error) {
+	objects, err := h.Storage.List(context.Background(), dir+"/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the media directory [%s]: %v", dir, err)
+	}
+
+	files := make([]string, 0, len(objects))
+	for _, object := range objects {
+		files = append(files, object.Key)
+	}
+
+	return files, nil
+}
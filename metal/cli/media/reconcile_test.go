@@ -0,0 +1,109 @@
+package media_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/metal/cli/clitest"
+	climedia "github.com/oullin/metal/cli/media"
+	"github.com/oullin/pkg/storage"
+)
+
+func writeMediaFile(t *testing.T, dir, rel string) {
+	t.Helper()
+
+	file := filepath.Join(dir, filepath.FromSlash(rel))
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	if err := os.WriteFile(file, []byte("img"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func TestReconcileAndPrune(t *testing.T) {
+	conn := clitest.NewTestConnection(t,
+		&database.User{},
+		&database.Post{},
+		&database.PostCoverImage{},
+		&database.Media{},
+	)
+
+	root := t.TempDir()
+	h := climedia.Handler{Media: &repository.Media{DB: conn}, Storage: storage.NewLocal(root, "/media")}
+
+	for _, rel := range []string{
+		"uploads/.gitkeep",
+		"uploads/kept.png",
+		"uploads/orphan.png",
+		"posts/hello/covers/hello-abc-480w.webp",
+		"posts/hello/inline.png",
+		"users/avatar.png",
+	} {
+		writeMediaFile(t, root, rel)
+	}
+
+	for _, rel := range []string{"uploads/kept.png", "uploads/gone.png"} {
+		row := database.Media{UUID: uuid.NewString(), Path: rel, MimeType: "image/png", Sha256: strings.Repeat("a", 64), Owner: "gus"}
+
+		if err := conn.Sql().Create(&row).Error; err != nil {
+			t.Fatalf("create media: %v", err)
+		}
+	}
+
+	user := database.User{UUID: uuid.NewString(), Username: "gus", FirstName: "G", LastName: "O", Email: "gus@example.com", PasswordHash: "x"}
+	if err := conn.Sql().Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	post := database.Post{UUID: uuid.NewString(), AuthorID: user.ID, Slug: "hello", Title: "Hello", Excerpt: "Ex", Content: "Body ![inline](/media/posts/hello/inline.png)"}
+	if err := conn.Sql().Create(&post).Error; err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+
+	cover := database.PostCoverImage{PostID: post.ID, Format: "webp", Width: 480, Height: 270, MimeType: "image/webp", Path: "posts/hello/covers/hello-abc-480w.webp"}
+	if err := conn.Sql().Create(&cover).Error; err != nil {
+		t.Fatalf("create cover: %v", err)
+	}
+
+	report, err := h.Reconcile()
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	if len(report.Orphaned) != 1 || report.Orphaned[0] != "uploads/orphan.png" {
+		t.Fatalf("unexpected orphans %v", report.Orphaned)
+	}
+
+	if len(report.Referenced) != 1 || report.Referenced[0] != "posts/hello/inline.png" {
+		t.Fatalf("expected the linked file to be reported apart, got %v", report.Referenced)
+	}
+
+	if len(report.Dangling) != 1 || report.Dangling[0].Path != "uploads/gone.png" {
+		t.Fatalf("unexpected dangling rows %+v", report.Dangling)
+	}
+
+	if err = h.Prune(report); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+
+	if _, err = os.Stat(filepath.Join(root, "uploads", "orphan.png")); !os.IsNotExist(err) {
+		t.Fatalf("expected the orphan to be removed, got %v", err)
+	}
+
+	if _, err = os.Stat(filepath.Join(root, "posts", "hello", "inline.png")); err != nil {
+		t.Fatalf("expected the linked file to be kept, got %v", err)
+	}
+
+	if report, err = h.Reconcile(); err != nil || report.CanPrune() || len(report.Referenced) != 1 {
+		t.Fatalf("expected nothing left to prune, got %+v err %v", report, err)
+	}
+}
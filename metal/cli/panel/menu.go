@@ -6,12 +6,15 @@ import (
 	"net/url"
 	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
 	"golang.org/x/term"
 
+	"github.com/oullin/database/repository"
 	"github.com/oullin/metal/cli/posts"
+	"github.com/oullin/metal/cli/trash"
 	"github.com/oullin/pkg/auth"
 	"github.com/oullin/pkg/cli"
 	"github.com/oullin/pkg/portal"
@@ -101,6 +104,24 @@ func (p *Menu) Print() {
 	p.PrintOption(fmt.Sprintf("%s---------------------%s", cli.Reset, cli.CyanColour), inner)
 	p.PrintOption(" ", inner)
 	p.PrintOption("7) Print Timestamp.", inner)
+	p.PrintOption(" ", inner)
+	p.PrintOption(fmt.Sprintf("%s------- Trash -------%s", cli.Reset, cli.CyanColour), inner)
+	p.PrintOption("8) List trashed items.", inner)
+	p.PrintOption("9) Restore trashed item.", inner)
+	p.PrintOption("10) Purge trash.", inner)
+	p.PrintOption(fmt.Sprintf("%s---------------------%s", cli.Reset, cli.CyanColour), inner)
+	p.PrintOption(" ", inner)
+	p.PrintOption(fmt.Sprintf("%s------- Media -------%s", cli.Reset, cli.CyanColour), inner)
+	p.PrintOption("11) List media.", inner)
+	p.PrintOption("12) Reconcile media storage.", inner)
+	p.PrintOption(fmt.Sprintf("%s---------------------%s", cli.Reset, cli.CyanColour), inner)
+	p.PrintOption(" ", inner)
+	p.PrintOption(fmt.Sprintf("%s------ Content ------%s", cli.Reset, cli.CyanColour), inner)
+	p.PrintOption("13) Validate fixtures.", inner)
+	p.PrintOption("14) Import fixtures into the database.", inner)
+	p.PrintOption("15) Generate CV (HTML and PDF).", inner)
+	p.PrintOption(fmt.Sprintf("%s---------------------%s", cli.Reset, cli.CyanColour), inner)
+	p.PrintOption(" ", inner)
 	p.PrintOption("0) Exit.", inner)
 
 	fmt.Println(footer + cli.Reset)
@@ -211,3 +232,90 @@ func (p *Menu) CapturePostSlug() (string, error) {
 
 	return slug, nil
 }
+
+// CaptureTrashKind reads one of the trash kinds. An empty answer is only accepted
+// when allowAll is set, and stands for every kind.
+func (p *Menu) CaptureTrashKind(allowAll bool) (string, error) {
+	kinds := repository.GetTrashKinds()
+
+	fmt.Printf("Enter the trash kind (%s): ", strings.Join(kinds, ", "))
+
+	kind, err := p.Reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("%sError reading the trash kind: %v %s", cli.RedColour, err, cli.Reset)
+	}
+
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if kind == "" && allowAll {
+		return "", nil
+	}
+
+	if !slices.Contains(kinds, kind) {
+		return "", fmt.Errorf("%sError: the trash kind must be one of [%s]: %s", cli.RedColour, strings.Join(kinds, ", "), cli.Reset)
+	}
+
+	return kind, nil
+}
+
+func (p *Menu) CaptureTrashIdentifier() (string, error) {
+	fmt.Print("Enter the slug or UUID of the trashed item: ")
+
+	identifier, err := p.Reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("%sError reading the trashed item identifier: %v %s", cli.RedColour, err, cli.Reset)
+	}
+
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return "", fmt.Errorf("%sError: no identifier provided: %s", cli.RedColour, cli.Reset)
+	}
+
+	return identifier, nil
+}
+
+func (p *Menu) CaptureRetentionDays() (int, error) {
+	fmt.Printf("Purge items trashed more than how many days ago? [%d]: ", trash.DefaultRetentionDays)
+
+	input, err := p.Reader.ReadString('\n')
+	if err != nil {
+		return 0, fmt.Errorf("%sError reading the retention window: %v %s", cli.RedColour, err, cli.Reset)
+	}
+
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return trash.DefaultRetentionDays, nil
+	}
+
+	days, err := strconv.Atoi(input)
+	if err != nil || days < 0 {
+		return 0, fmt.Errorf("%sError: the retention window must be a non-negative number of days: %s", cli.RedColour, cli.Reset)
+	}
+
+	return days, nil
+}
+
+// CaptureMediaSearch reads an optional search term; an empty answer lists every file.
+func (p *Menu) CaptureMediaSearch() (string, error) {
+	fmt.Print("Search media by path or alt text (leave empty for all): ")
+
+	search, err := p.Reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("%sError reading the media search: %v %s", cli.RedColour, err, cli.Reset)
+	}
+
+	return strings.TrimSpace(search), nil
+}
+
+// CaptureConfirmation asks a yes/no question, defaulting to no.
+func (p *Menu) CaptureConfirmation(question string) (bool, error) {
+	fmt.Printf("%s [y/N]: ", question)
+
+	answer, err := p.Reader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("%sError reading the confirmation: %v %s", cli.RedColour, err, cli.Reset)
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes", nil
+}
@@ -149,3 +149,71 @@ func TestCapturePostSlug(t *testing.T) {
 		t.Fatalf("expected error")
 	}
 }
+
+func TestCaptureTrashKind(t *testing.T) {
+	m := panel.Menu{
+		Reader: bufio.NewReader(strings.NewReader("Posts\n")),
+	}
+
+	kind, err := m.CaptureTrashKind(false)
+
+	if err != nil || kind != "posts" {
+		t.Fatalf("got %q err %v", kind, err)
+	}
+
+	all := panel.Menu{
+		Reader: bufio.NewReader(strings.NewReader("\n")),
+	}
+
+	if kind, err := all.CaptureTrashKind(true); err != nil || kind != "" {
+		t.Fatalf("got %q err %v", kind, err)
+	}
+
+	bad := panel.Menu{
+		Reader: bufio.NewReader(strings.NewReader("\n")),
+	}
+
+	if _, err := bad.CaptureTrashKind(false); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestCaptureRetentionDays(t *testing.T) {
+	m := panel.Menu{
+		Reader: bufio.NewReader(strings.NewReader("\n7\n-3\n")),
+	}
+
+	if days, err := m.CaptureRetentionDays(); err != nil || days != 30 {
+		t.Fatalf("default: got %d err %v", days, err)
+	}
+
+	if days, err := m.CaptureRetentionDays(); err != nil || days != 7 {
+		t.Fatalf("got %d err %v", days, err)
+	}
+
+	if _, err := m.CaptureRetentionDays(); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestCaptureMediaSearch(t *testing.T) {
+	m := panel.Menu{
+		Reader: bufio.NewReader(strings.NewReader("  cover \n")),
+	}
+
+	if search, err := m.CaptureMediaSearch(); err != nil || search != "cover" {
+		t.Fatalf("got %q err %v", search, err)
+	}
+}
+
+func TestCaptureConfirmation(t *testing.T) {
+	m := panel.Menu{
+		Reader: bufio.NewReader(strings.NewReader("y\nYES\n\nnope\n")),
+	}
+
+	for _, want := range []bool{true, true, false, false} {
+		if got, err := m.CaptureConfirmation("Proceed?"); err != nil || got != want {
+			t.Fatalf("expected %v, got %v err %v", want, got, err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package posts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/pkg/cli"
+	pkgimages "github.com/oullin/pkg/images"
+	"github.com/oullin/pkg/markdown"
+	"github.com/oullin/pkg/media"
+)
+
+// MirrorContentImages downloads every remote image referenced by the post body into
+// posts/<slug> of the media storage, content-addressed, and points the Markdown at the copies.
+// Images that cannot be mirrored keep their original URL. Mirrored files are indexed in the
+// media library under the given owner.
+func (h Handler) MirrorContentImages(slug, owner, content string) string {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	prefix := path.Join(media.PostsDir, slug)
+	mirrored := make(map[string]string)
+
+	for _, source := range markdown.ImageSources(content) {
+		if !isRemoteImage(source) {
+			continue
+		}
+
+		stored, err := h.mirrorImage(source, prefix)
+		if err != nil {
+			cli.Warningln(fmt.Sprintf("Could not mirror the image [%s]: %s", source, err.Error()))
+			continue
+		}
+
+		rel := path.Join(prefix, stored.File.Name)
+		h.indexMirroredImage(rel, owner, stored)
+
+		mirrored[source] = media.GetPublicURL(rel)
+	}
+
+	if len(mirrored) == 0 {
+		return content
+	}
+
+	cli.Grayln(fmt.Sprintf("Mirrored %d content images for [%s]", len(mirrored), slug))
+
+	return markdown.RewriteImages(content, func(src string) string {
+		if local, ok := mirrored[src]; ok {
+			return local
+		}
+
+		return src
+	})
+}
+
+type mirroredImage struct {
+	File     pkgimages.StoredFile
+	MimeType string
+	Width    int
+	Height   int
+}
+
+func (h Handler) mirrorImage(source, prefix string) (mirroredImage, error) {
+	img, format, err := pkgimages.Fetch(h.Fetcher, source)
+	if err != nil {
+		return mirroredImage{}, err
+	}
+
+	ext := pkgimages.DetermineExtension(source, format)
+
+	stored, err := pkgimages.SaveContentAddressed(context.Background(), h.Storage, prefix, img, ext, pkgimages.DefaultJPEGQuality)
+	if err != nil {
+		return mirroredImage{}, err
+	}
+
+	bounds := img.Bounds()
+
+	return mirroredImage{
+		File:     stored,
+		MimeType: pkgimages.MIMEFromExtension(ext),
+		Width:    bounds.Dx(),
+		Height:   bounds.Dy(),
+	}, nil
+}
+
+// indexMirroredImage records the file in the media library. The post body already points at
+// the file, so a failure only leaves it for the reconcile command to report.
+func (h Handler) indexMirroredImage(rel, owner string, image mirroredImage) {
+	if h.Media == nil {
+		return
+	}
+
+	_, err := h.Media.Create(database.MediaAttrs{
+		Path:     rel,
+		MimeType: image.MimeType,
+		Size:     image.File.Size,
+		Width:    image.Width,
+		Height:   image.Height,
+		Sha256:   image.File.Sha256,
+		Owner:    owner,
+	})
+
+	// The same image mirrored again, e.g. for another post, is already indexed.
+	if err != nil && !errors.Is(err, repository.ErrMediaExists) {
+		cli.Warningln(fmt.Sprintf("Could not index the mirrored image [%s]: %s", rel, err.Error()))
+	}
+}
+
+func isRemoteImage(source string) bool {
+	parsed, err := url.Parse(source)
+	if err != nil {
+		return false
+	}
+
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -0,0 +1,66 @@
+package posts
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oullin/pkg/storage"
+)
+
+func TestMirrorContentImages(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.png" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	root := t.TempDir()
+	h := Handler{Storage: storage.NewLocal(root, "/media"), Fetcher: loopbackFetcher()}
+
+	content := "![one](" + srv.URL + "/one.png)\n" +
+		"<img src=\"" + srv.URL + "/two.png\">\n" +
+		"![gone](" + srv.URL + "/missing.png)\n" +
+		"![local](/media/posts/hello/known.png)\n"
+
+	var got string
+	_ = captureOutput(func() { got = h.MirrorContentImages("Hello", "gus", content) })
+
+	if strings.Contains(got, srv.URL+"/one.png") || strings.Contains(got, srv.URL+"/two.png") {
+		t.Fatalf("expected remote images to be rewritten:\n%s", got)
+	}
+
+	if !strings.Contains(got, srv.URL+"/missing.png") || !strings.Contains(got, "(/media/posts/hello/known.png)") {
+		t.Fatalf("expected unmirrored images to be kept:\n%s", got)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(root, "posts", "hello"))
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+
+	// Both remote images have identical bytes, so they share a single content-addressed file.
+	if len(entries) != 1 {
+		t.Fatalf("expected one mirrored file, got %d", len(entries))
+	}
+
+	if !strings.Contains(got, "(/media/posts/hello/"+entries[0].Name()+")") {
+		t.Fatalf("expected the local URL in the content:\n%s", got)
+	}
+}
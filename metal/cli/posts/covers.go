@@ -0,0 +1,108 @@
+package posts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/oullin/database"
+	"github.com/oullin/pkg/cli"
+	pkgimages "github.com/oullin/pkg/images"
+	"github.com/oullin/pkg/media"
+)
+
+const coversDir = "covers"
+
+// PrepareCoverImages renders the responsive variants of the post cover under
+// posts/<slug>/covers in the media storage and records them, with the cover placeholder,
+// against the post. Variants of an earlier cover are only deleted once the new ones are
+// recorded, so the rows never point at missing files.
+func (h Handler) PrepareCoverImages(post database.Post) error {
+	source := strings.TrimSpace(post.CoverImageURL)
+	if source == "" {
+		return nil
+	}
+
+	img, payload, err := pkgimages.FetchPayload(h.Fetcher, source)
+	if err != nil {
+		return fmt.Errorf("fetch cover [%s]: %w", source, err)
+	}
+
+	// Fingerprinting the file names with the image content lets the variants be cached
+	// forever: a cover replaced at the same URL gets new names.
+	sum := sha256.Sum256(payload)
+	slug := strings.ToLower(strings.TrimSpace(post.Slug))
+	prefix := path.Join(media.PostsDir, slug, coversDir)
+	ctx := context.Background()
+
+	variants, err := pkgimages.GenerateVariants(ctx, img, pkgimages.VariantsOptions{
+		Storage:  h.Storage,
+		Prefix:   prefix,
+		BaseName: slug + "-" + hex.EncodeToString(sum[:])[:10],
+	})
+	if err != nil {
+		return err
+	}
+
+	placeholder, err := pkgimages.NewPlaceholder(img)
+	if err != nil {
+		return err
+	}
+
+	attrs := make([]database.PostCoverImageAttrs, 0, len(variants))
+	keep := make(map[string]bool, len(variants))
+
+	for _, variant := range variants {
+		attrs = append(attrs, database.PostCoverImageAttrs{
+			Format:   variant.Format,
+			Width:    variant.Width,
+			Height:   variant.Height,
+			MimeType: variant.MIME,
+			Path:     path.Join(prefix, variant.FileName),
+		})
+
+		keep[path.Join(prefix, variant.FileName)] = true
+	}
+
+	placeholderAttrs := database.PostCoverPlaceholderAttrs{
+		BlurHash:      placeholder.BlurHash,
+		DominantColor: placeholder.DominantColor,
+		Width:         placeholder.Width,
+		Height:        placeholder.Height,
+	}
+
+	if err = h.Posts.SyncCoverImages(post, placeholderAttrs, attrs); err != nil {
+		return err
+	}
+
+	if err = h.pruneCovers(ctx, prefix, keep); err != nil {
+		return fmt.Errorf("prune stale covers: %w", err)
+	}
+
+	cli.Grayln(fmt.Sprintf("Generated %d cover image variants for [%s]", len(attrs), slug))
+
+	return nil
+}
+
+// pruneCovers deletes the files under prefix the post no longer references.
+func (h Handler) pruneCovers(ctx context.Context, prefix string, keep map[string]bool) error {
+	stored, err := h.Storage.List(ctx, prefix+"/")
+	if err != nil {
+		return err
+	}
+
+	for _, object := range stored {
+		if keep[object.Key] {
+			continue
+		}
+
+		if err = h.Storage.Delete(ctx, object.Key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
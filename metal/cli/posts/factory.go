@@ -10,6 +10,7 @@ import (
 	"github.com/oullin/database/repository"
 	"github.com/oullin/pkg/markdown"
 	"github.com/oullin/pkg/portal"
+	"github.com/oullin/pkg/storage"
 )
 
 type Handler struct {
@@ -17,10 +18,13 @@ type Handler struct {
 	Client      *portal.Client
 	Posts       *repository.Posts
 	Users       *repository.Users
+	Media       *repository.Media
+	Storage     storage.Storage
+	Fetcher     *portal.Fetcher // Downloads cover and inline images; defaults to a fetcher limited to public hosts.
 	IsDebugging bool
 }
 
-func NewHandler(input *Input, client *portal.Client, db *database.Connection) Handler {
+func NewHandler(input *Input, client *portal.Client, db *database.Connection, store storage.Storage) Handler {
 	tags := &repository.Tags{DB: db}
 	categories := &repository.Categories{DB: db}
 
@@ -29,6 +33,8 @@ func NewHandler(input *Input, client *portal.Client, db *database.Connection) Ha
 		IsDebugging: false,
 		Client:      client,
 		Users:       &repository.Users{DB: db},
+		Media:       &repository.Media{DB: db},
+		Storage:     store,
 		Posts:       &repository.Posts{DB: db, Categories: categories, Tags: tags},
 	}
 }
@@ -43,12 +43,26 @@ func (h Handler) HandlePost(payload *markdown.Post) error {
 		Tags:        h.ParseTags(payload),
 	}
 
-	if _, err = h.Posts.Create(attrs); err != nil {
+	post, err := h.Posts.Create(attrs)
+	if err != nil {
 		return fmt.Errorf("handler: error persiting the post [%s]: %s", attrs.Title, err.Error())
 	}
 
 	cli.Successln("\n" + fmt.Sprintf("Post [%s] created successfully.", attrs.Title))
 
+	// Images are only mirrored once the post exists, so a rejected post leaves no files or
+	// media rows behind.
+	if content := h.MirrorContentImages(post.Slug, author.Username, post.Content); content != post.Content {
+		if err = h.Posts.UpdateContent(*post, content); err != nil {
+			cli.Warningln(fmt.Sprintf("Mirrored images for [%s] were not linked: %s", post.Slug, err.Error()))
+		}
+	}
+
+	// A cover that cannot be processed must not undo the post; the original URL keeps being served.
+	if err = h.PrepareCoverImages(*post); err != nil {
+		cli.Warningln(fmt.Sprintf("Cover images for [%s] were not generated: %s", post.Slug, err.Error()))
+	}
+
 	return nil
 }
 
@@ -1,10 +1,15 @@
 package posts
 
 import (
+	"bytes"
+	"image"
+	"image/png"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,8 +17,10 @@ import (
 
 	"github.com/oullin/database"
 	"github.com/oullin/metal/cli/clitest"
+	pkgimages "github.com/oullin/pkg/images"
 	"github.com/oullin/pkg/markdown"
 	"github.com/oullin/pkg/portal"
+	"github.com/oullin/pkg/storage"
 )
 
 func captureOutput(fn func()) string {
@@ -28,8 +35,17 @@ func captureOutput(fn func()) string {
 	return string(out)
 }
 
+// loopbackFetcher lets the handler and the image downloads reach httptest servers, which
+// the default fetcher refuses for listening on loopback.
+func loopbackFetcher() *portal.Fetcher {
+	policy := pkgimages.NewFetchPolicy()
+	policy.AllowPrivate = true
+
+	return portal.NewFetcher(policy)
+}
+
 func setupPostsHandler(t *testing.T) (*Handler, *database.Connection) {
-	conn := clitest.NewTestConnection(t, &database.User{}, &database.Post{}, &database.Category{}, &database.PostCategory{}, &database.Tag{}, &database.PostTag{})
+	conn := clitest.NewTestConnection(t, &database.User{}, &database.Post{}, &database.PostCoverImage{}, &database.Media{}, &database.Category{}, &database.PostCategory{}, &database.Tag{}, &database.PostTag{})
 	user := database.User{
 		UUID:         uuid.NewString(),
 		Username:     "jdoe",
@@ -58,7 +74,10 @@ func setupPostsHandler(t *testing.T) (*Handler, *database.Connection) {
 		Url: "http://example",
 	}
 
-	h := NewHandler(input, portal.NewDefaultClient(nil), conn)
+	fetcher := loopbackFetcher()
+
+	h := NewHandler(input, portal.NewDefaultClient(fetcher), conn, storage.NewLocal(t.TempDir(), "/media"))
+	h.Fetcher = fetcher
 
 	return &h, conn
 }
@@ -150,9 +169,29 @@ func TestHandlePostDuplicateSlug(t *testing.T) {
 		t.Fatalf("first create: %v", err)
 	}
 
+	var served int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		w.Header().Set("Content-Type", "image/png")
+		_ = png.Encode(w, image.NewRGBA(image.Rect(0, 0, 8, 8)))
+	}))
+	defer srv.Close()
+
+	post.Content = "![inline](" + srv.URL + "/inline.png)"
+
 	if err := h.HandlePost(post); err == nil {
 		t.Fatalf("expected duplicate error")
 	}
+
+	// A rejected post must not leave mirrored files behind.
+	if served != 0 {
+		t.Fatalf("expected no image to be mirrored for a rejected post")
+	}
+
+	if _, err := os.Stat(filepath.Join(h.Storage.(*storage.Local).Root, "posts", "dup")); !os.IsNotExist(err) {
+		t.Fatalf("expected no mirrored files for a rejected post")
+	}
 }
 
 func TestNotParsed(t *testing.T) {
@@ -184,3 +223,96 @@ func TestNotParsedError(t *testing.T) {
 		t.Fatalf("expected error")
 	}
 }
+
+func TestPrepareCoverImages(t *testing.T) {
+	h, conn := setupPostsHandler(t)
+
+	var cover bytes.Buffer
+	if err := png.Encode(&cover, image.NewRGBA(image.Rect(0, 0, 600, 300))); err != nil {
+		t.Fatalf("encode cover: %v", err)
+	}
+
+	served := cover.Bytes()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(served)
+	}))
+	defer srv.Close()
+
+	post := &markdown.Post{
+		FrontMatter: markdown.FrontMatter{
+			Title:       "Covered",
+			Excerpt:     "ex",
+			Slug:        "covered",
+			Author:      "jdoe",
+			Categories:  "tech",
+			PublishedAt: time.Now().Format("2006-01-02"),
+		},
+		ImageURL: srv.URL + "/cover.png",
+		Content:  "body",
+	}
+
+	if err := h.HandlePost(post); err != nil {
+		t.Fatalf("handle: %v", err)
+	}
+
+	var p database.Post
+	if err := conn.Sql().Preload("CoverImages").First(&p, "slug = ?", "covered").Error; err != nil {
+		t.Fatalf("post not created: %v", err)
+	}
+
+	if len(p.CoverImages) == 0 {
+		t.Fatalf("expected cover variants to be recorded")
+	}
+
+	if p.CoverBlurHash == "" || p.CoverDominantColor == "" || p.CoverWidth != 600 || p.CoverHeight != 300 {
+		t.Fatalf("expected the cover placeholder to be recorded, got %+v", p)
+	}
+
+	for _, cover := range p.CoverImages {
+		if cover.Width > 600 {
+			t.Fatalf("variants must not upscale the source: %+v", cover)
+		}
+
+		if !strings.HasPrefix(cover.Path, "posts/covered/covers/") {
+			t.Fatalf("unexpected cover path %s", cover.Path)
+		}
+
+		if _, err := os.Stat(filepath.Join(h.Storage.(*storage.Local).Root, cover.Path)); err != nil {
+			t.Fatalf("expected %s on disk: %v", cover.Path, err)
+		}
+	}
+
+	// A cover replaced at the same URL gets new names, and the old files go once the new
+	// ones are recorded.
+	var replaced bytes.Buffer
+	if err := png.Encode(&replaced, image.NewRGBA(image.Rect(0, 0, 500, 300))); err != nil {
+		t.Fatalf("encode cover: %v", err)
+	}
+
+	served = replaced.Bytes()
+
+	if err := h.PrepareCoverImages(p); err != nil {
+		t.Fatalf("prepare replaced cover: %v", err)
+	}
+
+	var updated database.Post
+	if err := conn.Sql().Preload("CoverImages").First(&updated, "slug = ?", "covered").Error; err != nil {
+		t.Fatalf("reload post: %v", err)
+	}
+
+	root := h.Storage.(*storage.Local).Root
+
+	for _, cover := range p.CoverImages {
+		if _, err := os.Stat(filepath.Join(root, cover.Path)); !os.IsNotExist(err) {
+			t.Fatalf("expected the stale variant %s to be deleted", cover.Path)
+		}
+	}
+
+	for _, cover := range updated.CoverImages {
+		if _, err := os.Stat(filepath.Join(root, cover.Path)); err != nil {
+			t.Fatalf("expected %s on disk: %v", cover.Path, err)
+		}
+	}
+}
@@ -9,11 +9,13 @@ import (
 	"github.com/oullin/handler"
 	"github.com/oullin/handler/payload"
 	"github.com/oullin/metal/router"
+	"github.com/oullin/pkg/fixtures"
 )
 
 type Client struct {
 	WebsiteRoutes *router.WebsiteRoutes
 	Fixture       router.Fixture
+	Content       *router.Content
 	data          ClientData
 }
 
@@ -38,6 +40,14 @@ func NewClient(routes *router.WebsiteRoutes) *Client {
 	}
 }
 
+// WithContent reads the site content the way the API serves it: from the database once a
+// section has been imported, from its fixture file until then.
+func (c *Client) WithContent(content router.Content) *Client {
+	c.Content = &content
+
+	return c
+}
+
 func get[T any](handler func() router.StaticRouteResource, entityName string) (*T, error) {
 	var response T
 
@@ -50,14 +60,14 @@ func get[T any](handler func() router.StaticRouteResource, entityName string) (*
 
 func (c *Client) GetTalks() (*payload.TalksResponse, error) {
 	return get[payload.TalksResponse](func() router.StaticRouteResource {
-		return handler.NewTalksHandler(c.Fixture.GetTalksFile())
+		return handler.NewTalksHandlerWithLoader(c.Fixture.GetTalksFile(), loader(c, router.Content.Talks, c.Fixture.GetTalksFile()), true)
 	}, "talks")
 }
 
 func (c *Client) GetProfile() (*payload.ProfileResponse, error) {
 	c.data.profileOnce.Do(func() {
 		c.data.profile, c.data.profileErr = get[payload.ProfileResponse](func() router.StaticRouteResource {
-			return handler.NewProfileHandler(c.Fixture.GetProfileFile())
+			return handler.NewProfileHandlerWithLoader(c.Fixture.GetProfileFile(), loader(c, router.Content.Profile, c.Fixture.GetProfileFile()), true)
 		}, "profile")
 	})
 
@@ -67,7 +77,7 @@ func (c *Client) GetProfile() (*payload.ProfileResponse, error) {
 func (c *Client) GetProjects() (*payload.ProjectsResponse, error) {
 	c.data.projectsOnce.Do(func() {
 		c.data.projects, c.data.projectsErr = get[payload.ProjectsResponse](func() router.StaticRouteResource {
-			return handler.NewProjectsHandler(c.Fixture.GetProjectsFile())
+			return handler.NewProjectsHandlerWithLoader(c.Fixture.GetProjectsFile(), loader(c, router.Content.Projects, c.Fixture.GetProjectsFile()), true)
 		}, "projects")
 	})
 
@@ -76,14 +86,14 @@ func (c *Client) GetProjects() (*payload.ProjectsResponse, error) {
 
 func (c *Client) GetLinks() (*payload.LinksResponse, error) {
 	return get[payload.LinksResponse](func() router.StaticRouteResource {
-		return handler.NewLinksHandler(c.Fixture.GetLinksFile())
+		return handler.NewLinksHandlerWithLoader(c.Fixture.GetLinksFile(), loader(c, router.Content.Links, c.Fixture.GetLinksFile()), true)
 	}, "links")
 }
 
 func (c *Client) GetRecommendations() (*payload.RecommendationsResponse, error) {
 	c.data.recommendationsOnce.Do(func() {
 		c.data.recommendations, c.data.recommendationsErr = get[payload.RecommendationsResponse](func() router.StaticRouteResource {
-			return handler.NewRecommendationsHandler(c.Fixture.GetRecommendationsFile())
+			return handler.NewRecommendationsHandlerWithLoader(c.Fixture.GetRecommendationsFile(), loader(c, router.Content.Recommendations, c.Fixture.GetRecommendationsFile()), true)
 		}, "recommendations")
 	})
 
@@ -92,13 +102,13 @@ func (c *Client) GetRecommendations() (*payload.RecommendationsResponse, error)
 
 func (c *Client) GetExperience() (*payload.ExperienceResponse, error) {
 	return get[payload.ExperienceResponse](func() router.StaticRouteResource {
-		return handler.NewExperienceHandler(c.Fixture.GetExperienceFile())
+		return handler.NewExperienceHandlerWithLoader(c.Fixture.GetExperienceFile(), loader(c, router.Content.Experience, c.Fixture.GetExperienceFile()), true)
 	}, "experience")
 }
 
 func (c *Client) GetEducation() (*payload.EducationResponse, error) {
 	return get[payload.EducationResponse](func() router.StaticRouteResource {
-		return handler.NewEducationHandler(c.Fixture.GetEducationFile())
+		return handler.NewEducationHandlerWithLoader(c.Fixture.GetEducationFile(), loader(c, router.Content.Education, c.Fixture.GetEducationFile()), true)
 	}, "education")
 }
 
@@ -118,3 +128,14 @@ func fetch[T any](response *T, handler func() router.StaticRouteResource) error
 
 	return nil
 }
+
+// loader reads through the content when the client has one, straight from the file otherwise.
+func loader[T any](c *Client, fromContent func(router.Content) func() (T, error), file string) func() (T, error) {
+	if c.Content != nil {
+		return fromContent(*c.Content)
+	}
+
+	return func() (T, error) {
+		return fixtures.ReadFile[T](file)
+	}
+}
@@ -35,6 +35,16 @@ type TemplateData struct {
 	Categories     []string        `validate:"required"`
 	BgColor        string          `validate:"required"`
 	Body           []template.HTML `validate:"required"`
+
+	CoverPlaceholder *CoverPlaceholderData `validate:"omitempty"` // Only set for posts whose cover was processed.
+}
+
+type CoverPlaceholderData struct {
+	BlurHash      string  `validate:"required"`
+	DominantColor string  `validate:"required,hexcolor"`
+	AspectRatio   float64 `validate:"gt=0"`
+	Width         int     `validate:"gt=0"`
+	Height        int     `validate:"gt=0"`
 }
 
 type TagOgData struct {
@@ -37,6 +37,7 @@ type Generator struct {
 	Validator     *portal.Validator
 	DB            *database.Connection
 	WebsiteRoutes *router.WebsiteRoutes
+	Fetcher       *portal.Fetcher // Downloads the post cover images; defaults to a fetcher limited to public hosts.
 }
 
 func NewGenerator(db *database.Connection, env *env.Environment, val *portal.Validator) (*Generator, error) {
@@ -81,6 +82,11 @@ func NewGenerator(db *database.Connection, env *env.Environment, val *portal.Val
 	}
 
 	webRoutes := router.NewWebsiteRoutes(env)
+	client := NewClient(webRoutes)
+
+	if db != nil {
+		client.WithContent(router.NewContent(db, webRoutes.Fixture))
+	}
 
 	return &Generator{
 		DB:            db,
@@ -89,7 +95,7 @@ func NewGenerator(db *database.Connection, env *env.Environment, val *portal.Val
 		Validator:     val,
 		Page:          page,
 		WebsiteRoutes: webRoutes,
-		Client:        NewClient(webRoutes),
+		Client:        client,
 	}, nil
 }
 
@@ -727,9 +733,25 @@ func (g *Generator) BuildForPost(post payload.PostResponse, body []template.HTML
 		data.Description = description
 		data.OGTagOg.ImageAlt = imageAlt
 		data.Twitter.ImageAlt = imageAlt
+		data.CoverPlaceholder = coverPlaceholderFor(post)
 	})
 }
 
+func coverPlaceholderFor(post payload.PostResponse) *CoverPlaceholderData {
+	placeholder := post.CoverPlaceholder
+	if placeholder == nil || placeholder.AspectRatio <= 0 {
+		return nil
+	}
+
+	return &CoverPlaceholderData{
+		BlurHash:      placeholder.BlurHash,
+		DominantColor: placeholder.DominantColor,
+		AspectRatio:   placeholder.AspectRatio,
+		Width:         placeholder.Width,
+		Height:        placeholder.Height,
+	}
+}
+
 func (g *Generator) CanonicalPostPath(slug string) string {
 	cleaned := strings.TrimSpace(slug)
 	cleaned = strings.Trim(cleaned, "/")
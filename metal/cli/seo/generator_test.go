@@ -10,7 +10,6 @@ import (
 	"image/png"
 	"net/http"
 	"net/http/httptest"
-	"net/url"
 	"os"
 	"path"
 	"path/filepath"
@@ -133,6 +132,7 @@ func TestGeneratorGenerateAllPages(t *testing.T) {
 	h := dbtest.NewTestsHelper(t,
 		&database.User{},
 		&database.Post{},
+		&database.PostCoverImage{},
 		&database.Category{},
 		&database.PostCategory{},
 		&database.Tag{},
@@ -341,8 +341,6 @@ func TestGeneratorPreparePostImage(t *testing.T) {
 	h.ChangeRepoRoot()
 
 	outputDir := t.TempDir()
-	srcDir := t.TempDir()
-	srcPath := filepath.Join(srcDir, "source.png")
 
 	img := image.NewRGBA(image.Rect(0, 0, 300, 300))
 	for y := 0; y < 300; y++ {
@@ -351,20 +349,7 @@ func TestGeneratorPreparePostImage(t *testing.T) {
 		}
 	}
 
-	fh, err := os.Create(srcPath)
-	if err != nil {
-		t.Fatalf("create source image: %v", err)
-	}
-
-	if err := png.Encode(fh, img); err != nil {
-		t.Fatalf("encode image: %v", err)
-	}
-
-	if err := fh.Close(); err != nil {
-		t.Fatalf("close image: %v", err)
-	}
-
-	fileURL := url.URL{Scheme: "file", Path: srcPath}
+	sourceURL := serveTestImage(t, img)
 
 	imagesDir := filepath.Join(outputDir, "posts", "images")
 
@@ -374,11 +359,12 @@ func TestGeneratorPreparePostImage(t *testing.T) {
 			SiteURL:   "https://seo.example.test",
 			OutputDir: outputDir,
 		},
-		Env: &env.Environment{Seo: env.SeoEnvironment{SpaDir: outputDir, SpaImagesDir: imagesDir}},
-		Web: NewWeb(),
+		Env:     &env.Environment{Seo: env.SeoEnvironment{SpaDir: outputDir, SpaImagesDir: imagesDir}},
+		Web:     NewWeb(),
+		Fetcher: loopbackFetcher(),
 	}
 
-	post := payload.PostResponse{Slug: "awesome-post", CoverImageURL: fileURL.String()}
+	post := payload.PostResponse{Slug: "awesome-post", CoverImageURL: sourceURL}
 
 	prepared, err := gen.preparePostImage(post)
 	if err != nil {
@@ -404,7 +390,7 @@ func TestGeneratorPreparePostImage(t *testing.T) {
 		t.Fatalf("expected destination image to have content")
 	}
 
-	fh, err = os.Open(destPath)
+	fh, err := os.Open(destPath)
 	if err != nil {
 		t.Fatalf("open destination image: %v", err)
 	}
@@ -457,8 +443,9 @@ func TestGeneratorPreparePostImageRemote(t *testing.T) {
 			SiteURL:   "https://seo.example.test",
 			OutputDir: outputDir,
 		},
-		Env: &env.Environment{Seo: env.SeoEnvironment{SpaDir: outputDir, SpaImagesDir: imagesDir}},
-		Web: NewWeb(),
+		Env:     &env.Environment{Seo: env.SeoEnvironment{SpaDir: outputDir, SpaImagesDir: imagesDir}},
+		Web:     NewWeb(),
+		Fetcher: loopbackFetcher(),
 	}
 
 	post := payload.PostResponse{Slug: "remote-post", CoverImageURL: server.URL + "/cover.png"}
@@ -512,3 +499,77 @@ func TestGeneratorPreparePostImageRemote(t *testing.T) {
 	}
 
 }
+
+func TestGeneratorBuildForPostExportsCoverPlaceholder(t *testing.T) {
+	page := Page{
+		SiteName:      "SEO Test Suite",
+		SiteURL:       "https://seo.example.test",
+		Lang:          "en_GB",
+		AboutPhotoUrl: "https://seo.example.test/photo.png",
+		LogoURL:       "https://seo.example.test/logo.png",
+		SameAsURL:     []string{"https://github.com/oullin"},
+		Categories:    []string{"golang"},
+		StubPath:      StubPath,
+		OutputDir:     t.TempDir(),
+	}
+
+	tmpl, err := page.Load()
+	if err != nil {
+		t.Fatalf("load template: %v", err)
+	}
+
+	page.Template = tmpl
+
+	gen := &Generator{
+		Page:      page,
+		Validator: newTestValidator(t),
+		Web:       NewWeb(),
+	}
+
+	post := payload.PostResponse{
+		Slug:    "placeholder-post",
+		Title:   "A post with a cover placeholder",
+		Excerpt: "An excerpt long enough for the description rules.",
+		CoverPlaceholder: &payload.CoverPlaceholderResponse{
+			BlurHash:      "LKO2?U%2Tw=w]~RBVZRi};RPxuwH",
+			DominantColor: "#1a2b3c",
+			AspectRatio:   1.9048,
+			Width:         1200,
+			Height:        630,
+		},
+	}
+
+	data, err := gen.BuildForPost(post, []template.HTML{"<h1>Post</h1>"})
+	if err != nil {
+		t.Fatalf("build err: %v", err)
+	}
+
+	if data.CoverPlaceholder == nil || data.CoverPlaceholder.DominantColor != "#1a2b3c" {
+		t.Fatalf("expected the cover placeholder in the template data, got %+v", data.CoverPlaceholder)
+	}
+
+	if err = gen.Export("placeholder-post", data); err != nil {
+		t.Fatalf("export err: %v", err)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(page.OutputDir, "placeholder-post.seo.html"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+
+	content := string(raw)
+	for _, want := range []string{
+		`<meta name="cover:blurhash" content="LKO2?U%2Tw=w]~RBVZRi};RPxuwH">`,
+		`<meta name="cover:dominant-color" content="#1a2b3c">`,
+		`<meta name="cover:aspect-ratio" content="1.9048">`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Fatalf("expected %s in output: %q", want, content)
+		}
+	}
+
+	post.CoverPlaceholder = nil
+	if data, err = gen.BuildForPost(post, []template.HTML{"<h1>Post</h1>"}); err != nil || data.CoverPlaceholder != nil {
+		t.Fatalf("expected no placeholder for unprocessed covers, got %+v err %v", data.CoverPlaceholder, err)
+	}
+}
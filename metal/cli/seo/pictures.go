@@ -1,9 +1,10 @@
 package seo
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
-	"os"
 	"path"
 	"path/filepath"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/oullin/pkg/cli"
 	pkgimages "github.com/oullin/pkg/images"
 	"github.com/oullin/pkg/portal"
+	"github.com/oullin/pkg/storage"
 )
 
 type preparedImage struct {
@@ -39,7 +41,7 @@ func (g *Generator) preparePostImage(post payload.PostResponse) (preparedImage,
 		return preparedImage{}, err
 	}
 
-	img, format, err := pkgimages.Fetch(source)
+	img, format, err := pkgimages.Fetch(g.Fetcher, source)
 	if err != nil {
 		var decodeErr *pkgimages.DecodeError
 		if errors.As(err, &decodeErr) {
@@ -61,33 +63,42 @@ func (g *Generator) preparePostImage(post payload.PostResponse) (preparedImage,
 	ext := pkgimages.DetermineExtension(source, format)
 	fileName := pkgimages.BuildFileName(post.Slug, ext, "post-image")
 
-	if err := os.MkdirAll(spaImagesDir, 0o755); err != nil {
-		cli.Errorln(fmt.Sprintf("Unable to create SPA images directory %s: %v", spaImagesDir, err))
-		return preparedImage{}, fmt.Errorf("create destination dir: %w", err)
-	}
-
-	destPath := filepath.Join(spaImagesDir, fileName)
-	if err := pkgimages.Save(destPath, resized, ext, pkgimages.DefaultJPEGQuality); err != nil {
-		cli.Errorln(fmt.Sprintf("Unable to save resized image to %s: %v", destPath, err))
-		return preparedImage{}, fmt.Errorf("write resized image: %w", err)
-	}
-
 	relativeDir, err := filepath.Rel(g.Page.OutputDir, spaImagesDir)
 	if err != nil {
 		cli.Errorln(fmt.Sprintf("Unable to determine relative path for %s: %v", spaImagesDir, err))
 		return preparedImage{}, fmt.Errorf("determine relative image path: %w", err)
 	}
 
-	relativeDir = filepath.ToSlash(relativeDir)
-	relativeDir = strings.Trim(relativeDir, "/")
+	relativeDir = strings.Trim(filepath.ToSlash(relativeDir), "/")
+
+	store, err := g.imageStorage(spaImagesDir, relativeDir)
+	if err != nil {
+		cli.Errorln(fmt.Sprintf("Unable to open the SEO image storage: %v", err))
+		return preparedImage{}, err
+	}
+
+	var buf bytes.Buffer
+	if err := pkgimages.Encode(&buf, resized, ext, pkgimages.DefaultJPEGQuality); err != nil {
+		return preparedImage{}, fmt.Errorf("encode resized image: %w", err)
+	}
+
+	if err := store.Put(context.Background(), fileName, buf.Bytes(), pkgimages.MIMEFromExtension(ext)); err != nil {
+		cli.Errorln(fmt.Sprintf("Unable to store resized image %s: %v", fileName, err))
+		return preparedImage{}, fmt.Errorf("write resized image: %w", err)
+	}
 
-	relative := path.Join(relativeDir, fileName)
-	relative = pkgimages.NormalizeRelativeURL(relative)
+	imageURL := store.URL(fileName)
 
-	cli.Grayln(fmt.Sprintf("Post image relative path: %s", relative))
+	// The local driver hands back a path relative to the SPA, which still needs the site URL.
+	if !strings.Contains(imageURL, "://") {
+		relative := pkgimages.NormalizeRelativeURL(path.Join(relativeDir, fileName))
+		cli.Grayln(fmt.Sprintf("Post image relative path: %s", relative))
+
+		imageURL = g.siteURLFor(relative)
+	}
 
 	return preparedImage{
-		URL:  g.siteURLFor(relative),
+		URL:  imageURL,
 		Mime: pkgimages.MIMEFromExtension(ext),
 	}, nil
 }
@@ -104,6 +115,12 @@ func (g *Generator) spaImagesDir() (string, error) {
 	return g.Env.Seo.SpaImagesDir, nil
 }
 
+// imageStorage returns where the SEO images go: the SPA images directory for the local
+// driver, the seo prefix of the bucket for S3.
+func (g *Generator) imageStorage(dir, relativeDir string) (storage.Storage, error) {
+	return storage.New(g.Env.Storage, storage.Area{Dir: dir, URL: relativeDir, Prefix: "seo"})
+}
+
 func (g *Generator) siteURLFor(rel string) string {
 	base := strings.TrimSuffix(g.Page.SiteURL, "/")
 	rel = strings.TrimPrefix(rel, "/")
@@ -1,10 +1,12 @@
 package seo
 
 import (
+	"bytes"
 	"image"
 	"image/color"
 	"image/png"
-	"net/url"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,8 +14,37 @@ import (
 
 	"github.com/oullin/handler/payload"
 	"github.com/oullin/metal/env"
+	pkgimages "github.com/oullin/pkg/images"
+	"github.com/oullin/pkg/portal"
 )
 
+// loopbackFetcher lets the image downloads reach httptest servers, which the default
+// fetcher refuses for listening on loopback.
+func loopbackFetcher() *portal.Fetcher {
+	policy := pkgimages.NewFetchPolicy()
+	policy.AllowPrivate = true
+
+	return portal.NewFetcher(policy)
+}
+
+// serveTestImage serves the image as a PNG and returns its URL.
+func serveTestImage(t *testing.T, img image.Image) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode source image: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(buf.Bytes())
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL + "/source.png"
+}
+
 func TestGeneratorPreparePostImageMissingURL(t *testing.T) {
 	gen := &Generator{Page: Page{}}
 
@@ -82,12 +113,6 @@ func TestGeneratorPreparePostImageNormalizesRelativeURL(t *testing.T) {
 		t.Fatalf("create images dir: %v", err)
 	}
 
-	srcPath := filepath.Join(root, "source.png")
-	fh, err := os.Create(srcPath)
-	if err != nil {
-		t.Fatalf("create source image: %v", err)
-	}
-
 	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
 	for y := 0; y < 20; y++ {
 		for x := 0; x < 20; x++ {
@@ -95,15 +120,7 @@ func TestGeneratorPreparePostImageNormalizesRelativeURL(t *testing.T) {
 		}
 	}
 
-	if err := png.Encode(fh, img); err != nil {
-		t.Fatalf("encode source image: %v", err)
-	}
-
-	if err := fh.Close(); err != nil {
-		t.Fatalf("close source image: %v", err)
-	}
-
-	fileURL := url.URL{Scheme: "file", Path: srcPath}
+	sourceURL := serveTestImage(t, img)
 
 	gen := &Generator{
 		Page: Page{
@@ -111,10 +128,11 @@ func TestGeneratorPreparePostImageNormalizesRelativeURL(t *testing.T) {
 			SiteURL:   "https://seo.example.test",
 			OutputDir: spaDir,
 		},
-		Env: &env.Environment{Seo: env.SeoEnvironment{SpaDir: spaDir, SpaImagesDir: imagesDir}},
+		Env:     &env.Environment{Seo: env.SeoEnvironment{SpaDir: spaDir, SpaImagesDir: imagesDir}},
+		Fetcher: loopbackFetcher(),
 	}
 
-	post := payload.PostResponse{Slug: "awesome-post", CoverImageURL: fileURL.String()}
+	post := payload.PostResponse{Slug: "awesome-post", CoverImageURL: sourceURL}
 
 	prepared, err := gen.preparePostImage(post)
 	if err != nil {
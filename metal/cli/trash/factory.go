@@ -0,0 +1,18 @@
+package trash
+
+import (
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+)
+
+const DefaultRetentionDays = 30
+
+type Handler struct {
+	Trash *repository.Trash
+}
+
+func NewHandler(db *database.Connection) Handler {
+	return Handler{
+		Trash: &repository.Trash{DB: db},
+	}
+}
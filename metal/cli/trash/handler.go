@@ -0,0 +1,71 @@
+package trash
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/oullin/database/repository"
+	"github.com/oullin/pkg/cli"
+)
+
+func (h Handler) List(kind string) error {
+	items, err := h.Trash.List(kind)
+
+	if err != nil {
+		return fmt.Errorf("failed to list the trash: %v", err)
+	}
+
+	if len(items) == 0 {
+		cli.Warningln("\nThe trash is empty.\n")
+
+		return nil
+	}
+
+	cli.Successln(fmt.Sprintf("\nFound %d trashed item(s):\n", len(items)))
+
+	for _, item := range items {
+		cli.Blueln("   > " + fmt.Sprintf("[%s] %s", item.Kind, item.Identifier))
+		cli.Grayln("     " + fmt.Sprintf("%s (deleted at %s)", item.Label, item.DeletedAt.Format(time.RFC3339)))
+	}
+
+	fmt.Println(" ")
+
+	return nil
+}
+
+func (h Handler) Restore(kind, identifier string) error {
+	if err := h.Trash.Restore(kind, identifier); err != nil {
+		return fmt.Errorf("failed to restore the given %s [%s]: %v", kind, identifier, err)
+	}
+
+	cli.Successln(fmt.Sprintf("\nThe given %s [%s] has been restored.\n", kind, identifier))
+
+	return nil
+}
+
+func (h Handler) Purge(retentionDays int) error {
+	if retentionDays < 0 {
+		return fmt.Errorf("the retention window must not be negative, got [%d] days", retentionDays)
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+	result, err := h.Trash.Purge(cutoff)
+
+	if err != nil {
+		return fmt.Errorf("failed to purge the trash: %v", err)
+	}
+
+	cli.Successln(fmt.Sprintf("\nPurged items trashed before %s:\n", cutoff.Format(time.RFC3339)))
+
+	for _, kind := range repository.GetTrashKinds() {
+		cli.Blueln("   > " + fmt.Sprintf("%s: %d", kind, result.Purged[kind]))
+	}
+
+	if result.SkippedComments > 0 {
+		cli.Warningln(fmt.Sprintf("   > %d comment(s) kept since they still have replies.", result.SkippedComments))
+	}
+
+	fmt.Println(" ")
+
+	return nil
+}
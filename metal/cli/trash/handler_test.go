@@ -0,0 +1,54 @@
+package trash_test
+
+import (
+	"testing"
+
+	"github.com/oullin/database"
+	"github.com/oullin/metal/cli/clitest"
+	"github.com/oullin/metal/cli/trash"
+)
+
+func setupTrashHandler(t *testing.T) trash.Handler {
+	conn := clitest.NewTestConnection(t,
+		&database.User{},
+		&database.Post{},
+		&database.PostCoverImage{},
+		&database.Category{},
+		&database.PostCategory{},
+		&database.Tag{},
+		&database.PostTag{},
+		&database.PostView{},
+		&database.Comment{},
+		&database.Like{},
+	)
+
+	return trash.NewHandler(conn)
+}
+
+func TestListAndPurgeEmptyTrash(t *testing.T) {
+	h := setupTrashHandler(t)
+
+	if err := h.List(""); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+
+	if err := h.Purge(trash.DefaultRetentionDays); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+}
+
+func TestRestoreMissingItem(t *testing.T) {
+	h := setupTrashHandler(t)
+
+	if err := h.Restore("posts", "missing"); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestPurgeRejectsNegativeRetention(t *testing.T) {
+	h := trash.Handler{}
+
+	if err := h.Purge(-1); err == nil {
+		t.Fatalf("expected error")
+	}
+}
@@ -0,0 +1,20 @@
+package env
+
+const StorageDriverLocal = "local"
+const StorageDriverS3 = "s3"
+
+// StorageEnvironment picks where media, SEO images and avatars are kept.
+type StorageEnvironment struct {
+	Driver    string `validate:"required,oneof=local s3"`
+	Endpoint  string `validate:"required_if=Driver s3,omitempty,url"`
+	Region    string `validate:"required_if=Driver s3"`
+	Bucket    string `validate:"required_if=Driver s3"`
+	AccessKey string `validate:"required_if=Driver s3"`
+	SecretKey string `validate:"required_if=Driver s3"`
+	PublicURL string `validate:"omitempty,url"`
+	PathStyle bool
+}
+
+func (s StorageEnvironment) IsS3() bool {
+	return s.Driver == StorageDriverS3
+}
@@ -2,14 +2,18 @@ package kernel
 
 import (
 	"fmt"
+	"log/slog"
 	"net/http"
 
 	"github.com/oullin/database"
 	"github.com/oullin/database/repository"
+	"github.com/oullin/handler"
 	"github.com/oullin/metal/env"
 	"github.com/oullin/metal/router"
 	"github.com/oullin/pkg/auth"
+	"github.com/oullin/pkg/fixtures"
 	"github.com/oullin/pkg/llogs"
+	"github.com/oullin/pkg/media"
 	"github.com/oullin/pkg/middleware"
 	"github.com/oullin/pkg/portal"
 )
@@ -32,6 +36,17 @@ func NewApp(e *env.Environment, validator *portal.Validator) (*App, error) {
 		db:        NewDbConnection(e),
 	}
 
+	// Imported sections are served from the database, so their fixture files no longer matter.
+	imported, err := repository.Content{DB: app.db}.ImportedSlugs()
+	if err != nil {
+		slog.Warn("kernel: could not list the imported content sections, checking every fixture", "error", err)
+	}
+
+	fixture := router.NewFixture()
+	if err := fixture.CheckFixtures(validator, imported...); err != nil {
+		return nil, fmt.Errorf("kernel error > fixtures: %w", err)
+	}
+
 	if modem, err := app.NewRouter(); err != nil {
 		return nil, err
 	} else {
@@ -56,6 +71,12 @@ func (a *App) NewRouter() (*router.Router, error) {
 		return nil, fmt.Errorf("kernel error > router: could not create a token handler: %w", err)
 	}
 
+	store, err := media.NewStorage(envi.Storage)
+
+	if err != nil {
+		return nil, fmt.Errorf("kernel error > router: could not open the media storage: %w", err)
+	}
+
 	pipe := middleware.Pipeline{
 		Env:          envi,
 		TokenHandler: tokenHandler,
@@ -73,6 +94,9 @@ func (a *App) NewRouter() (*router.Router, error) {
 		Validator:     a.validator,
 		Mux:           http.NewServeMux(),
 		WebsiteRoutes: router.NewWebsiteRoutes(envi),
+		Storage:       store,
+		Fixtures:      fixtures.NewWatcher(),
+		Sections:      handler.NewSiteHandler(!envi.App.IsLocal()),
 	}
 
 	return &modem, nil
@@ -95,7 +119,14 @@ func (a *App) Boot() {
 	modem.Talks()
 	modem.Education()
 	modem.Recommendations()
+	modem.Site()
+	modem.Resume()
 	modem.Posts()
+	modem.AdminPosts()
+	modem.Media()
+	modem.Images()
 	modem.Categories()
 	modem.Signature()
+
+	modem.Fixtures.Start(fixtures.PollInterval)
 }
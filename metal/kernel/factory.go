@@ -118,6 +118,21 @@ func NewEnv(validate *portal.Validator) *env.Environment {
 		SpaImagesDir: env.GetEnvVar("ENV_SPA_IMAGES_DIR"),
 	}
 
+	storageEnv := env.StorageEnvironment{
+		Driver:    env.GetEnvVar("ENV_STORAGE_DRIVER"),
+		Endpoint:  env.GetEnvVar("ENV_STORAGE_S3_ENDPOINT"),
+		Region:    env.GetEnvVar("ENV_STORAGE_S3_REGION"),
+		Bucket:    env.GetEnvVar("ENV_STORAGE_S3_BUCKET"),
+		AccessKey: env.GetSecretOrEnv("storage_access_key", "ENV_STORAGE_S3_ACCESS_KEY"),
+		SecretKey: env.GetSecretOrEnv("storage_secret_key", "ENV_STORAGE_S3_SECRET_KEY"),
+		PublicURL: env.GetEnvVar("ENV_STORAGE_PUBLIC_URL"),
+		PathStyle: env.GetEnvVar("ENV_STORAGE_S3_PATH_STYLE") == "true",
+	}
+
+	if storageEnv.Driver == "" {
+		storageEnv.Driver = env.StorageDriverLocal
+	}
+
 	if _, err := validate.Rejects(app); err != nil {
 		panic(errorSuffix + "invalid [APP] model: " + validate.GetErrorsAsJson())
 	}
@@ -146,6 +161,10 @@ func NewEnv(validate *portal.Validator) *env.Environment {
 		panic(errorSuffix + "invalid [seo] model: " + validate.GetErrorsAsJson())
 	}
 
+	if _, err := validate.Rejects(storageEnv); err != nil {
+		panic(errorSuffix + "invalid [storage] model: " + validate.GetErrorsAsJson())
+	}
+
 	blog := &env.Environment{
 		App:     app,
 		DB:      db,
@@ -154,6 +173,7 @@ func NewEnv(validate *portal.Validator) *env.Environment {
 		Sentry:  sentryEnv,
 		Ping:    pingEnv,
 		Seo:     seoEnv,
+		Storage: storageEnv,
 	}
 
 	if _, err := validate.Rejects(blog); err != nil {
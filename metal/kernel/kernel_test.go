@@ -14,6 +14,7 @@ import (
 
 	"github.com/oullin/database"
 	"github.com/oullin/database/repository"
+	"github.com/oullin/handler"
 	"github.com/oullin/metal/env"
 	"github.com/oullin/metal/router"
 	"github.com/oullin/pkg/auth"
@@ -238,7 +239,7 @@ func TestAppBootRoutes(t *testing.T) {
 		t.Fatalf("key err: %v", err)
 	}
 
-	handler, err := auth.NewTokensHandler(key)
+	tokenHandler, err := auth.NewTokensHandler(key)
 
 	if err != nil {
 		t.Fatalf("handler err: %v", err)
@@ -250,11 +251,12 @@ func TestAppBootRoutes(t *testing.T) {
 		Pipeline: middleware.Pipeline{
 			Env:              env,
 			ApiKeys:          &repository.ApiKeys{DB: &database.Connection{}},
-			TokenHandler:     handler,
+			TokenHandler:     tokenHandler,
 			PublicMiddleware: middleware.NewPublicMiddleware("", false),
 		},
 		WebsiteRoutes: router.NewWebsiteRoutes(env),
 		Db:            &database.Connection{},
+		Sections:      handler.NewSiteHandler(false),
 	}
 
 	app := &App{}
@@ -274,9 +276,31 @@ func TestAppBootRoutes(t *testing.T) {
 		{"GET", "/talks"},
 		{"GET", "/education"},
 		{"GET", "/recommendations"},
+		{"GET", "/site"},
+		{"GET", "/resume.json"},
+		{"GET", "/contact.vcf"},
 		{"POST", "/posts"},
 		{"GET", "/posts/slug"},
+		{"POST", "/admin/posts"},
+		{"PUT", "/admin/posts/slug"},
+		{"DELETE", "/admin/posts/slug"},
+		{"POST", "/admin/posts/slug/restore"},
+		{"GET", "/media"},
+		{"POST", "/media"},
+		{"GET", "/media/6e3b1c1a-6f43-4b4e-9d8e-1f2a3b4c5d6e"},
+		{"DELETE", "/media/6e3b1c1a-6f43-4b4e-9d8e-1f2a3b4c5d6e"},
+		{"GET", "/media/posts/slug/cover-480w.webp"},
+		{"GET", "/media/uploads/file.png"},
+		{"GET", "/images/posts/slug/cover.jpg"},
 		{"GET", "/categories"},
+		{"PUT", "/admin/profile"},
+		{"POST", "/admin/talks"},
+		{"GET", "/admin/talks/6e3b1c1a-6f43-4b4e-9d8e-1f2a3b4c5d6e"},
+		{"PUT", "/admin/projects/6e3b1c1a-6f43-4b4e-9d8e-1f2a3b4c5d6e"},
+		{"DELETE", "/admin/recommendations/6e3b1c1a-6f43-4b4e-9d8e-1f2a3b4c5d6e"},
+		{"POST", "/admin/experience"},
+		{"POST", "/admin/education"},
+		{"POST", "/admin/links"},
 	}
 
 	for _, rt := range routes {
@@ -0,0 +1,89 @@
+package router
+
+import (
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/handler/payload"
+	"github.com/oullin/pkg/fixtures"
+)
+
+// Content builds the loaders behind the public fixture routes. Each section is read from
+// the database once it has been imported, and from its fixture file until then.
+type Content struct {
+	Repository repository.Content
+	Fixture    Fixture
+}
+
+func NewContent(db *database.Connection, fixture Fixture) Content {
+	return Content{
+		Repository: repository.Content{DB: db},
+		Fixture:    fixture,
+	}
+}
+
+func (c Content) Profile() func() (payload.ProfileResponse, error) {
+	return loadSection(c, FixtureProfile, c.Fixture.GetProfileFile(), func(version string) (payload.ProfileResponse, error) {
+		profile, err := c.Repository.Profile()
+		if err != nil || profile == nil {
+			return payload.ProfileResponse{Version: version}, err
+		}
+
+		return payload.GetProfileResponse(version, *profile), nil
+	})
+}
+
+func (c Content) Experience() func() (payload.ExperienceResponse, error) {
+	return loadList(c, FixtureExperience, c.Fixture.GetExperienceFile(), payload.GetExperienceResponse)
+}
+
+func (c Content) Education() func() (payload.EducationResponse, error) {
+	return loadList(c, FixtureEducation, c.Fixture.GetEducationFile(), payload.GetEducationResponse)
+}
+
+func (c Content) Talks() func() (payload.TalksResponse, error) {
+	return loadList(c, FixtureTalks, c.Fixture.GetTalksFile(), payload.GetTalksResponse)
+}
+
+func (c Content) Links() func() (payload.LinksResponse, error) {
+	return loadList(c, FixtureLinks, c.Fixture.GetLinksFile(), payload.GetLinksResponse)
+}
+
+func (c Content) Projects() func() (payload.ProjectsResponse, error) {
+	return loadList(c, FixtureProjects, c.Fixture.GetProjectsFile(), payload.GetProjectsResponse)
+}
+
+func (c Content) Recommendations() func() (payload.RecommendationsResponse, error) {
+	return loadList(c, FixtureRecommendations, c.Fixture.GetRecommendationsFile(), payload.GetRecommendationsResponse)
+}
+
+func loadList[T any, M repository.ContentItem](c Content, section, file string, present func(string, []M) T) func() (T, error) {
+	return loadSection(c, section, file, func(version string) (T, error) {
+		items, err := repository.ListContent[M](c.Repository)
+		if err != nil {
+			var empty T
+
+			return empty, err
+		}
+
+		return present(version, items), nil
+	})
+}
+
+// loadSection only falls back to the file while the section has not been imported. A
+// database error is returned instead, so the last good snapshot keeps being served.
+func loadSection[T any](c Content, section, file string, load func(version string) (T, error)) func() (T, error) {
+	return func() (T, error) {
+		imported, err := c.Repository.Section(section)
+		if err != nil {
+			var empty T
+
+			return empty, err
+		}
+
+		if imported == nil {
+			return fixtures.ReadFile[T](file)
+		}
+
+		return load(imported.Version)
+	}
+}
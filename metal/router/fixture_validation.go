@@ -0,0 +1,93 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/oullin/handler/payload"
+	"github.com/oullin/pkg/portal"
+)
+
+// FixtureReport is the outcome of validating one fixture file. Err is set when the file
+// could not be read or parsed at all, in which case there are no violations to list.
+type FixtureReport struct {
+	Slug       string
+	File       string
+	Err        error
+	Violations []portal.Violation
+}
+
+func (r FixtureReport) Passes() bool {
+	return r.Err == nil && len(r.Violations) == 0
+}
+
+// NewFixtureIn reads the fixtures out of dir instead of ./storage/fixture.
+func NewFixtureIn(dir string) Fixture {
+	fixture := NewFixture()
+	fixture.basePath = dir + string(os.PathSeparator)
+
+	return fixture
+}
+
+// ValidateFixtures checks every fixture file against the rules of its payload type and
+// returns one report per file, in slug order.
+func (f *Fixture) ValidateFixtures(validator *portal.Validator) []FixtureReport {
+	return []FixtureReport{
+		validateFixture[payload.EducationResponse](FixtureEducation, f.GetEducationFile(), validator),
+		validateFixture[payload.ExperienceResponse](FixtureExperience, f.GetExperienceFile(), validator),
+		validateFixture[payload.LinksResponse](FixtureLinks, f.GetLinksFile(), validator),
+		validateFixture[payload.ProfileResponse](FixtureProfile, f.GetProfileFile(), validator),
+		validateFixture[payload.ProjectsResponse](FixtureProjects, f.GetProjectsFile(), validator),
+		validateFixture[payload.RecommendationsResponse](FixtureRecommendations, f.GetRecommendationsFile(), validator),
+		validateFixture[payload.TalksResponse](FixtureTalks, f.GetTalksFile(), validator),
+	}
+}
+
+// CheckFixtures is ValidateFixtures folded into one error naming every broken rule, or nil.
+// The sections given as skipped, e.g. the ones imported into the database, are left out.
+func (f *Fixture) CheckFixtures(validator *portal.Validator, skipped ...string) error {
+	var errs []error
+
+	for _, report := range f.ValidateFixtures(validator) {
+		if slices.Contains(skipped, report.Slug) {
+			continue
+		}
+
+		if report.Err != nil {
+			errs = append(errs, report.Err)
+		}
+
+		for _, violation := range report.Violations {
+			errs = append(errs, fmt.Errorf("%s %s: %s", report.File, violation.Path, violation.Message))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateFixture[T any](slug, file string, validator *portal.Validator) FixtureReport {
+	report := FixtureReport{Slug: slug, File: file}
+
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		report.Err = fmt.Errorf("read %s: %w", file, err)
+
+		return report
+	}
+
+	var data T
+	if err = json.Unmarshal(raw, &data); err != nil {
+		report.Err = fmt.Errorf("parse %s: %w", file, err)
+
+		return report
+	}
+
+	if report.Violations, err = validator.Violations(&data); err != nil {
+		report.Err = fmt.Errorf("validate %s: %w", file, err)
+	}
+
+	return report
+}
@@ -0,0 +1,78 @@
+package router_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oullin/metal/router"
+	"github.com/oullin/pkg/portal"
+)
+
+func TestFixtures_ShippedFixturesAreValid(t *testing.T) {
+	fixture := router.NewFixtureIn(filepath.Join("..", "..", "storage", "fixture"))
+
+	if err := fixture.CheckFixtures(portal.GetDefaultValidator()); err != nil {
+		t.Fatalf("shipped fixtures are invalid:\n%v", err)
+	}
+}
+
+func TestFixtures_ReportViolationsWithJSONPaths(t *testing.T) {
+	source := filepath.Join("..", "..", "storage", "fixture")
+	dir := t.TempDir()
+
+	entries, err := os.ReadDir(source)
+	if err != nil {
+		t.Fatalf("read fixtures: %v", err)
+	}
+
+	for _, entry := range entries {
+		raw, err := os.ReadFile(filepath.Join(source, entry.Name()))
+		if err != nil {
+			t.Fatalf("read %s: %v", entry.Name(), err)
+		}
+
+		if err = os.WriteFile(filepath.Join(dir, entry.Name()), raw, 0o644); err != nil {
+			t.Fatalf("write %s: %v", entry.Name(), err)
+		}
+	}
+
+	broken := `{"version":"v1","data":[{"uuid":"b222d84c-5bbe-4c21-8ba8-a9baa7e5eaa9","title":"","created_at":"11/02/2019"}]}`
+	if err = os.WriteFile(filepath.Join(dir, "talks.json"), []byte(broken), 0o644); err != nil {
+		t.Fatalf("write talks: %v", err)
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, "links.json"), []byte("{"), 0o644); err != nil {
+		t.Fatalf("write links: %v", err)
+	}
+
+	fixture := router.NewFixtureIn(dir)
+	paths := map[string][]string{}
+	failed := map[string]bool{}
+
+	for _, report := range fixture.ValidateFixtures(portal.GetDefaultValidator()) {
+		name := filepath.Base(report.File)
+		failed[name] = !report.Passes()
+
+		for _, violation := range report.Violations {
+			paths[name] = append(paths[name], violation.Path)
+		}
+	}
+
+	if got := strings.Join(paths["talks.json"], ","); got != "$.data[0].title,$.data[0].created_at" {
+		t.Fatalf("unexpected talks violations %q", got)
+	}
+
+	if !failed["links.json"] || failed["profile.json"] {
+		t.Fatalf("unexpected outcome %v", failed)
+	}
+
+	if err = fixture.CheckFixtures(portal.GetDefaultValidator()); err == nil || !strings.Contains(err.Error(), "$.data[0].title") {
+		t.Fatalf("expected the joined error to name the path, got %v", err)
+	}
+
+	if err = fixture.CheckFixtures(portal.GetDefaultValidator(), router.FixtureTalks, router.FixtureLinks); err != nil {
+		t.Fatalf("expected the skipped sections to be left out, got %v", err)
+	}
+}
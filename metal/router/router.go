@@ -2,15 +2,21 @@ package router
 
 import (
 	"net/http"
+	"path/filepath"
 	"strings"
 
 	"github.com/oullin/database"
 	"github.com/oullin/database/repository"
 	"github.com/oullin/handler"
+	"github.com/oullin/metal/cli/posts"
 	"github.com/oullin/metal/env"
 	"github.com/oullin/pkg/endpoint"
+	"github.com/oullin/pkg/fixtures"
+	"github.com/oullin/pkg/media"
 	"github.com/oullin/pkg/middleware"
 	"github.com/oullin/pkg/portal"
+	"github.com/oullin/pkg/projects"
+	"github.com/oullin/pkg/storage"
 )
 
 type Router struct {
@@ -20,6 +26,9 @@ type Router struct {
 	Mux           *http.ServeMux
 	Pipeline      middleware.Pipeline
 	Db            *database.Connection
+	Storage       storage.Storage
+	Fixtures      *fixtures.Watcher
+	Sections      *handler.SiteHandler
 }
 
 func (r *Router) PublicPipelineFor(apiHandler endpoint.ApiHandler) http.HandlerFunc {
@@ -45,9 +54,29 @@ func (r *Router) PipelineFor(apiHandler endpoint.ApiHandler) http.HandlerFunc {
 	)
 }
 
+// AdminPipelineFor chains the signed token check with the admin authorisation check.
+func (r *Router) AdminPipelineFor(apiHandler endpoint.ApiHandler) http.HandlerFunc {
+	tokenMiddleware := middleware.NewTokenMiddleware(
+		r.Pipeline.TokenHandler,
+		r.Pipeline.ApiKeys,
+	)
+
+	adminMiddleware := middleware.NewAdminMiddleware(
+		&repository.Users{DB: r.Db},
+	)
+
+	return endpoint.NewApiHandler(
+		r.Pipeline.Chain(
+			apiHandler,
+			tokenMiddleware.Handle,
+			adminMiddleware.Handle,
+		),
+	)
+}
+
 func (r *Router) Posts() {
 	repo := repository.Posts{DB: r.Db}
-	abstract := handler.NewPostsHandler(&repo)
+	abstract := handler.NewPostsHandler(&repo, r.Validator)
 
 	index := r.PipelineFor(abstract.Index)
 	show := r.PipelineFor(abstract.Show)
@@ -56,6 +85,57 @@ func (r *Router) Posts() {
 	r.Mux.HandleFunc("GET /posts/{slug}", show)
 }
 
+func (r *Router) AdminPosts() {
+	repo := repository.Posts{
+		DB:         r.Db,
+		Categories: &repository.Categories{DB: r.Db},
+		Tags:       &repository.Tags{DB: r.Db},
+	}
+
+	images := posts.Handler{
+		Posts:   &repo,
+		Media:   &repository.Media{DB: r.Db},
+		Storage: r.Storage,
+	}
+
+	abstract := handler.NewAdminPostsHandler(&repo, r.Validator).WithImages(images)
+
+	r.Mux.HandleFunc("POST /admin/posts", r.AdminPipelineFor(abstract.Store))
+	r.Mux.HandleFunc("PUT /admin/posts/{slug}", r.AdminPipelineFor(abstract.Update))
+	r.Mux.HandleFunc("DELETE /admin/posts/{slug}", r.AdminPipelineFor(abstract.Destroy))
+	r.Mux.HandleFunc("POST /admin/posts/{slug}/restore", r.AdminPipelineFor(abstract.Restore))
+}
+
+func (r *Router) Media() {
+	repo := repository.Media{DB: r.Db}
+	abstract := handler.NewMediaHandler(&repo, r.Storage)
+
+	showPostImage := endpoint.NewApiHandler(
+		r.Pipeline.Chain(abstract.ShowPostImage),
+	)
+
+	showUpload := endpoint.NewApiHandler(
+		r.Pipeline.Chain(abstract.ShowUpload),
+	)
+
+	r.Mux.HandleFunc("GET /media", r.PipelineFor(abstract.Index))
+	r.Mux.HandleFunc("POST /media", r.PipelineFor(abstract.Store))
+	r.Mux.HandleFunc("GET /media/{uuid}", r.PipelineFor(abstract.Show))
+	r.Mux.HandleFunc("DELETE /media/{uuid}", r.AdminPipelineFor(abstract.Destroy))
+	r.Mux.HandleFunc("GET /media/posts/{path...}", showPostImage)
+	r.Mux.HandleFunc("GET /media/uploads/{path...}", showUpload)
+}
+
+func (r *Router) Images() {
+	abstract := handler.NewImagesHandler(r.Storage, media.GetImagesCacheDir())
+
+	show := endpoint.NewApiHandler(
+		r.Pipeline.Chain(abstract.Show),
+	)
+
+	r.Mux.HandleFunc("GET /images/{path...}", show)
+}
+
 func (r *Router) Categories() {
 	repo := repository.Categories{DB: r.Db}
 	abstract := handler.NewCategoriesHandler(&repo)
@@ -104,80 +184,144 @@ func (r *Router) Metrics() {
 }
 
 func (r *Router) Profile() {
-	maker := handler.NewProfileHandlerWithCache
+	content := r.content()
+	maker := handler.NewProfileHandlerWithLoader
 
 	r.composeFixtures(
 		r.WebsiteRoutes.Fixture.GetProfile(),
 		func(file string, cacheEnabled bool) StaticRouteResource {
-			return maker(file, cacheEnabled)
+			return maker(file, content.Profile(), cacheEnabled)
 		},
 	)
+
+	admin := handler.NewAdminProfileHandler(&content.Repository, r.Validator).OnChange(r.reloadFixtures)
+
+	r.Mux.HandleFunc("PUT /admin/profile", r.AdminPipelineFor(admin.Update))
 }
 
 func (r *Router) Experience() {
-	maker := handler.NewExperienceHandlerWithCache
+	content := r.content()
+	maker := handler.NewExperienceHandlerWithLoader
 
 	r.composeFixtures(
 		r.WebsiteRoutes.Fixture.GetExperience(),
 		func(file string, cacheEnabled bool) StaticRouteResource {
-			return maker(file, cacheEnabled)
+			return maker(file, content.Experience(), cacheEnabled)
 		},
 	)
+
+	addAdminContentRoutes(r, FixtureExperience, handler.NewAdminExperienceHandler(&content.Repository, r.Validator))
 }
 
 func (r *Router) Projects() {
-	maker := handler.NewProjectsHandlerWithCache
+	content := r.content()
+	maker := handler.NewProjectsHandlerWithLoader
+	cache := projects.NewPublishedAtCache(filepath.Join(media.GetCacheDir(), "projects", "published_at.json"), projects.PublishedAtCacheTTL)
+	registry := projects.NewDefaultRegistry()
+	resolver := projects.NewBackgroundResolver(registry, cache)
+	stats := projects.NewStatsRefresher(registry, projects.NewStatsStore(filepath.Join(media.GetCacheDir(), "projects", "stats.json")))
+	stats.Start(projects.StatsRefreshInterval)
 
 	r.composeFixtures(
 		r.WebsiteRoutes.Fixture.GetProjects(),
 		func(file string, cacheEnabled bool) StaticRouteResource {
-			return maker(file, cacheEnabled)
+			return maker(file, content.Projects(), cacheEnabled).
+				WithPublishedAtLookup(resolver.Lookup).
+				WithStatsLookup(stats.Lookup)
 		},
 	)
+
+	addAdminContentRoutes(r, FixtureProjects, handler.NewAdminProjectsHandler(&content.Repository, r.Validator))
 }
 
 func (r *Router) Links() {
-	maker := handler.NewLinksHandlerWithCache
+	content := r.content()
+	maker := handler.NewLinksHandlerWithLoader
 
 	r.composeFixtures(
 		r.WebsiteRoutes.Fixture.GetLinks(),
 		func(file string, cacheEnabled bool) StaticRouteResource {
-			return maker(file, cacheEnabled)
+			return maker(file, content.Links(), cacheEnabled)
 		},
 	)
+
+	addAdminContentRoutes(r, FixtureLinks, handler.NewAdminLinksHandler(&content.Repository, r.Validator))
 }
 
 func (r *Router) Talks() {
-	maker := handler.NewTalksHandlerWithCache
+	content := r.content()
+	maker := handler.NewTalksHandlerWithLoader
 
 	r.composeFixtures(
 		r.WebsiteRoutes.Fixture.GetTalks(),
 		func(file string, cacheEnabled bool) StaticRouteResource {
-			return maker(file, cacheEnabled)
+			return maker(file, content.Talks(), cacheEnabled)
 		},
 	)
+
+	addAdminContentRoutes(r, FixtureTalks, handler.NewAdminTalksHandler(&content.Repository, r.Validator))
 }
 
 func (r *Router) Education() {
-	maker := handler.NewEducationHandlerWithCache
+	content := r.content()
+	maker := handler.NewEducationHandlerWithLoader
 
 	r.composeFixtures(
 		r.WebsiteRoutes.Fixture.GetEducation(),
 		func(file string, cacheEnabled bool) StaticRouteResource {
-			return maker(file, cacheEnabled)
+			return maker(file, content.Education(), cacheEnabled)
 		},
 	)
+
+	addAdminContentRoutes(r, FixtureEducation, handler.NewAdminEducationHandler(&content.Repository, r.Validator))
 }
 
 func (r *Router) Recommendations() {
-	maker := handler.NewRecommendationsHandlerWithCache
+	content := r.content()
+	maker := handler.NewRecommendationsHandlerWithLoader
 
 	r.composeFixtures(
 		r.WebsiteRoutes.Fixture.GetRecommendations(),
 		func(file string, cacheEnabled bool) StaticRouteResource {
-			return maker(file, cacheEnabled)
+			return maker(file, content.Recommendations(), cacheEnabled)
 		},
 	)
+
+	addAdminContentRoutes(r, FixtureRecommendations, handler.NewAdminRecommendationsHandler(&content.Repository, r.Validator))
+}
+
+// Site embeds every fixture route in one response. Sections are looked up per request,
+// so routes registered after it are included too.
+func (r *Router) Site() {
+	r.Mux.HandleFunc("GET /site", r.PipelineFor(r.Sections.Handle))
+}
+
+// Resume exports the profile sections registered for GET /site as a CV and a contact card.
+// Both are public, so they can be linked to and imported without a signed request.
+func (r *Router) Resume() {
+	abstract := handler.NewResumeHandler(r.Sections, r.WebsiteRoutes.SiteURL, !r.Env.App.IsLocal())
+
+	r.Mux.HandleFunc("GET /resume.json", r.PublicPipelineFor(abstract.JSONResume))
+	r.Mux.HandleFunc("GET /contact.vcf", r.PublicPipelineFor(abstract.VCard))
+}
+
+func (r *Router) content() Content {
+	return NewContent(r.Db, r.WebsiteRoutes.Fixture)
+}
+
+// reloadFixtures refreshes every public fixture route right after an admin write. Polls
+// leave database-backed routes alone unless their fixture file changed.
+func (r *Router) reloadFixtures() {
+	r.Fixtures.ReloadAll(true)
+}
+
+func addAdminContentRoutes[D any, M repository.ContentItem](r *Router, section string, abstract handler.AdminContentHandler[D, M]) {
+	abstract = abstract.OnChange(r.reloadFixtures)
+
+	r.Mux.HandleFunc("POST /admin/"+section, r.AdminPipelineFor(abstract.Store))
+	r.Mux.HandleFunc("GET /admin/"+section+"/{uuid}", r.AdminPipelineFor(abstract.Show))
+	r.Mux.HandleFunc("PUT /admin/"+section+"/{uuid}", r.AdminPipelineFor(abstract.Update))
+	r.Mux.HandleFunc("DELETE /admin/"+section+"/{uuid}", r.AdminPipelineFor(abstract.Destroy))
 }
 
 func (r *Router) composeFixtures(fxt *Fixture, maker func(file string, cacheEnabled bool) StaticRouteResource) {
@@ -191,6 +335,14 @@ func addStaticRoute[H StaticRouteResource](r *Router, route, fixture string, mak
 	abstract := maker(fixture, !r.Env.App.IsLocal())
 	resolver := r.PipelineFor(abstract.Handle)
 
+	if resource, ok := any(abstract).(FixtureResource); ok {
+		r.Fixtures.Add(resource.Fixture())
+	}
+
 	route = strings.TrimLeft(route, "/")
+
+	if section, ok := any(abstract).(handler.SiteSection); ok {
+		r.Sections.Add(route, section)
+	}
 	r.Mux.HandleFunc("GET /"+route, resolver)
 }
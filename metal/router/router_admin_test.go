@@ -0,0 +1,57 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/metal/router"
+	"github.com/oullin/pkg/auth"
+	"github.com/oullin/pkg/middleware"
+	"github.com/oullin/pkg/portal"
+)
+
+func TestAdminPostsRoutes_RequireSignedRequests(t *testing.T) {
+	key, err := auth.GenerateAESKey()
+	if err != nil {
+		t.Fatalf("key err: %v", err)
+	}
+
+	tokenHandler, err := auth.NewTokensHandler(key)
+	if err != nil {
+		t.Fatalf("token handler err: %v", err)
+	}
+
+	r := router.Router{
+		Mux: http.NewServeMux(),
+		Db:  &database.Connection{},
+		Pipeline: middleware.Pipeline{
+			TokenHandler: tokenHandler,
+			ApiKeys:      &repository.ApiKeys{DB: &database.Connection{}},
+		},
+		Validator: portal.GetDefaultValidator(),
+	}
+
+	r.AdminPosts()
+
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{"POST", "/admin/posts"},
+		{"PUT", "/admin/posts/hello"},
+		{"DELETE", "/admin/posts/hello"},
+		{"POST", "/admin/posts/hello/restore"},
+	}
+
+	for _, rt := range routes {
+		rec := httptest.NewRecorder()
+		r.Mux.ServeHTTP(rec, httptest.NewRequest(rt.method, rt.path, nil))
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("%s %s: expected %d, got %d", rt.method, rt.path, http.StatusUnauthorized, rec.Code)
+		}
+	}
+}
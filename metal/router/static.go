@@ -5,12 +5,19 @@ import (
 
 	"github.com/oullin/metal/env"
 	"github.com/oullin/pkg/endpoint"
+	"github.com/oullin/pkg/fixtures"
 )
 
 type StaticRouteResource interface {
 	Handle(http.ResponseWriter, *http.Request) *endpoint.ApiError
 }
 
+// FixtureResource is a static route served from a fixture snapshot the router keeps
+// reloading when its file changes.
+type FixtureResource interface {
+	Fixture() fixtures.Reloader
+}
+
 type WebsiteRoutes struct {
 	OutputDir string
 	Lang      string
@@ -0,0 +1,286 @@
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+)
+
+var diskLRUNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// DiskLRU is a size-bounded directory of generated files. Once the total size goes over
+// the limit, the least recently used files are removed. The index lives in memory and is
+// rebuilt from the directory, oldest modification first, when the cache is opened.
+type DiskLRU struct {
+	dir      string
+	maxBytes int64
+
+	mu       sync.Mutex
+	size     int64
+	order    *list.List // Front is the most recently used entry.
+	entries  map[string]*list.Element
+	inflight map[string]*diskLRUCall
+}
+
+type diskLRUEntry struct {
+	name string
+	size int64
+}
+
+type diskLRUCall struct {
+	done chan struct{}
+	err  error
+}
+
+func NewDiskLRU(dir string, maxBytes int64) (*DiskLRU, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("disk cache: the size limit must be positive, got %d", maxBytes)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("disk cache: create dir: %w", err)
+	}
+
+	c := &DiskLRU{
+		dir:      dir,
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		inflight: make(map[string]*diskLRUCall),
+	}
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// GetOrCreate opens the named file, calling create to write it on a miss. create receives
+// a temporary path that is moved into place once it returns without error. Concurrent
+// callers for the same name share a single create call.
+//
+// The file is returned open, so it stays readable until the caller closes it even if it
+// is evicted in the meantime.
+func (c *DiskLRU) GetOrCreate(name string, create func(path string) error) (*os.File, error) {
+	if !diskLRUNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("disk cache: invalid name [%s]", name)
+	}
+
+	for {
+		c.mu.Lock()
+
+		if file, ok := c.open(name); ok {
+			c.mu.Unlock()
+
+			return file, nil
+		}
+
+		if call, ok := c.inflight[name]; ok {
+			c.mu.Unlock()
+			<-call.done
+
+			if call.err != nil {
+				return nil, call.err
+			}
+
+			// Look again: the entry may have been evicted before this caller got to it.
+			continue
+		}
+
+		call := &diskLRUCall{done: make(chan struct{})}
+		c.inflight[name] = call
+		c.mu.Unlock()
+
+		file, err := c.create(name, create)
+
+		c.mu.Lock()
+		delete(c.inflight, name)
+		c.mu.Unlock()
+
+		call.err = err
+		close(call.done)
+
+		return file, err
+	}
+}
+
+// Size returns the total size of the cached files, in bytes.
+func (c *DiskLRU) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.size
+}
+
+func (c *DiskLRU) create(name string, create func(path string) error) (*os.File, error) {
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*-"+name)
+	if err != nil {
+		return nil, fmt.Errorf("disk cache: create temp file: %w", err)
+	}
+
+	tmpPath := tmp.Name()
+	_ = tmp.Close()
+
+	if err = create(tmpPath); err != nil {
+		_ = os.Remove(tmpPath)
+
+		return nil, err
+	}
+
+	info, err := os.Stat(tmpPath)
+	if err == nil {
+		err = os.Rename(tmpPath, c.pathFor(name))
+	}
+
+	if err != nil {
+		_ = os.Remove(tmpPath)
+
+		return nil, fmt.Errorf("disk cache: store [%s]: %w", name, err)
+	}
+
+	// Nothing else writes or removes the name while it is in flight, since evicted files
+	// are unlinked under the lock.
+	file, err := os.Open(c.pathFor(name))
+	if err != nil {
+		return nil, fmt.Errorf("disk cache: open [%s]: %w", name, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.add(name, info.Size())
+
+	if err = c.removeEvicted(c.evict(name)); err != nil {
+		_ = file.Close()
+
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// open opens a cached entry and marks it as the most recently used. An entry whose file
+// has gone is forgotten. Callers hold the lock.
+func (c *DiskLRU) open(name string) (*os.File, bool) {
+	element, ok := c.entries[name]
+	if !ok {
+		return nil, false
+	}
+
+	file, err := os.Open(c.pathFor(name))
+	if err != nil {
+		c.order.Remove(element)
+		delete(c.entries, name)
+		c.size -= element.Value.(*diskLRUEntry).size
+
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+
+	return file, true
+}
+
+// removeEvicted unlinks the evicted files. It runs under the lock, so a file being created
+// again under an evicted name cannot be removed by a late unlink. Open files stay readable.
+func (c *DiskLRU) removeEvicted(evicted []string) error {
+	for _, stale := range evicted {
+		if err := os.Remove(c.pathFor(stale)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("disk cache: evict [%s]: %w", stale, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *DiskLRU) add(name string, size int64) {
+	if element, ok := c.entries[name]; ok {
+		c.size -= element.Value.(*diskLRUEntry).size
+		c.order.Remove(element)
+	}
+
+	c.entries[name] = c.order.PushFront(&diskLRUEntry{name: name, size: size})
+	c.size += size
+}
+
+// evict drops the least recently used entries until the cache fits, never the given one.
+func (c *DiskLRU) evict(keep string) []string {
+	var evicted []string
+
+	for c.size > c.maxBytes {
+		element := c.order.Back()
+		if element == nil {
+			break
+		}
+
+		entry := element.Value.(*diskLRUEntry)
+		if entry.name == keep {
+			break
+		}
+
+		c.order.Remove(element)
+		delete(c.entries, entry.name)
+		c.size -= entry.size
+
+		evicted = append(evicted, entry.name)
+	}
+
+	return evicted
+}
+
+func (c *DiskLRU) load() error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("disk cache: read dir: %w", err)
+	}
+
+	type found struct {
+		name    string
+		size    int64
+		modTime time.Time
+	}
+
+	var existing []found
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		// Leftovers from interrupted writes.
+		if !diskLRUNamePattern.MatchString(file.Name()) {
+			_ = os.Remove(filepath.Join(c.dir, file.Name()))
+			continue
+		}
+
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+
+		existing = append(existing, found{name: file.Name(), size: info.Size(), modTime: info.ModTime()})
+	}
+
+	slices.SortFunc(existing, func(a, b found) int {
+		return a.modTime.Compare(b.modTime)
+	})
+
+	for _, file := range existing {
+		c.add(file.name, file.size)
+	}
+
+	_ = c.removeEvicted(c.evict(""))
+
+	return nil
+}
+
+func (c *DiskLRU) pathFor(name string) string {
+	return filepath.Join(c.dir, name)
+}
@@ -0,0 +1,247 @@
+package cache_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/oullin/pkg/cache"
+)
+
+func writeBytes(n int) func(string) error {
+	return func(path string) error {
+		return os.WriteFile(path, make([]byte, n), 0o644)
+	}
+}
+
+// getOrCreate closes the returned file straight away, for tests that only need the entry.
+func getOrCreate(c *cache.DiskLRU, name string, create func(string) error) error {
+	file, err := c.GetOrCreate(name, create)
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+func TestDiskLRU_GetOrCreateCachesTheFile(t *testing.T) {
+	c, err := cache.NewDiskLRU(t.TempDir(), 1024)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	calls := 0
+	create := func(path string) error {
+		calls++
+		return writeBytes(10)(path)
+	}
+
+	first, err := c.GetOrCreate("a.jpg", create)
+	if err != nil {
+		t.Fatalf("first: %v", err)
+	}
+	defer first.Close()
+
+	second, err := c.GetOrCreate("a.jpg", create)
+	if err != nil {
+		t.Fatalf("second: %v", err)
+	}
+	defer second.Close()
+
+	if first.Name() != second.Name() || calls != 1 {
+		t.Fatalf("expected one create call for the same path, got %d (%s, %s)", calls, first.Name(), second.Name())
+	}
+
+	if info, err := second.Stat(); err != nil || info.Size() != 10 {
+		t.Fatalf("expected a 10 bytes file, got %v %v", info, err)
+	}
+
+	if c.Size() != 10 {
+		t.Fatalf("expected size 10, got %d", c.Size())
+	}
+}
+
+func TestDiskLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := cache.NewDiskLRU(dir, 25)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	for _, name := range []string{"a", "b"} {
+		if err := getOrCreate(c, name, writeBytes(10)); err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+	}
+
+	// Touch a so that b becomes the oldest entry.
+	if err := getOrCreate(c, "a", writeBytes(10)); err != nil {
+		t.Fatalf("touch a: %v", err)
+	}
+
+	if err := getOrCreate(c, "c", writeBytes(10)); err != nil {
+		t.Fatalf("create c: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "b")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected b to be evicted, got %v", err)
+	}
+
+	for _, name := range []string{"a", "c"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("expected %s to be kept: %v", name, err)
+		}
+	}
+
+	if c.Size() != 20 {
+		t.Fatalf("expected size 20, got %d", c.Size())
+	}
+}
+
+func TestDiskLRU_EvictedFilesStayReadableWhileOpen(t *testing.T) {
+	c, err := cache.NewDiskLRU(t.TempDir(), 15)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	served, err := c.GetOrCreate("a", func(path string) error { return os.WriteFile(path, []byte("0123456789"), 0o644) })
+	if err != nil {
+		t.Fatalf("create a: %v", err)
+	}
+	defer served.Close()
+
+	// b pushes a out while it is still being served.
+	if err := getOrCreate(c, "b", writeBytes(10)); err != nil {
+		t.Fatalf("create b: %v", err)
+	}
+
+	if _, err := os.Stat(served.Name()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a to be evicted, got %v", err)
+	}
+
+	body, err := io.ReadAll(served)
+	if err != nil || string(body) != "0123456789" {
+		t.Fatalf("expected the open file to stay readable, got %q %v", body, err)
+	}
+}
+
+func TestDiskLRU_RebuildsFromDisk(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	for i, name := range []string{"old", "mid", "new"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, make([]byte, 10), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+
+		stamp := now.Add(time.Duration(i-3) * time.Minute)
+		if err := os.Chtimes(path, stamp, stamp); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".tmp-123-new"), []byte("partial"), 0o644); err != nil {
+		t.Fatalf("write temp: %v", err)
+	}
+
+	c, err := cache.NewDiskLRU(dir, 20)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	if c.Size() != 20 {
+		t.Fatalf("expected size 20, got %d", c.Size())
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "old")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the oldest file to be evicted, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".tmp-123-new")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected leftovers to be removed, got %v", err)
+	}
+
+	calls := 0
+	if err := getOrCreate(c, "new", func(string) error { calls++; return nil }); err != nil || calls != 0 {
+		t.Fatalf("expected a hit for an existing file, got %d calls, %v", calls, err)
+	}
+}
+
+func TestDiskLRU_FailedCreateIsNotCached(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := cache.NewDiskLRU(dir, 100)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	boom := errors.New("boom")
+	if err := getOrCreate(c, "a", func(string) error { return boom }); !errors.Is(err, boom) {
+		t.Fatalf("expected the create error, got %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Fatalf("expected no files after a failed create, got %d", len(entries))
+	}
+
+	if err := getOrCreate(c, "a", writeBytes(1)); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+}
+
+func TestDiskLRU_RejectsInvalidNames(t *testing.T) {
+	c, err := cache.NewDiskLRU(t.TempDir(), 100)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	for _, name := range []string{"", "../a", "a/b", ".hidden"} {
+		if err := getOrCreate(c, name, writeBytes(1)); err == nil {
+			t.Fatalf("expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestDiskLRU_ConcurrentCallersShareCreate(t *testing.T) {
+	c, err := cache.NewDiskLRU(t.TempDir(), 100)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	create := func(path string) error {
+		calls.Add(1)
+		<-release
+
+		return writeBytes(1)(path)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := getOrCreate(c, "shared", create); err != nil {
+				t.Errorf("get: %v", err)
+			}
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected a single create call, got %d", calls.Load())
+	}
+}
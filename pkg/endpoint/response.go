@@ -87,6 +87,22 @@ func NewResponseForPayload(payload any, maxAgeSeconds int, cacheEnabled bool, wr
 	return resp, err
 }
 
+// NewResponseForBody serves JSON encoded up front, e.g. a fixture snapshot, with the ETag
+// made of its precomputed checksum, so nothing is encoded or hashed per request.
+func NewResponseForBody(body []byte, checksum string, maxAgeSeconds int, cacheEnabled bool, writer http.ResponseWriter, request *http.Request) *Response {
+	var resp *Response
+
+	if cacheEnabled && len(body) <= MaxResponseCacheSize {
+		resp = NewResponseWithCache(checksum, maxAgeSeconds, writer, request)
+	} else {
+		resp = NewNoCacheResponse(writer, request)
+	}
+
+	resp.body = body
+
+	return resp
+}
+
 func NewNoCacheResponse(writer http.ResponseWriter, request *http.Request) *Response {
 	cacheControl := "no-store"
 
@@ -106,7 +122,32 @@ func (r *Response) WithHeaders(callback func(w http.ResponseWriter)) {
 	callback(r.writer)
 }
 
+// WithContentType serves the body as the given media type rather than as JSON.
+func (r *Response) WithContentType(contentType string) *Response {
+	headers := r.headers
+
+	r.headers = func(w http.ResponseWriter) {
+		headers(w)
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	return r
+}
+
 func (r *Response) RespondOk(payload any) error {
+	return r.RespondWithStatus(http.StatusOK, payload)
+}
+
+func (r *Response) RespondCreated(payload any) error {
+	return r.RespondWithStatus(http.StatusCreated, payload)
+}
+
+func (r *Response) RespondNoContent() {
+	r.headers(r.writer)
+	r.writer.WriteHeader(http.StatusNoContent)
+}
+
+func (r *Response) RespondWithStatus(status int, payload any) error {
 	body := r.body
 	if len(body) == 0 {
 		var err error
@@ -118,7 +159,7 @@ func (r *Response) RespondOk(payload any) error {
 
 	w := r.writer
 	r.headers(w)
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(status)
 
 	_, err := w.Write(body)
 
@@ -204,6 +245,26 @@ func UnprocessableEntity(msg string, errs map[string]any) *ApiError {
 	}
 }
 
+func Conflict(msg string) *ApiError {
+	message := fmt.Sprintf("Conflict error: %s", msg)
+
+	return &ApiError{
+		Message: message,
+		Status:  http.StatusConflict,
+		Err:     errors.New(message),
+	}
+}
+
+func PayloadTooLarge(msg string) *ApiError {
+	message := fmt.Sprintf("Payload too large: %s", msg)
+
+	return &ApiError{
+		Message: message,
+		Status:  http.StatusRequestEntityTooLarge,
+		Err:     errors.New(message),
+	}
+}
+
 func NotFound(msg string) *ApiError {
 	message := fmt.Sprintf("Not found error: %s", msg)
 
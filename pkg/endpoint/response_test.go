@@ -163,6 +163,37 @@ func TestResponse_ForPayloadDisablesCache(t *testing.T) {
 	}
 }
 
+func TestResponse_ForBodyUsesThePrecomputedChecksum(t *testing.T) {
+	body := []byte(`{"a":"b"}`)
+	payload := &marshalCounter{}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	rec := httptest.NewRecorder()
+
+	r := endpoint.NewResponseForBody(body, "abc123", 3600, true, rec, req)
+	if err := r.RespondOk(payload); err != nil {
+		t.Fatalf("respond ok: %v", err)
+	}
+
+	if rec.Header().Get("ETag") != `"abc123"` || rec.Body.String() != string(body) || payload.calls.Load() != 0 {
+		t.Fatalf("expected the body as is, got %q %q after %d marshal calls", rec.Header().Get("ETag"), rec.Body.String(), payload.calls.Load())
+	}
+
+	req.Header.Set("If-None-Match", `"abc123"`)
+	if !endpoint.NewResponseForBody(body, "abc123", 3600, true, httptest.NewRecorder(), req).HasCache() {
+		t.Fatalf("expected the checksum to match")
+	}
+
+	rec = httptest.NewRecorder()
+	if err := endpoint.NewResponseForBody(body, "abc123", 3600, false, rec, req).RespondOk(nil); err != nil {
+		t.Fatalf("respond ok: %v", err)
+	}
+
+	if rec.Header().Get("ETag") != "" || rec.Header().Get("Cache-Control") != "no-store" || rec.Body.String() != string(body) {
+		t.Fatalf("expected an uncached body, got %q %q", rec.Header().Get("Cache-Control"), rec.Body.String())
+	}
+}
+
 func TestResponse_WithHeaders(t *testing.T) {
 	req := httptest.NewRequest("GET", "/", nil)
 	rec := httptest.NewRecorder()
@@ -241,3 +272,23 @@ func TestApiErrorHelpers(t *testing.T) {
 		t.Fatalf("expected not found status %d", http.StatusNotFound)
 	}
 }
+
+func TestResponse_RespondCreatedAndNoContent(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", nil)
+	rec := httptest.NewRecorder()
+
+	if err := endpoint.NewNoCacheResponse(rec, req).RespondCreated(map[string]string{"a": "b"}); err != nil {
+		t.Fatalf("respond created: %v", err)
+	}
+
+	if rec.Code != http.StatusCreated || rec.Body.Len() == 0 {
+		t.Fatalf("expected 201 with body, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	endpoint.NewNoCacheResponse(rec, req).RespondNoContent()
+
+	if rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+		t.Fatalf("expected empty 204, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,216 @@
+package fixtures
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Snapshot is one good version of a fixture: the parsed data, its JSON encoding and the
+// checksum the ETag is made of. Snapshots are shared by every request and must not be
+// modified.
+type Snapshot[T any] struct {
+	Data     T
+	Body     []byte
+	Checksum string
+	LoadedAt time.Time
+}
+
+// Source keeps the current snapshot of a fixture file. A reload swaps the snapshot in one
+// go, and one that fails keeps the last good snapshot in place.
+type Source[T any] struct {
+	path    string
+	load    func() (T, error)
+	prepare func(*T) error
+	current atomic.Pointer[Snapshot[T]]
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	err     error
+}
+
+// NewSource loads the file right away. Prepare, when given, validates the parsed data and
+// may normalise it, e.g. by sorting, before it is frozen into the snapshot. A file that
+// fails to load is logged and retried on the next reload.
+func NewSource[T any](path string, prepare func(*T) error) *Source[T] {
+	source := &Source[T]{path: path, prepare: prepare}
+
+	if _, err := source.Reload(true); err != nil {
+		slog.Error("fixtures: could not load fixture", "path", path, "error", err)
+	}
+
+	return source
+}
+
+// NewLoadedSource reads the data through load, e.g. from the database, rather than from
+// file, the fixture load falls back to. A poll only calls load once that file changed, so
+// it costs a stat rather than a query; forced reloads, e.g. after an admin write or on
+// SIGHUP, always call it and report a new snapshot when the encoded data changed.
+func NewLoadedSource[T any](file string, load func() (T, error), prepare func(*T) error) *Source[T] {
+	source := &Source[T]{path: file, load: load, prepare: prepare}
+
+	if _, err := source.Reload(true); err != nil {
+		slog.Error("fixtures: could not load fixture", "path", file, "error", err)
+	}
+
+	return source
+}
+
+func (s *Source[T]) Path() string {
+	return s.path
+}
+
+// Current returns the snapshot being served, or the load error while there has never
+// been a good one.
+func (s *Source[T]) Current() (*Snapshot[T], error) {
+	if snapshot := s.current.Load(); snapshot != nil {
+		return snapshot, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return nil, fmt.Errorf("fixtures: %s is not loaded", s.path)
+}
+
+// Reload reads the file again when its modification time or size changed since the last
+// attempt, or always when forced. It reports whether a new snapshot is being served.
+// A failed attempt is not retried until the file changes again, so a broken edit is
+// reported once rather than on every poll.
+func (s *Source[T]) Reload(force bool) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.load != nil {
+		if !s.fileChanged() && !force {
+			return false, nil
+		}
+
+		return s.reloadFromLoader()
+	}
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		s.err = fmt.Errorf("fixtures: stat %s: %w", s.path, err)
+
+		return false, s.err
+	}
+
+	if !force && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return false, nil
+	}
+
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+
+	snapshot, err := s.readFile()
+	if err != nil {
+		s.err = err
+
+		return false, err
+	}
+
+	s.err = nil
+	s.current.Store(snapshot)
+
+	return true, nil
+}
+
+// fileChanged records the modification time and size of the fallback file of a loaded
+// source and tells whether they moved. A missing file has nothing to report.
+func (s *Source[T]) fileChanged() bool {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false
+	}
+
+	if info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return false
+	}
+
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+
+	return true
+}
+
+func (s *Source[T]) reloadFromLoader() (bool, error) {
+	data, err := s.load()
+	if err != nil {
+		s.err = fmt.Errorf("fixtures: load %s: %w", s.path, err)
+
+		return false, s.err
+	}
+
+	snapshot, err := s.freeze(data)
+	if err != nil {
+		s.err = err
+
+		return false, err
+	}
+
+	s.err = nil
+
+	if previous := s.current.Load(); previous != nil && previous.Checksum == snapshot.Checksum {
+		return false, nil
+	}
+
+	s.current.Store(snapshot)
+
+	return true, nil
+}
+
+func (s *Source[T]) readFile() (*Snapshot[T], error) {
+	data, err := ReadFile[T](s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.freeze(data)
+}
+
+// ReadFile parses a fixture file as it is, without preparing it.
+func ReadFile[T any](path string) (T, error) {
+	var data T
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return data, fmt.Errorf("fixtures: read %s: %w", path, err)
+	}
+
+	if err = json.Unmarshal(raw, &data); err != nil {
+		return data, fmt.Errorf("fixtures: parse %s: %w", path, err)
+	}
+
+	return data, nil
+}
+
+func (s *Source[T]) freeze(data T) (*Snapshot[T], error) {
+	if s.prepare != nil {
+		if err := s.prepare(&data); err != nil {
+			return nil, fmt.Errorf("fixtures: invalid %s: %w", s.path, err)
+		}
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("fixtures: encode %s: %w", s.path, err)
+	}
+
+	return &Snapshot[T]{
+		Data:     data,
+		Body:     body,
+		Checksum: fmt.Sprintf("%x", sha256.Sum256(body)),
+		LoadedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,173 @@
+package fixtures
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type talks struct {
+	Version string   `json:"version"`
+	Data    []string `json:"data"`
+}
+
+func writeFixture(t *testing.T, path, body string, modTime time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	// Pin the modification time so quick successive writes are still told apart.
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+}
+
+func TestSourceServesASnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "talks.json")
+	writeFixture(t, path, `{"version":"1", "data":["b","a"]}`, time.Unix(1000, 0))
+
+	source := NewSource(path, func(data *talks) error {
+		data.Data[0], data.Data[1] = data.Data[1], data.Data[0]
+
+		return nil
+	})
+
+	snapshot, err := source.Current()
+	if err != nil {
+		t.Fatalf("current: %v", err)
+	}
+
+	if string(snapshot.Body) != `{"version":"1","data":["a","b"]}` || snapshot.Checksum == "" {
+		t.Fatalf("unexpected snapshot %s %q", snapshot.Body, snapshot.Checksum)
+	}
+
+	if again, _ := source.Current(); again != snapshot {
+		t.Fatalf("expected the same snapshot until the file changes")
+	}
+}
+
+func TestSourceReloadsChangedFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "talks.json")
+	writeFixture(t, path, `{"version":"1"}`, time.Unix(1000, 0))
+
+	source := NewSource[talks](path, nil)
+	first, _ := source.Current()
+
+	if reloaded, err := source.Reload(false); reloaded || err != nil {
+		t.Fatalf("expected an unchanged file to be skipped, got %t %v", reloaded, err)
+	}
+
+	writeFixture(t, path, `{"version":"2"}`, time.Unix(2000, 0))
+
+	if reloaded, err := source.Reload(false); !reloaded || err != nil {
+		t.Fatalf("expected the changed file to be reloaded, got %t %v", reloaded, err)
+	}
+
+	second, _ := source.Current()
+	if second.Data.Version != "2" || second.Checksum == first.Checksum || first.Data.Version != "1" {
+		t.Fatalf("expected a new snapshot next to the untouched old one, got %+v and %+v", first.Data, second.Data)
+	}
+}
+
+func TestSourceKeepsTheLastGoodSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "talks.json")
+	writeFixture(t, path, `{"version":"1"}`, time.Unix(1000, 0))
+
+	invalid := errors.New("version 3 is not allowed")
+	source := NewSource(path, func(data *talks) error {
+		if data.Version == "3" {
+			return invalid
+		}
+
+		return nil
+	})
+
+	for i, body := range []string{`{"version":`, `{"version":"3"}`} {
+		writeFixture(t, path, body, time.Unix(int64(2000+i), 0))
+
+		if reloaded, err := source.Reload(false); reloaded || err == nil {
+			t.Fatalf("expected %s to be refused, got %t %v", body, reloaded, err)
+		}
+
+		if snapshot, err := source.Current(); err != nil || snapshot.Data.Version != "1" {
+			t.Fatalf("expected the last good snapshot, got %+v %v", snapshot, err)
+		}
+	}
+
+	// The broken version is not parsed again on every poll, only once it changes.
+	if reloaded, err := source.Reload(false); reloaded || err != nil {
+		t.Fatalf("expected the refused file to be skipped until it changes, got %t %v", reloaded, err)
+	}
+}
+
+func TestSourceReportsAFixtureThatNeverLoaded(t *testing.T) {
+	source := NewSource[talks](filepath.Join(t.TempDir(), "missing.json"), nil)
+
+	if _, err := source.Current(); err == nil {
+		t.Fatalf("expected an error without a good snapshot")
+	}
+}
+
+func TestLoadedSourceSwapsOnlyWhenTheDataChanges(t *testing.T) {
+	data := talks{Version: "1", Data: []string{"a"}}
+	var failure error
+
+	source := NewLoadedSource("database:talks", func() (talks, error) {
+		return data, failure
+	}, nil)
+
+	first, err := source.Current()
+	if err != nil || string(first.Body) != `{"version":"1","data":["a"]}` {
+		t.Fatalf("unexpected first snapshot %v %v", first, err)
+	}
+
+	if reloaded, err := source.Reload(true); reloaded || err != nil {
+		t.Fatalf("expected unchanged data to keep the snapshot, got %v %v", reloaded, err)
+	}
+
+	data.Data = []string{"a", "b"}
+
+	if reloaded, err := source.Reload(true); !reloaded || err != nil {
+		t.Fatalf("expected changed data to swap the snapshot, got %v %v", reloaded, err)
+	}
+
+	failure = errors.New("connection refused")
+
+	if _, err := source.Reload(true); err == nil {
+		t.Fatalf("expected the load error")
+	}
+
+	if current, err := source.Current(); err != nil || string(current.Body) != `{"version":"1","data":["a","b"]}` {
+		t.Fatalf("expected the last good snapshot, got %v %v", current, err)
+	}
+}
+
+func TestLoadedSourcePollsOnlyWhenTheFallbackFileChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "talks.json")
+	writeFixture(t, path, `{"version":"1"}`, time.Unix(1000, 0))
+
+	calls := 0
+	source := NewLoadedSource(path, func() (talks, error) {
+		calls++
+
+		return ReadFile[talks](path)
+	}, nil)
+
+	if reloaded, err := source.Reload(false); reloaded || err != nil || calls != 1 {
+		t.Fatalf("expected the poll to skip the loader, got %t %v after %d calls", reloaded, err, calls)
+	}
+
+	writeFixture(t, path, `{"version":"2"}`, time.Unix(2000, 0))
+
+	if reloaded, err := source.Reload(false); !reloaded || err != nil || calls != 2 {
+		t.Fatalf("expected the changed file to be loaded, got %t %v after %d calls", reloaded, err, calls)
+	}
+
+	if reloaded, err := source.Reload(true); reloaded || err != nil || calls != 3 {
+		t.Fatalf("expected a forced reload to call the loader, got %t %v after %d calls", reloaded, err, calls)
+	}
+}
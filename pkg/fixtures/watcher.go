@@ -0,0 +1,91 @@
+package fixtures
+
+import (
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// PollInterval is how often the watched fixture files are checked for changes.
+const PollInterval = 5 * time.Second
+
+// Reloader is anything a Watcher keeps fresh; every Source is one.
+type Reloader interface {
+	Path() string
+	Reload(force bool) (bool, error)
+}
+
+// Watcher reloads its sources when their files change, and all of them on SIGHUP.
+type Watcher struct {
+	mu      sync.Mutex
+	sources []Reloader
+}
+
+func NewWatcher() *Watcher {
+	return &Watcher{}
+}
+
+// Add watches the source. A nil watcher ignores it, so code that builds routes without
+// one keeps working.
+func (w *Watcher) Add(source Reloader) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.sources = append(w.sources, source)
+}
+
+// ReloadAll reloads every changed source, or every source when forced, and logs the
+// outcome. Sources that fail keep serving their last good snapshot.
+func (w *Watcher) ReloadAll(force bool) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	sources := append([]Reloader(nil), w.sources...)
+	w.mu.Unlock()
+
+	for _, source := range sources {
+		reloaded, err := source.Reload(force)
+
+		switch {
+		case err != nil:
+			slog.Error("fixtures: keeping the last good version", "path", source.Path(), "error", err)
+		case reloaded:
+			slog.Info("fixtures: reloaded", "path", source.Path())
+		}
+	}
+}
+
+// Start polls the sources every interval and reloads all of them on SIGHUP, for as long
+// as the process runs.
+func (w *Watcher) Start(interval time.Duration) {
+	if w == nil {
+		return
+	}
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				w.ReloadAll(false)
+			case <-hangup:
+				slog.Info("fixtures: SIGHUP received, reloading every fixture")
+				w.ReloadAll(true)
+			}
+		}
+	}()
+}
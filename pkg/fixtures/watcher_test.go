@@ -0,0 +1,44 @@
+package fixtures
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcherReloadsItsSources(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.json")
+	second := filepath.Join(dir, "second.json")
+
+	writeFixture(t, first, `{"version":"1"}`, time.Unix(1000, 0))
+	writeFixture(t, second, `{"version":"1"}`, time.Unix(1000, 0))
+
+	watcher := NewWatcher()
+	sources := []*Source[talks]{NewSource[talks](first, nil), NewSource[talks](second, nil)}
+
+	for _, source := range sources {
+		watcher.Add(source)
+	}
+
+	writeFixture(t, first, `{"version":"2"}`, time.Unix(2000, 0))
+	writeFixture(t, second, `{"version":`, time.Unix(2000, 0))
+
+	watcher.ReloadAll(false)
+
+	if snapshot, _ := sources[0].Current(); snapshot.Data.Version != "2" {
+		t.Fatalf("expected the changed fixture to be reloaded, got %+v", snapshot.Data)
+	}
+
+	if snapshot, _ := sources[1].Current(); snapshot.Data.Version != "1" {
+		t.Fatalf("expected the broken fixture to keep its last good version, got %+v", snapshot.Data)
+	}
+}
+
+func TestNilWatcherIgnoresSources(t *testing.T) {
+	var watcher *Watcher
+
+	watcher.Add(NewSource[talks](filepath.Join(t.TempDir(), "missing.json"), nil))
+	watcher.ReloadAll(true)
+	watcher.Start(time.Hour)
+}
@@ -0,0 +1,261 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	stdimage "image"
+
+	"golang.org/x/image/draw"
+)
+
+// EXIF orientations, see https://www.exif.org/Exif2-2.PDF (tag 0x0112).
+const (
+	OrientationNormal     = 1
+	OrientationFlipH      = 2
+	OrientationRotate180  = 3
+	OrientationFlipV      = 4
+	OrientationTranspose  = 5
+	OrientationRotate90   = 6 // Rotate 90° clockwise to display.
+	OrientationTransverse = 7
+	OrientationRotate270  = 8 // Rotate 90° counter-clockwise to display.
+)
+
+const exifOrientationTag = 0x0112
+
+var (
+	jpegSignature = []byte{0xFF, 0xD8}
+	pngSignature  = []byte("\x89PNG\r\n\x1a\n")
+	exifHeader    = []byte("Exif\x00\x00")
+)
+
+// ReadOrientation returns the EXIF orientation of a JPEG, PNG or WebP payload, or
+// OrientationNormal when the tag is missing or cannot be read.
+func ReadOrientation(data []byte) int {
+	tiff := exifPayload(data)
+	if tiff == nil {
+		return OrientationNormal
+	}
+
+	orientation := tiffOrientation(tiff)
+	if orientation < OrientationNormal || orientation > OrientationRotate270 {
+		return OrientationNormal
+	}
+
+	return orientation
+}
+
+// ApplyOrientation returns the image as it is meant to be displayed for the given EXIF
+// orientation. Orientations 5 to 8 swap the width and the height.
+func ApplyOrientation(img stdimage.Image, orientation int) stdimage.Image {
+	if img == nil || orientation <= OrientationNormal || orientation > OrientationRotate270 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	src := stdimage.NewRGBA(stdimage.Rect(0, 0, width, height))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	dstWidth, dstHeight := width, height
+	if orientation >= OrientationTranspose {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := stdimage.NewRGBA(stdimage.Rect(0, 0, dstWidth, dstHeight))
+
+	for sy := 0; sy < height; sy++ {
+		for sx := 0; sx < width; sx++ {
+			var dx, dy int
+
+			switch orientation {
+			case OrientationFlipH:
+				dx, dy = width-1-sx, sy
+			case OrientationRotate180:
+				dx, dy = width-1-sx, height-1-sy
+			case OrientationFlipV:
+				dx, dy = sx, height-1-sy
+			case OrientationTranspose:
+				dx, dy = sy, sx
+			case OrientationRotate90:
+				dx, dy = height-1-sy, sx
+			case OrientationTransverse:
+				dx, dy = height-1-sy, width-1-sx
+			case OrientationRotate270:
+				dx, dy = sy, width-1-sx
+			}
+
+			from := src.PixOffset(sx, sy)
+			to := dst.PixOffset(dx, dy)
+
+			copy(dst.Pix[to:to+4], src.Pix[from:from+4])
+		}
+	}
+
+	return dst
+}
+
+// exifPayload returns the TIFF structure embedded in the EXIF block of the payload.
+func exifPayload(data []byte) []byte {
+	var block []byte
+
+	switch {
+	case bytes.HasPrefix(data, jpegSignature):
+		for _, segment := range jpegSegments(data) {
+			if segment.marker == 0xE1 && bytes.HasPrefix(segment.payload, exifHeader) {
+				block = segment.payload
+				break
+			}
+		}
+	case bytes.HasPrefix(data, pngSignature):
+		for _, chunk := range pngChunks(data) {
+			if chunk.kind == "eXIf" {
+				block = chunk.payload
+				break
+			}
+		}
+	case isWebP(data):
+		for _, chunk := range webpChunks(data) {
+			if chunk.kind == "EXIF" {
+				block = chunk.payload
+				break
+			}
+		}
+	}
+
+	return bytes.TrimPrefix(block, exifHeader)
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	if order.Uint16(tiff[2:4]) != 42 {
+		return 0
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 0
+	}
+
+	entries := int(order.Uint16(tiff[offset : offset+2]))
+
+	for i := 0; i < entries; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+
+		if order.Uint16(tiff[entry:entry+2]) == exifOrientationTag {
+			return int(order.Uint16(tiff[entry+8 : entry+10]))
+		}
+	}
+
+	return 0
+}
+
+type jpegSegment struct {
+	marker  byte
+	start   int // Offset of the 0xFF marker byte.
+	end     int // Offset right after the segment.
+	payload []byte
+}
+
+// jpegSegments lists the marker segments that precede the scan data.
+func jpegSegments(data []byte) []jpegSegment {
+	var segments []jpegSegment
+
+	for i := len(jpegSignature); i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return segments
+		}
+
+		marker := data[i+1]
+		if marker == 0xFF { // Fill byte.
+			i++
+			continue
+		}
+
+		if marker == 0xDA || marker == 0xD9 { // Start of scan or end of image.
+			return segments
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		end := i + 2 + length
+
+		if length < 2 || end > len(data) {
+			return segments
+		}
+
+		segments = append(segments, jpegSegment{marker: marker, start: i, end: end, payload: data[i+4 : end]})
+		i = end
+	}
+
+	return segments
+}
+
+type imageChunk struct {
+	kind    string
+	start   int
+	end     int
+	payload []byte
+}
+
+func pngChunks(data []byte) []imageChunk {
+	var chunks []imageChunk
+
+	for i := len(pngSignature); i+12 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[i : i+4]))
+		end := i + 12 + length
+
+		if length < 0 || end > len(data) {
+			return chunks
+		}
+
+		kind := string(data[i+4 : i+8])
+		chunks = append(chunks, imageChunk{kind: kind, start: i, end: end, payload: data[i+8 : i+8+length]})
+
+		if kind == "IEND" {
+			return chunks
+		}
+
+		i = end
+	}
+
+	return chunks
+}
+
+func isWebP(data []byte) bool {
+	return len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP"
+}
+
+func webpChunks(data []byte) []imageChunk {
+	var chunks []imageChunk
+
+	for i := 12; i+8 <= len(data); {
+		length := int(binary.LittleEndian.Uint32(data[i+4 : i+8]))
+		end := i + 8 + length + length%2 // Chunks are padded to an even size.
+
+		if length < 0 || i+8+length > len(data) {
+			return chunks
+		}
+
+		chunks = append(chunks, imageChunk{kind: string(data[i : i+4]), start: i, end: min(end, len(data)), payload: data[i+8 : i+8+length]})
+		i = end
+	}
+
+	return chunks
+}
@@ -0,0 +1,325 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	stdimage "image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// exifBlock builds an APP1-style EXIF payload holding an orientation and a GPS IFD pointer.
+func exifBlock(orientation uint16) []byte {
+	tiff := []byte("II")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 42)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 8)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+
+	// Orientation, SHORT, one value.
+	tiff = binary.LittleEndian.AppendUint16(tiff, exifOrientationTag)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, orientation)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0)
+
+	// GPS IFD pointer, LONG, one value.
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x8825)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 4)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 38)
+
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0)
+	tiff = append(tiff, []byte("GPSLatitude 51.5072N")...)
+
+	return append(append([]byte{}, exifHeader...), tiff...)
+}
+
+func jpegSegmentBytes(marker byte, payload []byte) []byte {
+	segment := []byte{0xFF, marker}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+
+	return append(segment, payload...)
+}
+
+func pngChunkBytes(kind string, payload []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	chunk = append(chunk, kind...)
+	chunk = append(chunk, payload...)
+
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// markedImage is w x h with a red pixel in the top-left corner, so transforms can be traced.
+func markedImage(width, height int) *stdimage.RGBA {
+	img := stdimage.NewRGBA(stdimage.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{B: 255, A: 255})
+		}
+	}
+
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+
+	return img
+}
+
+func jpegWithMetadata(t *testing.T, img stdimage.Image, orientation uint16) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	encoded := buf.Bytes()
+
+	var out bytes.Buffer
+	out.Write(encoded[:2])
+	out.Write(jpegSegmentBytes(0xE1, exifBlock(orientation)))
+	out.Write(jpegSegmentBytes(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta>GPSLongitude</x:xmpmeta>")))
+	out.Write(jpegSegmentBytes(0xE2, []byte("ICC_PROFILE\x00\x01\x01profile")))
+	out.Write(jpegSegmentBytes(0xFE, []byte("shot at home")))
+	out.Write(encoded[2:])
+
+	return out.Bytes()
+}
+
+func TestReadOrientation(t *testing.T) {
+	t.Parallel()
+
+	img := markedImage(4, 2)
+
+	if got := ReadOrientation(jpegWithMetadata(t, img, OrientationRotate90)); got != OrientationRotate90 {
+		t.Fatalf("expected orientation 6, got %d", got)
+	}
+
+	var plain bytes.Buffer
+	if err := jpeg.Encode(&plain, img, nil); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	if got := ReadOrientation(plain.Bytes()); got != OrientationNormal {
+		t.Fatalf("expected the default orientation without EXIF, got %d", got)
+	}
+
+	if got := ReadOrientation(jpegWithMetadata(t, img, 42)); got != OrientationNormal {
+		t.Fatalf("expected out of range values to be ignored, got %d", got)
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	t.Parallel()
+
+	// Where the top-left pixel of a 3x2 image lands, and the resulting size.
+	cases := map[int]struct {
+		x, y, width, height int
+	}{
+		OrientationNormal:     {0, 0, 3, 2},
+		OrientationFlipH:      {2, 0, 3, 2},
+		OrientationRotate180:  {2, 1, 3, 2},
+		OrientationFlipV:      {0, 1, 3, 2},
+		OrientationTranspose:  {0, 0, 2, 3},
+		OrientationRotate90:   {1, 0, 2, 3},
+		OrientationTransverse: {1, 2, 2, 3},
+		OrientationRotate270:  {0, 2, 2, 3},
+	}
+
+	for orientation, want := range cases {
+		got := ApplyOrientation(markedImage(3, 2), orientation)
+
+		if got.Bounds().Dx() != want.width || got.Bounds().Dy() != want.height {
+			t.Fatalf("orientation %d: unexpected size %v", orientation, got.Bounds())
+		}
+
+		if r, _, _, _ := got.At(want.x, want.y).RGBA(); r>>8 != 255 {
+			t.Fatalf("orientation %d: expected the marked pixel at %d,%d", orientation, want.x, want.y)
+		}
+	}
+}
+
+func TestDecodeAppliesOrientation(t *testing.T) {
+	t.Parallel()
+
+	img, _, err := Decode(jpegWithMetadata(t, markedImage(40, 20), OrientationRotate90))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if img.Bounds().Dx() != 20 || img.Bounds().Dy() != 40 {
+		t.Fatalf("expected the image to be turned upright, got %v", img.Bounds())
+	}
+}
+
+func TestDecodeReaderAppliesOrientation(t *testing.T) {
+	t.Parallel()
+
+	img, format, err := DecodeReader(bytes.NewReader(jpegWithMetadata(t, markedImage(40, 20), OrientationRotate90)))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if format != "jpeg" || img.Bounds().Dx() != 20 || img.Bounds().Dy() != 40 {
+		t.Fatalf("expected an upright jpeg, got %s %v", format, img.Bounds())
+	}
+}
+
+func TestDecodeRejectsTooManyPixels(t *testing.T) {
+	t.Parallel()
+
+	// Only the header is needed: the dimensions are refused before any pixel is read.
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], 20000)
+	binary.BigEndian.PutUint32(ihdr[8:], 20000)
+	ihdr[12], ihdr[13] = 8, 0 // 8-bit greyscale.
+
+	header := append([]byte(nil), pngSignature...)
+	header = binary.BigEndian.AppendUint32(header, 13)
+	header = append(header, ihdr...)
+	header = binary.BigEndian.AppendUint32(header, crc32.ChecksumIEEE(ihdr))
+
+	if _, _, err := Decode(header); !errors.Is(err, ErrTooManyPixels) {
+		t.Fatalf("expected ErrTooManyPixels from Decode, got %v", err)
+	}
+
+	if _, _, err := DecodeReader(bytes.NewReader(header)); !errors.Is(err, ErrTooManyPixels) {
+		t.Fatalf("expected ErrTooManyPixels from DecodeReader, got %v", err)
+	}
+}
+
+func assertNoMetadata(t *testing.T, data []byte) {
+	t.Helper()
+
+	for _, marker := range []string{"Exif", "GPS", "xmpmeta", "shot at home"} {
+		if bytes.Contains(data, []byte(marker)) {
+			t.Fatalf("expected %q to be stripped", marker)
+		}
+	}
+}
+
+func TestSanitiseJPEG(t *testing.T) {
+	t.Parallel()
+
+	source := jpegWithMetadata(t, markedImage(40, 20), OrientationNormal)
+
+	clean, err := Sanitise(source)
+	if err != nil {
+		t.Fatalf("sanitise: %v", err)
+	}
+
+	assertNoMetadata(t, clean)
+
+	if !bytes.Contains(clean, []byte("ICC_PROFILE")) {
+		t.Fatalf("expected the colour profile to be kept")
+	}
+
+	if _, err = jpeg.Decode(bytes.NewReader(clean)); err != nil {
+		t.Fatalf("expected a decodable jpeg: %v", err)
+	}
+
+	rotated, err := Sanitise(jpegWithMetadata(t, markedImage(40, 20), OrientationRotate270))
+	if err != nil {
+		t.Fatalf("sanitise rotated: %v", err)
+	}
+
+	assertNoMetadata(t, rotated)
+
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(rotated))
+	if err != nil || cfg.Width != 20 || cfg.Height != 40 {
+		t.Fatalf("expected the rotated jpeg to be stored upright, got %+v err %v", cfg, err)
+	}
+}
+
+func TestSanitisePNG(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, markedImage(3, 2)); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	chunks := pngChunks(buf.Bytes())
+
+	var source bytes.Buffer
+	source.Write(pngSignature)
+	source.Write(buf.Bytes()[chunks[0].start:chunks[0].end])
+	source.Write(pngChunkBytes("eXIf", exifBlock(OrientationNormal)[len(exifHeader):]))
+	source.Write(pngChunkBytes("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00<x:xmpmeta>GPS</x:xmpmeta>")))
+	source.Write(pngChunkBytes("tEXt", []byte("Comment\x00shot at home")))
+	source.Write(buf.Bytes()[chunks[0].end:])
+
+	clean, err := Sanitise(source.Bytes())
+	if err != nil {
+		t.Fatalf("sanitise: %v", err)
+	}
+
+	assertNoMetadata(t, clean)
+
+	if !bytes.Equal(clean, buf.Bytes()) {
+		t.Fatalf("expected only the metadata chunks to be dropped")
+	}
+}
+
+func TestSanitiseWebP(t *testing.T) {
+	t.Parallel()
+
+	vp8x := make([]byte, 10)
+	vp8x[0] = webpEXIFFlag | webpXMPFlag | 0x10 // 0x10 flags an alpha channel and must survive.
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+
+	for _, chunk := range []struct {
+		kind    string
+		payload []byte
+	}{
+		{"VP8X", vp8x},
+		{"VP8L", []byte{1, 2, 3}},
+		{"EXIF", exifBlock(OrientationNormal)},
+		{"XMP ", []byte("<x:xmpmeta>GPS</x:xmpmeta>")},
+	} {
+		body.WriteString(chunk.kind)
+		_ = binary.Write(&body, binary.LittleEndian, uint32(len(chunk.payload)))
+		body.Write(chunk.payload)
+
+		if len(chunk.payload)%2 == 1 {
+			body.WriteByte(0)
+		}
+	}
+
+	source := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(body.Len()))...)
+	source = append(source, body.Bytes()...)
+
+	clean, err := Sanitise(source)
+	if err != nil {
+		t.Fatalf("sanitise: %v", err)
+	}
+
+	assertNoMetadata(t, clean)
+
+	if got := binary.LittleEndian.Uint32(clean[4:8]); int(got) != len(clean)-8 {
+		t.Fatalf("expected the RIFF size to match, got %d for %d bytes", got, len(clean))
+	}
+
+	chunks := webpChunks(clean)
+	if len(chunks) != 2 || chunks[0].kind != "VP8X" || chunks[1].kind != "VP8L" {
+		t.Fatalf("unexpected chunks %+v", chunks)
+	}
+
+	if flags := chunks[0].payload[0]; flags != 0x10 {
+		t.Fatalf("expected only the metadata flags to be cleared, got %#x", flags)
+	}
+}
+
+func TestSanitiseRejectsUnknownFormats(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Sanitise([]byte("GIF89a....")); !errors.Is(err, ErrSanitiseUnsupported) {
+		t.Fatalf("expected ErrSanitiseUnsupported, got %v", err)
+	}
+}
@@ -3,14 +3,26 @@ package images
 import (
 	"io"
 	"sync"
+	"time"
+
+	"github.com/oullin/pkg/portal"
 )
 
 const (
 	// Image encoding defaults.
 	DefaultJPEGQuality = 85
 
+	// MaxPixels caps the width times the height of any decoded image, so a small but highly
+	// compressed file cannot expand into gigabytes of memory.
+	MaxPixels = 50_000_000
+
+	// orientationScanBytes is how much of the head of a streamed image is kept to read its
+	// EXIF orientation from.
+	orientationScanBytes = 256 << 10
+
 	// Remote image download limits.
 	maxRemoteImageBytes = 32 << 20 // 32MiB should cover large blog assets.
+	remoteImageTimeout  = 10 * time.Second
 
 	// Remote image HTTP header values.
 	supportedImageAcceptHeader = "image/webp,image/png,image/jpeg,image/gif;q=0.9,*/*;q=0.1"
@@ -25,8 +37,21 @@ var (
 
 	// avifInitOnce ensures the AVIF decoder is initialized only a single time.
 	avifInitOnce sync.Once
+
+	// avifEncoderInitOnce ensures the AVIF encoder is initialized only a single time.
+	avifEncoderInitOnce sync.Once
+
+	// defaultFetcher downloads the images of callers that bring no fetcher of their own;
+	// only public http(s) hosts are reachable.
+	defaultFetcher = portal.NewFetcher(NewFetchPolicy())
 )
 
+// NewFetchPolicy is the policy remote images are downloaded with. Callers building their
+// own fetcher, e.g. to let tests reach loopback, start from it.
+func NewFetchPolicy() portal.FetchPolicy {
+	return portal.FetchPolicy{MaxBytes: maxRemoteImageBytes, Timeout: remoteImageTimeout}
+}
+
 type composedReadCloser struct {
 	io.Reader
 	io.Closer
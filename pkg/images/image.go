@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"compress/gzip"
 	"compress/zlib"
+	"context"
 	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	stdimage "image"
@@ -19,7 +21,6 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
-	"time"
 	"unicode"
 
 	"golang.org/x/image/draw"
@@ -28,6 +29,9 @@ import (
 	"github.com/andybalholm/brotli"
 	"github.com/gen2brain/avif"
 	"github.com/klauspost/compress/zstd"
+
+	"github.com/oullin/pkg/portal"
+	"github.com/oullin/pkg/storage"
 )
 
 type fetchRequest struct {
@@ -43,10 +47,30 @@ func (r fetchRequest) key() string {
 	return r.Accept + " " + r.URL.String()
 }
 
-func Fetch(source string) (stdimage.Image, string, error) {
+// Fetch downloads and decodes the image at source with the given fetcher, or with one
+// limited to public hosts when nil.
+func Fetch(fetcher *portal.Fetcher, source string) (stdimage.Image, string, error) {
+	img, format, _, err := fetch(fetcher, source)
+
+	return img, format, err
+}
+
+// FetchPayload is Fetch returning the bytes the image was decoded from, e.g. to
+// fingerprint its content.
+func FetchPayload(fetcher *portal.Fetcher, source string) (stdimage.Image, []byte, error) {
+	img, _, payload, err := fetch(fetcher, source)
+
+	return img, payload, err
+}
+
+func fetch(fetcher *portal.Fetcher, source string) (stdimage.Image, string, []byte, error) {
+	if fetcher == nil {
+		fetcher = defaultFetcher
+	}
+
 	parsed, err := url.Parse(source)
 	if err != nil {
-		return nil, "", fmt.Errorf("parse url: %w", err)
+		return nil, "", nil, fmt.Errorf("parse url: %w", err)
 	}
 
 	queue := []fetchRequest{{URL: parsed, Accept: supportedImageAcceptHeader}}
@@ -74,7 +98,7 @@ func Fetch(source string) (stdimage.Image, string, error) {
 		}
 		seen[key] = struct{}{}
 
-		reader, contentType, encoding, err := openSource(current)
+		reader, contentType, encoding, err := openSource(fetcher, current)
 		if err != nil {
 			lastErr = err
 			continue
@@ -88,7 +112,7 @@ func Fetch(source string) (stdimage.Image, string, error) {
 
 		img, format, err := decodeImagePayload(payload)
 		if err == nil {
-			return img, format, nil
+			return img, format, payload, nil
 		}
 
 		lastDecodeErr = err
@@ -100,14 +124,82 @@ func Fetch(source string) (stdimage.Image, string, error) {
 	}
 
 	if lastDecodeErr != nil {
-		return nil, "", newDecodeError(lastDecodeErr, lastPayload, lastContentType, lastEncoding)
+		return nil, "", nil, newDecodeError(lastDecodeErr, lastPayload, lastContentType, lastEncoding)
 	}
 
 	if lastErr != nil {
-		return nil, "", lastErr
+		return nil, "", nil, lastErr
+	}
+
+	return nil, "", nil, errors.New("failed to fetch image")
+}
+
+// Decode decodes an in-memory image payload, e.g. an upload, with the same
+// tolerance for leading noise and compression wrappers as Fetch.
+func Decode(payload []byte) (stdimage.Image, string, error) {
+	if len(payload) == 0 {
+		return nil, "", errors.New("empty image payload")
 	}
 
-	return nil, "", errors.New("failed to fetch image")
+	img, format, err := decodeImagePayload(payload)
+	if err != nil {
+		return nil, "", newDecodeError(err, payload, "", "")
+	}
+
+	return img, format, nil
+}
+
+// ErrTooManyPixels is returned for images whose dimensions exceed MaxPixels.
+var ErrTooManyPixels = fmt.Errorf("image: dimensions exceed %d pixels", MaxPixels)
+
+// DecodeReader decodes an image streamed from r, e.g. an upload spooled to disk, without
+// holding its encoded bytes in memory. The dimensions are checked before the pixels are.
+func DecodeReader(r io.ReadSeeker) (stdimage.Image, string, error) {
+	if err := checkPixels(r); err != nil {
+		return nil, "", err
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, "", err
+	}
+
+	head := &headBuffer{limit: orientationScanBytes}
+
+	img, format, err := stdimage.Decode(io.TeeReader(r, head))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return ApplyOrientation(img, ReadOrientation(head.Bytes())), format, nil
+}
+
+// checkPixels reads the image header and rejects dimensions above MaxPixels. Headers it
+// cannot read are left for the decoder to report.
+func checkPixels(r io.Reader) error {
+	config, _, err := stdimage.DecodeConfig(r)
+	if err != nil {
+		return nil
+	}
+
+	if config.Width <= 0 || config.Height <= 0 || int64(config.Width)*int64(config.Height) > MaxPixels {
+		return ErrTooManyPixels
+	}
+
+	return nil
+}
+
+// headBuffer keeps the first limit bytes written to it and drops the rest.
+type headBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *headBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(room, len(p))])
+	}
+
+	return len(p), nil
 }
 
 func readImagePayload(reader io.ReadCloser) ([]byte, error) {
@@ -146,9 +238,13 @@ func decodeImagePayload(data []byte) (stdimage.Image, string, error) {
 		}
 		seen[hash] = struct{}{}
 
+		if err := checkPixels(bytes.NewReader(candidate)); err != nil {
+			return nil, "", err
+		}
+
 		img, format, err := stdimage.Decode(bytes.NewReader(candidate))
 		if err == nil {
-			return img, format, nil
+			return ApplyOrientation(img, ReadOrientation(candidate)), format, nil
 		}
 
 		lastErr = err
@@ -260,6 +356,14 @@ func decodeAVIF(data []byte) (stdimage.Image, error) {
 	return img, nil
 }
 
+func encodeAVIF(w io.Writer, img stdimage.Image, quality int) error {
+	avifEncoderInitOnce.Do(func() {
+		avif.InitEncoder()
+	})
+
+	return avif.Encode(w, img, avif.Options{Quality: quality, QualityAlpha: quality, Speed: avif.DefaultSpeed, ChromaSubsampling: stdimage.YCbCrSubsampleRatio420})
+}
+
 func githubAttachmentFallbacks(current fetchRequest, payload []byte) []fetchRequest {
 	u := current.URL
 	if u == nil || len(payload) == 0 {
@@ -527,16 +631,79 @@ func Save(path string, img stdimage.Image, ext string, quality int) error {
 	}
 	defer fh.Close()
 
+	return Encode(fh, img, ext, quality)
+}
+
+// Encode writes the image in the format of the given extension. The output goes through
+// Sanitise, so nothing that leaves this package as a stored file carries metadata.
+func Encode(w io.Writer, img stdimage.Image, ext string, quality int) error {
+	var buf bytes.Buffer
+	var err error
+
 	switch ext {
 	case ".png":
 		encoder := &png.Encoder{CompressionLevel: png.DefaultCompression}
-		return encoder.Encode(fh, img)
+		err = encoder.Encode(&buf, img)
 	case ".webp":
-		return encodeWebp(fh, img, quality)
+		err = encodeWebp(&buf, img, quality)
+	case ".avif":
+		// The AVIF encoder only writes the image items; there are no metadata boxes to strip.
+		return encodeAVIF(w, img, quality)
 	default:
 		options := &jpeg.Options{Quality: quality}
-		return jpeg.Encode(fh, img, options)
+		err = jpeg.Encode(&buf, img, options)
 	}
+
+	if err != nil {
+		return err
+	}
+
+	clean, err := Sanitise(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("sanitise encoded image: %w", err)
+	}
+
+	_, err = w.Write(clean)
+
+	return err
+}
+
+// StoredFile describes a content-addressed file written by SaveContentAddressed.
+type StoredFile struct {
+	Name   string
+	Sha256 string
+	Size   int64
+}
+
+// SaveContentAddressed encodes the image and stores it under prefix, named after the
+// SHA-256 of the encoded bytes. Identical images share an object, which is written once.
+func SaveContentAddressed(ctx context.Context, store storage.Storage, prefix string, img stdimage.Image, ext string, quality int) (StoredFile, error) {
+	var buf bytes.Buffer
+
+	if err := Encode(&buf, img, ext, quality); err != nil {
+		return StoredFile{}, fmt.Errorf("encode image: %w", err)
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	digest := hex.EncodeToString(sum[:])
+
+	stored := StoredFile{
+		Name:   digest[:32] + ext,
+		Sha256: digest,
+		Size:   int64(buf.Len()),
+	}
+
+	key := path.Join(prefix, stored.Name)
+
+	if _, err := store.Stat(ctx, key); err == nil {
+		return stored, nil
+	}
+
+	if err := store.Put(ctx, key, buf.Bytes(), MIMEFromExtension(ext)); err != nil {
+		return StoredFile{}, err
+	}
+
+	return stored, nil
 }
 
 func Move(src, dst string) error {
@@ -581,6 +748,8 @@ func MIMEFromExtension(ext string) string {
 		return "image/jpeg"
 	case ".webp":
 		return "image/webp"
+	case ".avif":
+		return "image/avif"
 	default:
 		return "image/png"
 	}
@@ -614,50 +783,32 @@ func NormalizeRelativeURL(rel string) string {
 	return b.String()
 }
 
-func openSource(req fetchRequest) (io.ReadCloser, string, string, error) {
-	parsed := req.URL
-	switch parsed.Scheme {
-	case "http", "https":
-		client := &http.Client{Timeout: 10 * time.Second}
-
-		httpReq, err := http.NewRequest(http.MethodGet, parsed.String(), nil)
-		if err != nil {
-			return nil, "", "", fmt.Errorf("create request: %w", err)
-		}
-
-		accept := strings.TrimSpace(req.Accept)
-		if accept == "" {
-			accept = supportedImageAcceptHeader
-		}
-
-		httpReq.Header.Set("Accept", accept)
-		httpReq.Header.Set("User-Agent", defaultRemoteImageUA)
+func openSource(fetcher *portal.Fetcher, req fetchRequest) (io.ReadCloser, string, string, error) {
+	accept := strings.TrimSpace(req.Accept)
+	if accept == "" {
+		accept = supportedImageAcceptHeader
+	}
 
-		resp, err := client.Do(httpReq)
-		if err != nil {
-			return nil, "", "", fmt.Errorf("download image: %w", err)
-		}
+	header := http.Header{}
+	header.Set("Accept", accept)
+	header.Set("User-Agent", defaultRemoteImageUA)
 
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			defer resp.Body.Close()
-			return nil, "", "", fmt.Errorf("download image: unexpected status %s", resp.Status)
-		}
+	resp, err := fetcher.Get(context.Background(), req.URL.String(), header)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("download image: %w", err)
+	}
 
-		reader, encoding, err := wrapHTTPBody(resp)
-		if err != nil {
-			return nil, "", "", err
-		}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, "", "", fmt.Errorf("download image: unexpected status %s", resp.Status)
+	}
 
-		return reader, resp.Header.Get("Content-Type"), encoding, nil
-	case "file":
-		reader, err := openLocal(parsed)
-		return reader, "", "", err
-	case "":
-		reader, err := os.Open(parsed.Path)
-		return reader, "", "", err
-	default:
-		return nil, "", "", fmt.Errorf("unsupported image scheme: %s", parsed.Scheme)
+	reader, encoding, err := wrapHTTPBody(resp)
+	if err != nil {
+		return nil, "", "", err
 	}
+
+	return reader, resp.Header.Get("Content-Type"), encoding, nil
 }
 
 func wrapHTTPBody(resp *http.Response) (io.ReadCloser, string, error) {
@@ -690,21 +841,3 @@ func wrapHTTPBody(resp *http.Response) (io.ReadCloser, string, error) {
 		return resp.Body, encoding, nil
 	}
 }
-
-func openLocal(parsed *url.URL) (io.ReadCloser, error) {
-	pathValue := parsed.Path
-	if pathValue == "" {
-		pathValue = parsed.Opaque
-	}
-
-	if parsed.Host != "" {
-		pathValue = "//" + parsed.Host + pathValue
-	}
-
-	unescaped, err := url.PathUnescape(pathValue)
-	if err != nil {
-		return nil, fmt.Errorf("decode file path: %w", err)
-	}
-
-	return os.Open(unescaped)
-}
@@ -3,6 +3,7 @@ package images
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/base64"
 	"errors"
 	"image"
@@ -19,10 +20,17 @@ import (
 
 	"github.com/andybalholm/brotli"
 	"github.com/klauspost/compress/zstd"
+
+	"github.com/oullin/pkg/portal"
+	"github.com/oullin/pkg/storage"
 )
 
 const avifFixtureBase64 = "AAAAHGZ0eXBhdmlmAAAAAGF2aWZtaWYxbWlhZgAAAOptZXRhAAAAAAAAACFoZGxyAAAAAAAAAABwaWN0AAAAAAAAAAAAAAAAAAAAAA5waXRtAAAAAAABAAAAImlsb2MAAAAAREAAAQABAAAAAAEOAAEAAAAAAAAAHwAAACNpaW5mAAAAAAABAAAAFWluZmUCAAAAAAEAAGF2MDEAAAAAamlwcnAAAABLaXBjbwAAABNjb2xybmNseAABAA0ABoAAAAAMYXYxQ4EADAAAAAAUaXNwZQAAAAAAAAACAAAAAgAAABBwaXhpAAAAAAMICAgAAAAXaXBtYQAAAAAAAAABAAEEAYIDBAAAACdtZGF0EgAKCBgANggIaDQgMhEYAAooooQAALATVl9ApOsM/A=="
 
+// loopback reaches the httptest servers, which the default fetcher refuses for
+// listening on loopback.
+var loopback = portal.NewFetcher(portal.FetchPolicy{AllowPrivate: true, MaxBytes: maxRemoteImageBytes})
+
 func createTestImage(width, height int) image.Image {
 	img := image.NewRGBA(image.Rect(0, 0, width, height))
 	for y := 0; y < height; y++ {
@@ -48,27 +56,17 @@ func writePNG(t *testing.T, path string, img image.Image) {
 	}
 }
 
-func TestFetchLocal(t *testing.T) {
+func TestFetchRefusesLocalFiles(t *testing.T) {
 	t.Parallel()
 
 	dir := t.TempDir()
 	src := filepath.Join(dir, "local.png")
 	writePNG(t, src, createTestImage(100, 80))
 
-	fileURL := url.URL{Scheme: "file", Path: src}
-
-	img, format, err := Fetch(fileURL.String())
-	if err != nil {
-		t.Fatalf("fetch local image: %v", err)
-	}
-
-	if format != "png" {
-		t.Fatalf("expected png format, got %q", format)
-	}
-
-	bounds := img.Bounds()
-	if bounds.Dx() != 100 || bounds.Dy() != 80 {
-		t.Fatalf("unexpected dimensions: %dx%d", bounds.Dx(), bounds.Dy())
+	for _, source := range []string{(&url.URL{Scheme: "file", Path: src}).String(), src} {
+		if _, _, err := Fetch(nil, source); !errors.Is(err, portal.ErrFetchScheme) {
+			t.Fatalf("expected %s to be refused, got %v", source, err)
+		}
 	}
 }
 
@@ -86,7 +84,7 @@ func TestFetchRemote(t *testing.T) {
 	}))
 	defer server.Close()
 
-	img, format, err := Fetch(server.URL + "/cover.png")
+	img, format, err := Fetch(loopback, server.URL+"/cover.png")
 	if err != nil {
 		t.Fatalf("fetch remote image: %v", err)
 	}
@@ -125,7 +123,7 @@ func TestFetchRemoteWebP(t *testing.T) {
 	}))
 	defer server.Close()
 
-	img, format, err := Fetch(server.URL + "/cover.webp")
+	img, format, err := Fetch(loopback, server.URL+"/cover.webp")
 	if err != nil {
 		t.Fatalf("fetch remote webp: %v", err)
 	}
@@ -164,7 +162,7 @@ func TestFetchRemoteJPEGWithLeadingNoise(t *testing.T) {
 	}))
 	defer server.Close()
 
-	imgResult, format, err := Fetch(server.URL + "/cover.jpg")
+	imgResult, format, err := Fetch(loopback, server.URL+"/cover.jpg")
 	if err != nil {
 		t.Fatalf("fetch jpeg with leading noise: %v", err)
 	}
@@ -202,7 +200,7 @@ func TestFetchRemoteJPEGEmbeddedAfterHTML(t *testing.T) {
 	}))
 	defer server.Close()
 
-	imgResult, format, err := Fetch(server.URL + "/asset")
+	imgResult, format, err := Fetch(loopback, server.URL+"/asset")
 	if err != nil {
 		t.Fatalf("fetch embedded jpeg: %v", err)
 	}
@@ -249,7 +247,7 @@ func TestFetchRemoteJPEGCompressedWithoutEncodingHeader(t *testing.T) {
 	}))
 	defer server.Close()
 
-	imgResult, format, err := Fetch(server.URL + "/cover.jpg")
+	imgResult, format, err := Fetch(loopback, server.URL+"/cover.jpg")
 	if err != nil {
 		t.Fatalf("fetch compressed jpeg: %v", err)
 	}
@@ -295,7 +293,7 @@ func TestFetchRemoteSetsAcceptHeader(t *testing.T) {
 	}))
 	defer server.Close()
 
-	img, format, err := Fetch(server.URL + "/cover.png")
+	img, format, err := Fetch(loopback, server.URL+"/cover.png")
 	if err != nil {
 		t.Fatalf("fetch remote image: %v", err)
 	}
@@ -340,7 +338,7 @@ func TestFetchRemoteBrotliEncoded(t *testing.T) {
 	}))
 	defer server.Close()
 
-	img, format, err := Fetch(server.URL + "/cover.png")
+	img, format, err := Fetch(loopback, server.URL+"/cover.png")
 	if err != nil {
 		t.Fatalf("fetch brotli image: %v", err)
 	}
@@ -385,7 +383,7 @@ func TestFetchRemoteBrotliWithoutEncodingHeader(t *testing.T) {
 	}))
 	defer server.Close()
 
-	img, format, err := Fetch(server.URL + "/cover.png")
+	img, format, err := Fetch(loopback, server.URL+"/cover.png")
 	if err != nil {
 		t.Fatalf("fetch brotli payload without header: %v", err)
 	}
@@ -435,7 +433,7 @@ func TestFetchRemoteZstdEncoded(t *testing.T) {
 	}))
 	defer server.Close()
 
-	img, format, err := Fetch(server.URL + "/cover.png")
+	img, format, err := Fetch(loopback, server.URL+"/cover.png")
 	if err != nil {
 		t.Fatalf("fetch zstd image: %v", err)
 	}
@@ -476,7 +474,7 @@ func TestFetchRemoteAVIFDecode(t *testing.T) {
 	}))
 	defer server.Close()
 
-	img, format, err := Fetch(server.URL + "/user-attachments/assets/e5abb532-59bf-49bb-a9d2-0c31872718d7")
+	img, format, err := Fetch(loopback, server.URL+"/user-attachments/assets/e5abb532-59bf-49bb-a9d2-0c31872718d7")
 	if err != nil {
 		t.Fatalf("fetch avif image: %v", err)
 	}
@@ -547,7 +545,7 @@ func TestFetchRemoteAVIFGitHubFallback(t *testing.T) {
 	}))
 	defer server.Close()
 
-	img, format, err := Fetch(server.URL + "/user-attachments/assets/" + attachmentID)
+	img, format, err := Fetch(loopback, server.URL+"/user-attachments/assets/"+attachmentID)
 	if err != nil {
 		t.Fatalf("fetch remote avif fallback: %v", err)
 	}
@@ -595,7 +593,7 @@ func TestFetchRemoteDecodeErrorIncludesContentType(t *testing.T) {
 	}))
 	defer server.Close()
 
-	_, _, err := Fetch(server.URL + "/cover.png")
+	_, _, err := Fetch(loopback, server.URL+"/cover.png")
 	if err == nil {
 		t.Fatal("expected error decoding html response")
 	}
@@ -628,7 +626,7 @@ func TestFetchRemoteDecodeErrorIncludesContentEncoding(t *testing.T) {
 	}))
 	defer server.Close()
 
-	_, _, err := Fetch(server.URL + "/cover.png")
+	_, _, err := Fetch(loopback, server.URL+"/cover.png")
 	if err == nil {
 		t.Fatal("expected error decoding brotli encoded html")
 	}
@@ -655,7 +653,7 @@ func TestFetchRemoteDecodeErrorProvidesDiagnostics(t *testing.T) {
 	}))
 	defer server.Close()
 
-	_, _, err := Fetch(server.URL + "/cover.jpg")
+	_, _, err := Fetch(loopback, server.URL+"/cover.jpg")
 	if err == nil {
 		t.Fatal("expected decode error")
 	}
@@ -775,6 +773,7 @@ func TestMIMEFromExtension(t *testing.T) {
 		".jpeg": "image/png",
 		".gif":  "image/png",
 		".webp": "image/webp",
+		".avif": "image/avif",
 	}
 
 	for ext, want := range tests {
@@ -971,3 +970,46 @@ func TestNormalizeRelativeURL(t *testing.T) {
 		})
 	}
 }
+
+func TestSaveContentAddressed(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "posts", "hello")
+	store := storage.NewLocal(root, "")
+	ctx := context.Background()
+	img := createTestImage(20, 10)
+
+	first, err := SaveContentAddressed(ctx, store, "posts/hello", img, ".png", DefaultJPEGQuality)
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	if len(first.Name) != 36 || filepath.Ext(first.Name) != ".png" || first.Name[:32] != first.Sha256[:32] {
+		t.Fatalf("unexpected stored file %+v", first)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, first.Name))
+	if err != nil || info.Size() != first.Size {
+		t.Fatalf("expected the stored size to match the file: %+v (%v)", first, err)
+	}
+
+	second, err := SaveContentAddressed(ctx, store, "posts/hello", img, ".png", DefaultJPEGQuality)
+	if err != nil || second != first {
+		t.Fatalf("expected identical images to share a file, got %+v (%v)", second, err)
+	}
+
+	other, err := SaveContentAddressed(ctx, store, "posts/hello", createTestImage(30, 10), ".png", DefaultJPEGQuality)
+	if err != nil || other.Name == first.Name {
+		t.Fatalf("expected a distinct file for a distinct image, got %+v (%v)", other, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected two files and no temporary leftovers, got %d", len(entries))
+	}
+}
@@ -0,0 +1,226 @@
+package images
+
+import (
+	"errors"
+	"fmt"
+	stdimage "image"
+	"math"
+	"strings"
+)
+
+// placeholderSampleSize bounds the longest side of the thumbnail the placeholder is computed
+// from; the hash only keeps a handful of frequencies, so more pixels add cost and nothing else.
+const placeholderSampleSize = 64
+
+const blurHashCharacters = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// Placeholder is what a client needs to paint a cover before the image loads.
+type Placeholder struct {
+	BlurHash      string // https://blurha.sh, 4x3 components (3x4 for portrait images).
+	DominantColor string // Hex colour, e.g. #1a2b3c.
+	Width         int    // Intrinsic width of the source image.
+	Height        int    // Intrinsic height of the source image.
+}
+
+// AspectRatio returns width / height, rounded to four decimals, or zero when unknown.
+func (p Placeholder) AspectRatio() float64 {
+	return AspectRatio(p.Width, p.Height)
+}
+
+func AspectRatio(width, height int) float64 {
+	if width <= 0 || height <= 0 {
+		return 0
+	}
+
+	return math.Round(float64(width)/float64(height)*10000) / 10000
+}
+
+func NewPlaceholder(img stdimage.Image) (Placeholder, error) {
+	if img == nil {
+		return Placeholder{}, errors.New("placeholder: nil image")
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width <= 0 || height <= 0 {
+		return Placeholder{}, errors.New("placeholder: empty image")
+	}
+
+	sample := placeholderSample(img, width, height)
+
+	xComponents, yComponents := 4, 3
+	if height > width {
+		xComponents, yComponents = 3, 4
+	}
+
+	return Placeholder{
+		BlurHash:      encodeBlurHash(sample, xComponents, yComponents),
+		DominantColor: dominantColor(sample),
+		Width:         width,
+		Height:        height,
+	}, nil
+}
+
+func placeholderSample(img stdimage.Image, width, height int) *stdimage.RGBA {
+	sampleWidth, sampleHeight := width, height
+
+	if width >= height && width > placeholderSampleSize {
+		sampleWidth = placeholderSampleSize
+		sampleHeight = max(1, height*placeholderSampleSize/width)
+	} else if height > width && height > placeholderSampleSize {
+		sampleHeight = placeholderSampleSize
+		sampleWidth = max(1, width*placeholderSampleSize/height)
+	}
+
+	return Resize(img, sampleWidth, sampleHeight).(*stdimage.RGBA)
+}
+
+// dominantColor buckets the opaque pixels into 4 bits per channel and averages the fullest
+// bucket, which favours the largest flat area over the mean of the whole image.
+func dominantColor(img *stdimage.RGBA) string {
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+
+	buckets := make(map[int]*bucket)
+	var top *bucket
+
+	bounds := img.Bounds()
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			if c.A < 128 {
+				continue
+			}
+
+			// Undo the premultiplied alpha of semi-transparent pixels.
+			r, g, b := int(c.R)*255/int(c.A), int(c.G)*255/int(c.A), int(c.B)*255/int(c.A)
+			key := (r>>4)<<8 | (g>>4)<<4 | b>>4
+
+			current, ok := buckets[key]
+			if !ok {
+				current = &bucket{}
+				buckets[key] = current
+			}
+
+			current.count++
+			current.r += r
+			current.g += g
+			current.b += b
+
+			if top == nil || current.count > top.count {
+				top = current
+			}
+		}
+	}
+
+	if top == nil {
+		return "#ffffff"
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", top.r/top.count, top.g/top.count, top.b/top.count)
+}
+
+func encodeBlurHash(img *stdimage.RGBA, xComponents, yComponents int) string {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	factors := make([][3]float64, 0, xComponents*yComponents)
+
+	for j := 0; j < yComponents; j++ {
+		for i := 0; i < xComponents; i++ {
+			var factor [3]float64
+
+			normalisation := 2.0
+			if i == 0 && j == 0 {
+				normalisation = 1
+			}
+
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					basis := math.Cos(math.Pi*float64(i*x)/float64(width)) * math.Cos(math.Pi*float64(j*y)/float64(height))
+					c := img.RGBAAt(bounds.Min.X+x, bounds.Min.Y+y)
+
+					factor[0] += basis * sRGBToLinear(c.R)
+					factor[1] += basis * sRGBToLinear(c.G)
+					factor[2] += basis * sRGBToLinear(c.B)
+				}
+			}
+
+			scale := normalisation / float64(width*height)
+			factors = append(factors, [3]float64{factor[0] * scale, factor[1] * scale, factor[2] * scale})
+		}
+	}
+
+	var hash strings.Builder
+
+	hash.WriteString(encodeBase83((xComponents-1)+(yComponents-1)*9, 1))
+
+	dc, ac := factors[0], factors[1:]
+	maximum := 1.0
+
+	if len(ac) > 0 {
+		actual := 0.0
+		for _, factor := range ac {
+			actual = math.Max(actual, math.Max(math.Abs(factor[0]), math.Max(math.Abs(factor[1]), math.Abs(factor[2]))))
+		}
+
+		quantised := int(math.Max(0, math.Min(82, math.Floor(actual*166-0.5))))
+		maximum = float64(quantised+1) / 166
+
+		hash.WriteString(encodeBase83(quantised, 1))
+	} else {
+		hash.WriteString(encodeBase83(0, 1))
+	}
+
+	hash.WriteString(encodeBase83(linearToSRGB(dc[0])<<16|linearToSRGB(dc[1])<<8|linearToSRGB(dc[2]), 4))
+
+	for _, factor := range ac {
+		quantR := quantiseAC(factor[0], maximum)
+		quantG := quantiseAC(factor[1], maximum)
+		quantB := quantiseAC(factor[2], maximum)
+
+		hash.WriteString(encodeBase83(quantR*19*19+quantG*19+quantB, 2))
+	}
+
+	return hash.String()
+}
+
+func quantiseAC(value, maximum float64) int {
+	signed := math.Copysign(math.Pow(math.Abs(value/maximum), 0.5), value)
+
+	return int(math.Max(0, math.Min(18, math.Floor(signed*9+9.5))))
+}
+
+func encodeBase83(value, length int) string {
+	encoded := make([]byte, length)
+
+	for i := 1; i <= length; i++ {
+		digit := (value / int(math.Pow(83, float64(length-i)))) % 83
+		encoded[i-1] = blurHashCharacters[digit]
+	}
+
+	return string(encoded)
+}
+
+func sRGBToLinear(value uint8) float64 {
+	v := float64(value) / 255
+
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(value float64) int {
+	v := math.Max(0, math.Min(1, value))
+
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
@@ -0,0 +1,97 @@
+package images
+
+import (
+	stdimage "image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func decodeBase83(t *testing.T, value string) int {
+	t.Helper()
+
+	decoded := 0
+	for _, char := range value {
+		decoded = decoded*83 + strings.IndexRune(blurHashCharacters, char)
+	}
+
+	return decoded
+}
+
+func TestNewPlaceholderSolidImage(t *testing.T) {
+	t.Parallel()
+
+	img := stdimage.NewRGBA(stdimage.Rect(0, 0, 300, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 300; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	placeholder, err := NewPlaceholder(img)
+	if err != nil {
+		t.Fatalf("placeholder: %v", err)
+	}
+
+	if placeholder.DominantColor != "#ff0000" {
+		t.Fatalf("unexpected dominant colour %s", placeholder.DominantColor)
+	}
+
+	if placeholder.Width != 300 || placeholder.Height != 200 || placeholder.AspectRatio() != 1.5 {
+		t.Fatalf("unexpected dimensions %+v", placeholder)
+	}
+
+	// 1 size flag + 1 maximum + 4 DC + 2 per AC component for 4x3 components.
+	if len(placeholder.BlurHash) != 28 {
+		t.Fatalf("unexpected hash length %q", placeholder.BlurHash)
+	}
+
+	if got := decodeBase83(t, placeholder.BlurHash[:1]); got != 3+2*9 {
+		t.Fatalf("unexpected size flag %d", got)
+	}
+
+	if got := decodeBase83(t, placeholder.BlurHash[2:6]); got != 0xff0000 {
+		t.Fatalf("unexpected DC colour %06x", got)
+	}
+}
+
+func TestNewPlaceholderPortraitAndDominantArea(t *testing.T) {
+	t.Parallel()
+
+	img := stdimage.NewRGBA(stdimage.Rect(0, 0, 100, 400))
+	for y := 0; y < 400; y++ {
+		for x := 0; x < 100; x++ {
+			c := color.RGBA{B: 255, A: 255}
+			if y < 100 {
+				c = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+
+			img.Set(x, y, c)
+		}
+	}
+
+	placeholder, err := NewPlaceholder(img)
+	if err != nil {
+		t.Fatalf("placeholder: %v", err)
+	}
+
+	if placeholder.DominantColor != "#0000ff" {
+		t.Fatalf("expected the larger area to win, got %s", placeholder.DominantColor)
+	}
+
+	if got := decodeBase83(t, placeholder.BlurHash[:1]); got != 2+3*9 {
+		t.Fatalf("expected 3x4 components for portraits, got flag %d", got)
+	}
+
+	if placeholder.AspectRatio() != 0.25 {
+		t.Fatalf("unexpected aspect ratio %v", placeholder.AspectRatio())
+	}
+}
+
+func TestNewPlaceholderRejectsEmptyImages(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewPlaceholder(stdimage.NewRGBA(stdimage.Rect(0, 0, 0, 0))); err == nil {
+		t.Fatalf("expected an error for empty images")
+	}
+}
@@ -0,0 +1,169 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image/jpeg"
+	"image/png"
+	"slices"
+)
+
+// sanitiseJPEGQuality is only used when a rotated JPEG has to be re-encoded upright.
+const sanitiseJPEGQuality = 92
+
+// ErrSanitiseUnsupported is returned for payloads whose metadata cannot be stripped.
+var ErrSanitiseUnsupported = errors.New("sanitise: unsupported image format")
+
+// keptPNGChunks are the chunks needed to render a PNG; text, time and EXIF chunks are dropped.
+var keptPNGChunks = []string{"IHDR", "PLTE", "IDAT", "IEND", "tRNS", "cHRM", "gAMA", "iCCP", "sBIT", "sRGB", "bKGD", "pHYs", "acTL", "fcTL", "fdAT"}
+
+// droppedWebPChunks carry metadata only.
+var droppedWebPChunks = []string{"EXIF", "XMP "}
+
+const (
+	webpXMPFlag  = 0x04
+	webpEXIFFlag = 0x08
+)
+
+// Sanitise strips EXIF (GPS included), XMP, IPTC and comments from a JPEG, PNG or WebP payload
+// without touching the pixels. ICC colour profiles are kept since they carry no location data.
+// Images with a non-default orientation are first turned upright, since the tag that described
+// the rotation is removed.
+func Sanitise(data []byte) ([]byte, error) {
+	orientation := ReadOrientation(data)
+
+	switch {
+	case bytes.HasPrefix(data, jpegSignature):
+		if orientation != OrientationNormal {
+			return reencodeUpright(data, ".jpg")
+		}
+
+		return sanitiseJPEG(data)
+	case bytes.HasPrefix(data, pngSignature):
+		if orientation != OrientationNormal {
+			return reencodeUpright(data, ".png")
+		}
+
+		return sanitisePNG(data)
+	case isWebP(data):
+		if orientation != OrientationNormal {
+			return reencodeUpright(data, ".webp")
+		}
+
+		return sanitiseWebP(data)
+	default:
+		return nil, ErrSanitiseUnsupported
+	}
+}
+
+// reencodeUpright relies on Decode applying the orientation; the fresh encoding carries no metadata.
+func reencodeUpright(data []byte, ext string) ([]byte, error) {
+	img, _, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	switch ext {
+	case ".png":
+		err = png.Encode(&buf, img)
+	case ".webp":
+		err = encodeWebp(&buf, img, sanitiseJPEGQuality)
+	default:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: sanitiseJPEGQuality})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func sanitiseJPEG(data []byte) ([]byte, error) {
+	segments := jpegSegments(data)
+	if len(segments) == 0 {
+		return nil, errors.New("sanitise: malformed jpeg")
+	}
+
+	var out bytes.Buffer
+	out.Write(jpegSignature)
+
+	for _, segment := range segments {
+		if keepJPEGSegment(segment) {
+			out.Write(data[segment.start:segment.end])
+		}
+	}
+
+	// Everything after the last header segment is scan data, written as-is.
+	out.Write(data[segments[len(segments)-1].end:])
+
+	return out.Bytes(), nil
+}
+
+func keepJPEGSegment(segment jpegSegment) bool {
+	switch {
+	case segment.marker == 0xE0: // JFIF
+		return true
+	case segment.marker == 0xE2: // ICC profile; other APP2 payloads (e.g. FlashPix) are dropped.
+		return bytes.HasPrefix(segment.payload, []byte("ICC_PROFILE\x00"))
+	case segment.marker == 0xEE: // Adobe colour transform, needed to decode CMYK.
+		return true
+	case segment.marker >= 0xE1 && segment.marker <= 0xEF: // EXIF, XMP, IPTC and vendor blocks.
+		return false
+	case segment.marker == 0xFE: // Comment
+		return false
+	default:
+		return true
+	}
+}
+
+func sanitisePNG(data []byte) ([]byte, error) {
+	chunks := pngChunks(data)
+	if len(chunks) == 0 || chunks[len(chunks)-1].kind != "IEND" {
+		return nil, errors.New("sanitise: malformed png")
+	}
+
+	var out bytes.Buffer
+	out.Write(pngSignature)
+
+	for _, chunk := range chunks {
+		if slices.Contains(keptPNGChunks, chunk.kind) {
+			out.Write(data[chunk.start:chunk.end])
+		}
+	}
+
+	return out.Bytes(), nil
+}
+
+func sanitiseWebP(data []byte) ([]byte, error) {
+	chunks := webpChunks(data)
+	if len(chunks) == 0 {
+		return nil, errors.New("sanitise: malformed webp")
+	}
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+
+	for _, chunk := range chunks {
+		if slices.Contains(droppedWebPChunks, chunk.kind) {
+			continue
+		}
+
+		start := body.Len()
+		body.Write(data[chunk.start:chunk.end])
+
+		// The extended header advertises which metadata chunks follow.
+		if chunk.kind == "VP8X" && chunk.end-chunk.start > 8 {
+			body.Bytes()[start+8] &^= webpXMPFlag | webpEXIFFlag
+		}
+	}
+
+	out := make([]byte, 8, 8+body.Len())
+	copy(out, "RIFF")
+	binary.LittleEndian.PutUint32(out[4:8], uint32(body.Len()))
+
+	return append(out, body.Bytes()...), nil
+}
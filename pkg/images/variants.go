@@ -0,0 +1,163 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	stdimage "image"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/oullin/pkg/storage"
+)
+
+const (
+	FormatJPEG = "jpeg"
+	FormatWebP = "webp"
+	FormatAVIF = "avif"
+)
+
+// DefaultVariantWidths lists the responsive widths generated for post covers.
+var DefaultVariantWidths = []int{480, 768, 1024, 1440, 1920}
+
+// Variant describes one encoded rendition of a source image.
+type Variant struct {
+	Format   string
+	MIME     string
+	FileName string
+	Width    int
+	Height   int
+}
+
+type VariantsOptions struct {
+	Storage  storage.Storage
+	Prefix   string   // Key prefix the variants are stored under, e.g. "posts/<slug>/covers".
+	BaseName string   // File name prefix, e.g. "<slug>-<fingerprint>".
+	Widths   []int    // Target widths; defaults to DefaultVariantWidths.
+	Formats  []string // Target formats; defaults to GetVariantFormats().
+	Quality  int      // Encoder quality; defaults to DefaultJPEGQuality.
+}
+
+// GetVariantFormats returns the formats that can be encoded in this build.
+// WebP encoding relies on cgo, so it is left out when cgo is disabled.
+func GetVariantFormats() []string {
+	formats := []string{FormatJPEG}
+
+	if webpEncodeSupported() {
+		formats = append(formats, FormatWebP)
+	}
+
+	return append(formats, FormatAVIF)
+}
+
+// VariantWidthsFor keeps the widths that do not upscale the source. When the source is
+// narrower than every requested width, its own width is the single variant.
+func VariantWidthsFor(sourceWidth int, widths []int) []int {
+	var kept []int
+
+	for _, width := range widths {
+		if width > 0 && width <= sourceWidth && !slices.Contains(kept, width) {
+			kept = append(kept, width)
+		}
+	}
+
+	if len(kept) == 0 && sourceWidth > 0 {
+		kept = append(kept, sourceWidth)
+	}
+
+	slices.Sort(kept)
+
+	return kept
+}
+
+// GenerateVariants resizes the given image to every width, keeping its aspect ratio,
+// and stores each size in every format under options.Prefix.
+func GenerateVariants(ctx context.Context, img stdimage.Image, options VariantsOptions) ([]Variant, error) {
+	if img == nil {
+		return nil, errors.New("generate variants: nil image")
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() <= 0 || bounds.Dy() <= 0 {
+		return nil, errors.New("generate variants: empty image")
+	}
+
+	baseName := strings.TrimSpace(options.BaseName)
+	if baseName == "" || strings.ContainsAny(baseName, `/\`) {
+		return nil, fmt.Errorf("generate variants: invalid base name [%s]", options.BaseName)
+	}
+
+	widths := options.Widths
+	if len(widths) == 0 {
+		widths = DefaultVariantWidths
+	}
+
+	formats := options.Formats
+	if len(formats) == 0 {
+		formats = GetVariantFormats()
+	}
+
+	quality := options.Quality
+	if quality <= 0 {
+		quality = DefaultJPEGQuality
+	}
+
+	if options.Storage == nil {
+		return nil, errors.New("generate variants: no storage")
+	}
+
+	var variants []Variant
+
+	for _, width := range VariantWidthsFor(bounds.Dx(), widths) {
+		height := max(1, bounds.Dy()*width/bounds.Dx())
+
+		resized := img
+		if width != bounds.Dx() {
+			resized = Resize(img, width, height)
+		}
+
+		for _, format := range formats {
+			ext, err := extensionForFormat(format)
+			if err != nil {
+				return nil, err
+			}
+
+			fileName := fmt.Sprintf("%s-%dw%s", baseName, width, ext)
+
+			var buf bytes.Buffer
+
+			if err = Encode(&buf, resized, ext, quality); err != nil {
+				return nil, fmt.Errorf("generate variants: encode %s: %w", fileName, err)
+			}
+
+			if err = options.Storage.Put(ctx, path.Join(options.Prefix, fileName), buf.Bytes(), MIMEFromExtension(ext)); err != nil {
+				return nil, fmt.Errorf("generate variants: store %s: %w", fileName, err)
+			}
+
+			variants = append(variants, Variant{
+				Format:   format,
+				MIME:     MIMEFromExtension(ext),
+				FileName: fileName,
+				Width:    width,
+				Height:   height,
+			})
+		}
+	}
+
+	return variants, nil
+}
+
+func extensionForFormat(format string) (string, error) {
+	switch format {
+	case FormatJPEG:
+		return ".jpg", nil
+	case FormatWebP:
+		return ".webp", nil
+	case FormatAVIF:
+		return ".avif", nil
+	default:
+		return "", fmt.Errorf("generate variants: unsupported format [%s]", format)
+	}
+}
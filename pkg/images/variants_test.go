@@ -0,0 +1,99 @@
+package images
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/oullin/pkg/storage"
+)
+
+func TestVariantWidthsFor(t *testing.T) {
+	t.Parallel()
+
+	if got := VariantWidthsFor(1000, []int{1440, 480, 768, 480}); !reflect.DeepEqual(got, []int{480, 768}) {
+		t.Fatalf("unexpected widths: %v", got)
+	}
+
+	if got := VariantWidthsFor(300, []int{480, 768}); !reflect.DeepEqual(got, []int{300}) {
+		t.Fatalf("expected the source width as single variant, got %v", got)
+	}
+}
+
+func TestGenerateVariants(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "posts", "hello", "covers")
+
+	variants, err := GenerateVariants(context.Background(), createTestImage(200, 100), VariantsOptions{
+		Storage:  storage.NewLocal(root, ""),
+		Prefix:   "posts/hello/covers",
+		BaseName: "post-abc",
+		Widths:   []int{100, 160, 400},
+		Formats:  []string{FormatJPEG, FormatAVIF},
+	})
+	if err != nil {
+		t.Fatalf("generate variants: %v", err)
+	}
+
+	if len(variants) != 4 {
+		t.Fatalf("expected two widths in two formats, got %+v", variants)
+	}
+
+	first := variants[0]
+	if first.FileName != "post-abc-100w.jpg" || first.Width != 100 || first.Height != 50 || first.MIME != "image/jpeg" {
+		t.Fatalf("unexpected first variant: %+v", first)
+	}
+
+	last := variants[3]
+	if last.FileName != "post-abc-160w.avif" || last.Height != 80 || last.MIME != "image/avif" {
+		t.Fatalf("unexpected last variant: %+v", last)
+	}
+
+	for _, variant := range variants {
+		info, err := os.Stat(filepath.Join(dir, variant.FileName))
+		if err != nil || info.Size() == 0 {
+			t.Fatalf("expected %s to be written: %v", variant.FileName, err)
+		}
+	}
+
+	payload, err := os.ReadFile(filepath.Join(dir, last.FileName))
+	if err != nil {
+		t.Fatalf("read avif: %v", err)
+	}
+
+	img, err := decodeAVIF(payload)
+	if err != nil {
+		t.Fatalf("decode avif: %v", err)
+	}
+
+	if img.Bounds().Dx() != 160 {
+		t.Fatalf("unexpected avif width %d", img.Bounds().Dx())
+	}
+}
+
+func TestGenerateVariantsRejectsInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := storage.NewLocal(t.TempDir(), "")
+
+	if _, err := GenerateVariants(ctx, nil, VariantsOptions{Storage: store, BaseName: "x"}); err == nil {
+		t.Fatalf("expected nil images to be rejected")
+	}
+
+	if _, err := GenerateVariants(ctx, createTestImage(10, 10), VariantsOptions{Storage: store, BaseName: "../x"}); err == nil {
+		t.Fatalf("expected path-like base names to be rejected")
+	}
+
+	if _, err := GenerateVariants(ctx, createTestImage(10, 10), VariantsOptions{Storage: store, BaseName: "x", Formats: []string{"gif"}}); err == nil {
+		t.Fatalf("expected unsupported formats to be rejected")
+	}
+
+	if _, err := GenerateVariants(ctx, createTestImage(10, 10), VariantsOptions{BaseName: "x"}); err == nil {
+		t.Fatalf("expected a missing storage to be rejected")
+	}
+}
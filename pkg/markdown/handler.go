@@ -1,6 +1,7 @@
 package markdown
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -13,17 +14,16 @@ import (
 )
 
 func (p Parser) Fetch() (string, error) {
-	req, err := http.NewRequest("GET", p.Url, nil)
-
-	if err != nil {
-		return "", err
+	fetcher := p.Fetcher
+	if fetcher == nil {
+		fetcher = portal.NewFetcher(portal.FetchPolicy{Timeout: 10 * time.Second})
 	}
 
-	req.Header.Set("Cache-Control", "no-cache")
-	req.Header.Set("Pragma", "no-cache")
+	header := http.Header{}
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Pragma", "no-cache")
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := fetcher.Get(context.Background(), p.Url, header)
 	if err != nil {
 		return "", err
 	}
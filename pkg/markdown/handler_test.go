@@ -2,6 +2,7 @@ package markdown_test
 
 import (
 	"github.com/oullin/pkg/markdown"
+	"github.com/oullin/pkg/portal"
 
 	"net/http"
 	"net/http/httptest"
@@ -14,7 +15,7 @@ func TestParserFetch(t *testing.T) {
 	}))
 	defer server.Close()
 
-	p := markdown.Parser{Url: server.URL}
+	p := markdown.Parser{Url: server.URL, Fetcher: portal.NewFetcher(portal.FetchPolicy{AllowPrivate: true})}
 
 	content, err := p.Fetch()
 
@@ -29,9 +30,22 @@ func TestParserFetchError(t *testing.T) {
 	}))
 	defer server.Close()
 
-	p := markdown.Parser{Url: server.URL}
+	p := markdown.Parser{Url: server.URL, Fetcher: portal.NewFetcher(portal.FetchPolicy{AllowPrivate: true})}
 
 	if _, err := p.Fetch(); err == nil {
 		t.Fatalf("expected error")
 	}
 }
+
+func TestParserFetchRefusesPrivateHosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("the request should not have reached the server")
+	}))
+	defer server.Close()
+
+	for _, target := range []string{server.URL, "file:///etc/passwd", "http://169.254.169.254/latest/meta-data"} {
+		if _, err := (markdown.Parser{Url: target}).Fetch(); err == nil {
+			t.Fatalf("expected %s to be refused", target)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// inlineImagePattern matches ![alt](src) and ![alt](<src> "title"); group 2 is the source.
+var inlineImagePattern = regexp.MustCompile(`(!\[[^\]]*\]\(\s*<?)([^\s)>]+)(>?(?:\s+"[^"]*")?\s*\))`)
+
+// htmlImagePattern matches the src attribute of raw <img> tags; group 2 is the source.
+var htmlImagePattern = regexp.MustCompile(`(?i)(<img\b[^>]*?\bsrc\s*=\s*["'])([^"']+)(["'])`)
+
+// ImageSources lists the unique image sources referenced by the given Markdown,
+// in order of appearance. Images inside fenced code blocks are ignored.
+func ImageSources(content string) []string {
+	var sources []string
+	seen := make(map[string]struct{})
+
+	RewriteImages(content, func(src string) string {
+		if _, ok := seen[src]; !ok {
+			seen[src] = struct{}{}
+			sources = append(sources, src)
+		}
+
+		return src
+	})
+
+	return sources
+}
+
+// RewriteImages replaces every image source in the given Markdown, inline or raw
+// HTML, with the value returned by rewrite. Fenced code blocks are left untouched.
+func RewriteImages(content string, rewrite func(src string) string) string {
+	lines := strings.SplitAfter(content, "\n")
+	fence := ""
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+
+			continue
+		}
+
+		if marker := fenceMarker(trimmed); marker != "" {
+			fence = marker
+			continue
+		}
+
+		for _, pattern := range []*regexp.Regexp{inlineImagePattern, htmlImagePattern} {
+			line = pattern.ReplaceAllStringFunc(line, func(match string) string {
+				parts := pattern.FindStringSubmatch(match)
+
+				return parts[1] + rewrite(parts[2]) + parts[3]
+			})
+		}
+
+		lines[i] = line
+	}
+
+	return strings.Join(lines, "")
+}
+
+func fenceMarker(line string) string {
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, marker) {
+			return marker
+		}
+	}
+
+	return ""
+}
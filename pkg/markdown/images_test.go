@@ -0,0 +1,47 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const imagesDocument = `Intro ![diagram](https://github.com/user-attachments/assets/abc "Diagram") text.
+
+<img width="600" src="https://example.com/shot.png" alt="shot">
+
+` + "```md" + `
+![ignored](https://example.com/in-code.png)
+` + "```" + `
+
+Again ![diagram](https://github.com/user-attachments/assets/abc) again.
+`
+
+func TestImageSources(t *testing.T) {
+	got := ImageSources(imagesDocument)
+	want := []string{
+		"https://github.com/user-attachments/assets/abc",
+		"https://example.com/shot.png",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected sources: %v", got)
+	}
+}
+
+func TestRewriteImages(t *testing.T) {
+	got := RewriteImages(imagesDocument, func(src string) string {
+		return strings.Replace(src, "https://", "/media/", 1)
+	})
+
+	for _, expected := range []string{
+		`![diagram](/media/github.com/user-attachments/assets/abc "Diagram")`,
+		`src="/media/example.com/shot.png"`,
+		`![ignored](https://example.com/in-code.png)`,
+		`Again ![diagram](/media/github.com/user-attachments/assets/abc) again.`,
+	} {
+		if !strings.Contains(got, expected) {
+			t.Fatalf("expected %q in:\n%s", expected, got)
+		}
+	}
+}
@@ -26,7 +26,8 @@ type Post struct {
 }
 
 type Parser struct {
-	Url string
+	Url     string
+	Fetcher *portal.Fetcher // Defaults to a fetcher limited to public hosts.
 }
 
 func (f FrontMatter) GetPublishedAt() (*time.Time, error) {
@@ -1,29 +1,37 @@
 package media
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/google/uuid"
+
+	"github.com/oullin/pkg/images"
+	"github.com/oullin/pkg/storage"
 )
 
 const Dir = "media"
 const UsersDir = "users"
 const PostsDir = "posts"
+const UploadsDir = "uploads"
 const StorageDir = "storage"
+const CacheDir = "cache"
+const ImagesCacheDir = "images"
 
 var maxFileSize = int64(50 * 1024 * 1024) // 50 MB in bytes
 var allowedExtensions = []string{".jpg", ".jpeg", ".png"}
+var allowedContentTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
 
 type Media struct {
 	file         []byte
 	baseFileName string
 	ext          string
 	name         string
-	path         string
+	key          string
 	uniqueId     string
 	size         int64
 }
@@ -52,45 +60,37 @@ func NewMedia(uniqueId string, file []byte, baseFileName string) (*Media, error)
 		ext:          ext,
 		name:         filename,
 		size:         size,
-		path:         filepath.Join(GetUsersImagesDir(), filename),
+		key:          path.Join(UsersDir, filename),
 	}
 
 	return &media, nil
 }
 
-func (m *Media) Upload(directory string) error {
-	prefix := m.uniqueId
-	//value := m.name
-
-	//if strings.HasPrefix(value, prefix) {
-	if err := m.RemovePrefixedFiles(directory, prefix); err != nil {
+// Upload replaces the previous files of the same owner with this one.
+func (m *Media) Upload(ctx context.Context, store storage.Storage) error {
+	if err := m.RemovePrefixedFiles(ctx, store, m.uniqueId); err != nil {
 		return errors.New("there was an error removing the file: " + err.Error())
 	}
-	//}
-
-	err := os.WriteFile(m.path, m.file, 0644)
 
+	// Avatars are stored as uploaded, so location and camera metadata has to go first.
+	file, err := images.Sanitise(m.file)
 	if err != nil {
-		return err
+		return fmt.Errorf("there was an error sanitising the file: %w", err)
 	}
 
-	return nil
+	return store.Put(ctx, m.key, file, images.MIMEFromExtension(m.ext))
 }
 
-func (m *Media) RemovePrefixedFiles(dirPath, prefix string) error {
-	entries, err := os.ReadDir(dirPath)
+func (m *Media) RemovePrefixedFiles(ctx context.Context, store storage.Storage, prefix string) error {
+	objects, err := store.List(ctx, path.Join(UsersDir, prefix))
 
 	if err != nil {
-		return fmt.Errorf("error reading the directory '%s': %w", dirPath, err)
+		return fmt.Errorf("error listing the files prefixed with '%s': %w", prefix, err)
 	}
 
-	for _, entry := range entries {
-		if strings.HasPrefix(entry.Name(), prefix) {
-			fullPath := filepath.Join(dirPath, entry.Name())
-			err := os.RemoveAll(fullPath)
-			if err != nil {
-				return fmt.Errorf("error removing file '%s' in directory '%s': %w", fullPath, dirPath, err)
-			}
+	for _, object := range objects {
+		if err = store.Delete(ctx, object.Key); err != nil {
+			return fmt.Errorf("error removing file '%s': %w", object.Key, err)
 		}
 	}
 
@@ -100,16 +100,19 @@ func (m *Media) RemovePrefixedFiles(dirPath, prefix string) error {
 func (m *Media) GetFileName() string {
 	return m.name
 }
-func (m *Media) GetFilePath(prefix string) string {
-	filePath := m.path
 
-	dir := filepath.Dir(filePath)
-	ext := filepath.Ext(filePath)
-	base := filepath.Base(filePath)
-	name := strings.TrimSuffix(base, ext)
+// GetKey is where the file is stored, relative to the media storage.
+func (m *Media) GetKey() string {
+	return m.key
+}
+
+func (m *Media) GetFilePath(prefix string) string {
+	dir := path.Dir(m.key)
+	ext := path.Ext(m.key)
+	name := strings.TrimSuffix(path.Base(m.key), ext)
 	newName := fmt.Sprintf("%s-%s%s", prefix, name, ext)
 
-	return filepath.Join(dir, newName)
+	return path.Join(dir, newName)
 }
 
 func (m *Media) GetHeaderName() string {
@@ -1,10 +1,16 @@
 package media
 
 import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/oullin/metal/env"
 )
 
 func setupTempDir(t *testing.T) string {
@@ -18,9 +24,24 @@ func setupTempDir(t *testing.T) string {
 	return dir
 }
 
+// jpegWithExif returns a small JPEG carrying an EXIF block with GPS data.
+func jpegWithExif(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4)), nil); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	exif := []byte("Exif\x00\x00GPSLatitude")
+	segment := append([]byte{0xFF, 0xE1, 0x00, byte(len(exif) + 2)}, exif...)
+
+	return append(append(buf.Bytes()[:2:2], segment...), buf.Bytes()[2:]...)
+}
+
 func TestNewMediaAndUpload(t *testing.T) {
 	setupTempDir(t)
-	data := []byte{1, 2, 3}
+	data := jpegWithExif(t)
 
 	m, err := NewMedia("uid", data, "pic.jpg")
 
@@ -40,17 +61,46 @@ func TestNewMediaAndUpload(t *testing.T) {
 		t.Fatalf("header")
 	}
 
-	if err := m.Upload(GetUsersImagesDir()); err != nil {
+	ctx := context.Background()
+	store, err := NewStorage(env.StorageEnvironment{Driver: env.StorageDriverLocal})
+	if err != nil {
+		t.Fatalf("storage: %v", err)
+	}
+
+	previous, _ := NewMedia("uid", data, "old.jpg")
+	if err := previous.Upload(ctx, store); err != nil {
+		t.Fatalf("upload previous: %v", err)
+	}
+
+	if err := m.Upload(ctx, store); err != nil {
 		t.Fatalf("upload: %v", err)
 	}
 
-	if _, err := os.Stat(m.path); err != nil {
+	stored, err := os.ReadFile(filepath.Join(GetMediaDir(), filepath.FromSlash(m.GetKey())))
+	if err != nil {
 		t.Fatalf("file not created")
 	}
 
-	if err := m.RemovePrefixedFiles(GetUsersImagesDir(), "uid"); err != nil {
+	if _, err := os.Stat(filepath.Join(GetMediaDir(), filepath.FromSlash(previous.GetKey()))); !os.IsNotExist(err) {
+		t.Fatalf("expected the previous file of the same owner to be replaced")
+	}
+
+	if bytes.Contains(stored, []byte("GPSLatitude")) {
+		t.Fatalf("expected the metadata to be stripped")
+	}
+
+	invalid, _ := NewMedia("bad", []byte{1, 2, 3}, "pic.jpg")
+	if err := invalid.Upload(ctx, store); err == nil {
+		t.Fatalf("expected non image payloads to be rejected")
+	}
+
+	if err := m.RemovePrefixedFiles(ctx, store, "uid"); err != nil {
 		t.Fatalf("remove: %v", err)
 	}
+
+	if objects, _ := store.List(ctx, UsersDir+"/uid"); len(objects) != 0 {
+		t.Fatalf("expected the prefixed files to be removed, got %v", objects)
+	}
 }
 
 func TestNewMediaErrors(t *testing.T) {
@@ -81,7 +131,7 @@ func TestGetFilePath(t *testing.T) {
 
 	p := m.GetFilePath("thumb")
 
-	if !strings.Contains(filepath.Base(p), "thumb-") {
+	if !strings.HasPrefix(p, UsersDir+"/thumb-u-") {
 		t.Fatalf("file path wrong: %s", p)
 	}
 }
@@ -110,3 +160,30 @@ func TestGetStorageDir(t *testing.T) {
 		t.Fatalf("unexpected storage dir, got: %s, want: %s", p, expected)
 	}
 }
+
+func TestGetPublicURL(t *testing.T) {
+	cases := map[string]string{
+		"posts/hello/cover-480w.webp": "/media/posts/hello/cover-480w.webp",
+		"/posts/hello/cover.jpg":      "/media/posts/hello/cover.jpg",
+		"../posts/../../etc/passwd":   "/media/etc/passwd",
+		`posts\hello\cover-768w.avif`: "/media/posts/hello/cover-768w.avif",
+	}
+
+	for rel, want := range cases {
+		if got := GetPublicURL(rel); got != want {
+			t.Fatalf("GetPublicURL(%q) = %q, want %q", rel, got, want)
+		}
+	}
+}
+
+func TestSniffContentType(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	if got, err := SniffContentType(png); err != nil || got != "image/png" {
+		t.Fatalf("got %q err %v", got, err)
+	}
+
+	if _, err := SniffContentType([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>")); err == nil {
+		t.Fatalf("expected non raster content to be rejected")
+	}
+}
@@ -1,8 +1,16 @@
 package media
 
 import (
+	"fmt"
+	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/oullin/metal/env"
+	"github.com/oullin/pkg/storage"
 )
 
 func hasValidExt(ext string) bool {
@@ -33,6 +41,12 @@ func GetStorageDir() string {
 	return filepath.Join(dir, StorageDir)
 }
 
+// NewStorage opens the storage media is kept in: storage/media on disk, served from /media,
+// or the "media" prefix of the bucket.
+func NewStorage(e env.StorageEnvironment) (storage.Storage, error) {
+	return storage.New(e, storage.Area{Dir: GetMediaDir(), URL: "/" + Dir, Prefix: Dir})
+}
+
 func GetMediaDir() string {
 	return GetStorageDir() + "/" + Dir
 }
@@ -44,3 +58,44 @@ func GetUsersImagesDir() string {
 func GetPostsImagesDir() string {
 	return GetMediaDir() + "/" + PostsDir
 }
+
+func GetUploadsDir() string {
+	return GetMediaDir() + "/" + UploadsDir
+}
+
+// GetCacheDir holds data the app can rebuild at any time, e.g. after a deploy wiped it.
+func GetCacheDir() string {
+	return GetStorageDir() + "/" + CacheDir
+}
+
+// GetImagesCacheDir holds the renditions generated on demand by the image resizing endpoint.
+func GetImagesCacheDir() string {
+	return GetCacheDir() + "/" + ImagesCacheDir
+}
+
+func GetMaxFileSize() int64 {
+	return maxFileSize
+}
+
+// SniffContentType detects the type of the given leading bytes, ignoring any name or
+// header the client sent, and reports whether it is an image we accept.
+func SniffContentType(sample []byte) (string, error) {
+	if len(sample) > 512 {
+		sample = sample[:512]
+	}
+
+	contentType := http.DetectContentType(sample)
+
+	if !slices.Contains(allowedContentTypes, contentType) {
+		return contentType, fmt.Errorf("the given content type [%s] is not allowed", contentType)
+	}
+
+	return contentType, nil
+}
+
+// GetPublicURL maps a path relative to the media directory onto the URL it is served from.
+func GetPublicURL(rel string) string {
+	cleaned := strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(rel, "\\", "/")), "/")
+
+	return "/" + Dir + "/" + cleaned
+}
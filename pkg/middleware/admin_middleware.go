@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/pkg/endpoint"
+	"github.com/oullin/pkg/middleware/mwguards"
+	"github.com/oullin/pkg/portal"
+)
+
+// AdminMiddleware authorises requests already authenticated by TokenCheckMiddleware.
+// The calling API account name must match the username of a User flagged as admin;
+// that user is attached to the request context under portal.AuthAdminUserKey.
+type AdminMiddleware struct {
+	Users *repository.Users
+}
+
+func NewAdminMiddleware(users *repository.Users) AdminMiddleware {
+	return AdminMiddleware{Users: users}
+}
+
+func (a AdminMiddleware) Handle(next endpoint.ApiHandler) endpoint.ApiHandler {
+	return func(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+		accountName, _ := r.Context().Value(portal.AuthAccountNameKey).(string)
+		accountName = strings.TrimSpace(accountName)
+
+		if accountName == "" {
+			return mwguards.UnauthenticatedError(
+				"Invalid authentication context",
+				"admin middleware: the request carries no authenticated account",
+			)
+		}
+
+		if a.Users == nil {
+			return mwguards.ForbiddenError(
+				"Forbidden",
+				"admin middleware missing dependencies: Users",
+				map[string]any{"account_name": accountName},
+			)
+		}
+
+		user := a.Users.FindBy(accountName)
+
+		if user == nil || !user.IsAdmin {
+			return mwguards.ForbiddenError(
+				"Forbidden",
+				"admin middleware: account ["+accountName+"] is not linked to an admin user",
+				map[string]any{"account_name": accountName},
+			)
+		}
+
+		ctx := context.WithValue(r.Context(), portal.AuthAdminUserKey, user)
+
+		return next(w, r.WithContext(ctx))
+	}
+}
+
+// AdminUserFrom returns the admin user attached by AdminMiddleware, if any.
+func AdminUserFrom(r *http.Request) *database.User {
+	user, _ := r.Context().Value(portal.AuthAdminUserKey).(*database.User)
+
+	return user
+}
@@ -0,0 +1,85 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/oullin/database"
+	"github.com/oullin/database/repository"
+	"github.com/oullin/internal/testutil/dbtest"
+	"github.com/oullin/pkg/endpoint"
+	"github.com/oullin/pkg/middleware"
+	"github.com/oullin/pkg/portal"
+)
+
+func adminNext(called *bool) endpoint.ApiHandler {
+	return func(w http.ResponseWriter, r *http.Request) *endpoint.ApiError {
+		*called = true
+
+		if middleware.AdminUserFrom(r) == nil {
+			return endpoint.InternalError("expected admin user in context")
+		}
+
+		return nil
+	}
+}
+
+func withAccount(req *http.Request, account string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), portal.AuthAccountNameKey, account))
+}
+
+func TestAdminMiddlewareRequiresAuthenticatedAccount(t *testing.T) {
+	called := false
+	mw := middleware.NewAdminMiddleware(&repository.Users{})
+
+	err := mw.Handle(adminNext(&called))(httptest.NewRecorder(), httptest.NewRequest("POST", "/admin/posts", nil))
+
+	if err == nil || err.Status != http.StatusUnauthorized {
+		t.Fatalf("expected unauthorised error, got %+v", err)
+	}
+
+	if called {
+		t.Fatalf("next handler must not run")
+	}
+}
+
+func TestAdminMiddlewareMissingUsersRepository(t *testing.T) {
+	called := false
+	mw := middleware.NewAdminMiddleware(nil)
+	req := withAccount(httptest.NewRequest("POST", "/admin/posts", nil), "editor")
+
+	err := mw.Handle(adminNext(&called))(httptest.NewRecorder(), req)
+
+	if err == nil || err.Status != http.StatusForbidden {
+		t.Fatalf("expected forbidden error, got %+v", err)
+	}
+}
+
+func TestAdminMiddlewareAuthorisesAdminsPostgres(t *testing.T) {
+	h := dbtest.NewTestsHelper(t, &database.User{})
+
+	admin := h.SeedUser("Ada", "Admin", "editor")
+	_ = h.SeedUser("Rob", "Reader", "reader")
+
+	if err := h.Conn().Sql().Model(&admin).Update("is_admin", true).Error; err != nil {
+		t.Fatalf("promote admin: %v", err)
+	}
+
+	mw := middleware.NewAdminMiddleware(&repository.Users{DB: h.Conn()})
+
+	called := false
+	req := withAccount(httptest.NewRequest("POST", "/admin/posts", nil), "editor")
+
+	if err := mw.Handle(adminNext(&called))(httptest.NewRecorder(), req); err != nil || !called {
+		t.Fatalf("expected admin to pass, got %+v", err)
+	}
+
+	called = false
+	req = withAccount(httptest.NewRequest("POST", "/admin/posts", nil), "reader")
+
+	if err := mw.Handle(adminNext(&called))(httptest.NewRecorder(), req); err == nil || err.Status != http.StatusForbidden || called {
+		t.Fatalf("expected non-admin to be forbidden, got %+v", err)
+	}
+}
@@ -123,3 +123,16 @@ func TimestampTooNewError(message, logMessage string, data ...map[string]any) *e
 		Data:    d,
 	}
 }
+
+func ForbiddenError(message, logMessage string, data ...map[string]any) *endpoint.ApiError {
+	message, logMessage = normaliseMessages(message, logMessage)
+
+	d := normaliseData(data...)
+	slog.Error(logMessage, "data", d)
+
+	return &endpoint.ApiError{
+		Message: message,
+		Status:  http.StatusForbidden,
+		Data:    d,
+	}
+}
@@ -17,3 +17,18 @@ func IsFoundButHasErrors(err error) bool {
 func HasDbIssues(err error) bool {
 	return IsNotFound(err) || IsFoundButHasErrors(err)
 }
+
+// IsUniqueViolation reports whether the write was refused by a unique constraint, e.g. a
+// concurrent insert of the same key winning the race.
+func IsUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		return stateErr.SQLState() == "23505"
+	}
+
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
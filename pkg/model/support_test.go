@@ -2,6 +2,7 @@ package model
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	stdgorm "gorm.io/gorm"
@@ -40,3 +41,23 @@ func TestHasDbIssues(t *testing.T) {
 		t.Fatalf("nil should be false")
 	}
 }
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string { return "sql state " + string(e) }
+
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func TestIsUniqueViolation(t *testing.T) {
+	if !IsUniqueViolation(fmt.Errorf("insert: %w", sqlStateError("23505"))) {
+		t.Fatalf("expected a wrapped 23505 to be a unique violation")
+	}
+
+	if !IsUniqueViolation(stdgorm.ErrDuplicatedKey) {
+		t.Fatalf("expected the translated gorm error to be a unique violation")
+	}
+
+	if IsUniqueViolation(sqlStateError("23503")) || IsUniqueViolation(errors.New("foo")) || IsUniqueViolation(nil) {
+		t.Fatalf("expected other errors not to be unique violations")
+	}
+}
@@ -0,0 +1,167 @@
+package pdf
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Font is one of the standard PDF fonts, which every reader ships, so nothing has to be
+// embedded.
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+	HelveticaOblique
+)
+
+var fontNames = [...]string{
+	Helvetica:        "Helvetica",
+	HelveticaBold:    "Helvetica-Bold",
+	HelveticaOblique: "Helvetica-Oblique",
+}
+
+// regularWidths and boldWidths are the advance widths, in thousandths of the font size, of
+// the printable ASCII characters from the Adobe font metrics. The oblique face shares the
+// regular widths.
+var regularWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 to ?
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ to O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P to _
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` to o
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p to ~
+}
+
+var boldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// punctuationWidths covers the Windows-1252 punctuation the fixtures use; any other
+// character outside ASCII, mostly accented letters, is measured as an average letter.
+var punctuationWidths = map[byte][2]int{
+	0x91: {222, 278},   // ‘
+	0x92: {222, 278},   // ’
+	0x93: {333, 500},   // “
+	0x94: {333, 500},   // ”
+	0x95: {350, 350},   // •
+	0x96: {556, 556},   // –
+	0x97: {1000, 1000}, // —
+	0xA0: {278, 278},   // no-break space
+}
+
+const averageWidth = 556
+
+func (f Font) width(char byte) int {
+	bold := 0
+	if f == HelveticaBold {
+		bold = 1
+	}
+
+	switch {
+	case char >= 32 && char <= 126 && bold == 1:
+		return boldWidths[char-32]
+	case char >= 32 && char <= 126:
+		return regularWidths[char-32]
+	}
+
+	if widths, ok := punctuationWidths[char]; ok {
+		return widths[bold]
+	}
+
+	return averageWidth
+}
+
+// TextWidth is the width of the text in points.
+func TextWidth(text string, font Font, size float64) float64 {
+	total := 0
+
+	for _, char := range encode(text) {
+		total += font.width(char)
+	}
+
+	return float64(total) * size / 1000
+}
+
+// Wrap breaks the text into lines no wider than width. New lines in the text are kept, so
+// an empty line separates paragraphs; a word wider than the line is cut.
+func Wrap(text string, font Font, size, width float64) []string {
+	var lines []string
+
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := ""
+
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+
+			if TextWidth(candidate, font, size) <= width {
+				line = candidate
+				continue
+			}
+
+			if line != "" {
+				lines = append(lines, line)
+			}
+
+			line = word
+
+			for TextWidth(line, font, size) > width {
+				cut := cutAt(line, font, size, width)
+				lines = append(lines, line[:cut])
+				line = line[cut:]
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// cutAt is the byte index at which the word stops fitting, after at least one character.
+func cutAt(word string, font Font, size, width float64) int {
+	cut := 0
+
+	for i, r := range word {
+		end := i + utf8.RuneLen(r)
+
+		if cut > 0 && TextWidth(word[:end], font, size) > width {
+			break
+		}
+
+		cut = end
+	}
+
+	return cut
+}
+
+// replacements map the characters Windows-1252 lacks onto the closest ones it has.
+var replacements = strings.NewReplacer("\u2010", "-", "\u2011", "-", "\u2012", "-", "\u2212", "-", "\u2009", " ", "\u202f", " ")
+
+// encode turns the text into the Windows-1252 bytes the standard fonts are set up with.
+// Characters it cannot hold are written as a question mark.
+func encode(text string) []byte {
+	text = replacements.Replace(text)
+	encoded := make([]byte, 0, len(text))
+
+	for _, r := range text {
+		if char, ok := charmap.Windows1252.EncodeRune(r); ok {
+			encoded = append(encoded, char)
+			continue
+		}
+
+		encoded = append(encoded, '?')
+	}
+
+	return encoded
+}
@@ -0,0 +1,182 @@
+// Package pdf writes simple PDF documents, text, lines and filled rectangles on A4 pages,
+// with the standard fonts so no font file has to ship with the binary. Its only dependency
+// outside the standard library is golang.org/x/text, for the WinAnsi text encoding.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A4 in points, the unit every coordinate is given in.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type Color struct {
+	R, G, B uint8
+}
+
+// Document is a PDF being drawn page by page. Coordinates start at the top left corner of
+// the page and grow downwards; text is placed by its baseline.
+type Document struct {
+	title string
+	pages []*bytes.Buffer
+}
+
+func New(title string) *Document {
+	return &Document{title: title}
+}
+
+// AddPage starts a new page, which every later drawing goes to.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) Text(x, y float64, font Font, size float64, color Color, text string) {
+	fmt.Fprintf(d.page(), "BT /F%d %s Tf %s rg %s %s Td (%s) Tj ET\n",
+		font+1, number(size), color.operands(), number(x), number(PageHeight-y), escape(encode(text)))
+}
+
+func (d *Document) Line(x1, y1, x2, y2, width float64, color Color) {
+	fmt.Fprintf(d.page(), "%s w %s RG %s %s m %s %s l S\n",
+		number(width), color.operands(), number(x1), number(PageHeight-y1), number(x2), number(PageHeight-y2))
+}
+
+// Rect fills the rectangle whose top left corner is at x, y.
+func (d *Document) Rect(x, y, width, height float64, color Color) {
+	fmt.Fprintf(d.page(), "%s rg %s %s %s %s re f\n",
+		color.operands(), number(x), number(PageHeight-y-height), number(width), number(height))
+}
+
+// Write encodes the document. The output only depends on what was drawn, so the same
+// content always gives the same bytes.
+func (d *Document) Write(w io.Writer) error {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	out := &writer{}
+	out.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+
+	fonts := len(fontNames)
+	pageObject := func(i int) int { return 4 + fonts + i*2 }
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", pageObject(i))
+	}
+
+	out.object(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	out.object(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	out.object(3, fmt.Sprintf("<< /Title (%s) /Producer (oullin) >>", escape(encode(d.title))))
+
+	resources := make([]string, fonts)
+	for i, name := range fontNames {
+		out.object(4+i, fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+		resources[i] = fmt.Sprintf("/F%d %d 0 R", i+1, 4+i)
+	}
+
+	for i, content := range d.pages {
+		stream, err := deflate(content.Bytes())
+		if err != nil {
+			return err
+		}
+
+		out.object(pageObject(i), fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			number(PageWidth), number(PageHeight), strings.Join(resources, " "), pageObject(i)+1))
+		out.object(pageObject(i)+1, fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", len(stream), stream))
+	}
+
+	xref := out.buf.Len()
+	out.printf("xref\n0 %d\n0000000000 65535 f \n", len(out.offsets)+1)
+
+	for _, offset := range out.offsets {
+		out.printf("%010d 00000 n \n", offset)
+	}
+
+	out.printf("trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(out.offsets)+1, xref)
+
+	_, err := w.Write(out.buf.Bytes())
+
+	return err
+}
+
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	return d.pages[len(d.pages)-1]
+}
+
+// writer keeps the offset of every object for the cross-reference table. Objects must be
+// written in the order of their numbers.
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (w *writer) printf(format string, args ...any) {
+	fmt.Fprintf(&w.buf, format, args...)
+}
+
+func (w *writer) object(number int, body string) {
+	w.offsets = append(w.offsets, w.buf.Len())
+	w.printf("%d 0 obj\n%s\nendobj\n", number, body)
+}
+
+func deflate(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c Color) operands() string {
+	return fmt.Sprintf("%s %s %s", number(float64(c.R)/255), number(float64(c.G)/255), number(float64(c.B)/255))
+}
+
+// number writes a coordinate or colour component, rounded to a thousandth of a point.
+func number(value float64) string {
+	return strconv.FormatFloat(math.Round(value*1000)/1000, 'f', -1, 64)
+}
+
+// escape writes the bytes as the body of a PDF literal string.
+func escape(text []byte) string {
+	var out strings.Builder
+
+	for _, char := range text {
+		switch char {
+		case '\\', '(', ')':
+			out.WriteByte('\\')
+			out.WriteByte(char)
+		case '\n':
+			out.WriteString(`\n`)
+		case '\r':
+			out.WriteString(`\r`)
+		default:
+			out.WriteByte(char)
+		}
+	}
+
+	return out.String()
+}
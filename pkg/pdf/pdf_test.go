@@ -0,0 +1,93 @@
+package pdf_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/oullin/pkg/pdf"
+)
+
+func TestWrapKeepsLinesWithinTheWidth(t *testing.T) {
+	text := "Designed real-time pipelines with robust back-pressure and exactly-once guarantees.\nShipped."
+	width := 150.0
+
+	lines := pdf.Wrap(text, pdf.Helvetica, 10, width)
+
+	if len(lines) < 3 || lines[len(lines)-1] != "Shipped." {
+		t.Fatalf("unexpected lines %q", lines)
+	}
+
+	for _, line := range lines {
+		if pdf.TextWidth(line, pdf.Helvetica, 10) > width {
+			t.Fatalf("line %q is wider than %v", line, width)
+		}
+	}
+
+	if got := pdf.Wrap(strings.Repeat("w", 60), pdf.HelveticaBold, 10, 100); len(got) < 2 {
+		t.Fatalf("expected a long word to be cut, got %q", got)
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	if got := pdf.TextWidth("ab", pdf.Helvetica, 10); got != 11.12 {
+		t.Fatalf("expected 11.12, got %v", got)
+	}
+
+	if pdf.TextWidth("Go", pdf.HelveticaBold, 10) <= pdf.TextWidth("Go", pdf.Helvetica, 10) {
+		t.Fatalf("expected bold text to be wider")
+	}
+}
+
+func TestWriteProducesAWellFormedDocument(t *testing.T) {
+	doc := pdf.New("Gustavo (CV)")
+
+	doc.AddPage()
+	doc.Text(50, 60, pdf.HelveticaBold, 20, pdf.Color{}, "Gustavo Ocanto")
+	doc.Line(50, 70, 545, 70, 0.5, pdf.Color{R: 200, G: 200, B: 200})
+	doc.AddPage()
+	doc.Rect(50, 50, 10, 10, pdf.Color{R: 255})
+	doc.Text(50, 80, pdf.HelveticaOblique, 10, pdf.Color{}, "café (hands‑on) \\ done")
+
+	var out bytes.Buffer
+	if err := doc.Write(&out); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	body := out.String()
+
+	if !strings.HasPrefix(body, "%PDF-1.4\n") || !strings.HasSuffix(body, "%%EOF\n") {
+		t.Fatalf("unexpected header or trailer")
+	}
+
+	if !strings.Contains(body, "/Type /Pages /Kids [7 0 R 9 0 R] /Count 2") || !strings.Contains(body, "/Title (Gustavo \\(CV\\))") {
+		t.Fatalf("unexpected page tree or info:\n%s", body)
+	}
+
+	start, err := strconv.Atoi(regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(body)[1])
+	if err != nil || !strings.HasPrefix(body[start:], "xref\n0 11\n") {
+		t.Fatalf("startxref does not point at the xref table")
+	}
+
+	for i, match := range regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(body, -1) {
+		offset, _ := strconv.Atoi(match[1])
+		if !strings.HasPrefix(body[offset:], strconv.Itoa(i+1)+" 0 obj\n") {
+			t.Fatalf("xref entry %d points at %q", i+1, body[offset:offset+10])
+		}
+	}
+
+	stream := body[strings.LastIndex(body, ">>\nstream\n")+len(">>\nstream\n"):]
+	reader, err := zlib.NewReader(strings.NewReader(stream))
+	if err != nil {
+		t.Fatalf("open content stream: %v", err)
+	}
+
+	content, _ := io.ReadAll(reader)
+	if !strings.Contains(string(content), "(caf\xe9 \\(hands-on\\) \\\\ done) Tj") {
+		t.Fatalf("unexpected content stream %q", content)
+	}
+}
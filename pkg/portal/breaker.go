@@ -0,0 +1,179 @@
+package portal
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+
+	// maxBreakerHosts is how many failing hosts are kept before the stale ones are swept.
+	maxBreakerHosts = 1024
+)
+
+var ErrCircuitOpen = errors.New("fetch: circuit open")
+
+// BreakerPolicy configures the per-host circuit breakers of a Fetcher. After Threshold
+// consecutive failures a host is left alone for Cooldown; a single trial request then
+// decides whether it is healthy again.
+type BreakerPolicy struct {
+	Threshold int           // Consecutive failures opening the circuit; 5 when zero, disabled when negative.
+	Cooldown  time.Duration // Time the circuit stays open before a trial request; 30s when zero.
+}
+
+func (p BreakerPolicy) withDefaults() BreakerPolicy {
+	if p.Threshold == 0 {
+		p.Threshold = defaultBreakerThreshold
+	}
+
+	if p.Cooldown <= 0 {
+		p.Cooldown = defaultBreakerCooldown
+	}
+
+	return p
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+type breaker struct {
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// breakers only keeps the hosts that failed lately: a host is dropped as soon as a request
+// to it succeeds, so the hosts fetched from, which are up to the callers, never pile up.
+type breakers struct {
+	policy BreakerPolicy
+	label  func(host string) string
+	now    func() time.Time
+	mu     sync.Mutex
+	hosts  map[string]*breaker
+}
+
+func newBreakers(policy BreakerPolicy, label func(host string) string) *breakers {
+	return &breakers{
+		policy: policy,
+		label:  label,
+		now:    time.Now,
+		hosts:  make(map[string]*breaker),
+	}
+}
+
+// allow lets a request through unless the host's circuit is open. Once the cooldown is
+// over, exactly one request goes through as the trial while the others keep failing fast.
+func (b *breakers) allow(host string) error {
+	if b.policy.Threshold < 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entry, ok := b.hosts[host]
+	if !ok {
+		return nil
+	}
+
+	switch entry.state {
+	case breakerOpen:
+		if b.now().Sub(entry.openedAt) < b.policy.Cooldown {
+			return fmt.Errorf("%w: %s", ErrCircuitOpen, host)
+		}
+
+		entry.state = breakerHalfOpen
+
+		return nil
+	case breakerHalfOpen:
+		return fmt.Errorf("%w: %s, trial request in flight", ErrCircuitOpen, host)
+	default:
+		return nil
+	}
+}
+
+// record feeds the outcome of a request let through by allow back into the breaker.
+func (b *breakers) record(host string, resp *http.Response, err error) {
+	if b.policy.Threshold < 0 {
+		return
+	}
+
+	if err != nil && isPermanentFetchError(err) {
+		b.release(host)
+
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !isHostFailure(resp, err) {
+		delete(b.hosts, host)
+
+		return
+	}
+
+	entry, ok := b.hosts[host]
+	if !ok {
+		b.sweep()
+
+		entry = &breaker{}
+		b.hosts[host] = entry
+	}
+
+	entry.failures++
+
+	if entry.state == breakerHalfOpen || entry.failures >= b.policy.Threshold {
+		entry.state = breakerOpen
+		entry.openedAt = b.now()
+		entry.failures = 0
+
+		circuitTrips.WithLabelValues(b.label(host)).Inc()
+	}
+}
+
+// release is for requests whose outcome says nothing about the host, e.g. cancelled by the
+// caller: a trial request hands its turn over to the next one.
+func (b *breakers) release(host string) {
+	if b.policy.Threshold < 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if entry, ok := b.hosts[host]; ok && entry.state == breakerHalfOpen {
+		entry.state = breakerOpen
+	}
+}
+
+// sweep drops, once maxBreakerHosts are kept, the hosts that are not failing fast right
+// now: those below the threshold and those whose cooldown is over. A host failing again
+// starts over from a closed circuit. The caller holds the lock.
+func (b *breakers) sweep() {
+	if len(b.hosts) < maxBreakerHosts {
+		return
+	}
+
+	for host, entry := range b.hosts {
+		if entry.state == breakerClosed || (entry.state == breakerOpen && b.now().Sub(entry.openedAt) >= b.policy.Cooldown) {
+			delete(b.hosts, host)
+		}
+	}
+}
+
+// isHostFailure tells whether an outcome counts against the host: network errors and
+// server errors do, client errors and rate limits don't.
+func isHostFailure(resp *http.Response, err error) bool {
+	return err != nil || resp.StatusCode >= 500
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"time"
 )
 
 type Response struct {
@@ -15,33 +14,20 @@ type Response struct {
 
 type Client struct {
 	UserAgent      string
-	client         *http.Client
-	transport      *http.Transport
+	fetcher        *Fetcher
 	OnHeaders      func(req *http.Request)
 	AbortOnNone2xx bool
 }
 
-func GetDefaultTransport() *http.Transport {
-	return &http.Transport{
-		MaxIdleConns:        100,
-		MaxIdleConnsPerHost: 10,
-		IdleConnTimeout:     90 * time.Second,
-	}
-}
-
-func NewDefaultClient(transport *http.Transport) *Client {
-	if transport == nil {
-		transport = GetDefaultTransport()
-	}
-
-	client := &http.Client{
-		Transport: transport,
-		Timeout:   15 * time.Second,
+// NewDefaultClient builds a client on top of the given fetcher, or of one with the
+// default policy when nil, so requests only ever reach public hosts.
+func NewDefaultClient(fetcher *Fetcher) *Client {
+	if fetcher == nil {
+		fetcher = NewFetcher(FetchPolicy{})
 	}
 
 	return &Client{
-		client:         client,
-		transport:      transport,
+		fetcher:        fetcher,
 		UserAgent:      "oullin.io",
 		OnHeaders:      nil,
 		AbortOnNone2xx: false,
@@ -58,7 +44,7 @@ func (f *Client) Get(ctx context.Context, url string) (string, error) {
 }
 
 func (f *Client) GetResponse(ctx context.Context, url string) (Response, error) {
-	if f == nil || f.client == nil {
+	if f == nil || f.fetcher == nil {
 		return Response{}, fmt.Errorf("client is nil")
 	}
 
@@ -74,14 +60,14 @@ func (f *Client) GetResponse(ctx context.Context, url string) (Response, error)
 
 	req.Header.Set("User-Agent", f.UserAgent)
 
-	resp, err := f.client.Do(req)
+	resp, err := f.fetcher.Do(req)
 	if err != nil {
 		return Response{}, fmt.Errorf("http request failed: %w", err)
 	}
 
 	defer resp.Body.Close()
 
-	body, err := ReadWithSizeLimit(resp.Body)
+	body, err := ReadWithSizeLimit(resp.Body, f.fetcher.Policy().MaxBytes)
 	if err != nil {
 		return Response{}, fmt.Errorf("failed to read response body: %w", err)
 	}
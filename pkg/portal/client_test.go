@@ -5,13 +5,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/oullin/pkg/portal"
 )
 
 func TestClientTransportAndGet(t *testing.T) {
-	tr := portal.GetDefaultTransport()
-	c := portal.NewDefaultClient(tr)
+	c := portal.NewDefaultClient(portal.NewFetcher(portal.FetchPolicy{AllowPrivate: true}))
 
 	if c.UserAgent != "oullin.io" {
 		t.Fatalf("unexpected default user agent: %s", c.UserAgent)
@@ -40,7 +40,7 @@ func TestClientGetNil(t *testing.T) {
 }
 
 func TestClientOnHeadersAndAbort(t *testing.T) {
-	c := portal.NewDefaultClient(nil)
+	c := portal.NewDefaultClient(portal.NewFetcher(portal.FetchPolicy{AllowPrivate: true}))
 	called := false
 	c.OnHeaders = func(req *http.Request) {
 		req.Header.Set("X-Test", "ok")
@@ -70,7 +70,7 @@ func TestClientOnHeadersAndAbort(t *testing.T) {
 }
 
 func TestClientGetResponsePreservesMetadataOnAbort(t *testing.T) {
-	c := portal.NewDefaultClient(nil)
+	c := portal.NewDefaultClient(portal.NewFetcher(portal.FetchPolicy{AllowPrivate: true}))
 	c.AbortOnNone2xx = true
 
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -99,7 +99,7 @@ func TestClientGetResponsePreservesMetadataOnAbort(t *testing.T) {
 }
 
 func TestClientGetResponseIncludesHeaders(t *testing.T) {
-	c := portal.NewDefaultClient(nil)
+	c := portal.NewDefaultClient(portal.NewFetcher(portal.FetchPolicy{AllowPrivate: true}))
 
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Link", `<https://example.test?page=2>; rel="last"`)
@@ -125,3 +125,30 @@ func TestClientGetResponseIncludesHeaders(t *testing.T) {
 		t.Fatalf("unexpected link header %q", resp.Header.Get("Link"))
 	}
 }
+
+func TestClientRetriesTransientFailures(t *testing.T) {
+	calls := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	c := portal.NewDefaultClient(portal.NewFetcher(portal.FetchPolicy{
+		AllowPrivate: true,
+		Retry:        portal.RetryPolicy{BaseDelay: time.Millisecond},
+	}))
+	c.AbortOnNone2xx = true
+
+	out, err := c.Get(context.Background(), srv.URL)
+	if err != nil || out != "hello" || calls != 2 {
+		t.Fatalf("expected the 503 to be retried, got %q %v after %d calls", out, err, calls)
+	}
+}
@@ -18,4 +18,5 @@ const IntendedOriginHeader = "X-API-Intended-Origin"
 type contextKey string
 
 const AuthAccountNameKey contextKey = "auth.account_name"
+const AuthAdminUserKey contextKey = "auth.admin_user"
 const RequestIDKey contextKey = "request.id"
@@ -0,0 +1,345 @@
+package portal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultFetchMaxRedirects = 5
+	defaultFetchMaxBytes     = 5 * 1024 * 1024
+	defaultFetchTimeout      = 15 * time.Second
+)
+
+var (
+	ErrFetchScheme       = errors.New("fetch: scheme not allowed")
+	ErrFetchHost         = errors.New("fetch: host not allowed")
+	ErrFetchAddress      = errors.New("fetch: address not allowed")
+	ErrFetchRedirects    = errors.New("fetch: too many redirects")
+	ErrFetchBodyTooLarge = errors.New("fetch: response body too large")
+)
+
+// Addresses no outbound fetch may reach on top of the loopback, private, link-local,
+// multicast and unspecified ranges netip already knows about.
+var blockedFetchPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "This" network.
+	netip.MustParsePrefix("100.64.0.0/10"),   // Carrier-grade NAT.
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments.
+	netip.MustParsePrefix("198.18.0.0/15"),   // Benchmarking.
+	netip.MustParsePrefix("240.0.0.0/4"),     // Reserved, including broadcast.
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, which maps onto IPv4 again.
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // Local-use NAT64.
+	netip.MustParsePrefix("2001:db8::/32"),   // Documentation.
+	netip.MustParsePrefix("2002::/16"),       // 6to4, which embeds an IPv4 address.
+	netip.MustParsePrefix("2001::/32"),       // Teredo, likewise.
+	netip.MustParsePrefix("fec0::/10"),       // Deprecated site-local.
+	netip.MustParsePrefix("100::/64"),        // Discard-only.
+	netip.MustParsePrefix("::ffff:0:0:0/96"), // IPv4-translated.
+}
+
+// FetchPolicy limits where a Fetcher may go and how much it may bring back.
+type FetchPolicy struct {
+	Schemes      []string      // Allowed URL schemes; http and https when empty.
+	Hosts        []string      // Allowed host names; any public host when empty. "*.example.com" matches its sub-domains.
+	AllowPrivate bool          // Lets loopback and private addresses through. Only meant for tests and local setups.
+	MaxRedirects int           // Redirects followed before giving up; 5 when zero, none when negative.
+	MaxBytes     int64         // Largest response body read; 5MB when zero.
+	Timeout      time.Duration // Timeout of every attempt, body included; 15s when zero.
+	Retry        RetryPolicy   // How transient failures are retried.
+	Breaker      BreakerPolicy // When a failing host is left alone for a while.
+}
+
+// Fetcher is the single way out to the network for content whose URL comes from
+// somewhere we do not control: post sources, inline images, cover images, repository APIs.
+// The URL is checked against the policy before the request, on every redirect, and the
+// resolved IP is checked again when dialling, so DNS rebinding cannot sneak past the check.
+type Fetcher struct {
+	policy   FetchPolicy
+	client   *http.Client
+	breakers *breakers
+}
+
+func NewFetcher(policy FetchPolicy) *Fetcher {
+	if len(policy.Schemes) == 0 {
+		policy.Schemes = []string{"http", "https"}
+	}
+
+	if policy.MaxRedirects == 0 {
+		policy.MaxRedirects = defaultFetchMaxRedirects
+	}
+
+	if policy.MaxBytes <= 0 {
+		policy.MaxBytes = defaultFetchMaxBytes
+	}
+
+	if policy.Timeout <= 0 {
+		policy.Timeout = defaultFetchTimeout
+	}
+
+	policy.Retry = policy.Retry.withDefaults()
+	policy.Breaker = policy.Breaker.withDefaults()
+
+	f := &Fetcher{policy: policy, breakers: newBreakers(policy.Breaker, policy.metricHost)}
+
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   f.controlDial,
+	}
+
+	transport := &http.Transport{
+		// A proxy would resolve the target itself, out of reach of the dial-time check.
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: policy.Timeout,
+	}
+
+	f.client = &http.Client{
+		Transport:     transport,
+		Timeout:       policy.Timeout,
+		CheckRedirect: f.checkRedirect,
+	}
+
+	return f
+}
+
+func (f *Fetcher) Policy() FetchPolicy {
+	return f.policy
+}
+
+// Do sends the request once its URL passes the policy, retrying transient failures and
+// failing fast while the host's circuit is open. The returned body fails with
+// ErrFetchBodyTooLarge past the policy's MaxBytes.
+func (f *Fetcher) Do(req *http.Request) (*http.Response, error) {
+	if f == nil || f.client == nil {
+		return nil, errors.New("fetch: fetcher is nil")
+	}
+
+	if err := f.CheckURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	resp, err := f.send(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.ContentLength > f.policy.MaxBytes {
+		_ = resp.Body.Close()
+
+		return nil, fmt.Errorf("%w: %d bytes announced, %d allowed", ErrFetchBodyTooLarge, resp.ContentLength, f.policy.MaxBytes)
+	}
+
+	resp.Body = &cappedBody{ReadCloser: resp.Body, remaining: f.policy.MaxBytes}
+
+	return resp, nil
+}
+
+func (f *Fetcher) send(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	host := strings.ToLower(req.URL.Hostname())
+	retry := f.policy.Retry
+
+	for attempt := 1; ; attempt++ {
+		if err := f.breakers.allow(host); err != nil {
+			return nil, err
+		}
+
+		resp, err := f.client.Do(req)
+
+		if ctx.Err() != nil {
+			f.breakers.release(host)
+		} else {
+			f.breakers.record(host, resp, err)
+		}
+
+		if attempt >= retry.MaxAttempts || ctx.Err() != nil || !isIdempotent(req) || !retry.RetryOn(resp, err) {
+			return resp, err
+		}
+
+		wait, ok := retry.delay(attempt, resp, time.Now())
+		if !ok {
+			return resp, err
+		}
+
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+
+			req.Body = body
+		}
+
+		discard(resp)
+		fetchRetries.WithLabelValues(f.policy.metricHost(host), retryReason(resp)).Inc()
+
+		if err = sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Get is a plain GET with the given headers.
+func (f *Fetcher) Get(ctx context.Context, target string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch: create request: %w", err)
+	}
+
+	for name, values := range header {
+		req.Header[name] = slices.Clone(values)
+	}
+
+	return f.Do(req)
+}
+
+// CheckURL tells whether the URL may be fetched: an allowed scheme, an allowed host and,
+// for IP literals, a public address. Host names are resolved and checked when dialling.
+func (f *Fetcher) CheckURL(target *url.URL) error {
+	if target == nil {
+		return fmt.Errorf("%w: empty url", ErrFetchHost)
+	}
+
+	scheme := strings.ToLower(target.Scheme)
+	if !slices.Contains(f.policy.Schemes, scheme) {
+		return fmt.Errorf("%w: %q", ErrFetchScheme, target.Scheme)
+	}
+
+	if target.User != nil {
+		return fmt.Errorf("%w: credentials in the url", ErrFetchHost)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(target.Hostname()), ".")
+	if host == "" {
+		return fmt.Errorf("%w: empty host", ErrFetchHost)
+	}
+
+	if !f.hostAllowed(host) {
+		return fmt.Errorf("%w: %q", ErrFetchHost, host)
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return f.checkAddr(addr)
+	}
+
+	if !f.policy.AllowPrivate && (host == "localhost" || strings.HasSuffix(host, ".localhost")) {
+		return fmt.Errorf("%w: %q", ErrFetchHost, host)
+	}
+
+	return nil
+}
+
+func (f *Fetcher) hostAllowed(host string) bool {
+	if len(f.policy.Hosts) == 0 {
+		return true
+	}
+
+	for _, allowed := range f.policy.Hosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+
+			continue
+		}
+
+		if host == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *Fetcher) checkAddr(addr netip.Addr) error {
+	if f.policy.AllowPrivate || IsPublicAddr(addr) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrFetchAddress, addr)
+}
+
+func (f *Fetcher) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > f.policy.MaxRedirects {
+		return fmt.Errorf("%w: stopped after %d", ErrFetchRedirects, f.policy.MaxRedirects)
+	}
+
+	return f.CheckURL(req.URL)
+}
+
+// controlDial runs once the host name has been resolved, right before connecting, so it
+// sees the address actually dialled rather than whatever an earlier lookup returned.
+func (f *Fetcher) controlDial(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrFetchAddress, address)
+	}
+
+	return f.checkAddr(addrPort.Addr())
+}
+
+// IsPublicAddr reports whether the address is routable on the public internet.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsValid() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+
+	for _, prefix := range blockedFetchPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type cappedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *cappedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrFetchBodyTooLarge
+	}
+
+	// Read one byte past the cap so a body of exactly MaxBytes still ends cleanly.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+
+	if b.remaining < 0 {
+		return n + int(b.remaining), ErrFetchBodyTooLarge
+	}
+
+	return n, err
+}
@@ -0,0 +1,26 @@
+package portal
+
+import (
+	"errors"
+	"testing"
+)
+
+// Host names only get their address checked when dialling, which is what keeps a name
+// re-bound to a private address between the URL check and the connection out.
+func TestFetcherControlDialChecksTheDialledAddress(t *testing.T) {
+	fetcher := NewFetcher(FetchPolicy{})
+
+	for _, address := range []string{"127.0.0.1:443", "[::1]:80", "10.0.0.7:8080", "[::ffff:192.168.0.1]:80", "not-an-address"} {
+		if err := fetcher.controlDial("tcp", address, nil); !errors.Is(err, ErrFetchAddress) {
+			t.Fatalf("%s: expected the dial to be refused, got %v", address, err)
+		}
+	}
+
+	if err := fetcher.controlDial("tcp", "140.82.112.3:443", nil); err != nil {
+		t.Fatalf("expected a public address to be dialled, got %v", err)
+	}
+
+	if err := NewFetcher(FetchPolicy{AllowPrivate: true}).controlDial("tcp", "127.0.0.1:443", nil); err != nil {
+		t.Fatalf("expected AllowPrivate to let loopback through, got %v", err)
+	}
+}
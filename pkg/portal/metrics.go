@@ -0,0 +1,36 @@
+package portal
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	fetchRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "portal_fetch_retries_total",
+		Help: "Outbound requests retried, by host (see metricHost) and the status code (or \"error\") that caused the retry.",
+	}, []string{"host", "reason"})
+
+	circuitTrips = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "portal_fetch_circuit_trips_total",
+		Help: "Times the circuit breaker of a host (see metricHost) opened.",
+	}, []string{"host"})
+)
+
+// otherHost is the label shared by every host a policy does not name, so inline images or
+// post sources on arbitrary sites cannot grow a time series each.
+const otherHost = "other"
+
+// metricHost is the label of the given host: the host itself when the policy names it, e.g.
+// the repository APIs, otherHost for anything else.
+func (p FetchPolicy) metricHost(host string) string {
+	for _, allowed := range p.Hosts {
+		if strings.EqualFold(strings.TrimSpace(allowed), host) {
+			return host
+		}
+	}
+
+	return otherHost
+}
@@ -0,0 +1,165 @@
+package portal
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts = 3
+	defaultRetryBaseDelay   = 250 * time.Millisecond
+	defaultRetryMaxDelay    = 30 * time.Second
+)
+
+// RetryPolicy decides which failed requests a Fetcher tries again and how long it waits
+// in between. Only idempotent requests are ever retried.
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts in total, the first one included; 3 when zero, 1 disables retries.
+	BaseDelay   time.Duration // Backoff before the first retry, doubled on every attempt; 250ms when zero.
+	MaxDelay    time.Duration // Longest single wait; a server asking for more is not retried. 30s when zero.
+
+	// RetryOn overrides which outcomes are worth another attempt; see ShouldRetry for the default.
+	RetryOn func(resp *http.Response, err error) bool
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultRetryMaxAttempts
+	}
+
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = defaultRetryBaseDelay
+	}
+
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = defaultRetryMaxDelay
+	}
+
+	if p.RetryOn == nil {
+		p.RetryOn = ShouldRetry
+	}
+
+	return p
+}
+
+// ShouldRetry retries network errors, 429s, 502s, 503s and 504s, and GitHub's rate
+// limits, which come back as 403s with either a Retry-After or no requests remaining.
+// Requests refused by the fetch policy are never retried.
+func ShouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return !isPermanentFetchError(err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case http.StatusForbidden:
+		return resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0"
+	default:
+		return false
+	}
+}
+
+func isPermanentFetchError(err error) bool {
+	for _, permanent := range []error{
+		ErrFetchScheme,
+		ErrFetchHost,
+		ErrFetchAddress,
+		ErrFetchRedirects,
+		ErrFetchBodyTooLarge,
+		ErrCircuitOpen,
+	} {
+		if errors.Is(err, permanent) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// delay is how long to wait before the given retry, counted from 1. The server's own hint,
+// from Retry-After or X-RateLimit-Reset, wins over the jittered backoff; false means the
+// hint is longer than the policy is willing to wait.
+func (p RetryPolicy) delay(retry int, resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp != nil {
+		if hint, ok := serverRetryHint(resp.Header, now); ok {
+			return hint, hint <= p.MaxDelay
+		}
+	}
+
+	backoff := p.BaseDelay << min(retry-1, 20)
+	if backoff <= 0 || backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+
+	// Full jitter keeps clients that failed together from retrying together.
+	return rand.N(backoff) + 1, true
+}
+
+func serverRetryHint(header http.Header, now time.Time) (time.Duration, bool) {
+	if value := strings.TrimSpace(header.Get("Retry-After")); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+
+		if at, err := http.ParseTime(value); err == nil {
+			return max(at.Sub(now), 0), true
+		}
+	}
+
+	if header.Get("X-RateLimit-Remaining") != "0" {
+		return 0, false
+	}
+
+	reset, err := strconv.ParseInt(strings.TrimSpace(header.Get("X-RateLimit-Reset")), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(time.Unix(reset, 0).Sub(now), 0), true
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	default:
+		return false
+	}
+}
+
+func retryReason(resp *http.Response) string {
+	if resp == nil {
+		return "error"
+	}
+
+	return strconv.Itoa(resp.StatusCode)
+}
+
+// discard drains a response that is about to be retried so its connection can be reused.
+func discard(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+}
+
+func sleep(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
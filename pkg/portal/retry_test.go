@@ -0,0 +1,329 @@
+package portal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func newTestFetcher(retry RetryPolicy, breaker BreakerPolicy) *Fetcher {
+	if retry.BaseDelay == 0 {
+		retry.BaseDelay = time.Millisecond
+	}
+
+	// Naming the httptest host keeps it out of the shared "other" metric label.
+	return NewFetcher(FetchPolicy{AllowPrivate: true, Hosts: []string{"127.0.0.1"}, Retry: retry, Breaker: breaker})
+}
+
+func hostOf(t *testing.T, raw string) string {
+	t.Helper()
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("parse %s: %v", raw, err)
+	}
+
+	return parsed.Hostname()
+}
+
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	t.Helper()
+
+	var metric dto.Metric
+	if err := counter.Write(&metric); err != nil {
+		t.Fatalf("read counter: %v", err)
+	}
+
+	return metric.GetCounter().GetValue()
+}
+
+func TestFetcherRetriesTransientFailures(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer srv.Close()
+
+	host := hostOf(t, srv.URL)
+	before502 := counterValue(t, fetchRetries.WithLabelValues(host, "502"))
+	before429 := counterValue(t, fetchRetries.WithLabelValues(host, "429"))
+
+	resp, err := newTestFetcher(RetryPolicy{}, BreakerPolicy{}).Get(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Fatalf("expected success on the third attempt, got %d after %d calls", resp.StatusCode, calls.Load())
+	}
+
+	if got := counterValue(t, fetchRetries.WithLabelValues(host, "502")) - before502; got != 1 {
+		t.Fatalf("expected one 502 retry to be counted, got %v", got)
+	}
+
+	if got := counterValue(t, fetchRetries.WithLabelValues(host, "429")) - before429; got != 1 {
+		t.Fatalf("expected one 429 retry to be counted, got %v", got)
+	}
+}
+
+func TestFetcherHonoursGitHubRateLimits(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	resp, err := newTestFetcher(RetryPolicy{}, BreakerPolicy{}).Get(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Fatalf("expected the rate limited request to be retried, got %d after %d calls", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestFetcherGivesUpOnLongServerHints(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	resp, err := newTestFetcher(RetryPolicy{MaxDelay: time.Second}, BreakerPolicy{}).Get(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Fatalf("expected the 503 to be handed back untouched, got %d after %d calls", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestFetcherDoesNotRetryUnsafeRequests(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("payload"))
+
+	resp, err := newTestFetcher(RetryPolicy{}, BreakerPolicy{}).Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected a single attempt, got %d", calls.Load())
+	}
+}
+
+func TestFetcherStopsRetryingWhenTheCallerGivesUp(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	fetcher := newTestFetcher(RetryPolicy{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: time.Second}, BreakerPolicy{})
+
+	start := time.Now()
+	if _, err := fetcher.Get(ctx, srv.URL, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to end the retries, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 900*time.Millisecond {
+		t.Fatalf("expected the wait to be cut short, took %s", elapsed)
+	}
+}
+
+func TestFetcherCircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	var healthy atomic.Bool
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	fetcher := newTestFetcher(RetryPolicy{MaxAttempts: 1}, BreakerPolicy{Threshold: 2, Cooldown: time.Minute})
+	fetcher.breakers.now = func() time.Time { return now }
+
+	host := hostOf(t, srv.URL)
+	trips := counterValue(t, circuitTrips.WithLabelValues(host))
+
+	get := func() error {
+		resp, err := fetcher.Get(context.Background(), srv.URL, nil)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := get(); err != nil {
+			t.Fatalf("get %d: %v", i, err)
+		}
+	}
+
+	if err := get(); !errors.Is(err, ErrCircuitOpen) || calls.Load() != 2 {
+		t.Fatalf("expected the open circuit to fail fast, got %v after %d calls", err, calls.Load())
+	}
+
+	if got := counterValue(t, circuitTrips.WithLabelValues(host)) - trips; got != 1 {
+		t.Fatalf("expected one trip to be counted, got %v", got)
+	}
+
+	// The trial request after the cooldown fails, so the circuit opens again straight away.
+	now = now.Add(time.Minute)
+
+	if err := get(); err != nil || calls.Load() != 3 {
+		t.Fatalf("expected a trial request, got %v after %d calls", err, calls.Load())
+	}
+
+	if err := get(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the failed trial to reopen the circuit, got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	healthy.Store(true)
+
+	for i := 0; i < 3; i++ {
+		if err := get(); err != nil {
+			t.Fatalf("expected the healthy host to close the circuit, got %v", err)
+		}
+	}
+
+	if got := counterValue(t, circuitTrips.WithLabelValues(host)) - trips; got != 2 {
+		t.Fatalf("expected two trips to be counted, got %v", got)
+	}
+
+	if len(fetcher.breakers.hosts) != 0 {
+		t.Fatalf("expected the healthy host to be dropped, got %v", fetcher.breakers.hosts)
+	}
+}
+
+func TestBreakersSweepTheHostsNotFailingFast(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := newBreakers(BreakerPolicy{Threshold: 1, Cooldown: time.Minute}, func(string) string { return "other" })
+	b.now = func() time.Time { return now }
+
+	failed := &http.Response{StatusCode: http.StatusBadGateway}
+
+	for i := 0; i < maxBreakerHosts; i++ {
+		b.record("host-"+strconv.Itoa(i)+".example", failed, nil)
+	}
+
+	now = now.Add(time.Minute)
+	b.record("fresh.example", failed, nil)
+
+	if len(b.hosts) != 1 || b.allow("fresh.example") == nil {
+		t.Fatalf("expected only the fresh open circuit to be kept, got %d hosts", len(b.hosts))
+	}
+}
+
+func TestFetchPolicyMetricHost(t *testing.T) {
+	policy := FetchPolicy{Hosts: []string{"api.github.com", "*.example.com"}}
+
+	cases := map[string]string{
+		"api.github.com":     "api.github.com",
+		"cdn.example.com":    otherHost,
+		"images.example.org": otherHost,
+	}
+
+	for host, want := range cases {
+		if got := policy.metricHost(host); got != want {
+			t.Errorf("metricHost(%q) = %q, want %q", host, got, want)
+		}
+	}
+
+	if got := (FetchPolicy{}).metricHost("api.github.com"); got != otherHost {
+		t.Errorf("expected an open policy to label every host as %q, got %q", otherHost, got)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}.withDefaults()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for retry, ceiling := range map[int]time.Duration{1: 100 * time.Millisecond, 3: 400 * time.Millisecond, 40: time.Second} {
+		for i := 0; i < 50; i++ {
+			if wait, ok := policy.delay(retry, nil, now); !ok || wait <= 0 || wait > ceiling {
+				t.Fatalf("retry %d: expected a wait within (0, %s], got %s", retry, ceiling, wait)
+			}
+		}
+	}
+
+	hinted := func(header http.Header) (time.Duration, bool) {
+		return policy.delay(1, &http.Response{Header: header}, now)
+	}
+
+	if wait, ok := hinted(http.Header{"Retry-After": {"1"}}); !ok || wait != time.Second {
+		t.Fatalf("unexpected Retry-After seconds wait %s %t", wait, ok)
+	}
+
+	if wait, ok := hinted(http.Header{"Retry-After": {now.Add(500 * time.Millisecond).Format(http.TimeFormat)}}); !ok || wait != 0 {
+		// HTTP dates have a one second resolution, so half a second ahead rounds down to now.
+		t.Fatalf("unexpected Retry-After date wait %s %t", wait, ok)
+	}
+
+	reset := strconv.FormatInt(now.Add(800*time.Millisecond).Unix(), 10)
+	if wait, ok := hinted(http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {reset}}); !ok || wait != 0 {
+		t.Fatalf("unexpected rate limit reset wait %s %t", wait, ok)
+	}
+
+	reset = strconv.FormatInt(now.Add(time.Hour).Unix(), 10)
+	if _, ok := hinted(http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {reset}}); ok {
+		t.Fatalf("expected an hour long reset to be refused")
+	}
+
+	if _, ok := hinted(http.Header{"X-Ratelimit-Remaining": {"10"}, "X-Ratelimit-Reset": {reset}}); !ok {
+		t.Fatalf("expected the reset to be ignored while requests remain")
+	}
+}
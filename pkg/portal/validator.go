@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
 
@@ -78,26 +79,114 @@ func (v *Validator) getDefaultError() error {
 }
 
 func (v *Validator) parseError(validateErrs validator.ValidationErrors) {
-	var e error
-
 	for _, current := range validateErrs {
 		field := NewStringable(current.Field()).ToSnakeCase()
 
-		switch strings.ToLower(current.Tag()) {
-		case "required":
-			e = fmt.Errorf("field '%s' cannot be blank", field)
-		case "email":
-			e = fmt.Errorf("field '%s' must be a valid email address", field)
-		case "eth_addr":
-			e = fmt.Errorf("field '%s' must be a valid Ethereum address", field)
-		case "len":
-			e = fmt.Errorf("field '%s' must be exactly %v characters long", field, current.Param())
-		default:
-			e = fmt.Errorf("field '%s': '%v' must satisfy '%s' '%v' criteria", field, current.Value(), current.Tag(), current.Param())
+		v.Errors[field] = describeFieldError(field, current)
+	}
+}
+
+func describeFieldError(field string, current validator.FieldError) string {
+	switch strings.ToLower(current.Tag()) {
+	case "required":
+		return fmt.Sprintf("field '%s' cannot be blank", field)
+	case "email":
+		return fmt.Sprintf("field '%s' must be a valid email address", field)
+	case "eth_addr":
+		return fmt.Sprintf("field '%s' must be a valid Ethereum address", field)
+	case "len":
+		return fmt.Sprintf("field '%s' must be exactly %v characters long", field, current.Param())
+	default:
+		return fmt.Sprintf("field '%s': '%v' must satisfy '%s' '%v' criteria", field, current.Value(), current.Tag(), current.Param())
+	}
+}
+
+// Violation is one broken rule, located by the JSON path of the offending value,
+// e.g. $.data[3].sort.
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Violations lists every broken rule of data, a struct or a pointer to one. Unlike Passes
+// it leaves Errors alone, so it is safe to call from several goroutines.
+func (v *Validator) Violations(data any) ([]Violation, error) {
+	err := v.instance.Struct(data)
+	if err == nil {
+		return nil, nil
+	}
+
+	var validateErrs validator.ValidationErrors
+	if !errors.As(err, &validateErrs) {
+		return nil, fmt.Errorf("%s: %w", v.getDefaultError().Error(), err)
+	}
+
+	root := reflect.TypeOf(data)
+	violations := make([]Violation, 0, len(validateErrs))
+
+	for _, current := range validateErrs {
+		violations = append(violations, Violation{
+			Path:    jsonPath(root, current.StructNamespace()),
+			Message: describeFieldError(NewStringable(current.Field()).ToSnakeCase(), current),
+		})
+	}
+
+	return violations, nil
+}
+
+// jsonPath turns a namespace such as ProjectsResponse.Data[3].Sort into $.data[3].sort by
+// following the json tags from the root type down.
+func jsonPath(root reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")
+	if len(segments) > 0 {
+		segments = segments[1:] // The root type itself.
+	}
+
+	current := root
+	path := "$"
+
+	for _, segment := range segments {
+		name, index, _ := strings.Cut(segment, "[")
+		if index != "" {
+			index = "[" + index
+		}
+
+		for current != nil && current.Kind() == reflect.Pointer {
+			current = current.Elem()
 		}
 
-		v.Errors[field] = e.Error()
+		jsonName := NewStringable(name).ToSnakeCase()
+
+		if current != nil && current.Kind() == reflect.Struct {
+			if field, ok := current.FieldByName(name); ok {
+				if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag != "" && tag != "-" {
+					jsonName = tag
+				}
+
+				current = field.Type
+			} else {
+				current = nil
+			}
+		}
+
+		path += "." + jsonName + index
+
+		// Step through the slice, array or map the index points into.
+		for i := strings.Count(index, "["); i > 0 && current != nil; i-- {
+			for current.Kind() == reflect.Pointer {
+				current = current.Elem()
+			}
+
+			switch current.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				current = current.Elem()
+			default:
+				current = nil
+			}
+		}
 	}
+
+	return path
 }
 
 func (v *Validator) GetErrorsAsJson() string {
@@ -60,3 +60,45 @@ func TestValidator_Rejects(t *testing.T) {
 		t.Fatalf("expected reject")
 	}
 }
+
+type violationsItem struct {
+	Sort *int   `json:"sort" validate:"required,gt=0"`
+	URL  string `json:"url" validate:"required,url"`
+}
+
+type violationsPayload struct {
+	Version string           `json:"version" validate:"required"`
+	Items   []violationsItem `json:"data" validate:"dive"`
+}
+
+func TestValidator_ViolationsUseJSONPaths(t *testing.T) {
+	v := portal.GetDefaultValidator()
+	recorded := len(v.GetErrors())
+
+	one, zero := 1, 0
+	violations, err := v.Violations(&violationsPayload{
+		Items: []violationsItem{
+			{Sort: &one, URL: "https://oullin.io"},
+			{Sort: &zero, URL: "not a url"},
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("violations: %v", err)
+	}
+
+	want := map[string]bool{"$.version": true, "$.data[1].sort": true, "$.data[1].url": true}
+	if len(violations) != len(want) {
+		t.Fatalf("expected %d violations, got %+v", len(want), violations)
+	}
+
+	for _, violation := range violations {
+		if !want[violation.Path] || violation.Message == "" {
+			t.Fatalf("unexpected violation %+v", violation)
+		}
+	}
+
+	if len(v.GetErrors()) != recorded {
+		t.Fatalf("violations must not record errors: %v", v.GetErrors())
+	}
+}
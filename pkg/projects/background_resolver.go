@@ -0,0 +1,120 @@
+package projects
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/oullin/handler/payload"
+)
+
+const (
+	backgroundQueueSize      = 64
+	backgroundResolveTimeout = 30 * time.Second
+	backgroundFailureDelay   = 5 * time.Minute
+)
+
+// PublishedAtLookup returns the published date known for a project without waiting, and
+// whether the project is hosted somewhere dates are resolved from at all. The date stays
+// empty until it is resolved.
+type PublishedAtLookup func(project payload.ProjectsData) (string, bool)
+
+// BackgroundResolver answers published_at lookups from the cache and resolves missing or
+// stale dates on a worker goroutine, so a slow or unreachable code host never holds up, or
+// fails, the request asking for them.
+type BackgroundResolver struct {
+	registry     *Registry
+	cache        *PublishedAtCache
+	queue        chan payload.ProjectsData
+	now          func() time.Time
+	failureDelay time.Duration
+	mu           sync.Mutex
+	pending      map[string]bool
+	failedAt     map[string]time.Time
+}
+
+func NewBackgroundResolver(registry *Registry, cache *PublishedAtCache) *BackgroundResolver {
+	b := &BackgroundResolver{
+		registry:     registry,
+		cache:        cache,
+		queue:        make(chan payload.ProjectsData, backgroundQueueSize),
+		now:          time.Now,
+		failureDelay: backgroundFailureDelay,
+		pending:      make(map[string]bool),
+		failedAt:     make(map[string]time.Time),
+	}
+
+	go b.work()
+
+	return b
+}
+
+// Lookup returns the cached date of a project on a registered host, scheduling a refresh
+// when there is none yet or it has expired. Projects hosted elsewhere are never looked up.
+func (b *BackgroundResolver) Lookup(project payload.ProjectsData) (string, bool) {
+	repo, _, ok := b.registry.Parse(project.URL)
+	if !ok {
+		return "", false
+	}
+
+	key := repo.Key()
+
+	publishedAt, fresh, cached := b.cache.Get(key)
+	if !cached || !fresh {
+		b.schedule(key, project)
+	}
+
+	return publishedAt, true
+}
+
+func (b *BackgroundResolver) schedule(key string, project payload.ProjectsData) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.pending[key] {
+		return
+	}
+
+	// Back off after a failure rather than asking a struggling GitHub on every request.
+	if failedAt, ok := b.failedAt[key]; ok && b.now().Sub(failedAt) < b.failureDelay {
+		return
+	}
+
+	select {
+	case b.queue <- project:
+		b.pending[key] = true
+	default:
+		// The queue is full; a later request schedules it again.
+	}
+}
+
+func (b *BackgroundResolver) work() {
+	for project := range b.queue {
+		repo, provider, _ := b.registry.Parse(project.URL)
+		key := repo.Key()
+
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundResolveTimeout)
+		publishedAt, err := provider.PublishedAt(ctx, repo)
+		cancel()
+
+		if err == nil {
+			err = b.cache.Set(key, publishedAt)
+		}
+
+		b.mu.Lock()
+		delete(b.pending, key)
+
+		if err != nil {
+			b.failedAt[key] = b.now()
+		} else {
+			delete(b.failedAt, key)
+		}
+
+		b.mu.Unlock()
+
+		if err != nil {
+			slog.Warn("projects: could not resolve published_at", "project", project.UUID, "repository", key, "error", err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package projects
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/oullin/handler/payload"
+)
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met in time")
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBackgroundResolverNeverBlocksTheLookup(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int32
+
+	resolve := func(ctx context.Context, repo Repository) (string, error) {
+		calls.Add(1)
+		<-release
+
+		return "2024-01-02T03:04:05Z", nil
+	}
+
+	cache := NewPublishedAtCache(filepath.Join(t.TempDir(), "published_at.json"), time.Hour)
+	resolver := NewBackgroundResolver(stubRegistry(resolve), cache)
+	project := payload.ProjectsData{UUID: "p", URL: "https://github.com/Example/Project"}
+
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if got, ok := resolver.Lookup(project); !ok || got != "" {
+			t.Fatalf("expected nothing while resolving, got %q", got)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("expected the lookups not to wait on the resolver, took %s", elapsed)
+	}
+
+	close(release)
+
+	waitFor(t, func() bool {
+		got, _ := resolver.Lookup(project)
+		return got != ""
+	})
+
+	if got, _ := resolver.Lookup(project); got != "2024-01-02T03:04:05Z" {
+		t.Fatalf("unexpected published_at %q", got)
+	}
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected concurrent lookups to share one resolution, got %d", calls.Load())
+	}
+
+	if _, _, ok := cache.Get("github.com/example/project"); !ok {
+		t.Fatalf("expected the result to be cached under the lower-cased repository")
+	}
+}
+
+func TestBackgroundResolverBacksOffAfterFailures(t *testing.T) {
+	var calls atomic.Int32
+
+	resolve := func(ctx context.Context, repo Repository) (string, error) {
+		calls.Add(1)
+
+		return "", errors.New("github is down")
+	}
+
+	now := time.Date(2026, 3, 18, 0, 0, 0, 0, time.UTC)
+	cache := NewPublishedAtCache(filepath.Join(t.TempDir(), "published_at.json"), time.Hour)
+	resolver := NewBackgroundResolver(stubRegistry(resolve), cache)
+	resolver.mu.Lock()
+	resolver.now = func() time.Time { return now }
+	resolver.mu.Unlock()
+
+	project := payload.ProjectsData{URL: "https://github.com/example/project"}
+
+	resolver.Lookup(project)
+
+	waitFor(t, func() bool {
+		resolver.mu.Lock()
+		defer resolver.mu.Unlock()
+
+		return len(resolver.failedAt) == 1
+	})
+
+	resolver.Lookup(project)
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected no retry during the back-off, got %d calls", calls.Load())
+	}
+
+	resolver.mu.Lock()
+	now = now.Add(backgroundFailureDelay)
+	resolver.mu.Unlock()
+
+	resolver.Lookup(project)
+
+	waitFor(t, func() bool { return calls.Load() == 2 })
+}
+
+func TestBackgroundResolverSkipsProjectsHostedElsewhere(t *testing.T) {
+	resolve := func(ctx context.Context, repo Repository) (string, error) {
+		t.Fatalf("did not expect %s to be resolved", repo.Key())
+
+		return "", nil
+	}
+
+	resolver := NewBackgroundResolver(stubRegistry(resolve), NewPublishedAtCache(filepath.Join(t.TempDir(), "published_at.json"), time.Hour))
+
+	if _, ok := resolver.Lookup(payload.ProjectsData{URL: "https://example.com/example/project"}); ok {
+		t.Fatalf("expected a project hosted elsewhere not to be looked up")
+	}
+}
+
+func TestBackgroundResolverRefreshesStaleDates(t *testing.T) {
+	resolved := make(chan struct{}, 1)
+
+	resolve := func(ctx context.Context, repo Repository) (string, error) {
+		defer func() { resolved <- struct{}{} }()
+
+		return "2020-01-01T00:00:00Z", nil
+	}
+
+	now := time.Date(2026, 3, 18, 0, 0, 0, 0, time.UTC)
+	cache := NewPublishedAtCache(filepath.Join(t.TempDir(), "published_at.json"), time.Hour)
+	cache.now = func() time.Time { return now }
+
+	if err := cache.Set("github.com/example/project", "2019-01-01T00:00:00Z"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	resolver := NewBackgroundResolver(stubRegistry(resolve), cache)
+	project := payload.ProjectsData{URL: "https://github.com/example/project"}
+
+	if got, ok := resolver.Lookup(project); !ok || got != "2019-01-01T00:00:00Z" {
+		t.Fatalf("expected the fresh cached date, got %q", got)
+	}
+
+	select {
+	case <-resolved:
+		t.Fatalf("did not expect a fresh date to be resolved again")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cache.mu.Lock()
+	now = now.Add(2 * time.Hour)
+	cache.mu.Unlock()
+
+	if got, ok := resolver.Lookup(project); !ok || got != "2019-01-01T00:00:00Z" {
+		t.Fatalf("expected the stale date while refreshing, got %q", got)
+	}
+
+	<-resolved
+
+	waitFor(t, func() bool {
+		got, _ := resolver.Lookup(project)
+		return got == "2020-01-01T00:00:00Z"
+	})
+}
@@ -0,0 +1,91 @@
+package projects
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/oullin/handler/payload"
+	"github.com/oullin/pkg/portal"
+)
+
+const bitbucketAPIBaseURL = "https://api.bitbucket.org/2.0"
+
+type bitbucketRepositoryResponse struct {
+	Language  string `json:"language"`
+	CreatedOn string `json:"created_on"`
+	UpdatedOn string `json:"updated_on"`
+}
+
+type bitbucketPageResponse struct {
+	Size int `json:"size"`
+}
+
+// bitbucketProvider dates a repository by the day it was created. Bitbucket has no stars,
+// topics or license detection, so watchers stand in for stars and the single language it
+// knows about makes up the whole breakdown.
+type bitbucketProvider struct {
+	fetcher    *portal.Fetcher
+	apiBaseURL string
+}
+
+func NewBitbucketProvider() Provider {
+	return &bitbucketProvider{fetcher: newAPIFetcher(bitbucketAPIBaseURL), apiBaseURL: bitbucketAPIBaseURL}
+}
+
+func (p *bitbucketProvider) Split(segments []string) (string, string, bool) {
+	return splitOwnerName(segments)
+}
+
+func (p *bitbucketProvider) PublishedAt(ctx context.Context, repo Repository) (string, error) {
+	var repository bitbucketRepositoryResponse
+	if err := fetchJSON(ctx, p.fetcher, p.repositoryURL(repo), bitbucketHeaders(), &repository); err != nil {
+		return "", fmt.Errorf("fetch repository for %s: %w", repo.Key(), err)
+	}
+
+	return normalizeTime(repository.CreatedOn), nil
+}
+
+func (p *bitbucketProvider) Stats(ctx context.Context, repo Repository, record StatsRecord) (StatsRecord, error) {
+	header := bitbucketHeaders()
+
+	var repository bitbucketRepositoryResponse
+	changed, err := revalidate(ctx, p.fetcher, p.repositoryURL(repo), header, record.ETags, &repository)
+	if err != nil {
+		return record, fmt.Errorf("fetch repository stats for %s: %w", repo.Key(), err)
+	}
+
+	if changed {
+		record.Stats.PushedAt = normalizeTime(repository.UpdatedOn)
+		record.Stats.Languages = []payload.ProjectLanguage{}
+
+		if repository.Language != "" {
+			record.Stats.Languages = []payload.ProjectLanguage{{Name: repository.Language, Percent: 100}}
+		}
+	}
+
+	var watchers bitbucketPageResponse
+	if changed, err = revalidate(ctx, p.fetcher, p.repositoryURL(repo)+"/watchers?pagelen=1", header, record.ETags, &watchers); err != nil {
+		return record, fmt.Errorf("fetch watchers for %s: %w", repo.Key(), err)
+	} else if changed {
+		record.Stats.Stars = watchers.Size
+	}
+
+	var forks bitbucketPageResponse
+	if changed, err = revalidate(ctx, p.fetcher, p.repositoryURL(repo)+"/forks?pagelen=1", header, record.ETags, &forks); err != nil {
+		return record, fmt.Errorf("fetch forks for %s: %w", repo.Key(), err)
+	} else if changed {
+		record.Stats.Forks = forks.Size
+	}
+
+	return record, nil
+}
+
+func (p *bitbucketProvider) repositoryURL(repo Repository) string {
+	return apiURL(p.apiBaseURL, "/repositories/%s/%s", url.PathEscape(repo.Owner), url.PathEscape(repo.Name))
+}
+
+func bitbucketHeaders() http.Header {
+	return http.Header{"Accept": {"application/json"}}
+}
@@ -0,0 +1,46 @@
+package projects
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBitbucketProviderStatsAndPublishedAt(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repositories/workspace/repo":
+			_, _ = w.Write([]byte(`{"language":"go","created_on":"2019-04-05T06:07:08.123456+00:00","updated_on":"2026-03-01T00:00:00+00:00"}`))
+		case "/repositories/workspace/repo/watchers":
+			_, _ = w.Write([]byte(`{"size":4,"values":[]}`))
+		case "/repositories/workspace/repo/forks":
+			_, _ = w.Write([]byte(`{"size":3,"values":[]}`))
+		default:
+			t.Fatalf("unexpected request path %q", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	provider := &bitbucketProvider{fetcher: testFetcher(), apiBaseURL: srv.URL}
+	repo := Repository{Host: "bitbucket.org", Owner: "workspace", Name: "repo"}
+
+	publishedAt, err := provider.PublishedAt(context.Background(), repo)
+	if err != nil || publishedAt != "2019-04-05T06:07:08Z" {
+		t.Fatalf("unexpected published_at %q %v", publishedAt, err)
+	}
+
+	record, err := provider.Stats(context.Background(), repo, StatsRecord{ETags: map[string]string{}})
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+
+	stats := record.Stats
+	if stats.Stars != 4 || stats.Forks != 3 || stats.PushedAt != "2026-03-01T00:00:00Z" {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	if len(stats.Languages) != 1 || stats.Languages[0].Name != "go" || stats.Languages[0].Percent != 100 {
+		t.Fatalf("unexpected languages %+v", stats.Languages)
+	}
+}
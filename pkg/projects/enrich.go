@@ -10,6 +10,12 @@ import (
 const PageSize = 8
 
 func EnrichResponse(response *payload.ProjectsResponse) error {
+	return EnrichResponseWith(response, nil)
+}
+
+// ValidateResponse checks what does not depend on lookups: every project needs a positive
+// sort value. It also trims the fixture dates.
+func ValidateResponse(response *payload.ProjectsResponse) error {
 	if response == nil {
 		return nil
 	}
@@ -24,6 +30,26 @@ func EnrichResponse(response *payload.ProjectsResponse) error {
 		if *response.Data[i].Sort <= 0 {
 			return fmt.Errorf("project %q has invalid sort value %d: must be positive", response.Data[i].UUID, *response.Data[i].Sort)
 		}
+	}
+
+	return nil
+}
+
+// EnrichResponseWith fills the missing published_at of hosted projects from the lookup.
+// Those still unknown are left empty, and sorted last among equals, until it resolves them.
+func EnrichResponseWith(response *payload.ProjectsResponse, lookup PublishedAtLookup) error {
+	if err := ValidateResponse(response); err != nil || response == nil {
+		return err
+	}
+
+	for i := range response.Data {
+		if response.Data[i].PublishedAt == "" && lookup != nil {
+			if publishedAt, hosted := lookup(response.Data[i]); hosted {
+				response.Data[i].PublishedAt = publishedAt
+
+				continue
+			}
+		}
 
 		if response.Data[i].PublishedAt == "" {
 			return fmt.Errorf("project %q has empty published_at", response.Data[i].UUID)
@@ -138,3 +138,48 @@ func TestEnrichResponse_RejectsInvalidData(t *testing.T) {
 		})
 	}
 }
+
+func TestEnrichResponseWith_FillsHostedDatesFromTheLookup(t *testing.T) {
+	lookup := func(project payload.ProjectsData) (string, bool) {
+		switch {
+		case project.UUID == "known":
+			return "2024-01-02T03:04:05Z", true
+		case strings.HasPrefix(project.URL, "https://github.com/"):
+			return "", true
+		default:
+			return "", false
+		}
+	}
+
+	response := &payload.ProjectsResponse{
+		Data: []payload.ProjectsData{
+			{UUID: "pending", Sort: intPtr(1), URL: "https://github.com/example/pending"},
+			{UUID: "known", Sort: intPtr(1), URL: "https://github.com/example/known"},
+			{UUID: "dated", Sort: intPtr(2), URL: "https://github.com/example/dated", PublishedAt: "2025-01-01"},
+		},
+	}
+
+	if err := EnrichResponseWith(response, lookup); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.Data[0].UUID != "known" || response.Data[0].PublishedAt != "2024-01-02T03:04:05Z" {
+		t.Fatalf("expected the resolved project first, got %+v", response.Data[0])
+	}
+
+	if response.Data[1].UUID != "pending" || response.Data[1].PublishedAt != "" {
+		t.Fatalf("expected the pending project to keep an empty date, got %+v", response.Data[1])
+	}
+
+	if response.Data[2].PublishedAt != "2025-01-01" {
+		t.Fatalf("expected the fixture date to win, got %+v", response.Data[2])
+	}
+
+	elsewhere := &payload.ProjectsResponse{
+		Data: []payload.ProjectsData{{UUID: "elsewhere", Sort: intPtr(1), URL: "https://example.com/project"}},
+	}
+
+	if err := EnrichResponseWith(elsewhere, lookup); err == nil || !strings.Contains(err.Error(), "empty published_at") {
+		t.Fatalf("expected projects hosted elsewhere to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package projects
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/oullin/pkg/portal"
+)
+
+type giteaRepositoryResponse struct {
+	StarsCount int      `json:"stars_count"`
+	ForksCount int      `json:"forks_count"`
+	Topics     []string `json:"topics"`
+	CreatedAt  string   `json:"created_at"`
+	UpdatedAt  string   `json:"updated_at"`
+	Licenses   []string `json:"licenses"`
+}
+
+// giteaProvider serves Codeberg, gitea.com and self-hosted Gitea or Forgejo instances.
+// Gitea dates a repository by the day it was created.
+type giteaProvider struct {
+	fetcher    *portal.Fetcher
+	apiBaseURL string
+}
+
+// NewGiteaProvider talks to the v1 API at the base URL, e.g. https://codeberg.org/api/v1.
+func NewGiteaProvider(apiBaseURL string) Provider {
+	return &giteaProvider{fetcher: newAPIFetcher(apiBaseURL), apiBaseURL: apiBaseURL}
+}
+
+func (p *giteaProvider) Split(segments []string) (string, string, bool) {
+	return splitOwnerName(segments)
+}
+
+func (p *giteaProvider) PublishedAt(ctx context.Context, repo Repository) (string, error) {
+	var repository giteaRepositoryResponse
+	if err := fetchJSON(ctx, p.fetcher, p.repositoryURL(repo), giteaHeaders(), &repository); err != nil {
+		return "", fmt.Errorf("fetch repository for %s: %w", repo.Key(), err)
+	}
+
+	return normalizeTime(repository.CreatedAt), nil
+}
+
+func (p *giteaProvider) Stats(ctx context.Context, repo Repository, record StatsRecord) (StatsRecord, error) {
+	header := giteaHeaders()
+
+	var repository giteaRepositoryResponse
+	changed, err := revalidate(ctx, p.fetcher, p.repositoryURL(repo), header, record.ETags, &repository)
+	if err != nil {
+		return record, fmt.Errorf("fetch repository stats for %s: %w", repo.Key(), err)
+	}
+
+	if changed {
+		record.Stats.Stars = repository.StarsCount
+		record.Stats.Forks = repository.ForksCount
+		record.Stats.Topics = append([]string{}, repository.Topics...)
+		record.Stats.PushedAt = normalizeTime(repository.UpdatedAt)
+		record.Stats.License = ""
+
+		// Older Gitea versions do not detect licenses at all.
+		if len(repository.Licenses) > 0 {
+			record.Stats.License = repository.Licenses[0]
+		}
+	}
+
+	var languages map[string]int64
+	if changed, err = revalidate(ctx, p.fetcher, p.repositoryURL(repo)+"/languages", header, record.ETags, &languages); err != nil {
+		return record, fmt.Errorf("fetch languages for %s: %w", repo.Key(), err)
+	}
+
+	if changed {
+		record.Stats.Languages = languageBreakdown(languages)
+	}
+
+	return record, nil
+}
+
+func (p *giteaProvider) repositoryURL(repo Repository) string {
+	return apiURL(p.apiBaseURL, "/repos/%s/%s", url.PathEscape(repo.Owner), url.PathEscape(repo.Name))
+}
+
+func giteaHeaders() http.Header {
+	return http.Header{"Accept": {"application/json"}}
+}
@@ -0,0 +1,45 @@
+package projects
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGiteaProviderStatsAndPublishedAt(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo":
+			_, _ = w.Write([]byte(`{"stars_count":9,"forks_count":2,"topics":["forge"],"created_at":"2022-01-02T03:04:05+01:00",` +
+				`"updated_at":"2026-01-01T00:00:00Z","licenses":["GPL-3.0-or-later"]}`))
+		case "/repos/owner/repo/languages":
+			_, _ = w.Write([]byte(`{"Go":300,"CSS":100}`))
+		default:
+			t.Fatalf("unexpected request path %q", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	provider := &giteaProvider{fetcher: testFetcher(), apiBaseURL: srv.URL}
+	repo := Repository{Host: "codeberg.org", Owner: "owner", Name: "repo"}
+
+	publishedAt, err := provider.PublishedAt(context.Background(), repo)
+	if err != nil || publishedAt != "2022-01-02T02:04:05Z" {
+		t.Fatalf("unexpected published_at %q %v", publishedAt, err)
+	}
+
+	record, err := provider.Stats(context.Background(), repo, StatsRecord{ETags: map[string]string{}})
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+
+	stats := record.Stats
+	if stats.Stars != 9 || stats.Forks != 2 || stats.License != "GPL-3.0-or-later" || len(stats.Topics) != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	if len(stats.Languages) != 2 || stats.Languages[0].Name != "Go" || stats.Languages[0].Percent != 75 {
+		t.Fatalf("unexpected languages %+v", stats.Languages)
+	}
+}
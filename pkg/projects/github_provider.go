@@ -0,0 +1,81 @@
+package projects
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/oullin/pkg/portal"
+)
+
+type githubStatsResponse struct {
+	StargazersCount int      `json:"stargazers_count"`
+	ForksCount      int      `json:"forks_count"`
+	Topics          []string `json:"topics"`
+	PushedAt        string   `json:"pushed_at"`
+	License         *struct {
+		SPDXID string `json:"spdx_id"`
+	} `json:"license"`
+}
+
+// githubProvider dates a repository by its oldest commit on the default branch.
+type githubProvider struct {
+	dates      *githubPublishedAtResolver
+	fetcher    *portal.Fetcher
+	apiBaseURL string
+}
+
+func NewGitHubProvider() Provider {
+	return &githubProvider{
+		dates:      newGitHubPublishedAtResolver(),
+		fetcher:    newAPIFetcher(githubAPIBaseURL),
+		apiBaseURL: githubAPIBaseURL,
+	}
+}
+
+func (p *githubProvider) Split(segments []string) (string, string, bool) {
+	return splitOwnerName(segments)
+}
+
+func (p *githubProvider) PublishedAt(ctx context.Context, repo Repository) (string, error) {
+	return p.dates.resolve(ctx, repo)
+}
+
+// Stats asks for the repository and its languages separately, each with its own ETag.
+func (p *githubProvider) Stats(ctx context.Context, repo Repository, record StatsRecord) (StatsRecord, error) {
+	header := http.Header{}
+	setGitHubHeaders(header)
+
+	base := apiURL(p.apiBaseURL, "/repos/%s/%s", url.PathEscape(repo.Owner), url.PathEscape(repo.Name))
+
+	var repository githubStatsResponse
+	changed, err := revalidate(ctx, p.fetcher, base, header, record.ETags, &repository)
+	if err != nil {
+		return record, fmt.Errorf("fetch repository stats for %s: %w", repo.Key(), err)
+	}
+
+	if changed {
+		record.Stats.Stars = repository.StargazersCount
+		record.Stats.Forks = repository.ForksCount
+		record.Stats.Topics = append([]string{}, repository.Topics...)
+		record.Stats.PushedAt = normalizeTime(repository.PushedAt)
+		record.Stats.License = ""
+
+		// GitHub reports licenses it cannot identify as NOASSERTION.
+		if repository.License != nil && repository.License.SPDXID != "NOASSERTION" {
+			record.Stats.License = repository.License.SPDXID
+		}
+	}
+
+	var languages map[string]int64
+	if changed, err = revalidate(ctx, p.fetcher, base+"/languages", header, record.ETags, &languages); err != nil {
+		return record, fmt.Errorf("fetch languages for %s: %w", repo.Key(), err)
+	}
+
+	if changed {
+		record.Stats.Languages = languageBreakdown(languages)
+	}
+
+	return record, nil
+}
@@ -48,16 +48,15 @@ type githubRepositoryResponse struct {
 }
 
 func NewGitHubPublishedAtResolver() PublishedAtResolver {
-	client := portal.NewDefaultClient(nil)
+	return newGitHubPublishedAtResolver().Resolve
+}
+
+func newGitHubPublishedAtResolver() *githubPublishedAtResolver {
+	client := portal.NewDefaultClient(newAPIFetcher(githubAPIBaseURL))
 	client.AbortOnNone2xx = true
 
 	client.OnHeaders = func(req *http.Request) {
-		req.Header.Set("Accept", "application/vnd.github+json")
-		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
-
-		if token := strings.TrimSpace(os.Getenv("GITHUB_TOKEN")); token != "" {
-			req.Header.Set("Authorization", "Bearer "+token)
-		}
+		setGitHubHeaders(req.Header)
 	}
 
 	resolver := &githubPublishedAtResolver{
@@ -68,7 +67,16 @@ func NewGitHubPublishedAtResolver() PublishedAtResolver {
 		apiBaseURL: githubAPIBaseURL,
 	}
 
-	return resolver.Resolve
+	return resolver
+}
+
+func setGitHubHeaders(header http.Header) {
+	header.Set("Accept", "application/vnd.github+json")
+	header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	if token := strings.TrimSpace(os.Getenv("GITHUB_TOKEN")); token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
 }
 
 func (r *githubPublishedAtResolver) Resolve(ctx context.Context, project payload.ProjectsData) (string, error) {
@@ -78,6 +86,10 @@ func (r *githubPublishedAtResolver) Resolve(ctx context.Context, project payload
 		return "", nil
 	}
 
+	return r.resolve(ctx, repo)
+}
+
+func (r *githubPublishedAtResolver) resolve(ctx context.Context, repo Repository) (string, error) {
 	cacheKey := repo.Owner + "/" + repo.Name
 	if cached, ok := r.fromCache(cacheKey); ok {
 		return cached, nil
@@ -144,7 +156,7 @@ func (r *githubPublishedAtResolver) Resolve(ctx context.Context, project payload
 	return publishedAt, nil
 }
 
-func (r *githubPublishedAtResolver) repositoryURL(repo GitHubRepository) string {
+func (r *githubPublishedAtResolver) repositoryURL(repo Repository) string {
 	return fmt.Sprintf(
 		"%s/repos/%s/%s",
 		strings.TrimSuffix(r.apiBaseURL, "/"),
@@ -153,7 +165,7 @@ func (r *githubPublishedAtResolver) repositoryURL(repo GitHubRepository) string
 	)
 }
 
-func (r *githubPublishedAtResolver) commitsURL(repo GitHubRepository, branch string) string {
+func (r *githubPublishedAtResolver) commitsURL(repo Repository, branch string) string {
 	values := url.Values{}
 	values.Set("sha", branch)
 	values.Set("per_page", "1")
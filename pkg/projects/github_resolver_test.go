@@ -44,7 +44,7 @@ func TestGitHubPublishedAtResolverUsesOldestDefaultBranchCommit(t *testing.T) {
 	defer srv.Close()
 
 	resolver := &githubPublishedAtResolver{
-		client:     portal.NewDefaultClient(nil),
+		client:     portal.NewDefaultClient(portal.NewFetcher(portal.FetchPolicy{AllowPrivate: true})),
 		cache:      make(map[string]cacheEntry),
 		now:        func() time.Time { return time.Date(2026, 3, 18, 0, 0, 0, 0, time.UTC) },
 		ttl:        cacheTTL,
@@ -85,7 +85,7 @@ func TestGitHubPublishedAtResolverHandlesSingleCommitRepository(t *testing.T) {
 	defer srv.Close()
 
 	resolver := &githubPublishedAtResolver{
-		client:     portal.NewDefaultClient(nil),
+		client:     portal.NewDefaultClient(portal.NewFetcher(portal.FetchPolicy{AllowPrivate: true})),
 		cache:      make(map[string]cacheEntry),
 		now:        time.Now,
 		ttl:        cacheTTL,
@@ -122,7 +122,7 @@ func TestGitHubPublishedAtResolverUsesCache(t *testing.T) {
 
 	now := time.Date(2026, 3, 18, 0, 0, 0, 0, time.UTC)
 	resolver := &githubPublishedAtResolver{
-		client:     portal.NewDefaultClient(nil),
+		client:     portal.NewDefaultClient(portal.NewFetcher(portal.FetchPolicy{AllowPrivate: true})),
 		cache:      make(map[string]cacheEntry),
 		now:        func() time.Time { return now },
 		ttl:        cacheTTL,
@@ -152,7 +152,7 @@ func TestGitHubPublishedAtResolverUsesCache(t *testing.T) {
 
 func TestGitHubPublishedAtResolverSkipsNonGitHubURLs(t *testing.T) {
 	resolver := &githubPublishedAtResolver{
-		client:     portal.NewDefaultClient(nil),
+		client:     portal.NewDefaultClient(portal.NewFetcher(portal.FetchPolicy{AllowPrivate: true})),
 		cache:      make(map[string]cacheEntry),
 		now:        time.Now,
 		ttl:        cacheTTL,
@@ -1,51 +1,18 @@
 package projects
 
-import (
-	"net/url"
-	"strings"
-
-	"github.com/oullin/pkg/portal"
-)
-
-type GitHubRepository struct {
-	Owner string
-	Name  string
-}
-
-func ParseGitHubRepository(rawURL string) (GitHubRepository, bool) {
-	trimmed := strings.TrimSpace(rawURL)
-	if trimmed == "" {
-		return GitHubRepository{}, false
+func ParseGitHubRepository(rawURL string) (Repository, bool) {
+	host, segments, ok := splitRepositoryURL(rawURL)
+	if !ok || host != "github.com" {
+		return Repository{}, false
 	}
 
-	if !strings.Contains(trimmed, "://") {
-		trimmed = "https://" + trimmed
-	}
-
-	parsed, err := url.Parse(trimmed)
-	if err != nil {
-		return GitHubRepository{}, false
-	}
-
-	switch strings.ToLower(parsed.Hostname()) {
-	case "github.com", "www.github.com":
-	default:
-		return GitHubRepository{}, false
-	}
-
-	parts := portal.FilterNonEmpty(strings.Split(parsed.Path, "/"))
-	if len(parts) < 2 {
-		return GitHubRepository{}, false
-	}
-
-	name := strings.TrimSpace(strings.TrimSuffix(parts[1], ".git"))
-	owner := strings.TrimSpace(parts[0])
-
-	if owner == "" || name == "" {
-		return GitHubRepository{}, false
+	owner, name, ok := splitOwnerName(segments)
+	if !ok {
+		return Repository{}, false
 	}
 
-	return GitHubRepository{
+	return Repository{
+		Host:  host,
 		Owner: owner,
 		Name:  name,
 	}, true
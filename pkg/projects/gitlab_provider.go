@@ -0,0 +1,134 @@
+package projects
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/oullin/pkg/portal"
+)
+
+// GitLab reports licenses by their lower-cased SPDX id; these are the ids whose casing
+// cannot be guessed from that.
+var spdxCasing = map[string]string{
+	"agpl-3.0":     "AGPL-3.0",
+	"apache-2.0":   "Apache-2.0",
+	"bsd-2-clause": "BSD-2-Clause",
+	"bsd-3-clause": "BSD-3-Clause",
+	"bsl-1.0":      "BSL-1.0",
+	"cc0-1.0":      "CC0-1.0",
+	"epl-2.0":      "EPL-2.0",
+	"gpl-2.0":      "GPL-2.0",
+	"gpl-3.0":      "GPL-3.0",
+	"isc":          "ISC",
+	"lgpl-2.1":     "LGPL-2.1",
+	"lgpl-3.0":     "LGPL-3.0",
+	"mit":          "MIT",
+	"mpl-2.0":      "MPL-2.0",
+	"unlicense":    "Unlicense",
+}
+
+type gitlabProjectResponse struct {
+	StarCount      int      `json:"star_count"`
+	ForksCount     int      `json:"forks_count"`
+	Topics         []string `json:"topics"`
+	CreatedAt      string   `json:"created_at"`
+	LastActivityAt string   `json:"last_activity_at"`
+	License        *struct {
+		Key string `json:"key"`
+	} `json:"license"`
+}
+
+// gitlabProvider serves gitlab.com or a self-hosted instance. GitLab dates a repository by
+// the day the project was created and only reports languages as shares.
+type gitlabProvider struct {
+	fetcher    *portal.Fetcher
+	apiBaseURL string
+}
+
+// NewGitLabProvider talks to the v4 API at the base URL, e.g. https://gitlab.com/api/v4.
+func NewGitLabProvider(apiBaseURL string) Provider {
+	return &gitlabProvider{fetcher: newAPIFetcher(apiBaseURL), apiBaseURL: apiBaseURL}
+}
+
+// Split allows nested groups: everything up to the "-" that starts GitLab's own pages,
+// e.g. /group/subgroup/project/-/tree/main, is the project path.
+func (p *gitlabProvider) Split(segments []string) (string, string, bool) {
+	for i, segment := range segments {
+		if segment == "-" {
+			segments = segments[:i]
+
+			break
+		}
+	}
+
+	if len(segments) < 2 {
+		return "", "", false
+	}
+
+	owner := strings.Join(segments[:len(segments)-1], "/")
+	name := strings.TrimSuffix(segments[len(segments)-1], ".git")
+
+	return owner, name, name != ""
+}
+
+func (p *gitlabProvider) PublishedAt(ctx context.Context, repo Repository) (string, error) {
+	var project gitlabProjectResponse
+	if err := fetchJSON(ctx, p.fetcher, p.projectURL(repo), gitlabHeaders(), &project); err != nil {
+		return "", fmt.Errorf("fetch project for %s: %w", repo.Key(), err)
+	}
+
+	return normalizeTime(project.CreatedAt), nil
+}
+
+func (p *gitlabProvider) Stats(ctx context.Context, repo Repository, record StatsRecord) (StatsRecord, error) {
+	header := gitlabHeaders()
+
+	var project gitlabProjectResponse
+	changed, err := revalidate(ctx, p.fetcher, p.projectURL(repo)+"?license=true", header, record.ETags, &project)
+	if err != nil {
+		return record, fmt.Errorf("fetch project stats for %s: %w", repo.Key(), err)
+	}
+
+	if changed {
+		record.Stats.Stars = project.StarCount
+		record.Stats.Forks = project.ForksCount
+		record.Stats.Topics = append([]string{}, project.Topics...)
+		record.Stats.PushedAt = normalizeTime(project.LastActivityAt)
+		record.Stats.License = ""
+
+		if project.License != nil && project.License.Key != "" && project.License.Key != "other" {
+			record.Stats.License = spdxID(project.License.Key)
+		}
+	}
+
+	var languages map[string]float64
+	if changed, err = revalidate(ctx, p.fetcher, p.projectURL(repo)+"/languages", header, record.ETags, &languages); err != nil {
+		return record, fmt.Errorf("fetch languages for %s: %w", repo.Key(), err)
+	}
+
+	if changed {
+		record.Stats.Languages = languageShares(languages)
+	}
+
+	return record, nil
+}
+
+// projectURL addresses the project by its URL-encoded path, groups included.
+func (p *gitlabProvider) projectURL(repo Repository) string {
+	return apiURL(p.apiBaseURL, "/projects/%s", url.PathEscape(repo.Owner+"/"+repo.Name))
+}
+
+func gitlabHeaders() http.Header {
+	return http.Header{"Accept": {"application/json"}}
+}
+
+func spdxID(key string) string {
+	if id, ok := spdxCasing[strings.ToLower(key)]; ok {
+		return id
+	}
+
+	return strings.ToUpper(key)
+}
@@ -0,0 +1,61 @@
+package projects
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/oullin/handler/payload"
+)
+
+func TestGitLabProviderStatsAndPublishedAt(t *testing.T) {
+	var notModified int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/projects/group%2Fsub%2Fproject":
+			if r.Header.Get("If-None-Match") == `"p1"` {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+
+				return
+			}
+
+			w.Header().Set("ETag", `"p1"`)
+			_, _ = w.Write([]byte(`{"star_count":5,"forks_count":1,"topics":["cli"],"created_at":"2021-05-06T07:08:09.000Z",` +
+				`"last_activity_at":"2026-02-01T00:00:00.000Z","license":{"key":"apache-2.0"}}`))
+		case "/projects/group%2Fsub%2Fproject/languages":
+			_, _ = w.Write([]byte(`{"Go":66.666,"Makefile":33.334}`))
+		default:
+			t.Fatalf("unexpected request path %q", r.URL.EscapedPath())
+		}
+	}))
+	defer srv.Close()
+
+	provider := &gitlabProvider{fetcher: testFetcher(), apiBaseURL: srv.URL}
+	repo := Repository{Host: "gitlab.com", Owner: "group/sub", Name: "project"}
+
+	publishedAt, err := provider.PublishedAt(context.Background(), repo)
+	if err != nil || publishedAt != "2021-05-06T07:08:09Z" {
+		t.Fatalf("unexpected published_at %q %v", publishedAt, err)
+	}
+
+	record, err := provider.Stats(context.Background(), repo, StatsRecord{ETags: map[string]string{}})
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+
+	stats := record.Stats
+	if stats.Stars != 5 || stats.Forks != 1 || stats.License != "Apache-2.0" || stats.PushedAt != "2026-02-01T00:00:00Z" {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	if len(stats.Languages) != 2 || stats.Languages[0] != (payload.ProjectLanguage{Name: "Go", Percent: 66.7}) {
+		t.Fatalf("unexpected languages %+v", stats.Languages)
+	}
+
+	if record, err = provider.Stats(context.Background(), repo, record); err != nil || notModified != 1 || record.Stats.Stars != 5 {
+		t.Fatalf("expected the project to be revalidated, got %+v %v after %d 304s", record.Stats, err, notModified)
+	}
+}
@@ -0,0 +1,193 @@
+package projects
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/oullin/pkg/portal"
+)
+
+// Repository is a repository on one of the code hosts a Registry knows about.
+type Repository struct {
+	Host  string
+	Owner string // GitLab owners may span several groups, e.g. "group/subgroup".
+	Name  string
+}
+
+// Key identifies the repository in caches and stores: its lower-cased host/owner/name.
+func (r Repository) Key() string {
+	return strings.ToLower(r.Host + "/" + r.Owner + "/" + r.Name)
+}
+
+// Provider talks to the API of a code host.
+type Provider interface {
+	// Split picks the owner and name out of the path segments of a repository URL.
+	Split(segments []string) (owner, name string, ok bool)
+
+	// PublishedAt returns the date the repository started, empty when it cannot tell.
+	PublishedAt(ctx context.Context, repo Repository) (string, error)
+
+	// Stats revalidates the previous record with conditional requests and returns it
+	// updated, keeping whatever the host reports as unchanged.
+	Stats(ctx context.Context, repo Repository, previous StatsRecord) (StatsRecord, error)
+}
+
+// Registry maps code hosts to their providers. Hosts are registered up front; it is not
+// safe to register more once lookups start.
+type Registry struct {
+	providers map[string]Provider
+}
+
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[string]Provider)}
+}
+
+// NewDefaultRegistry knows GitHub, GitLab, Codeberg, Gitea and Bitbucket.
+func NewDefaultRegistry() *Registry {
+	registry := NewRegistry()
+
+	registry.Register("github.com", NewGitHubProvider())
+	registry.Register("gitlab.com", NewGitLabProvider("https://gitlab.com/api/v4"))
+	registry.Register("codeberg.org", NewGiteaProvider("https://codeberg.org/api/v1"))
+	registry.Register("gitea.com", NewGiteaProvider("https://gitea.com/api/v1"))
+	registry.Register("bitbucket.org", NewBitbucketProvider())
+
+	return registry
+}
+
+// Register serves the host, and its www. alias, with the provider. Self-hosted GitLab or
+// Gitea instances are registered the same way.
+func (r *Registry) Register(host string, provider Provider) {
+	r.providers[normalizeHost(host)] = provider
+}
+
+// Parse finds the repository a project URL points at, sub-paths included.
+func (r *Registry) Parse(rawURL string) (Repository, Provider, bool) {
+	host, segments, ok := splitRepositoryURL(rawURL)
+	if !ok {
+		return Repository{}, nil, false
+	}
+
+	provider, ok := r.providers[host]
+	if !ok {
+		return Repository{}, nil, false
+	}
+
+	owner, name, ok := provider.Split(segments)
+	if !ok {
+		return Repository{}, nil, false
+	}
+
+	return Repository{Host: host, Owner: owner, Name: name}, provider, true
+}
+
+// ParseKey turns a Repository.Key back into the repository and its provider.
+func (r *Registry) ParseKey(key string) (Repository, Provider, bool) {
+	return r.Parse("https://" + key)
+}
+
+func splitRepositoryURL(rawURL string) (string, []string, bool) {
+	trimmed := strings.TrimSpace(rawURL)
+	if trimmed == "" {
+		return "", nil, false
+	}
+
+	if !strings.Contains(trimmed, "://") {
+		trimmed = "https://" + trimmed
+	}
+
+	parsed, err := url.Parse(trimmed)
+	if err != nil {
+		return "", nil, false
+	}
+
+	return normalizeHost(parsed.Hostname()), portal.FilterNonEmpty(strings.Split(parsed.Path, "/")), true
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(host)), "www.")
+}
+
+// splitOwnerName is how most hosts lay out their URLs: /owner/name/anything/else.
+func splitOwnerName(segments []string) (string, string, bool) {
+	if len(segments) < 2 {
+		return "", "", false
+	}
+
+	owner := strings.TrimSpace(segments[0])
+	name := strings.TrimSpace(strings.TrimSuffix(segments[1], ".git"))
+
+	return owner, name, owner != "" && name != ""
+}
+
+// newAPIFetcher only reaches the API host: pagination links come back from the API too,
+// so nothing else is ever needed.
+func newAPIFetcher(apiBaseURL string) *portal.Fetcher {
+	host := apiBaseURL
+	if parsed, err := url.Parse(apiBaseURL); err == nil {
+		host = parsed.Hostname()
+	}
+
+	return portal.NewFetcher(portal.FetchPolicy{
+		Schemes: []string{"https"},
+		Hosts:   []string{host},
+	})
+}
+
+// revalidate decodes target into `into` unless the host answers 304 to the ETag stored
+// for it, in which case `into` is left alone and false is returned. The ETag of a fresh
+// response replaces the stored one.
+func revalidate(ctx context.Context, fetcher *portal.Fetcher, target string, header http.Header, etags map[string]string, into any) (bool, error) {
+	header = header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	if etag := etags[target]; etag != "" {
+		header.Set("If-None-Match", etag)
+	}
+
+	resp, err := fetcher.Get(ctx, target, header)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return false, nil
+	case http.StatusOK:
+	default:
+		_, _ = io.Copy(io.Discard, resp.Body)
+
+		return false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(into); err != nil {
+		return false, fmt.Errorf("decode: %w", err)
+	}
+
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		etags[target] = etag
+	} else {
+		delete(etags, target)
+	}
+
+	return true, nil
+}
+
+func apiURL(apiBaseURL string, format string, args ...any) string {
+	return strings.TrimSuffix(apiBaseURL, "/") + fmt.Sprintf(format, args...)
+}
+
+// fetchJSON is a plain GET decoded into `into`.
+func fetchJSON(ctx context.Context, fetcher *portal.Fetcher, target string, header http.Header, into any) error {
+	_, err := revalidate(ctx, fetcher, target, header, make(map[string]string), into)
+
+	return err
+}
@@ -0,0 +1,71 @@
+package projects
+
+import (
+	"context"
+	"testing"
+)
+
+type stubProvider struct {
+	publishedAt func(ctx context.Context, repo Repository) (string, error)
+}
+
+func (p stubProvider) Split(segments []string) (string, string, bool) {
+	return splitOwnerName(segments)
+}
+
+func (p stubProvider) PublishedAt(ctx context.Context, repo Repository) (string, error) {
+	return p.publishedAt(ctx, repo)
+}
+
+func (p stubProvider) Stats(ctx context.Context, repo Repository, record StatsRecord) (StatsRecord, error) {
+	return record, nil
+}
+
+// stubRegistry serves github.com with the resolve function.
+func stubRegistry(resolve func(ctx context.Context, repo Repository) (string, error)) *Registry {
+	registry := NewRegistry()
+	registry.Register("github.com", stubProvider{publishedAt: resolve})
+
+	return registry
+}
+
+func TestRegistryParse(t *testing.T) {
+	registry := NewDefaultRegistry()
+
+	tests := []struct {
+		rawURL string
+		key    string
+	}{
+		{rawURL: "https://github.com/oullin/api/pulls", key: "github.com/oullin/api"},
+		{rawURL: "https://www.GitHub.com/Oullin/API.git", key: "github.com/oullin/api"},
+		{rawURL: "https://gitlab.com/group/subgroup/project/-/tree/main", key: "gitlab.com/group/subgroup/project"},
+		{rawURL: "codeberg.org/forgejo/forgejo/src/branch/forgejo", key: "codeberg.org/forgejo/forgejo"},
+		{rawURL: "https://gitea.com/gitea/tea", key: "gitea.com/gitea/tea"},
+		{rawURL: "https://bitbucket.org/workspace/repo/src/main/", key: "bitbucket.org/workspace/repo"},
+		{rawURL: "https://github.com/oullin"},
+		{rawURL: "https://gitlab.com/group/-/issues"},
+		{rawURL: "https://example.com/owner/repo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rawURL, func(t *testing.T) {
+			repo, provider, ok := registry.Parse(tt.rawURL)
+
+			if tt.key == "" {
+				if ok {
+					t.Fatalf("expected no repository, got %+v", repo)
+				}
+
+				return
+			}
+
+			if !ok || provider == nil || repo.Key() != tt.key {
+				t.Fatalf("expected %s, got %+v %t", tt.key, repo, ok)
+			}
+
+			if again, _, ok := registry.ParseKey(repo.Key()); !ok || again.Key() != tt.key {
+				t.Fatalf("expected the key to parse back, got %+v %t", again, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package projects
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// PublishedAtCacheTTL is how long a resolved date is trusted before it is resolved again.
+// The date of the first commit of a repository hardly ever moves, so a week is plenty.
+const PublishedAtCacheTTL = 7 * 24 * time.Hour
+
+type publishedAtRecord struct {
+	PublishedAt string    `json:"published_at"`
+	ResolvedAt  time.Time `json:"resolved_at"`
+}
+
+// PublishedAtCache keeps resolved dates in a JSON file, so a deploy starts with everything
+// the previous one already asked GitHub for. Expired entries are still handed out, flagged
+// as stale, since an old date beats no date while a fresh one is being resolved.
+type PublishedAtCache struct {
+	path    string
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	records map[string]publishedAtRecord
+}
+
+// NewPublishedAtCache loads the cache file, if any. A missing or unreadable file only
+// means starting cold.
+func NewPublishedAtCache(path string, ttl time.Duration) *PublishedAtCache {
+	cache := &PublishedAtCache{
+		path:    path,
+		ttl:     ttl,
+		now:     time.Now,
+		records: make(map[string]publishedAtRecord),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("projects: could not read the published_at cache", "path", path, "error", err)
+		}
+
+		return cache
+	}
+
+	if err = json.Unmarshal(data, &cache.records); err != nil {
+		slog.Warn("projects: ignoring a corrupt published_at cache", "path", path, "error", err)
+
+		cache.records = make(map[string]publishedAtRecord)
+	}
+
+	return cache
+}
+
+// Get returns the cached date for the key and whether it is still within the TTL.
+func (c *PublishedAtCache) Get(key string) (publishedAt string, fresh bool, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	record, ok := c.records[key]
+	if !ok {
+		return "", false, false
+	}
+
+	return record.PublishedAt, c.now().Sub(record.ResolvedAt) < c.ttl, true
+}
+
+// Set records the date, an empty one included, and rewrites the cache file.
+func (c *PublishedAtCache) Set(key, publishedAt string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.records[key] = publishedAtRecord{PublishedAt: publishedAt, ResolvedAt: c.now().UTC()}
+
+	return c.persist()
+}
+
+func (c *PublishedAtCache) persist() error {
+	if err := writeJSONFile(c.path, c.records); err != nil {
+		return fmt.Errorf("write published_at cache: %w", err)
+	}
+
+	return nil
+}
+
+// writeJSONFile replaces the file in one go, so a crash never leaves half a cache behind.
+func writeJSONFile(path string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".tmp-*-"+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Close()
+	} else {
+		_ = tmp.Close()
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+
+	return err
+}
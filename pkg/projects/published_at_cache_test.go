@@ -0,0 +1,63 @@
+package projects
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPublishedAtCachePersistsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "projects", "published_at.json")
+	now := time.Date(2026, 3, 18, 0, 0, 0, 0, time.UTC)
+
+	cache := NewPublishedAtCache(path, time.Hour)
+	cache.now = func() time.Time { return now }
+
+	if err := cache.Set("example/project", "2024-01-02T03:04:05Z"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	if err := cache.Set("example/empty", ""); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	reloaded := NewPublishedAtCache(path, time.Hour)
+	reloaded.now = func() time.Time { return now.Add(30 * time.Minute) }
+
+	if got, fresh, ok := reloaded.Get("example/project"); !ok || !fresh || got != "2024-01-02T03:04:05Z" {
+		t.Fatalf("expected the persisted date, got %q fresh=%t ok=%t", got, fresh, ok)
+	}
+
+	if got, _, ok := reloaded.Get("example/empty"); !ok || got != "" {
+		t.Fatalf("expected empty results to be remembered too, got %q ok=%t", got, ok)
+	}
+
+	reloaded.now = func() time.Time { return now.Add(2 * time.Hour) }
+
+	if got, fresh, ok := reloaded.Get("example/project"); !ok || fresh || got != "2024-01-02T03:04:05Z" {
+		t.Fatalf("expected the expired date to be handed out as stale, got %q fresh=%t ok=%t", got, fresh, ok)
+	}
+
+	if _, _, ok := reloaded.Get("example/unknown"); ok {
+		t.Fatalf("expected unknown keys to miss")
+	}
+}
+
+func TestPublishedAtCacheStartsColdOnACorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "published_at.json")
+
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	cache := NewPublishedAtCache(path, time.Hour)
+
+	if _, _, ok := cache.Get("example/project"); ok {
+		t.Fatalf("expected an empty cache")
+	}
+
+	if err := cache.Set("example/project", "2024-01-02T03:04:05Z"); err != nil {
+		t.Fatalf("expected the corrupt file to be replaced, got %v", err)
+	}
+}
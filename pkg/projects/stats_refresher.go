@@ -0,0 +1,248 @@
+package projects
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oullin/handler/payload"
+)
+
+// StatsRefreshInterval is how often every known repository is revalidated. Unchanged
+// repositories answer with a 304, which GitHub does not count against the rate limit.
+const StatsRefreshInterval = time.Hour
+
+// StatsLookup returns the stats known for a project, if any, without waiting.
+type StatsLookup func(project payload.ProjectsData) (*payload.ProjectStats, bool)
+
+// StatsRefresher keeps the repository stats of open-source projects up to date. Every
+// known repository is revalidated on a schedule with conditional requests, and one seen
+// for the first time is fetched straight away, all on a worker goroutine so the requests
+// asking for stats never wait on a code host.
+type StatsRefresher struct {
+	registry *Registry
+	store    *StatsStore
+	now      func() time.Time
+	queue    chan string
+	mu       sync.Mutex
+	tracked  map[string]bool
+	pending  map[string]bool
+}
+
+func NewStatsRefresher(registry *Registry, store *StatsStore) *StatsRefresher {
+	s := &StatsRefresher{
+		registry: registry,
+		store:    store,
+		now:      time.Now,
+		queue:    make(chan string, backgroundQueueSize),
+		tracked:  make(map[string]bool),
+		pending:  make(map[string]bool),
+	}
+
+	// Repositories no registered host serves any more are left alone.
+	for _, key := range store.Keys() {
+		if _, _, ok := registry.ParseKey(key); ok {
+			s.tracked[key] = true
+		}
+	}
+
+	return s
+}
+
+// Start runs the worker and revalidates every known repository once per interval.
+func (s *StatsRefresher) Start(interval time.Duration) {
+	go s.work()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			s.mu.Lock()
+			keys := make([]string, 0, len(s.tracked))
+			for key := range s.tracked {
+				keys = append(keys, key)
+			}
+			s.mu.Unlock()
+
+			slices.Sort(keys)
+
+			for _, key := range keys {
+				s.schedule(key)
+			}
+		}
+	}()
+}
+
+// Lookup returns the stored stats of an open-source project on a registered host and
+// keeps it on the refresh schedule. A project without stats yet is fetched as soon as the
+// worker is free.
+func (s *StatsRefresher) Lookup(project payload.ProjectsData) (*payload.ProjectStats, bool) {
+	if !project.IsOpenSource {
+		return nil, false
+	}
+
+	repo, _, ok := s.registry.Parse(project.URL)
+	if !ok {
+		return nil, false
+	}
+
+	key := repo.Key()
+
+	s.mu.Lock()
+	s.tracked[key] = true
+	s.mu.Unlock()
+
+	record, ok := s.store.Get(key)
+	if !ok {
+		s.schedule(key)
+
+		return nil, false
+	}
+
+	stats := record.Stats
+
+	return &stats, true
+}
+
+func (s *StatsRefresher) schedule(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pending[key] {
+		return
+	}
+
+	select {
+	case s.queue <- key:
+		s.pending[key] = true
+	default:
+		// The queue is full; the next tick or request schedules it again.
+	}
+}
+
+func (s *StatsRefresher) work() {
+	for key := range s.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundResolveTimeout)
+		err := s.Refresh(ctx, key)
+		cancel()
+
+		s.mu.Lock()
+		delete(s.pending, key)
+		s.mu.Unlock()
+
+		if err != nil {
+			slog.Warn("projects: could not refresh repository stats", "repository", key, "error", err)
+		}
+	}
+}
+
+// Refresh revalidates the stats of one repository, keyed by its Repository.Key. Nothing
+// is stored when the host fails, so the last good stats stay.
+func (s *StatsRefresher) Refresh(ctx context.Context, key string) error {
+	repo, provider, ok := s.registry.ParseKey(key)
+	if !ok {
+		return fmt.Errorf("no provider for repository %q", key)
+	}
+
+	previous, _ := s.store.Get(key)
+	previous.ETags = maps.Clone(previous.ETags)
+
+	if previous.ETags == nil {
+		previous.ETags = make(map[string]string)
+	}
+
+	record, err := provider.Stats(ctx, repo, previous)
+	if err != nil {
+		return err
+	}
+
+	if record.Stats.Topics == nil {
+		record.Stats.Topics = []string{}
+	}
+
+	if record.Stats.Languages == nil {
+		record.Stats.Languages = []payload.ProjectLanguage{}
+	}
+
+	record.RefreshedAt = s.now().UTC()
+
+	return s.store.Set(key, record)
+}
+
+// languageBreakdown turns bytes per language into a list, largest first, with each
+// language's share of the code rounded to one decimal.
+func languageBreakdown(languages map[string]int64) []payload.ProjectLanguage {
+	var total int64
+	for _, bytes := range languages {
+		total += bytes
+	}
+
+	breakdown := make([]payload.ProjectLanguage, 0, len(languages))
+	for name, bytes := range languages {
+		percent := 0.0
+		if total > 0 {
+			percent = roundPercent(float64(bytes) * 100 / float64(total))
+		}
+
+		breakdown = append(breakdown, payload.ProjectLanguage{Name: name, Bytes: bytes, Percent: percent})
+	}
+
+	sortLanguages(breakdown)
+
+	return breakdown
+}
+
+// languageShares is languageBreakdown for hosts that only report percentages.
+func languageShares(languages map[string]float64) []payload.ProjectLanguage {
+	breakdown := make([]payload.ProjectLanguage, 0, len(languages))
+	for name, percent := range languages {
+		breakdown = append(breakdown, payload.ProjectLanguage{Name: name, Percent: roundPercent(percent)})
+	}
+
+	sortLanguages(breakdown)
+
+	return breakdown
+}
+
+func sortLanguages(breakdown []payload.ProjectLanguage) {
+	slices.SortFunc(breakdown, func(a, b payload.ProjectLanguage) int {
+		if order := cmp.Compare(b.Percent, a.Percent); order != 0 {
+			return order
+		}
+
+		return strings.Compare(a.Name, b.Name)
+	})
+}
+
+func roundPercent(percent float64) float64 {
+	return math.Round(percent*10) / 10
+}
+
+func normalizeTime(value string) string {
+	if parsed, ok := ParsePublishedAt(value); ok {
+		return parsed.UTC().Format(time.RFC3339)
+	}
+
+	return strings.TrimSpace(value)
+}
+
+// AttachStats sets the stats of every project the lookup knows about.
+func AttachStats(items []payload.ProjectsData, lookup StatsLookup) {
+	if lookup == nil {
+		return
+	}
+
+	for i := range items {
+		if stats, ok := lookup(items[i]); ok {
+			items[i].Stats = stats
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package projects
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/oullin/handler/payload"
+	"github.com/oullin/pkg/portal"
+)
+
+func testFetcher() *portal.Fetcher {
+	return portal.NewFetcher(portal.FetchPolicy{AllowPrivate: true})
+}
+
+// newTestStatsRefresher serves github.com from the stand-in API.
+func newTestStatsRefresher(t *testing.T, apiBaseURL string) *StatsRefresher {
+	t.Helper()
+
+	registry := NewRegistry()
+	registry.Register("github.com", &githubProvider{fetcher: testFetcher(), apiBaseURL: apiBaseURL})
+
+	refresher := NewStatsRefresher(registry, NewStatsStore(filepath.Join(t.TempDir(), "stats.json")))
+	refresher.now = func() time.Time { return time.Date(2026, 3, 18, 0, 0, 0, 0, time.UTC) }
+
+	return refresher
+}
+
+func TestStatsRefresherRevalidatesWithETags(t *testing.T) {
+	var stars atomic.Int32
+	var fullResponses atomic.Int32
+
+	stars.Store(10)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var etag, body string
+
+		switch r.URL.Path {
+		case "/repos/example/project":
+			etag = `"repo-` + strconv.Itoa(int(stars.Load())) + `"`
+			body = `{"stargazers_count":` + strconv.Itoa(int(stars.Load())) + `,"forks_count":3,"topics":["go","api"],` +
+				`"pushed_at":"2026-03-01T10:00:00Z","license":{"spdx_id":"MIT"}}`
+		case "/repos/example/project/languages":
+			etag = `"languages"`
+			body = `{"Go":750,"Shell":250}`
+		default:
+			t.Fatalf("unexpected request path %q", r.URL.Path)
+		}
+
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		fullResponses.Add(1)
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	refresher := newTestStatsRefresher(t, srv.URL)
+	project := payload.ProjectsData{IsOpenSource: true, URL: "https://github.com/Example/Project"}
+
+	if err := refresher.Refresh(context.Background(), "github.com/example/project"); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	stats, ok := refresher.Lookup(project)
+	if !ok {
+		t.Fatalf("expected stats after the first refresh")
+	}
+
+	if stats.Stars != 10 || stats.Forks != 3 || stats.License != "MIT" || stats.PushedAt != "2026-03-01T10:00:00Z" {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	if len(stats.Topics) != 2 || len(stats.Languages) != 2 || stats.Languages[0] != (payload.ProjectLanguage{Name: "Go", Bytes: 750, Percent: 75}) {
+		t.Fatalf("unexpected topics or languages %+v", stats)
+	}
+
+	// Nothing changed, so both endpoints answer 304 and the stored stats stay.
+	if err := refresher.Refresh(context.Background(), "github.com/example/project"); err != nil {
+		t.Fatalf("second refresh: %v", err)
+	}
+
+	if fullResponses.Load() != 2 {
+		t.Fatalf("expected the second refresh to be revalidated only, got %d full responses", fullResponses.Load())
+	}
+
+	if stats, _ = refresher.Lookup(project); stats.Stars != 10 || len(stats.Languages) != 2 {
+		t.Fatalf("expected the 304s to keep the stats, got %+v", stats)
+	}
+
+	// A new star changes the repository ETag; the languages are still revalidated only.
+	stars.Store(11)
+
+	if err := refresher.Refresh(context.Background(), "github.com/example/project"); err != nil {
+		t.Fatalf("third refresh: %v", err)
+	}
+
+	if fullResponses.Load() != 3 {
+		t.Fatalf("expected only the repository to be fetched again, got %d full responses", fullResponses.Load())
+	}
+
+	if stats, _ = refresher.Lookup(project); stats.Stars != 11 || len(stats.Languages) != 2 {
+		t.Fatalf("expected the new star count, got %+v", stats)
+	}
+}
+
+func TestStatsRefresherKeepsStatsWhenGitHubFails(t *testing.T) {
+	var failing atomic.Bool
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		if r.URL.Path == "/repos/example/project/languages" {
+			_, _ = w.Write([]byte(`{}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"stargazers_count":4,"license":{"spdx_id":"NOASSERTION"}}`))
+	}))
+	defer srv.Close()
+
+	refresher := newTestStatsRefresher(t, srv.URL)
+
+	if err := refresher.Refresh(context.Background(), "github.com/example/project"); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	failing.Store(true)
+
+	if err := refresher.Refresh(context.Background(), "github.com/example/project"); err == nil {
+		t.Fatalf("expected the 404 to be reported")
+	}
+
+	stats, ok := refresher.Lookup(payload.ProjectsData{IsOpenSource: true, URL: "https://github.com/example/project"})
+	if !ok || stats.Stars != 4 || stats.License != "" || stats.Topics == nil || stats.Languages == nil {
+		t.Fatalf("expected the earlier stats to survive, got %+v %t", stats, ok)
+	}
+}
+
+func TestStatsStoreSurvivesRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "projects", "stats.json")
+
+	record := StatsRecord{Stats: payload.ProjectStats{Stars: 7}, ETags: map[string]string{"https://api/repo": `"abc"`}}
+	if err := NewStatsStore(path).Set("example/project", record); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	reloaded := NewStatsStore(path)
+	if got, ok := reloaded.Get("example/project"); !ok || got.Stats.Stars != 7 || got.ETags["https://api/repo"] != `"abc"` {
+		t.Fatalf("unexpected reloaded record %+v %t", got, ok)
+	}
+
+	if keys := reloaded.Keys(); len(keys) != 1 || keys[0] != "example/project" {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+}
+
+func TestAttachStatsOnlyCoversOpenSourceGitHubProjects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/example/project/languages" {
+			_, _ = w.Write([]byte(`{"Go":1}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"stargazers_count":42}`))
+	}))
+	defer srv.Close()
+
+	refresher := newTestStatsRefresher(t, srv.URL)
+	if err := refresher.Refresh(context.Background(), "github.com/example/project"); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	items := []payload.ProjectsData{
+		{UUID: "open", IsOpenSource: true, URL: "https://github.com/example/project"},
+		{UUID: "closed", URL: "https://github.com/example/project"},
+		{UUID: "elsewhere", IsOpenSource: true, URL: "https://example.com/project"},
+	}
+
+	AttachStats(items, refresher.Lookup)
+
+	if items[0].Stats == nil || items[0].Stats.Stars != 42 || items[0].Stats.Languages[0].Percent != 100 {
+		t.Fatalf("expected stats on the open-source project, got %+v", items[0].Stats)
+	}
+
+	if items[1].Stats != nil || items[2].Stats != nil {
+		t.Fatalf("expected no stats on the others, got %+v %+v", items[1].Stats, items[2].Stats)
+	}
+}
@@ -0,0 +1,94 @@
+package projects
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/oullin/handler/payload"
+)
+
+// StatsRecord is what is kept per repository: the last stats and the ETags they came with,
+// keyed by API URL, so the next refresh can ask the host whether anything changed at all.
+type StatsRecord struct {
+	Stats       payload.ProjectStats `json:"stats"`
+	ETags       map[string]string    `json:"etags,omitempty"`
+	RefreshedAt time.Time            `json:"refreshed_at"`
+}
+
+// StatsStore keeps the repository stats in a JSON file, so a deploy serves the figures the
+// previous one fetched and only revalidates them.
+type StatsStore struct {
+	path    string
+	mu      sync.Mutex
+	records map[string]StatsRecord
+}
+
+// NewStatsStore loads the stats file, if any. A missing or unreadable file only means
+// starting cold.
+func NewStatsStore(path string) *StatsStore {
+	store := &StatsStore{
+		path:    path,
+		records: make(map[string]StatsRecord),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("projects: could not read the stats store", "path", path, "error", err)
+		}
+
+		return store
+	}
+
+	if err = json.Unmarshal(data, &store.records); err != nil {
+		slog.Warn("projects: ignoring a corrupt stats store", "path", path, "error", err)
+
+		store.records = make(map[string]StatsRecord)
+	}
+
+	return store
+}
+
+func (s *StatsStore) Get(key string) (StatsRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+
+	return record, ok
+}
+
+// Keys returns the stored repositories in order.
+func (s *StatsStore) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.records))
+	for key := range s.records {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Set records the stats of a repository and rewrites the stats file.
+func (s *StatsStore) Set(key string, record StatsRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[key] = record
+
+	if err := writeJSONFile(s.path, s.records); err != nil {
+		return fmt.Errorf("write stats store: %w", err)
+	}
+
+	return nil
+}
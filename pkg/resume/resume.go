@@ -0,0 +1,182 @@
+// Package resume turns the profile fixtures into machine-readable CVs: a JSON Resume
+// document (https://jsonresume.org/schema) and a vCard 4.0 contact card (RFC 6350).
+package resume
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/oullin/handler/listing"
+	"github.com/oullin/handler/payload"
+)
+
+// SchemaURL is the JSON Resume schema the documents are written against.
+const SchemaURL = "https://raw.githubusercontent.com/jsonresume/resume-schema/v1.0.0/schema.json"
+
+// Sources are the fixtures a resume is made of.
+type Sources struct {
+	Profile    payload.ProfileResponse
+	Experience payload.ExperienceResponse
+	Education  payload.EducationResponse
+	Links      payload.LinksResponse
+	SiteURL    string
+}
+
+type Resume struct {
+	Schema    string      `json:"$schema"`
+	Basics    Basics      `json:"basics"`
+	Work      []Work      `json:"work"`
+	Education []Education `json:"education"`
+	Skills    []Skill     `json:"skills"`
+	Meta      Meta        `json:"meta"`
+}
+
+type Basics struct {
+	Name     string    `json:"name"`
+	Label    string    `json:"label,omitempty"`
+	Email    string    `json:"email,omitempty"`
+	URL      string    `json:"url,omitempty"`
+	Profiles []Profile `json:"profiles"`
+}
+
+type Profile struct {
+	Network  string `json:"network"`
+	Username string `json:"username,omitempty"`
+	URL      string `json:"url"`
+}
+
+type Work struct {
+	Name      string `json:"name"`
+	Position  string `json:"position"`
+	Location  string `json:"location,omitempty"`
+	StartDate string `json:"startDate,omitempty"`
+	EndDate   string `json:"endDate,omitempty"`
+	Summary   string `json:"summary,omitempty"`
+}
+
+type Education struct {
+	Institution string `json:"institution"`
+	Area        string `json:"area,omitempty"`
+	StudyType   string `json:"studyType,omitempty"`
+	EndDate     string `json:"endDate,omitempty"`
+}
+
+type Skill struct {
+	Name     string   `json:"name"`
+	Level    string   `json:"level,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+type Meta struct {
+	Canonical string `json:"canonical,omitempty"`
+	Version   string `json:"version,omitempty"`
+}
+
+// New maps the fixtures onto JSON Resume, keeping their order.
+func New(sources Sources) Resume {
+	profile := sources.Profile.Data
+	siteURL := strings.TrimRight(sources.SiteURL, "/")
+
+	resume := Resume{
+		Schema: SchemaURL,
+		Basics: Basics{
+			Name:     profile.Name,
+			Label:    profile.Profession,
+			Email:    profile.Email,
+			URL:      siteURL,
+			Profiles: make([]Profile, 0, len(sources.Links.Data)),
+		},
+		Work:      make([]Work, 0, len(sources.Experience.Data)),
+		Education: make([]Education, 0, len(sources.Education.Data)),
+		Skills:    make([]Skill, 0, len(profile.Skills)),
+		Meta:      Meta{Version: sources.Profile.Version},
+	}
+
+	if siteURL != "" {
+		resume.Meta.Canonical = siteURL + "/resume.json"
+	}
+
+	for _, link := range sources.Links.Data {
+		resume.Basics.Profiles = append(resume.Basics.Profiles, Profile{
+			Network:  link.Name,
+			Username: strings.TrimPrefix(link.Handle, "@"),
+			URL:      link.URL,
+		})
+	}
+
+	for _, item := range sources.Experience.Data {
+		resume.Work = append(resume.Work, Work{
+			Name:      item.Company,
+			Position:  item.Position,
+			Location:  JoinNonEmpty(", ", item.City, item.Country),
+			StartDate: listing.ISODate(item.StartDate),
+			EndDate:   listing.ISODate(item.EndDate),
+			Summary:   strings.Join(Paragraphs(item.Summary), "\n\n"),
+		})
+	}
+
+	for _, item := range sources.Education.Data {
+		resume.Education = append(resume.Education, Education{
+			Institution: item.School,
+			Area:        item.Field,
+			StudyType:   item.Degree,
+			EndDate:     listing.ISODate(item.GraduatedAt),
+		})
+	}
+
+	for _, skill := range profile.Skills {
+		resume.Skills = append(resume.Skills, Skill{
+			Name:  skill.Item,
+			Level: skillLevel(skill.Percentage),
+		})
+	}
+
+	return resume
+}
+
+// skillLevel names the profile percentage the way JSON Resume levels are usually written.
+func skillLevel(percentage int) string {
+	switch {
+	case percentage >= 90:
+		return "Expert"
+	case percentage >= 75:
+		return "Advanced"
+	case percentage >= 50:
+		return "Intermediate"
+	case percentage > 0:
+		return "Beginner"
+	default:
+		return ""
+	}
+}
+
+// Paragraphs splits the text on the line breaks the fixtures use for the website, e.g.
+// <br/>, dropping blank ones.
+func Paragraphs(text string) []string {
+	for _, br := range []string{"<br/>", "<br />", "<br>"} {
+		text = strings.ReplaceAll(text, br, "\n")
+	}
+
+	var out []string
+
+	for _, paragraph := range strings.Split(text, "\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			out = append(out, paragraph)
+		}
+	}
+
+	return out
+}
+
+// JoinNonEmpty skips blank and repeated values, so Singapore, Singapore reads Singapore.
+func JoinNonEmpty(separator string, values ...string) string {
+	var parts []string
+
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" && !slices.ContainsFunc(parts, func(part string) bool { return strings.EqualFold(part, value) }) {
+			parts = append(parts, value)
+		}
+	}
+
+	return strings.Join(parts, separator)
+}
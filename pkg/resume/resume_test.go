@@ -0,0 +1,123 @@
+package resume_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/oullin/handler/payload"
+	"github.com/oullin/pkg/resume"
+)
+
+func sources() resume.Sources {
+	return resume.Sources{
+		SiteURL: "https://oullin.io/",
+		Profile: payload.ProfileResponse{
+			Version: "1.0.2",
+			Data: payload.ProfileDataResponse{
+				Nickname:   "gus",
+				Name:       "Gustavo Ocanto",
+				Email:      "gus@oullin.io",
+				Profession: "Founder, Oullin; Engineer",
+				Skills: []payload.ProfileSkillsResponse{
+					{Item: "Go", Percentage: 95},
+					{Item: "PHP", Percentage: 60},
+				},
+			},
+		},
+		Experience: payload.ExperienceResponse{Data: []payload.ExperienceData{
+			{Company: "Silverlake", Position: "Technical Lead", StartDate: "June, 2025", EndDate: "", City: "Singapore", Country: "Singapore", Summary: "Led a squad.<br/><br/>Shipped."},
+		}},
+		Education: payload.EducationResponse{Data: []payload.EducationData{
+			{School: "Universidad Alejandro de Humboldt", Degree: "Bachelor's degree", Field: "Computer Science", GraduatedAt: "2012"},
+		}},
+		Links: payload.LinksResponse{Data: []payload.LinksData{
+			{Name: "github", Handle: "@oullin", URL: "https://github.com/oullin"},
+		}},
+	}
+}
+
+func TestNewMapsTheFixturesOntoJSONResume(t *testing.T) {
+	doc := resume.New(sources())
+
+	if doc.Schema != resume.SchemaURL || doc.Basics.Name != "Gustavo Ocanto" || doc.Basics.URL != "https://oullin.io" {
+		t.Fatalf("unexpected basics %+v", doc.Basics)
+	}
+
+	if len(doc.Basics.Profiles) != 1 || doc.Basics.Profiles[0].Username != "oullin" || doc.Basics.Profiles[0].Network != "github" {
+		t.Fatalf("unexpected profiles %+v", doc.Basics.Profiles)
+	}
+
+	work := doc.Work[0]
+	if work.StartDate != "2025-06" || work.EndDate != "" || work.Location != "Singapore" || work.Summary != "Led a squad.\n\nShipped." {
+		t.Fatalf("unexpected work %+v", work)
+	}
+
+	if doc.Education[0].EndDate != "2012" || doc.Education[0].StudyType != "Bachelor's degree" || doc.Education[0].Area != "Computer Science" {
+		t.Fatalf("unexpected education %+v", doc.Education[0])
+	}
+
+	if doc.Skills[0].Level != "Expert" || doc.Skills[1].Level != "Intermediate" {
+		t.Fatalf("unexpected skills %+v", doc.Skills)
+	}
+
+	if doc.Meta.Version != "1.0.2" || doc.Meta.Canonical != "https://oullin.io/resume.json" {
+		t.Fatalf("unexpected meta %+v", doc.Meta)
+	}
+}
+
+func TestVCardEscapesAndFoldsLines(t *testing.T) {
+	data := sources()
+	data.Links.Data = append(data.Links.Data, payload.LinksData{Name: "blog", URL: "https://oullin.io/posts/" + strings.Repeat("a", 80)})
+
+	raw, err := resume.VCard(data)
+	if err != nil {
+		t.Fatalf("vcard: %v", err)
+	}
+
+	card := string(raw)
+
+	for _, line := range []string{
+		"BEGIN:VCARD\r\nVERSION:4.0\r\n",
+		"FN:Gustavo Ocanto\r\n",
+		"N:Ocanto;Gustavo;;;\r\n",
+		"TITLE:Founder\\, Oullin\\; Engineer\r\n",
+		"EMAIL;TYPE=work:gus@oullin.io\r\n",
+		"URL:https://oullin.io\r\n",
+		"SOCIALPROFILE;SERVICE-TYPE=github:https://github.com/oullin\r\n",
+		"END:VCARD\r\n",
+	} {
+		if !strings.Contains(card, line) {
+			t.Fatalf("expected %q in\n%s", line, card)
+		}
+	}
+
+	for _, line := range strings.Split(card, "\r\n") {
+		if len(line) > 75 {
+			t.Fatalf("expected lines of at most 75 octets, got %q", line)
+		}
+	}
+
+	if !strings.Contains(card, "\r\n a") {
+		t.Fatalf("expected the long link to be folded")
+	}
+}
+
+func TestVCardNeedsAName(t *testing.T) {
+	data := sources()
+	data.Profile.Data.Name = " "
+
+	if card, err := resume.VCard(data); !errors.Is(err, resume.ErrNoName) || card != nil {
+		t.Fatalf("expected a card without FN to be refused, got %q %v", card, err)
+	}
+}
+
+func TestParagraphsAndJoinNonEmpty(t *testing.T) {
+	if got := resume.Paragraphs(" Led a squad.<br/><br />Shipped.<br>\n "); strings.Join(got, "|") != "Led a squad.|Shipped." {
+		t.Fatalf("unexpected paragraphs %q", got)
+	}
+
+	if got := resume.JoinNonEmpty(", ", "Singapore", " ", "singapore", "Remote"); got != "Singapore, Remote" {
+		t.Fatalf("unexpected join %q", got)
+	}
+}
@@ -0,0 +1,109 @@
+package resume
+
+import (
+	"errors"
+	"strings"
+)
+
+// VCardContentType is the media type of the card VCard writes.
+const VCardContentType = "text/vcard; charset=utf-8"
+
+// vCardLineOctets is where RFC 6350 asks long lines to be folded.
+const vCardLineOctets = 75
+
+// ErrNoName is returned for a profile without a name: RFC 6350 requires every card to
+// have an FN.
+var ErrNoName = errors.New("resume: a vCard needs the profile name")
+
+// VCard writes the contact card of the profile: name, nickname, title, email, website and
+// one SOCIALPROFILE (RFC 9554) per link. The card holds no revision date, so it only
+// changes when the fixtures do.
+func VCard(sources Sources) ([]byte, error) {
+	profile := sources.Profile.Data
+
+	if strings.TrimSpace(profile.Name) == "" {
+		return nil, ErrNoName
+	}
+
+	given, family := splitName(profile.Name)
+
+	var card strings.Builder
+
+	write := func(name, value string) {
+		if strings.TrimSpace(value) == "" {
+			return
+		}
+
+		card.WriteString(fold(name + ":" + value))
+		card.WriteString("\r\n")
+	}
+
+	card.WriteString("BEGIN:VCARD\r\nVERSION:4.0\r\n")
+
+	write("FN", escape(profile.Name))
+	write("N", escape(family)+";"+escape(given)+";;;")
+	write("NICKNAME", escape(profile.Nickname))
+	write("TITLE", escape(profile.Profession))
+	write("EMAIL;TYPE=work", escape(profile.Email))
+	write("URL", strings.TrimRight(sources.SiteURL, "/"))
+
+	for _, link := range sources.Links.Data {
+		write("SOCIALPROFILE;SERVICE-TYPE="+parameter(link.Name), link.URL)
+	}
+
+	card.WriteString("END:VCARD\r\n")
+
+	return []byte(card.String()), nil
+}
+
+// splitName takes the last word of the name as the family name.
+func splitName(name string) (given, family string) {
+	words := strings.Fields(name)
+
+	if len(words) < 2 {
+		return name, ""
+	}
+
+	return strings.Join(words[:len(words)-1], " "), words[len(words)-1]
+}
+
+// escape escapes a text value as RFC 6350 section 3.4 asks.
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`).Replace(strings.TrimSpace(value))
+}
+
+// parameter keeps a parameter value to the characters it may hold unquoted.
+func parameter(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ';' || r == ':' || r == ',' || r == '"' || r < ' ' {
+			return -1
+		}
+
+		return r
+	}, strings.TrimSpace(value))
+}
+
+// fold splits a content line into lines of at most 75 octets, each continuation starting
+// with a space, without cutting a UTF-8 character in two.
+func fold(line string) string {
+	if len(line) <= vCardLineOctets {
+		return line
+	}
+
+	var folded strings.Builder
+	width := 0
+
+	for _, r := range line {
+		size := len(string(r))
+
+		if width+size > vCardLineOctets {
+			folded.WriteString("\r\n ")
+			width = 1
+		}
+
+		folded.WriteRune(r)
+		width += size
+	}
+
+	return folded.String()
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/oullin/metal/env"
+)
+
+// Area keeps the objects of one part of the app apart from the others: a directory on disk
+// for the local driver, a key prefix inside the bucket for S3.
+type Area struct {
+	Dir    string // Root directory for the local driver.
+	URL    string // Public URL Dir is served from, for the local driver.
+	Prefix string // Key prefix inside the bucket, for the S3 driver.
+}
+
+func New(e env.StorageEnvironment, area Area) (Storage, error) {
+	switch e.Driver {
+	case "", env.StorageDriverLocal:
+		return NewLocal(area.Dir, area.URL), nil
+	case env.StorageDriverS3:
+		return NewS3(S3Config{
+			Endpoint:  e.Endpoint,
+			Region:    e.Region,
+			Bucket:    e.Bucket,
+			AccessKey: e.AccessKey,
+			SecretKey: e.SecretKey,
+			Prefix:    area.Prefix,
+			PublicURL: e.PublicURL,
+			PathStyle: e.PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("storage: unknown driver [%s]", e.Driver)
+	}
+}
@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Local keeps the objects as files under Root.
+type Local struct {
+	Root    string
+	BaseURL string // Public URL the files under Root are served from, e.g. "/media".
+}
+
+func NewLocal(root, baseURL string) *Local {
+	return &Local{Root: root, BaseURL: baseURL}
+}
+
+// Put writes to a temporary file first, so readers never see a partial object.
+func (l *Local) Put(_ context.Context, key string, data []byte, _ string) error {
+	file, err := l.pathFor(key)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(file)
+
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("storage: create dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("storage: create temp file: %w", err)
+	}
+
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Close()
+	} else {
+		_ = tmp.Close()
+	}
+
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o644)
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), file)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("storage: put [%s]: %w", key, err)
+	}
+
+	return nil
+}
+
+func (l *Local) Get(_ context.Context, key string) (*Object, error) {
+	file, err := l.pathFor(key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := l.stat(file, key)
+	if err != nil {
+		return nil, err
+	}
+
+	fh, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("storage: open [%s]: %w", key, err)
+	}
+
+	return &Object{ObjectInfo: info, Body: fh}, nil
+}
+
+func (l *Local) Stat(_ context.Context, key string) (ObjectInfo, error) {
+	file, err := l.pathFor(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	return l.stat(file, key)
+}
+
+func (l *Local) Delete(_ context.Context, key string) error {
+	file, err := l.pathFor(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("storage: delete [%s]: %w", key, err)
+	}
+
+	return nil
+}
+
+// List walks the deepest directory the prefix names and skips hidden files, which are
+// either in-flight writes or not ours.
+func (l *Local) List(_ context.Context, prefix string) ([]ObjectInfo, error) {
+	prefix, err := cleanPrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	start := l.Root
+	if dir := path.Dir(prefix + "x"); dir != "." {
+		start = filepath.Join(l.Root, filepath.FromSlash(dir))
+	}
+
+	var objects []ObjectInfo
+
+	err = filepath.WalkDir(start, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return fs.SkipAll
+			}
+
+			return err
+		}
+
+		if strings.HasPrefix(entry.Name(), ".") && file != start {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(l.Root, file)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, l.infoFor(key, info))
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("storage: list [%s]: %w", prefix, err)
+	}
+
+	return objects, nil
+}
+
+// SignedURL returns the public URL: local files are served by the API to everyone, so there
+// is nothing to sign and the URL does not expire.
+func (l *Local) SignedURL(_ context.Context, key string, _ time.Duration) (string, error) {
+	if _, err := CleanKey(key); err != nil {
+		return "", err
+	}
+
+	return l.URL(key), nil
+}
+
+func (l *Local) URL(key string) string {
+	cleaned, _ := CleanKey(key)
+	base := strings.TrimSuffix(l.BaseURL, "/")
+
+	if base == "" {
+		return cleaned
+	}
+
+	return base + "/" + cleaned
+}
+
+func (l *Local) pathFor(key string) (string, error) {
+	cleaned, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(l.Root, filepath.FromSlash(cleaned)), nil
+}
+
+func (l *Local) stat(file, key string) (ObjectInfo, error) {
+	info, err := os.Stat(file)
+
+	if errors.Is(err, os.ErrNotExist) || (err == nil && info.IsDir()) {
+		return ObjectInfo{}, ErrNotFound
+	}
+
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("storage: stat [%s]: %w", key, err)
+	}
+
+	cleaned, _ := CleanKey(key)
+
+	return l.infoFor(cleaned, info), nil
+}
+
+func (l *Local) infoFor(key string, info fs.FileInfo) ObjectInfo {
+	return ObjectInfo{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: contentTypeFor(key),
+		ModTime:     info.ModTime(),
+	}
+}
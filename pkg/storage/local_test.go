@@ -0,0 +1,133 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oullin/pkg/storage"
+)
+
+func TestLocalPutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := storage.NewLocal(root, "/media")
+
+	if err := store.Put(ctx, "uploads/a.png", []byte("png"), "image/png"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(root, "uploads", "a.png")); err != nil || string(data) != "png" {
+		t.Fatalf("expected the file on disk, got %q %v", data, err)
+	}
+
+	object, err := store.Get(ctx, "uploads/a.png")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+
+	body, _ := io.ReadAll(object.Body)
+	_ = object.Body.Close()
+
+	if string(body) != "png" || object.Size != 3 || object.ContentType != "image/png" || object.Key != "uploads/a.png" {
+		t.Fatalf("unexpected object %+v %q", object.ObjectInfo, body)
+	}
+
+	if err = store.Delete(ctx, "uploads/a.png"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if err = store.Delete(ctx, "uploads/a.png"); err != nil {
+		t.Fatalf("expected deleting a missing object to succeed, got %v", err)
+	}
+
+	if _, err = store.Get(ctx, "uploads/a.png"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	if _, err = store.Stat(ctx, "uploads"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected directories to be reported as missing, got %v", err)
+	}
+}
+
+func TestLocalList(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := storage.NewLocal(root, "/media")
+
+	for _, key := range []string{"posts/hello/a.png", "posts/hello/covers/b.jpg", "posts/help/c.png", "uploads/d.png"} {
+		if err := store.Put(ctx, key, []byte("x"), ""); err != nil {
+			t.Fatalf("put %s: %v", key, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "posts", "hello", ".tmp-1"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	cases := map[string][]string{
+		"posts/hello/": {"posts/hello/a.png", "posts/hello/covers/b.jpg"},
+		"posts/hel":    {"posts/hello/a.png", "posts/hello/covers/b.jpg", "posts/help/c.png"},
+		"":             {"posts/hello/a.png", "posts/hello/covers/b.jpg", "posts/help/c.png", "uploads/d.png"},
+		"missing/":     nil,
+	}
+
+	for prefix, want := range cases {
+		objects, err := store.List(ctx, prefix)
+		if err != nil {
+			t.Fatalf("list %q: %v", prefix, err)
+		}
+
+		var got []string
+		for _, object := range objects {
+			got = append(got, object.Key)
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("list %q: expected %v, got %v", prefix, want, got)
+		}
+
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("list %q: expected %v, got %v", prefix, want, got)
+			}
+		}
+	}
+}
+
+func TestLocalRejectsEscapingKeys(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := storage.NewLocal(filepath.Join(root, "media"), "/media")
+
+	if err := store.Put(ctx, "../outside.png", []byte("x"), ""); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "outside.png")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the key to stay inside the root, got %v", err)
+	}
+
+	for _, key := range []string{"", "/", ".tmp-1", "posts/.hidden.png"} {
+		if err := store.Put(ctx, key, []byte("x"), ""); err == nil {
+			t.Fatalf("expected %q to be rejected", key)
+		}
+	}
+}
+
+func TestLocalURLs(t *testing.T) {
+	store := storage.NewLocal(t.TempDir(), "/media/")
+
+	if got := store.URL("uploads/a.png"); got != "/media/uploads/a.png" {
+		t.Fatalf("unexpected url %q", got)
+	}
+
+	signed, err := store.SignedURL(context.Background(), "uploads/a.png", time.Minute)
+	if err != nil || signed != "/media/uploads/a.png" {
+		t.Fatalf("unexpected signed url %q %v", signed, err)
+	}
+}
@@ -0,0 +1,338 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSignedURLTTL is the longest validity S3 accepts for a presigned URL.
+const maxSignedURLTTL = 7 * 24 * time.Hour
+
+type S3Config struct {
+	Endpoint  string // e.g. https://s3.eu-west-1.amazonaws.com or http://minio:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Prefix    string // Prepended to every key, so several areas can share a bucket.
+	PublicURL string // Optional CDN or public bucket URL used by URL; defaults to the object URL.
+	PathStyle bool   // Address the bucket as <endpoint>/<bucket>, as MinIO expects.
+	Client    *http.Client
+}
+
+// S3 talks to any S3-compatible object store (AWS, MinIO, R2, Spaces…) over its REST API.
+type S3 struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+	signer   sigV4Signer
+	now      func() time.Time
+}
+
+func NewS3(config S3Config) (*S3, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("s3: invalid endpoint [%s]", config.Endpoint)
+	}
+
+	if config.Bucket == "" || config.Region == "" || config.AccessKey == "" || config.SecretKey == "" {
+		return nil, errors.New("s3: the bucket, region and credentials are required")
+	}
+
+	if config.Prefix != "" {
+		prefix, err := CleanKey(config.Prefix)
+		if err != nil {
+			return nil, err
+		}
+
+		config.Prefix = prefix + "/"
+	}
+
+	client := config.Client
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+
+	return &S3{
+		config:   config,
+		endpoint: endpoint,
+		client:   client,
+		signer:   sigV4Signer{accessKey: config.AccessKey, secretKey: config.SecretKey, region: config.Region},
+		now:      time.Now,
+	}, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	target, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+
+	if contentType == "" {
+		contentType = contentTypeFor(key)
+	}
+
+	sum := sha256.Sum256(data)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.do(req, hex.EncodeToString(sum[:]))
+	if err != nil {
+		return fmt.Errorf("s3: put [%s]: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return statusError("put", key, resp)
+	}
+
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (*Object, error) {
+	resp, err := s.fetch(ctx, http.MethodGet, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Object{ObjectInfo: s.infoFrom(key, resp), Body: resp.Body}, nil
+}
+
+func (s *S3) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	resp, err := s.fetch(ctx, http.MethodHead, key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	_ = resp.Body.Close()
+
+	return s.infoFrom(key, resp), nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	target, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, target.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req, sigV4EmptyBodyHash)
+	if err != nil {
+		return fmt.Errorf("s3: delete [%s]: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		return statusError("delete", key, resp)
+	}
+
+	return nil
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string `xml:"Key"`
+		Size         int64  `xml:"Size"`
+		LastModified string `xml:"LastModified"`
+		ETag         string `xml:"ETag"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List pages through ListObjectsV2 until the listing is complete.
+func (s *S3) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	prefix, err := cleanPrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []ObjectInfo
+	token := ""
+
+	for {
+		target := s.bucketURL()
+		query := url.Values{"list-type": {"2"}, "prefix": {s.config.Prefix + prefix}}
+
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		target.RawQuery = query.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := s.do(req, sigV4EmptyBodyHash)
+		if err != nil {
+			return nil, fmt.Errorf("s3: list [%s]: %w", prefix, err)
+		}
+
+		if resp.StatusCode/100 != 2 {
+			err = statusError("list", prefix, resp)
+			_ = resp.Body.Close()
+
+			return nil, err
+		}
+
+		var page listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("s3: list [%s]: decode: %w", prefix, err)
+		}
+
+		for _, item := range page.Contents {
+			modTime, _ := time.Parse(time.RFC3339, item.LastModified)
+			key := strings.TrimPrefix(item.Key, s.config.Prefix)
+
+			objects = append(objects, ObjectInfo{
+				Key:         key,
+				Size:        item.Size,
+				ContentType: contentTypeFor(key),
+				ModTime:     modTime,
+				ETag:        item.ETag,
+			})
+		}
+
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+
+		token = page.NextContinuationToken
+	}
+}
+
+func (s *S3) SignedURL(_ context.Context, key string, ttl time.Duration) (string, error) {
+	target, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+
+	ttl = min(max(ttl, time.Second), maxSignedURLTTL)
+
+	return s.signer.presign(http.MethodGet, target, ttl, s.now()), nil
+}
+
+func (s *S3) URL(key string) string {
+	cleaned, _ := CleanKey(key)
+
+	if s.config.PublicURL != "" {
+		return strings.TrimSuffix(s.config.PublicURL, "/") + "/" + s.config.Prefix + cleaned
+	}
+
+	target, _ := s.objectURL(key)
+
+	return target.String()
+}
+
+func (s *S3) fetch(ctx context.Context, method, key string) (*http.Response, error) {
+	target, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req, sigV4EmptyBodyHash)
+	if err != nil {
+		return nil, fmt.Errorf("s3: %s [%s]: %w", strings.ToLower(method), key, err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		_ = resp.Body.Close()
+
+		return nil, ErrNotFound
+	}
+
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+
+		return nil, statusError(strings.ToLower(method), key, resp)
+	}
+
+	return resp, nil
+}
+
+func (s *S3) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	s.signer.sign(req, payloadHash, s.now())
+
+	return s.client.Do(req)
+}
+
+func (s *S3) bucketURL() *url.URL {
+	target := *s.endpoint
+
+	if s.config.PathStyle {
+		target.Path = strings.TrimSuffix(target.Path, "/") + "/" + s.config.Bucket + "/"
+	} else {
+		target.Host = s.config.Bucket + "." + target.Host
+		target.Path = strings.TrimSuffix(target.Path, "/") + "/"
+	}
+
+	return &target
+}
+
+func (s *S3) objectURL(key string) (*url.URL, error) {
+	cleaned, err := CleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	target := s.bucketURL()
+	target.Path += s.config.Prefix + cleaned
+	target.RawPath = sigV4EscapePath(target.Path)
+
+	return target, nil
+}
+
+func (s *S3) infoFrom(key string, resp *http.Response) ObjectInfo {
+	cleaned, _ := CleanKey(key)
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = contentTypeFor(cleaned)
+	}
+
+	return ObjectInfo{
+		Key:         cleaned,
+		Size:        size,
+		ContentType: contentType,
+		ModTime:     modTime,
+		ETag:        resp.Header.Get("ETag"),
+	}
+}
+
+func statusError(operation, key string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	return fmt.Errorf("s3: %s [%s]: unexpected status %d: %s", operation, key, resp.StatusCode, strings.TrimSpace(string(body)))
+}
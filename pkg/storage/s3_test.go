@@ -0,0 +1,305 @@
+package storage_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oullin/pkg/storage"
+)
+
+const fakeS3Bucket = "media"
+
+// fakeS3 is a MinIO-style stand-in: a path-style bucket kept in memory that checks every
+// request carries a SigV4 authorization and, for uploads, a matching payload hash.
+type fakeS3 struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	types    map[string]string
+	pageSize int
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	fake := &fakeS3{objects: map[string][]byte{}, types: map[string]string{}, pageSize: 2}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return fake, server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=minio/") {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/"+fakeS3Bucket+"/")
+
+	switch {
+	case r.Method == http.MethodGet && key == "" && r.URL.Query().Get("list-type") == "2":
+		f.list(w, r)
+	case r.Method == http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+
+		if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+			http.Error(w, "XAmzContentSHA256Mismatch", http.StatusBadRequest)
+			return
+		}
+
+		f.objects[key] = body
+		f.types[key] = r.Header.Get("Content-Type")
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		body, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", f.types[key])
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Header().Set("Last-Modified", time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC).Format(http.TimeFormat))
+		w.Header().Set("ETag", `"etag-`+key+`"`)
+
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(body)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	start, _ := strconv.Atoi(r.URL.Query().Get("continuation-token"))
+	end := min(start+f.pageSize, len(keys))
+
+	type content struct {
+		Key          string `xml:"Key"`
+		Size         int    `xml:"Size"`
+		LastModified string `xml:"LastModified"`
+	}
+
+	result := struct {
+		XMLName               xml.Name  `xml:"ListBucketResult"`
+		Contents              []content `xml:"Contents"`
+		IsTruncated           bool      `xml:"IsTruncated"`
+		NextContinuationToken string    `xml:"NextContinuationToken,omitempty"`
+	}{IsTruncated: end < len(keys)}
+
+	for _, key := range keys[start:end] {
+		result.Contents = append(result.Contents, content{Key: key, Size: len(f.objects[key]), LastModified: "2025-01-02T03:04:05.000Z"})
+	}
+
+	if result.IsTruncated {
+		result.NextContinuationToken = strconv.Itoa(end)
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+func newTestS3(t *testing.T, server *httptest.Server, prefix string) *storage.S3 {
+	t.Helper()
+
+	store, err := storage.NewS3(storage.S3Config{
+		Endpoint:  server.URL,
+		Region:    "us-east-1",
+		Bucket:    fakeS3Bucket,
+		AccessKey: "minio",
+		SecretKey: "minio-secret",
+		Prefix:    prefix,
+		PathStyle: true,
+		Client:    server.Client(),
+	})
+
+	if err != nil {
+		t.Fatalf("new s3: %v", err)
+	}
+
+	return store
+}
+
+func TestS3PutGetStatDelete(t *testing.T) {
+	ctx := context.Background()
+	fake, server := newFakeS3(t)
+	store := newTestS3(t, server, "media")
+
+	if err := store.Put(ctx, "uploads/a b.png", []byte("png"), "image/png"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	if _, ok := fake.objects["media/uploads/a b.png"]; !ok {
+		t.Fatalf("expected the prefixed key in the bucket, got %v", fake.objects)
+	}
+
+	object, err := store.Get(ctx, "uploads/a b.png")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+
+	body, _ := io.ReadAll(object.Body)
+	_ = object.Body.Close()
+
+	if string(body) != "png" || object.ContentType != "image/png" || object.Key != "uploads/a b.png" {
+		t.Fatalf("unexpected object %+v %q", object.ObjectInfo, body)
+	}
+
+	info, err := store.Stat(ctx, "uploads/a b.png")
+	if err != nil || info.Size != 3 || info.ModTime.IsZero() || info.ETag == "" {
+		t.Fatalf("unexpected stat %+v %v", info, err)
+	}
+
+	if err = store.Delete(ctx, "uploads/a b.png"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if _, err = store.Get(ctx, "uploads/a b.png"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	if _, err = store.Stat(ctx, "uploads/a b.png"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestS3ListPagesThroughResults(t *testing.T) {
+	ctx := context.Background()
+	_, server := newFakeS3(t)
+	store := newTestS3(t, server, "media")
+	other := newTestS3(t, server, "seo")
+
+	for _, key := range []string{"posts/a/1.png", "posts/a/2.png", "posts/b/3.png", "uploads/4.png"} {
+		if err := store.Put(ctx, key, []byte("x"), ""); err != nil {
+			t.Fatalf("put %s: %v", key, err)
+		}
+	}
+
+	if err := other.Put(ctx, "posts/a/elsewhere.png", []byte("x"), ""); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	objects, err := store.List(ctx, "posts/")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+
+	var keys []string
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+
+	if !slices.Equal(keys, []string{"posts/a/1.png", "posts/a/2.png", "posts/b/3.png"}) {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+
+	if objects[0].Size != 1 || objects[0].ModTime.IsZero() || objects[0].ContentType != "image/png" {
+		t.Fatalf("unexpected object info %+v", objects[0])
+	}
+}
+
+func TestS3RejectsBadCredentials(t *testing.T) {
+	_, server := newFakeS3(t)
+
+	store, err := storage.NewS3(storage.S3Config{
+		Endpoint:  server.URL,
+		Region:    "us-east-1",
+		Bucket:    fakeS3Bucket,
+		AccessKey: "someone-else",
+		SecretKey: "secret",
+		PathStyle: true,
+		Client:    server.Client(),
+	})
+
+	if err != nil {
+		t.Fatalf("new s3: %v", err)
+	}
+
+	err = store.Put(context.Background(), "a.png", []byte("x"), "")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected a 403 error, got %v", err)
+	}
+}
+
+func TestS3URLs(t *testing.T) {
+	_, server := newFakeS3(t)
+	store := newTestS3(t, server, "media")
+
+	if got := store.URL("uploads/a.png"); got != server.URL+"/media/media/uploads/a.png" {
+		t.Fatalf("unexpected url %q", got)
+	}
+
+	signed, err := store.SignedURL(context.Background(), "uploads/a.png", time.Hour)
+	if err != nil {
+		t.Fatalf("signed url: %v", err)
+	}
+
+	parsed, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	query := parsed.Query()
+	if parsed.Path != "/media/media/uploads/a.png" || query.Get("X-Amz-Expires") != "3600" || query.Get("X-Amz-Signature") == "" {
+		t.Fatalf("unexpected signed url %s", signed)
+	}
+
+	public, err := storage.NewS3(storage.S3Config{
+		Endpoint:  "https://s3.example.com",
+		Region:    "auto",
+		Bucket:    "assets",
+		AccessKey: "key",
+		SecretKey: "secret",
+		PublicURL: "https://cdn.example.com/",
+	})
+
+	if err != nil {
+		t.Fatalf("new s3: %v", err)
+	}
+
+	if got := public.URL("seo/a.png"); got != "https://cdn.example.com/seo/a.png" {
+		t.Fatalf("unexpected public url %q", got)
+	}
+}
+
+func TestNewS3ValidatesTheConfig(t *testing.T) {
+	cases := []storage.S3Config{
+		{Endpoint: "ftp://example.com", Region: "r", Bucket: "b", AccessKey: "a", SecretKey: "s"},
+		{Endpoint: "https://example.com", Region: "r", AccessKey: "a", SecretKey: "s"},
+		{Endpoint: "https://example.com", Region: "r", Bucket: "b"},
+	}
+
+	for _, config := range cases {
+		if _, err := storage.NewS3(config); err == nil {
+			t.Fatalf("expected %+v to be rejected", config)
+		}
+	}
+}
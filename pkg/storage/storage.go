@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by Get and Stat when the key holds no object.
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage is where media, SEO images and avatars are kept. Keys are slash-separated paths
+// relative to the root of the storage, e.g. "uploads/3f2a….png".
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (*Object, error)
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	// Delete succeeds when the object does not exist.
+	Delete(ctx context.Context, key string) error
+	// List returns the objects whose key starts with prefix, sorted by key.
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+	// SignedURL grants temporary read access to a private object.
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+	// URL is the public address of the object.
+	URL(key string) string
+}
+
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+	ETag        string
+}
+
+// Object is an open object; the caller closes Body.
+type Object struct {
+	ObjectInfo
+	Body io.ReadCloser
+}
+
+// CleanKey normalises a key and refuses the ones that would escape the root once cleaned,
+// or that name a hidden file; hidden names are reserved for in-flight writes.
+func CleanKey(key string) (string, error) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(key, "\\", "/")), "/")
+
+	if cleaned == "" {
+		return "", fmt.Errorf("storage: invalid key [%s]", key)
+	}
+
+	for _, segment := range strings.Split(cleaned, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return "", fmt.Errorf("storage: invalid key [%s]", key)
+		}
+	}
+
+	return cleaned, nil
+}
+
+// cleanPrefix is CleanKey for List prefixes, which may be empty and keep their trailing slash.
+func cleanPrefix(prefix string) (string, error) {
+	if strings.TrimSpace(prefix) == "" {
+		return "", nil
+	}
+
+	cleaned, err := CleanKey(prefix)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.HasSuffix(prefix, "/") {
+		cleaned += "/"
+	}
+
+	return cleaned, nil
+}
+
+func contentTypeFor(key string) string {
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		return contentType
+	}
+
+	return "application/octet-stream"
+}